@@ -0,0 +1,234 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kmscmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/storagecmd"
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// db type flag
+	dbTypeFlagName  = "db-type"
+	dbTypeEnvKey    = "ARIESD_DB_TYPE"
+	dbTypeFlagUsage = "Type of the database: leveldb (default), bolt or couchdb." +
+		" Alternatively, this can be set with the following environment variable: " + dbTypeEnvKey
+
+	// db path flag
+	dbPathFlagName      = "db-path"
+	dbPathEnvKey        = "ARIESD_DB_PATH"
+	dbPathFlagShorthand = "d"
+	dbPathFlagUsage     = "Path to database, the URL of the server for couchdb." +
+		" Alternatively, this can be set with the following environment variable: " + dbPathEnvKey
+
+	// master key URI flag
+	masterKeyURIFlagName  = "master-key-uri"
+	masterKeyURIEnvKey    = "ARIESD_MASTER_KEY_URI"
+	masterKeyURIFlagUsage = "URI of the master key of the keys (optional). Defaults to " + defaultMasterKeyURI + "," +
+		" the master key URI of the agent." +
+		" Alternatively, this can be set with the following environment variable: " + masterKeyURIEnvKey
+
+	// master key file flag
+	masterKeyFileFlagName  = "master-key-file"
+	masterKeyFileEnvKey    = "ARIESD_MASTER_KEY_FILE"
+	masterKeyFileFlagUsage = "Path to the file of the current master key of the keys (optional)." +
+		" The keys are not encrypted if not set." +
+		" Alternatively, this can be set with the following environment variable: " + masterKeyFileEnvKey
+
+	// master key passphrase flag
+	masterKeyPassphraseFlagName  = "master-key-passphrase"
+	masterKeyPassphraseEnvKey    = "ARIESD_MASTER_KEY_PASSPHRASE" // nolint:gosec
+	masterKeyPassphraseFlagUsage = "Passphrase protecting the current master key file (optional)." +
+		" Alternatively, this can be set with the following environment variable: " + masterKeyPassphraseEnvKey
+
+	// new master key file flag
+	newMasterKeyFileFlagName  = "new-master-key-file"
+	newMasterKeyFileEnvKey    = "ARIESD_NEW_MASTER_KEY_FILE"
+	newMasterKeyFileFlagUsage = "Path to the file of the new master key of the keys." +
+		" A new random master key is created in this file if it doesn't exist." +
+		" Alternatively, this can be set with the following environment variable: " + newMasterKeyFileEnvKey
+
+	// new master key passphrase flag
+	newMasterKeyPassphraseFlagName  = "new-master-key-passphrase"
+	newMasterKeyPassphraseEnvKey    = "ARIESD_NEW_MASTER_KEY_PASSPHRASE" // nolint:gosec
+	newMasterKeyPassphraseFlagUsage = "Passphrase protecting the new master key file (optional)." +
+		" Alternatively, this can be set with the following environment variable: " + newMasterKeyPassphraseEnvKey
+
+	// defaultMasterKeyURI is the master key URI of the keys of the agent
+	defaultMasterKeyURI = "local-lock://default/master/key/"
+)
+
+var logger = log.New("aries-framework/agent-rest")
+
+type parameters struct {
+	dbType, dbPath, masterKeyURI             string
+	masterKeyFile, masterKeyPassphrase       string
+	newMasterKeyFile, newMasterKeyPassphrase string
+}
+
+// Cmd returns the Cobra kms command, with its rotate-master-key and recovery-shares subcommands.
+func Cmd() (*cobra.Command, error) {
+	kmsCmd := &cobra.Command{
+		Use:   "kms",
+		Short: "Manage the agent keys",
+		Long:  `Manage the keys stored by the KMS of an agent`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	rotateCmd := createRotateMasterKeyCmd()
+	createFlags(rotateCmd)
+
+	recoveryCmd := createRecoverySharesCmd()
+	createRecoveryFlags(recoveryCmd)
+
+	kmsCmd.AddCommand(rotateCmd, recoveryCmd)
+
+	return kmsCmd, nil
+}
+
+func createRotateMasterKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-master-key",
+		Short: "Rotate the master key of the agent keys",
+		Long: `Re-encrypt the keys stored by the KMS of an agent with a new master key. ` +
+			`An interrupted rotation is completed by running the command again with the same parameters, ` +
+			`the agent must then be started with the new master key.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := getParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			return rotateMasterKey(params)
+		},
+	}
+}
+
+func createFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(dbTypeFlagName, "", "", dbTypeFlagUsage)
+	cmd.Flags().StringP(dbPathFlagName, dbPathFlagShorthand, "", dbPathFlagUsage)
+	cmd.Flags().StringP(masterKeyURIFlagName, "", "", masterKeyURIFlagUsage)
+	cmd.Flags().StringP(masterKeyFileFlagName, "", "", masterKeyFileFlagUsage)
+	cmd.Flags().StringP(masterKeyPassphraseFlagName, "", "", masterKeyPassphraseFlagUsage)
+	cmd.Flags().StringP(newMasterKeyFileFlagName, "", "", newMasterKeyFileFlagUsage)
+	cmd.Flags().StringP(newMasterKeyPassphraseFlagName, "", "", newMasterKeyPassphraseFlagUsage)
+}
+
+func getParameters(cmd *cobra.Command) (*parameters, error) {
+	params := &parameters{}
+
+	for _, v := range []struct {
+		value            *string
+		flagName, envKey string
+		isOptional       bool
+	}{
+		{&params.dbType, dbTypeFlagName, dbTypeEnvKey, true},
+		{&params.dbPath, dbPathFlagName, dbPathEnvKey, false},
+		{&params.masterKeyURI, masterKeyURIFlagName, masterKeyURIEnvKey, true},
+		{&params.masterKeyFile, masterKeyFileFlagName, masterKeyFileEnvKey, true},
+		{&params.masterKeyPassphrase, masterKeyPassphraseFlagName, masterKeyPassphraseEnvKey, true},
+		{&params.newMasterKeyFile, newMasterKeyFileFlagName, newMasterKeyFileEnvKey, false},
+		{&params.newMasterKeyPassphrase, newMasterKeyPassphraseFlagName, newMasterKeyPassphraseEnvKey, true},
+	} {
+		value, err := startcmd.GetUserSetVar(cmd, v.flagName, v.envKey, v.isOptional)
+		if err != nil {
+			return nil, err
+		}
+
+		*v.value = value
+	}
+
+	if params.masterKeyURI == "" {
+		params.masterKeyURI = defaultMasterKeyURI
+	}
+
+	if params.newMasterKeyFile == params.masterKeyFile {
+		return nil, errors.New("the new master key file must differ from the current master key file")
+	}
+
+	return params, nil
+}
+
+func rotateMasterKey(params *parameters) error {
+	secretLock, err := startcmd.NewSecretLock(params.masterKeyFile, params.masterKeyPassphrase)
+	if err != nil {
+		return err
+	}
+
+	newSecretLock, err := newMasterKeySecretLock(params.newMasterKeyFile, params.newMasterKeyPassphrase)
+	if err != nil {
+		return err
+	}
+
+	provider, err := storagecmd.NewProvider(params.dbType, params.dbPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if e := provider.Close(); e != nil {
+			logger.Warnf("failed to close storage provider: %s", e)
+		}
+	}()
+
+	rotated, err := localkms.RotateMasterKey(params.masterKeyURI,
+		&kmsProvider{storageProvider: provider, secretLock: secretLock}, newSecretLock)
+	if err != nil {
+		return fmt.Errorf("failed to rotate master key: %w", err)
+	}
+
+	logger.Infof("Re-encrypted %d keysets of %s with the master key %s", rotated, params.dbPath,
+		params.newMasterKeyFile)
+
+	return nil
+}
+
+// newMasterKeySecretLock returns the secret lock of the master key of masterKeyFile, creating a new master key if the
+// file doesn't exist
+func newMasterKeySecretLock(masterKeyFile, passphrase string) (secretlock.Service, error) {
+	if _, err := os.Stat(masterKeyFile); os.IsNotExist(err) {
+		masterLock, e := startcmd.NewMasterLock(passphrase)
+		if e != nil {
+			return nil, e
+		}
+
+		e = local.CreateMasterKeyFile(masterKeyFile, masterLock)
+		if e != nil {
+			return nil, fmt.Errorf("failed to create master key file: %w", e)
+		}
+
+		logger.Infof("Created a new master key in %s", masterKeyFile)
+	}
+
+	return startcmd.NewSecretLock(masterKeyFile, passphrase)
+}
+
+// kmsProvider is the kms.Provider of the rotated keys
+type kmsProvider struct {
+	storageProvider storage.Provider
+	secretLock      secretlock.Service
+}
+
+func (p *kmsProvider) StorageProvider() storage.Provider {
+	return p.storageProvider
+}
+
+func (p *kmsProvider) SecretLock() secretlock.Service {
+	return p.secretLock
+}
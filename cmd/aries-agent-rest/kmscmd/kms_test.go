@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kmscmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+)
+
+func setupDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "kmscmd")
+	require.NoError(t, err)
+
+	return dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func runCmd(t *testing.T, args ...string) error {
+	t.Helper()
+
+	kmsCmd, err := Cmd()
+	require.NoError(t, err)
+
+	kmsCmd.SetArgs(args)
+
+	return kmsCmd.Execute()
+}
+
+func TestKMSCmdContents(t *testing.T) {
+	kmsCmd, err := Cmd()
+	require.NoError(t, err)
+
+	require.Equal(t, "kms", kmsCmd.Use)
+	require.Len(t, kmsCmd.Commands(), 2)
+
+	// cobra sorts the subcommands by name
+	cmd := kmsCmd.Commands()[1]
+	require.Equal(t, "rotate-master-key", cmd.Use)
+
+	checkFlagPropertiesCorrect(t, cmd, dbTypeFlagName, "", dbTypeFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, dbPathFlagName, dbPathFlagShorthand, dbPathFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, masterKeyURIFlagName, "", masterKeyURIFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, masterKeyFileFlagName, "", masterKeyFileFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, masterKeyPassphraseFlagName, "", masterKeyPassphraseFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, newMasterKeyFileFlagName, "", newMasterKeyFileFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, newMasterKeyPassphraseFlagName, "", newMasterKeyPassphraseFlagUsage)
+
+	cmd = kmsCmd.Commands()[0]
+	require.Equal(t, "recovery-shares", cmd.Use)
+
+	checkFlagPropertiesCorrect(t, cmd, masterKeyFileFlagName, "", masterKeyFileFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, masterKeyPassphraseFlagName, "", masterKeyPassphraseFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, sharesFlagName, "", sharesFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, thresholdFlagName, "", thresholdFlagUsage)
+	checkFlagPropertiesCorrect(t, cmd, sharesDirFlagName, "", sharesDirFlagUsage)
+}
+
+func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName, flagShorthand, flagUsage string) {
+	flag := cmd.Flag(flagName)
+
+	require.NotNil(t, flag)
+	require.Equal(t, flagName, flag.Name)
+	require.Equal(t, flagShorthand, flag.Shorthand)
+	require.Equal(t, flagUsage, flag.Usage)
+	require.Empty(t, flag.Value.String())
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	dir, cleanup := setupDir(t)
+	defer cleanup()
+
+	dbPath := filepath.Join(dir, "db")
+	firstKeyFile := filepath.Join(dir, "first.key")
+	secondKeyFile := filepath.Join(dir, "second.key")
+
+	keyID := createKey(t, dbPath, &noop.NoLock{})
+
+	t.Run("encrypt unprotected keys with a new master key", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dbPath, "--new-master-key-file", firstKeyFile,
+			"--new-master-key-passphrase", "secret")
+		require.NoError(t, err)
+		require.FileExists(t, firstKeyFile)
+
+		require.Error(t, getKey(t, dbPath, &noop.NoLock{}, keyID))
+
+		secretLock, err := startcmd.NewSecretLock(firstKeyFile, "secret")
+		require.NoError(t, err)
+		require.NoError(t, getKey(t, dbPath, secretLock, keyID))
+	})
+
+	t.Run("rotate master key from env vars", func(t *testing.T) {
+		require.NoError(t, os.Setenv(dbPathEnvKey, dbPath))
+		require.NoError(t, os.Setenv(masterKeyFileEnvKey, firstKeyFile))
+		require.NoError(t, os.Setenv(masterKeyPassphraseEnvKey, "secret"))
+		require.NoError(t, os.Setenv(newMasterKeyFileEnvKey, secondKeyFile))
+
+		defer func() {
+			require.NoError(t, os.Unsetenv(dbPathEnvKey))
+			require.NoError(t, os.Unsetenv(masterKeyFileEnvKey))
+			require.NoError(t, os.Unsetenv(masterKeyPassphraseEnvKey))
+			require.NoError(t, os.Unsetenv(newMasterKeyFileEnvKey))
+		}()
+
+		err := runCmd(t, "rotate-master-key")
+		require.NoError(t, err)
+
+		// running the rotation again completes it, the keys being already rotated
+		err = runCmd(t, "rotate-master-key")
+		require.NoError(t, err)
+
+		secretLock, err := startcmd.NewSecretLock(secondKeyFile, "")
+		require.NoError(t, err)
+		require.NoError(t, getKey(t, dbPath, secretLock, keyID))
+	})
+
+	t.Run("wrong current master key", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dbPath, "--master-key-file", firstKeyFile,
+			"--master-key-passphrase", "secret", "--new-master-key-file", filepath.Join(dir, "third.key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "current master key doesn't decrypt keyset")
+	})
+}
+
+func TestRotateMasterKeyErrors(t *testing.T) {
+	dir, cleanup := setupDir(t)
+	defer cleanup()
+
+	t.Run("missing db path", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--new-master-key-file", filepath.Join(dir, "new.key"))
+		require.EqualError(t, err, "Neither db-path (command line flag) nor ARIESD_DB_PATH (environment variable) "+
+			"have been set.")
+	})
+
+	t.Run("missing new master key file", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dir)
+		require.EqualError(t, err, "Neither new-master-key-file (command line flag) nor ARIESD_NEW_MASTER_KEY_FILE "+
+			"(environment variable) have been set.")
+	})
+
+	t.Run("same master key files", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dir, "--master-key-file", filepath.Join(dir, "a.key"),
+			"--new-master-key-file", filepath.Join(dir, "a.key"))
+		require.EqualError(t, err, "the new master key file must differ from the current master key file")
+	})
+
+	t.Run("passphrase without master key file", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dir, "--master-key-passphrase", "secret",
+			"--new-master-key-file", filepath.Join(dir, "new.key"))
+		require.EqualError(t, err, "a master key passphrase requires a master key file")
+	})
+
+	t.Run("missing master key file", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dir, "--master-key-file", filepath.Join(dir, "missing.key"),
+			"--new-master-key-file", filepath.Join(dir, "new.key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read master key file")
+	})
+
+	t.Run("invalid new master key file path", func(t *testing.T) {
+		err := runCmd(t, "rotate-master-key", "--db-path", dir,
+			"--new-master-key-file", filepath.Join(dir, "missing", "new.key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create master key file")
+	})
+}
+
+// createKey creates a key in the localkms of the leveldb database at dbPath and returns its ID
+func createKey(t *testing.T, dbPath string, secretLock secretlock.Service) string {
+	t.Helper()
+
+	provider := leveldb.NewProvider(dbPath)
+
+	defer func() {
+		require.NoError(t, provider.Close())
+	}()
+
+	localKMS, err := localkms.New(defaultMasterKeyURI, &kmsProvider{storageProvider: provider, secretLock: secretLock})
+	require.NoError(t, err)
+
+	keyID, _, err := localKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	return keyID
+}
+
+// getKey gets the key keyID from the localkms of the leveldb database at dbPath
+func getKey(t *testing.T, dbPath string, secretLock secretlock.Service, keyID string) error {
+	t.Helper()
+
+	provider := leveldb.NewProvider(dbPath)
+
+	defer func() {
+		require.NoError(t, provider.Close())
+	}()
+
+	localKMS, err := localkms.New(defaultMasterKeyURI, &kmsProvider{storageProvider: provider, secretLock: secretLock})
+	require.NoError(t, err)
+
+	_, err = localKMS.Get(keyID)
+
+	return err
+}
@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kmscmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+)
+
+const (
+	// shares flag
+	sharesFlagName  = "shares"
+	sharesEnvKey    = "ARIESD_RECOVERY_SHARES"
+	sharesFlagUsage = "Number of recovery shares, one per custodian." +
+		" Alternatively, this can be set with the following environment variable: " + sharesEnvKey
+
+	// threshold flag
+	thresholdFlagName  = "threshold"
+	thresholdEnvKey    = "ARIESD_RECOVERY_THRESHOLD"
+	thresholdFlagUsage = "Number of recovery shares required to recover the master key." +
+		" Alternatively, this can be set with the following environment variable: " + thresholdEnvKey
+
+	// shares dir flag
+	sharesDirFlagName  = "shares-dir"
+	sharesDirEnvKey    = "ARIESD_RECOVERY_SHARES_DIR"
+	sharesDirFlagUsage = "Directory the recovery shares are written to, one file per share." +
+		" Alternatively, this can be set with the following environment variable: " + sharesDirEnvKey
+
+	// sharesDirPerm and shareFilePerm restrict the shares to the user running the command
+	sharesDirPerm = 0700
+	shareFilePerm = 0600
+)
+
+type recoveryParameters struct {
+	masterKeyFile, masterKeyPassphrase, sharesDir string
+	shares, threshold                             int
+}
+
+func createRecoverySharesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "recovery-shares",
+		Short: "Split the master key of the agent keys into recovery shares",
+		Long: `Split the master key file of an agent into recovery shares, any threshold of which recover the ` +
+			`master key with the REST operation POST /kms/recovery/master-key. The shares are generated offline, ` +
+			`from the master key file, and written to one file each to be handed to different custodians.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := getRecoveryParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			return writeRecoveryShares(params)
+		},
+	}
+}
+
+func createRecoveryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(masterKeyFileFlagName, "", "", masterKeyFileFlagUsage)
+	cmd.Flags().StringP(masterKeyPassphraseFlagName, "", "", masterKeyPassphraseFlagUsage)
+	cmd.Flags().StringP(sharesFlagName, "", "", sharesFlagUsage)
+	cmd.Flags().StringP(thresholdFlagName, "", "", thresholdFlagUsage)
+	cmd.Flags().StringP(sharesDirFlagName, "", "", sharesDirFlagUsage)
+}
+
+func getRecoveryParameters(cmd *cobra.Command) (*recoveryParameters, error) {
+	params := &recoveryParameters{}
+
+	for _, v := range []struct {
+		value            *string
+		flagName, envKey string
+		isOptional       bool
+	}{
+		{&params.masterKeyFile, masterKeyFileFlagName, masterKeyFileEnvKey, false},
+		{&params.masterKeyPassphrase, masterKeyPassphraseFlagName, masterKeyPassphraseEnvKey, true},
+		{&params.sharesDir, sharesDirFlagName, sharesDirEnvKey, false},
+	} {
+		value, err := startcmd.GetUserSetVar(cmd, v.flagName, v.envKey, v.isOptional)
+		if err != nil {
+			return nil, err
+		}
+
+		*v.value = value
+	}
+
+	for _, v := range []struct {
+		value            *int
+		flagName, envKey string
+	}{
+		{&params.shares, sharesFlagName, sharesEnvKey},
+		{&params.threshold, thresholdFlagName, thresholdEnvKey},
+	} {
+		value, err := startcmd.GetUserSetVar(cmd, v.flagName, v.envKey, false)
+		if err != nil {
+			return nil, err
+		}
+
+		*v.value, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", v.flagName, err)
+		}
+	}
+
+	if params.masterKeyFile == "" {
+		return nil, errors.New("the master key file is required")
+	}
+
+	return params, nil
+}
+
+// writeRecoveryShares writes the recovery shares of the master key file of params to the files share-1 to share-n of
+// the shares directory, which must not contain shares already
+func writeRecoveryShares(params *recoveryParameters) error {
+	secretLock, err := startcmd.NewSecretLock(params.masterKeyFile, params.masterKeyPassphrase)
+	if err != nil {
+		return err
+	}
+
+	lock, ok := secretLock.(*local.Lock)
+	if !ok {
+		return errors.New("the master key file doesn't support recovery shares")
+	}
+
+	shares, err := lock.MasterKeyShares(params.shares, params.threshold)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(params.sharesDir, sharesDirPerm)
+	if err != nil {
+		return fmt.Errorf("failed to create shares directory: %w", err)
+	}
+
+	paths := make([]string, len(shares))
+
+	for i := range shares {
+		paths[i] = filepath.Join(params.sharesDir, fmt.Sprintf("share-%d", i+1))
+
+		if _, err = os.Stat(paths[i]); err == nil {
+			return fmt.Errorf("recovery share %s already exists", paths[i])
+		}
+	}
+
+	for i, share := range shares {
+		err = ioutil.WriteFile(paths[i], []byte(share), shareFilePerm)
+		if err != nil {
+			return fmt.Errorf("failed to write recovery share: %w", err)
+		}
+	}
+
+	logger.Infof("Wrote %d recovery shares of %s to %s, %d of which recover the master key", len(shares),
+		params.masterKeyFile, params.sharesDir, params.threshold)
+
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kmscmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+)
+
+func TestRecoveryShares(t *testing.T) {
+	dir, cleanup := setupDir(t)
+	defer cleanup()
+
+	masterKeyFile := filepath.Join(dir, "master.key")
+
+	masterLock, err := startcmd.NewMasterLock("secret")
+	require.NoError(t, err)
+	require.NoError(t, local.CreateMasterKeyFile(masterKeyFile, masterLock))
+
+	secretLock, err := startcmd.NewSecretLock(masterKeyFile, "secret")
+	require.NoError(t, err)
+
+	encrypted, err := secretLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: "test"})
+	require.NoError(t, err)
+
+	t.Run("write recovery shares", func(t *testing.T) {
+		sharesDir := filepath.Join(dir, "shares")
+
+		err = runCmd(t, "recovery-shares", "--master-key-file", masterKeyFile, "--master-key-passphrase", "secret",
+			"--shares", "3", "--threshold", "2", "--shares-dir", sharesDir)
+		require.NoError(t, err)
+
+		var shares []string
+
+		for _, name := range []string{"share-1", "share-3"} {
+			info, e := os.Stat(filepath.Join(sharesDir, name))
+			require.NoError(t, e)
+			require.Equal(t, os.FileMode(shareFilePerm), info.Mode().Perm())
+
+			share, e := ioutil.ReadFile(filepath.Join(sharesDir, name)) // nolint:gosec
+			require.NoError(t, e)
+
+			shares = append(shares, string(share))
+		}
+
+		recovered, e := local.NewServiceFromShares(shares)
+		require.NoError(t, e)
+
+		decrypted, e := recovered.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encrypted.Ciphertext})
+		require.NoError(t, e)
+		require.Equal(t, "test", decrypted.Plaintext)
+
+		// existing shares are not overwritten
+		err = runCmd(t, "recovery-shares", "--master-key-file", masterKeyFile, "--master-key-passphrase", "secret",
+			"--shares", "3", "--threshold", "2", "--shares-dir", sharesDir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sharesDir := filepath.Join(dir, "other")
+
+		err = runCmd(t, "recovery-shares", "--shares", "3", "--threshold", "2", "--shares-dir", sharesDir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), masterKeyFileFlagName)
+
+		err = runCmd(t, "recovery-shares", "--master-key-file", masterKeyFile, "--shares", "three",
+			"--threshold", "2", "--shares-dir", sharesDir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid shares")
+
+		err = runCmd(t, "recovery-shares", "--master-key-file", "", "--shares", "3", "--threshold", "2",
+			"--shares-dir", sharesDir)
+		require.EqualError(t, err, "the master key file is required")
+
+		err = runCmd(t, "recovery-shares", "--master-key-file", masterKeyFile, "--master-key-passphrase", "wrong",
+			"--shares", "3", "--threshold", "2", "--shares-dir", sharesDir)
+		require.Error(t, err)
+
+		err = runCmd(t, "recovery-shares", "--master-key-file", masterKeyFile, "--master-key-passphrase", "secret",
+			"--shares", "2", "--threshold", "3", "--shares-dir", sharesDir)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid number of shares")
+
+		_, err = os.Stat(sharesDir)
+		require.True(t, os.IsNotExist(err))
+	})
+}
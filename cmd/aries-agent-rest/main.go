@@ -26,7 +26,9 @@ package main
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/kmscmd"
 	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/storagecmd"
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 )
 
@@ -46,7 +48,17 @@ func main() {
 		logger.Fatalf(err.Error())
 	}
 
-	rootCmd.AddCommand(startCmd)
+	storageCmd, err := storagecmd.Cmd()
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+
+	kmsCmd, err := kmscmd.Cmd()
+	if err != nil {
+		logger.Fatalf(err.Error())
+	}
+
+	rootCmd.AddCommand(startCmd, storageCmd, kmsCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.Fatalf("Failed to run aries-agent-rest: %s", err)
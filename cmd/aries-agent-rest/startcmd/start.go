@@ -28,6 +28,10 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/defaults"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/httpbinding"
 )
 
@@ -126,6 +130,26 @@ const (
 		" Refer https://github.com/hyperledger/aries-framework-go/blob/8449c727c7c44f47ed7c9f10f35f0cd051dcb4e9/pkg/framework/aries/framework.go#L165-L168." + // nolint lll
 		" Alternatively, this can be set with the following environment variable: " + agentTransportReturnRouteEnvKey
 
+	// master key file flag
+	agentMasterKeyFileFlagName  = "master-key-file"
+	agentMasterKeyFileEnvKey    = "ARIESD_MASTER_KEY_FILE"
+	agentMasterKeyFileFlagUsage = "Path to the file of the master key encrypting the agent keys (optional)." +
+		" The keys are not encrypted if not set, see the kms rotate-master-key command to encrypt them." +
+		" Alternatively, this can be set with the following environment variable: " + agentMasterKeyFileEnvKey
+
+	// master key passphrase flag
+	agentMasterKeyPassphraseFlagName  = "master-key-passphrase"
+	agentMasterKeyPassphraseEnvKey    = "ARIESD_MASTER_KEY_PASSPHRASE" // nolint:gosec
+	agentMasterKeyPassphraseFlagUsage = "Passphrase protecting the master key file (optional)." +
+		" Alternatively, this can be set with the following environment variable: " + agentMasterKeyPassphraseEnvKey
+
+	// key usage audit flag
+	agentKeyUsageAuditFlagName  = "key-usage-audit"
+	agentKeyUsageAuditEnvKey    = "ARIESD_KEY_USAGE_AUDIT"
+	agentKeyUsageAuditFlagUsage = "Record the use of the agent keys in the key usage audit log, see the kms audit API." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + agentKeyUsageAuditEnvKey
+
 	httpProtocol      = "http"
 	websocketProtocol = "ws"
 )
@@ -137,9 +161,10 @@ type agentParameters struct {
 	server                                           server
 	host, dbPath, defaultLabel, transportReturnRoute string
 	token                                            string
+	masterKeyFile, masterKeyPassphrase               string
 	webhookURLs, httpResolvers, outboundTransports   []string
 	inboundHostInternals, inboundHostExternals       []string
-	autoAccept                                       bool
+	autoAccept, keyUsageAudit                        bool
 	msgHandler                                       command.MessageHandler
 }
 
@@ -171,7 +196,7 @@ func createStartCMD(server server) *cobra.Command { //nolint funlen gocyclo
 		Long:  `Start an Aries agent controller`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// log level
-			logLevel, err := getUserSetVar(cmd, agentLogLevelFlagName, agentLogLevelEnvKey, true)
+			logLevel, err := GetUserSetVar(cmd, agentLogLevelFlagName, agentLogLevelEnvKey, true)
 			if err != nil {
 				return err
 			}
@@ -181,64 +206,80 @@ func createStartCMD(server server) *cobra.Command { //nolint funlen gocyclo
 				return err
 			}
 
-			host, err := getUserSetVar(cmd, agentHostFlagName, agentHostEnvKey, false)
+			host, err := GetUserSetVar(cmd, agentHostFlagName, agentHostEnvKey, false)
 			if err != nil {
 				return err
 			}
 
-			token, err := getUserSetVar(cmd, agentTokenFlagName, agentTokenEnvKey, true)
+			token, err := GetUserSetVar(cmd, agentTokenFlagName, agentTokenEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			inboundHosts, err := getUserSetVars(cmd, agentInboundHostFlagName, agentInboundHostEnvKey, true)
+			inboundHosts, err := GetUserSetVars(cmd, agentInboundHostFlagName, agentInboundHostEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			inboundHostExternals, err := getUserSetVars(cmd, agentInboundHostExternalFlagName,
+			inboundHostExternals, err := GetUserSetVars(cmd, agentInboundHostExternalFlagName,
 				agentInboundHostExternalEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			dbPath, err := getUserSetVar(cmd, agentDBPathFlagName, agentDBPathEnvKey, false)
+			dbPath, err := GetUserSetVar(cmd, agentDBPathFlagName, agentDBPathEnvKey, false)
 			if err != nil {
 				return err
 			}
 
-			defaultLabel, err := getUserSetVar(cmd, agentDefaultLabelFlagName, agentDefaultLabelEnvKey, true)
+			defaultLabel, err := GetUserSetVar(cmd, agentDefaultLabelFlagName, agentDefaultLabelEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			autoAccept, err := getAutoAcceptValue(cmd)
+			autoAccept, err := getBoolUserSetVar(cmd, agentAutoAcceptFlagName, agentAutoAcceptEnvKey)
 			if err != nil {
 				return err
 			}
 
-			webhookURLs, err := getUserSetVars(cmd, agentWebhookFlagName, agentWebhookEnvKey, autoAccept)
+			webhookURLs, err := GetUserSetVars(cmd, agentWebhookFlagName, agentWebhookEnvKey, autoAccept)
 			if err != nil {
 				return err
 			}
 
-			httpResolvers, err := getUserSetVars(cmd, agentHTTPResolverFlagName, agentHTTPResolverEnvKey, true)
+			httpResolvers, err := GetUserSetVars(cmd, agentHTTPResolverFlagName, agentHTTPResolverEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			outboundTransports, err := getUserSetVars(cmd, agentOutboundTransportFlagName,
+			outboundTransports, err := GetUserSetVars(cmd, agentOutboundTransportFlagName,
 				agentOutboundTransportEnvKey, true)
 			if err != nil {
 				return err
 			}
 
-			transportReturnRoute, err := getUserSetVar(cmd, agentTransportReturnRouteFlagName,
+			transportReturnRoute, err := GetUserSetVar(cmd, agentTransportReturnRouteFlagName,
 				agentTransportReturnRouteEnvKey, true)
 			if err != nil {
 				return err
 			}
 
+			masterKeyFile, err := GetUserSetVar(cmd, agentMasterKeyFileFlagName, agentMasterKeyFileEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			masterKeyPassphrase, err := GetUserSetVar(cmd, agentMasterKeyPassphraseFlagName,
+				agentMasterKeyPassphraseEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			keyUsageAudit, err := getBoolUserSetVar(cmd, agentKeyUsageAuditFlagName, agentKeyUsageAuditEnvKey)
+			if err != nil {
+				return err
+			}
+
 			parameters := &agentParameters{
 				server:               server,
 				host:                 host,
@@ -252,6 +293,9 @@ func createStartCMD(server server) *cobra.Command { //nolint funlen gocyclo
 				outboundTransports:   outboundTransports,
 				autoAccept:           autoAccept,
 				transportReturnRoute: transportReturnRoute,
+				masterKeyFile:        masterKeyFile,
+				masterKeyPassphrase:  masterKeyPassphrase,
+				keyUsageAudit:        keyUsageAudit,
 			}
 
 			return startAgent(parameters)
@@ -259,8 +303,8 @@ func createStartCMD(server server) *cobra.Command { //nolint funlen gocyclo
 	}
 }
 
-func getAutoAcceptValue(cmd *cobra.Command) (bool, error) {
-	v, err := getUserSetVar(cmd, agentAutoAcceptFlagName, agentAutoAcceptEnvKey, true)
+func getBoolUserSetVar(cmd *cobra.Command, flagName, envKey string) (bool, error) {
+	v, err := GetUserSetVar(cmd, flagName, envKey, true)
 	if err != nil {
 		return false, err
 	}
@@ -313,9 +357,20 @@ func createFlags(startCmd *cobra.Command) {
 
 	// transport return route option flag
 	startCmd.Flags().StringP(agentTransportReturnRouteFlagName, "", "", agentTransportReturnRouteFlagUsage)
+
+	// master key file
+	startCmd.Flags().StringP(agentMasterKeyFileFlagName, "", "", agentMasterKeyFileFlagUsage)
+
+	// master key passphrase
+	startCmd.Flags().StringP(agentMasterKeyPassphraseFlagName, "", "", agentMasterKeyPassphraseFlagUsage)
+
+	// key usage audit
+	startCmd.Flags().StringP(agentKeyUsageAuditFlagName, "", "", agentKeyUsageAuditFlagUsage)
 }
 
-func getUserSetVar(cmd *cobra.Command, hostFlagName, envKey string, isOptional bool) (string, error) {
+// GetUserSetVar returns the value of the string flag hostFlagName, or of the environment variable envKey if the flag
+// is not set. An error is returned if neither is set and the value is not optional.
+func GetUserSetVar(cmd *cobra.Command, hostFlagName, envKey string, isOptional bool) (string, error) {
 	if cmd.Flags().Changed(hostFlagName) {
 		value, err := cmd.Flags().GetString(hostFlagName)
 		if err != nil {
@@ -335,7 +390,9 @@ func getUserSetVar(cmd *cobra.Command, hostFlagName, envKey string, isOptional b
 		" (environment variable) have been set.")
 }
 
-func getUserSetVars(cmd *cobra.Command, flagName, envKey string, isOptional bool) ([]string, error) {
+// GetUserSetVars returns the values of the string slice flag flagName, or the comma-separated values of the environment
+// variable envKey if the flag is not set. An error is returned if neither is set and the values are not optional.
+func GetUserSetVars(cmd *cobra.Command, flagName, envKey string, isOptional bool) ([]string, error) {
 	if cmd.Flags().Changed(flagName) {
 		value, err := cmd.Flags().GetStringSlice(flagName)
 		if err != nil {
@@ -387,6 +444,64 @@ func getResolverOpts(httpResolvers []string) ([]aries.Option, error) {
 	return opts, nil
 }
 
+// getSecretLockOpts returns the option of the secret lock of the master key file, the keys are not encrypted if it is
+// not set
+func getSecretLockOpts(masterKeyFile, passphrase string) ([]aries.Option, error) {
+	if masterKeyFile == "" && passphrase == "" {
+		return nil, nil
+	}
+
+	secretLock, err := NewSecretLock(masterKeyFile, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return []aries.Option{aries.WithSecretLock(secretLock)}, nil
+}
+
+// NewSecretLock returns the local secret lock of the master key stored in masterKeyFile, protected with passphrase if
+// not empty, or a noop lock if masterKeyFile is empty.
+func NewSecretLock(masterKeyFile, passphrase string) (secretlock.Service, error) {
+	if masterKeyFile == "" {
+		if passphrase != "" {
+			return nil, errors.New("a master key passphrase requires a master key file")
+		}
+
+		return &noop.NoLock{}, nil
+	}
+
+	masterLock, err := NewMasterLock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := local.MasterKeyFromPath(masterKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+
+	secretLock, err := local.NewService(r, masterLock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secret lock: %w", err)
+	}
+
+	return secretLock, nil
+}
+
+// NewMasterLock returns the Argon2id master lock of the master key files protected with passphrase, nil if it is empty
+func NewMasterLock(passphrase string) (secretlock.Service, error) {
+	if passphrase == "" {
+		return nil, nil
+	}
+
+	masterLock, err := argon2.NewMasterLock(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create master lock: %w", err)
+	}
+
+	return masterLock, nil
+}
+
 func getOutboundTransportOpts(outboundTransports []string) ([]aries.Option, error) {
 	var opts []aries.Option
 
@@ -560,6 +675,18 @@ func createAriesAgent(parameters *agentParameters) (*context.Provider, error) {
 		opts = append(opts, aries.WithTransportReturnRoute(parameters.transportReturnRoute))
 	}
 
+	secretLockOpts, err := getSecretLockOpts(parameters.masterKeyFile, parameters.masterKeyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start aries agent rest on port [%s], failed to secret lock opts : %w",
+			parameters.host, err)
+	}
+
+	opts = append(opts, secretLockOpts...)
+
+	if parameters.keyUsageAudit {
+		opts = append(opts, aries.WithKeyUsageAudit())
+	}
+
 	inboundTransportOpt, err := getInboundTransportOpts(parameters.inboundHostInternals,
 		parameters.inboundHostExternals)
 	if err != nil {
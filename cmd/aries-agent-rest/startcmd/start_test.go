@@ -14,6 +14,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -22,6 +23,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
 )
 
 type mockServer struct{}
@@ -81,6 +84,9 @@ func TestStartCmdContents(t *testing.T) {
 	checkFlagPropertiesCorrect(t, startCmd, agentInboundHostFlagName,
 		agentInboundHostFlagShorthand, agentInboundHostFlagUsage, "[]")
 	checkFlagPropertiesCorrect(t, startCmd, agentDBPathFlagName, agentDBPathFlagShorthand, agentDBPathFlagUsage, "")
+	checkFlagPropertiesCorrect(t, startCmd, agentMasterKeyFileFlagName, "", agentMasterKeyFileFlagUsage, "")
+	checkFlagPropertiesCorrect(t, startCmd, agentMasterKeyPassphraseFlagName, "",
+		agentMasterKeyPassphraseFlagUsage, "")
 }
 
 func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName,
@@ -583,7 +589,8 @@ func TestStartMultipleAgentsWithSameDBPath(t *testing.T) {
 	err := startAgent(parameters)
 
 	require.NotNil(t, err)
-	require.Contains(t, err.Error(), "failed to OpenStore for")
+	// the KMS is the first service opening the locked database
+	require.Contains(t, err.Error(), "create KMS failed")
 }
 
 func TestStartAriesErrorWithResolvers(t *testing.T) {
@@ -748,6 +755,50 @@ func TestStartAriesWithAutoAccept(t *testing.T) {
 	})
 }
 
+func TestStartCmdWithKeyUsageAudit(t *testing.T) {
+	t.Run("start with key usage audit - success", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+
+		args := []string{
+			"--" + agentHostFlagName,
+			randomURL(),
+			"--" + agentInboundHostFlagName,
+			httpProtocol + "@" + randomURL(),
+			"--" + agentDBPathFlagName,
+			path,
+			"--" + agentKeyUsageAuditFlagName,
+			"true",
+		}
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("start with key usage audit - invalid", func(t *testing.T) {
+		startCmd, err := Cmd(&mockServer{})
+		require.NoError(t, err)
+
+		args := []string{
+			"--" + agentHostFlagName,
+			randomURL(),
+			"--" + agentDBPathFlagName,
+			"path",
+			"--" + agentKeyUsageAuditFlagName,
+			"invalid",
+		}
+		startCmd.SetArgs(args)
+
+		err = startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid syntax")
+	})
+}
+
 func TestStartAriesWithAuthorization(t *testing.T) {
 	const (
 		goodToken = "ABCD"
@@ -821,3 +872,48 @@ func generateTempDir(t testing.TB) (string, func()) {
 		}
 	}
 }
+
+func TestStartAriesWithMasterKey(t *testing.T) {
+	t.Run("start aries with master key file success", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+
+		masterKeyFile := filepath.Join(path, "master.key")
+		masterLock, e := argon2.NewMasterLock("secret")
+		require.NoError(t, e)
+		require.NoError(t, local.CreateMasterKeyFile(masterKeyFile, masterLock))
+
+		testHostURL := randomURL()
+		testInboundHostURL := randomURL()
+
+		go func() {
+			parameters := &agentParameters{
+				server:               &HTTPServer{},
+				host:                 testHostURL,
+				inboundHostInternals: []string{httpProtocol + "@" + testInboundHostURL},
+				dbPath:               filepath.Join(path, "db"),
+				defaultLabel:         "x",
+				masterKeyFile:        masterKeyFile,
+				masterKeyPassphrase:  "secret",
+			}
+
+			err := startAgent(parameters)
+			require.NoError(t, err)
+			require.FailNow(t, agentUnexpectedExitErrMsg+": "+err.Error())
+		}()
+
+		waitForServerToStart(t, testHostURL, testInboundHostURL)
+	})
+
+	t.Run("start aries with master key passphrase but no master key file", func(t *testing.T) {
+		parameters := &agentParameters{
+			server:              &mockServer{},
+			host:                randomURL(),
+			masterKeyPassphrase: "secret",
+		}
+
+		err := startAgent(parameters)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a master key passphrase requires a master key file")
+	})
+}
@@ -0,0 +1,273 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storagecmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/aries-framework-go/cmd/aries-agent-rest/startcmd"
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/bolt"
+	couchdbstore "github.com/hyperledger/aries-framework-go/pkg/storage/couchdb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/migrate"
+)
+
+const (
+	// db type flag
+	dbTypeFlagName  = "db-type"
+	dbTypeEnvKey    = "ARIESD_DB_TYPE"
+	dbTypeFlagUsage = "Type of the database: leveldb (default), bolt or couchdb." +
+		" Alternatively, this can be set with the following environment variable: " + dbTypeEnvKey
+
+	// db path flag
+	dbPathFlagName      = "db-path"
+	dbPathEnvKey        = "ARIESD_DB_PATH"
+	dbPathFlagShorthand = "d"
+	dbPathFlagUsage     = "Path to database, the URL of the server for couchdb." +
+		" Alternatively, this can be set with the following environment variable: " + dbPathEnvKey
+
+	// archive file flag
+	fileFlagName      = "file"
+	fileEnvKey        = "ARIESD_BACKUP_FILE"
+	fileFlagShorthand = "f"
+	fileFlagUsage     = "Path to the archive file." +
+		" Alternatively, this can be set with the following environment variable: " + fileEnvKey
+
+	// passphrase flag
+	passphraseFlagName  = "passphrase"
+	passphraseEnvKey    = "ARIESD_BACKUP_PASSPHRASE" // nolint:gosec
+	passphraseFlagUsage = "Passphrase encrypting the archive (optional)." +
+		" Alternatively, this can be set with the following environment variable: " + passphraseEnvKey
+
+	// namespaces flag
+	namespacesFlagName  = "namespaces"
+	namespacesEnvKey    = "ARIESD_BACKUP_NAMESPACES"
+	namespacesFlagUsage = "Comma-separated list of the stores to export (optional)." +
+		" Defaults to all the stores of the framework." +
+		" Alternatively, this can be set with the following environment variable: " + namespacesEnvKey
+
+	levelDBType = "leveldb"
+	boltType    = "bolt"
+	couchDBType = "couchdb"
+)
+
+var logger = log.New("aries-framework/agent-rest")
+
+type parameters struct {
+	dbType, dbPath, file, passphrase string
+	namespaces                       []string
+}
+
+// Cmd returns the Cobra storage command, with its export and import subcommands.
+func Cmd() (*cobra.Command, error) {
+	storageCmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Back up and restore agent storage",
+		Long:  `Export the stores of an agent to an archive, or import an archive into the stores of an agent`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	exportCmd := createExportCmd()
+	createFlags(exportCmd)
+	exportCmd.Flags().StringSliceP(namespacesFlagName, "", []string{}, namespacesFlagUsage)
+
+	importCmd := createImportCmd()
+	createFlags(importCmd)
+
+	storageCmd.AddCommand(exportCmd, importCmd)
+
+	return storageCmd, nil
+}
+
+func createExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "Export agent storage",
+		Long:  `Export the stores of an agent to an archive file`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := getParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			params.namespaces, err = startcmd.GetUserSetVars(cmd, namespacesFlagName, namespacesEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			return exportStorage(params)
+		},
+	}
+}
+
+func createImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import",
+		Short: "Import agent storage",
+		Long:  `Import an archive file into the stores of an agent`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := getParameters(cmd)
+			if err != nil {
+				return err
+			}
+
+			return importStorage(params)
+		},
+	}
+}
+
+func createFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(dbTypeFlagName, "", "", dbTypeFlagUsage)
+	cmd.Flags().StringP(dbPathFlagName, dbPathFlagShorthand, "", dbPathFlagUsage)
+	cmd.Flags().StringP(fileFlagName, fileFlagShorthand, "", fileFlagUsage)
+	cmd.Flags().StringP(passphraseFlagName, "", "", passphraseFlagUsage)
+}
+
+func getParameters(cmd *cobra.Command) (*parameters, error) {
+	dbType, err := startcmd.GetUserSetVar(cmd, dbTypeFlagName, dbTypeEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath, err := startcmd.GetUserSetVar(cmd, dbPathFlagName, dbPathEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := startcmd.GetUserSetVar(cmd, fileFlagName, fileEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := startcmd.GetUserSetVar(cmd, passphraseFlagName, passphraseEnvKey, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parameters{dbType: dbType, dbPath: dbPath, file: file, passphrase: passphrase}, nil
+}
+
+func exportStorage(params *parameters) error {
+	provider, opts, err := prepare(params)
+	if err != nil {
+		return err
+	}
+
+	defer closeProvider(provider)
+
+	if len(params.namespaces) > 0 {
+		opts = append(opts, migrate.WithNamespaces(params.namespaces...))
+	}
+
+	f, err := os.OpenFile(params.file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600) // nolint:gomnd
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	err = migrate.Export(provider, f, opts...)
+	if err != nil {
+		closeFile(f)
+
+		return fmt.Errorf("failed to export storage: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close archive file: %w", err)
+	}
+
+	logger.Infof("Exported %s database %s to %s", params.dbType, params.dbPath, params.file)
+
+	return nil
+}
+
+func importStorage(params *parameters) error {
+	provider, opts, err := prepare(params)
+	if err != nil {
+		return err
+	}
+
+	defer closeProvider(provider)
+
+	f, err := os.Open(params.file)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+
+	defer closeFile(f)
+
+	err = migrate.Import(provider, f, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to import storage: %w", err)
+	}
+
+	logger.Infof("Imported %s into %s database %s", params.file, params.dbType, params.dbPath)
+
+	return nil
+}
+
+// prepare creates the storage provider and the migrate options of the command
+func prepare(params *parameters) (storage.Provider, []migrate.Option, error) {
+	var opts []migrate.Option
+
+	if params.passphrase != "" {
+		lock, err := argon2.NewMasterLock(params.passphrase)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create secret lock: %w", err)
+		}
+
+		opts = append(opts, migrate.WithSecretLock(lock, ""))
+	}
+
+	if params.dbType == "" {
+		params.dbType = levelDBType
+	}
+
+	provider, err := NewProvider(params.dbType, params.dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provider, opts, nil
+}
+
+// NewProvider returns the storage provider of the database of type dbType (leveldb if empty, bolt or couchdb) at
+// dbPath, the URL of the server for couchdb.
+func NewProvider(dbType, dbPath string) (storage.Provider, error) {
+	switch dbType {
+	case "", levelDBType:
+		return leveldb.NewProvider(dbPath), nil
+	case boltType:
+		return bolt.NewProvider(dbPath)
+	case couchDBType:
+		return couchdbstore.NewProvider(dbPath)
+	default:
+		return nil, fmt.Errorf("database type %s not supported", dbType)
+	}
+}
+
+func closeProvider(p storage.Provider) {
+	err := p.Close()
+	if err != nil {
+		logger.Warnf("failed to close storage provider: %s", err)
+	}
+}
+
+func closeFile(f io.Closer) {
+	err := f.Close()
+	if err != nil {
+		logger.Warnf("failed to close archive file: %s", err)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storagecmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage/bolt"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+)
+
+func setupDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "storagecmd")
+	require.NoError(t, err)
+
+	return dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func runCmd(t *testing.T, args ...string) error {
+	t.Helper()
+
+	storageCmd, err := Cmd()
+	require.NoError(t, err)
+
+	storageCmd.SetArgs(args)
+
+	return storageCmd.Execute()
+}
+
+func TestStorageCmdContents(t *testing.T) {
+	storageCmd, err := Cmd()
+	require.NoError(t, err)
+
+	require.Equal(t, "storage", storageCmd.Use)
+	require.Len(t, storageCmd.Commands(), 2)
+
+	for _, cmd := range storageCmd.Commands() {
+		checkFlagPropertiesCorrect(t, cmd, dbPathFlagName, dbPathFlagShorthand, dbPathFlagUsage, "")
+		checkFlagPropertiesCorrect(t, cmd, fileFlagName, fileFlagShorthand, fileFlagUsage, "")
+		checkFlagPropertiesCorrect(t, cmd, passphraseFlagName, "", passphraseFlagUsage, "")
+	}
+}
+
+func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName,
+	flagShorthand, flagUsage, expectedVal string) {
+	flag := cmd.Flag(flagName)
+
+	require.NotNil(t, flag)
+	require.Equal(t, flagName, flag.Name)
+	require.Equal(t, flagShorthand, flag.Shorthand)
+	require.Equal(t, flagUsage, flag.Usage)
+	require.Equal(t, expectedVal, flag.Value.String())
+}
+
+func TestExportImport(t *testing.T) {
+	dir, cleanup := setupDir(t)
+	defer cleanup()
+
+	levelDBPath := filepath.Join(dir, "leveldb")
+	boltPath := filepath.Join(dir, "aries.db")
+	archivePath := filepath.Join(dir, "backup.json")
+
+	src := leveldb.NewProvider(levelDBPath)
+
+	store, err := src.OpenStore("kmsdb")
+	require.NoError(t, err)
+	require.NoError(t, store.Put("key1", []byte("keyset1")))
+
+	store, err = src.OpenStore("didexchange")
+	require.NoError(t, err)
+	require.NoError(t, store.Put("conn_1", []byte("record1")))
+
+	require.NoError(t, src.Close())
+
+	t.Run("leveldb to bolt with passphrase", func(t *testing.T) {
+		err = runCmd(t, "export", "--db-path", levelDBPath, "--file", archivePath, "--passphrase", "secret")
+		require.NoError(t, err)
+
+		err = runCmd(t, "import", "--db-type", "bolt", "--db-path", boltPath, "--file", archivePath)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a secret lock is required")
+
+		err = runCmd(t, "import", "--db-type", "bolt", "--db-path", boltPath, "--file", archivePath,
+			"--passphrase", "secret")
+		require.NoError(t, err)
+
+		dest, e := bolt.NewProvider(boltPath)
+		require.NoError(t, e)
+
+		defer func() {
+			require.NoError(t, dest.Close())
+		}()
+
+		store, e := dest.OpenStore("kmsdb")
+		require.NoError(t, e)
+
+		v, e := store.Get("key1")
+		require.NoError(t, e)
+		require.Equal(t, []byte("keyset1"), v)
+
+		store, e = dest.OpenStore("didexchange")
+		require.NoError(t, e)
+
+		v, e = store.Get("conn_1")
+		require.NoError(t, e)
+		require.Equal(t, []byte("record1"), v)
+	})
+
+	t.Run("export selected namespaces from env vars", func(t *testing.T) {
+		require.NoError(t, os.Setenv(dbPathEnvKey, levelDBPath))
+		require.NoError(t, os.Setenv(fileEnvKey, archivePath))
+		require.NoError(t, os.Setenv(namespacesEnvKey, "kmsdb"))
+
+		defer func() {
+			require.NoError(t, os.Unsetenv(dbPathEnvKey))
+			require.NoError(t, os.Unsetenv(fileEnvKey))
+			require.NoError(t, os.Unsetenv(namespacesEnvKey))
+		}()
+
+		err = runCmd(t, "export")
+		require.NoError(t, err)
+
+		archived, e := ioutil.ReadFile(archivePath) // nolint:gosec
+		require.NoError(t, e)
+		require.Contains(t, string(archived), "key1")
+		require.NotContains(t, string(archived), "conn_1")
+	})
+}
+
+func TestStorageCmdErrors(t *testing.T) {
+	dir, cleanup := setupDir(t)
+	defer cleanup()
+
+	t.Run("missing db path", func(t *testing.T) {
+		err := runCmd(t, "export", "--file", filepath.Join(dir, "backup.json"))
+		require.EqualError(t, err, "Neither db-path (command line flag) nor ARIESD_DB_PATH (environment variable) "+
+			"have been set.")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := runCmd(t, "import", "--db-path", dir)
+		require.EqualError(t, err, "Neither file (command line flag) nor ARIESD_BACKUP_FILE (environment variable) "+
+			"have been set.")
+	})
+
+	t.Run("unsupported db type", func(t *testing.T) {
+		err := runCmd(t, "export", "--db-type", "unknown", "--db-path", dir, "--file", filepath.Join(dir, "f"))
+		require.EqualError(t, err, "database type unknown not supported")
+	})
+
+	t.Run("missing archive file", func(t *testing.T) {
+		err := runCmd(t, "import", "--db-type", "bolt", "--db-path", filepath.Join(dir, "aries.db"),
+			"--file", filepath.Join(dir, "missing.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open archive file")
+	})
+
+	t.Run("invalid archive file path", func(t *testing.T) {
+		err := runCmd(t, "export", "--db-type", "bolt", "--db-path", filepath.Join(dir, "aries.db"),
+			"--file", filepath.Join(dir, "missing", "backup.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create archive file")
+	})
+}
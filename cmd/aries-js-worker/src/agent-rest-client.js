@@ -146,6 +146,42 @@ const pkgs = {
             path: "/kms/keyset",
             method: "POST",
         },
+        ImportKey: {
+            path: "/kms/keys/import",
+            method: "POST",
+        },
+        ExportKey: {
+            path: "/kms/keys/export",
+            method: "POST",
+        },
+        ImportEncryptedKey: {
+            path: "/kms/keys/import-encrypted",
+            method: "POST",
+        },
+        ListKeys: {
+            path: "/kms/keys",
+            method: "GET",
+        },
+        GetKeyMetadata: {
+            path: "/kms/keys/metadata",
+            method: "POST",
+        },
+        SetKeyLabels: {
+            path: "/kms/keys/labels",
+            method: "POST",
+        },
+        DeleteKey: {
+            path: "/kms/keys/delete",
+            method: "POST",
+        },
+        RecoverMasterKey: {
+            path: "/kms/recovery/master-key",
+            method: "POST",
+        },
+        QueryKeyAudit: {
+            path: "/kms/audit",
+            method: "POST",
+        },
     },
 }
 
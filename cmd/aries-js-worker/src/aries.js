@@ -529,6 +529,95 @@ const Aries = function (opts) {
             createKeySet: async function () {
                 return invoke(aw, pending, this.pkgname, "CreateKeySet", {}, "timeout while creating key set")
             },
+
+            /**
+             * Imports a private key in JWK format.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            importKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "ImportKey", req, "timeout while importing key")
+            },
+
+            /**
+             * Exports a private key encrypted with a passphrase.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            exportKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "ExportKey", req, "timeout while exporting key")
+            },
+
+            /**
+             * Imports a private key exported with a passphrase.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            importEncryptedKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "ImportEncryptedKey", req, "timeout while importing encrypted key")
+            },
+
+            /**
+             * Lists the keys of the KMS with their metadata.
+             *
+             * @returns {Promise<Object>}
+             */
+            listKeys: async function () {
+                return invoke(aw, pending, this.pkgname, "ListKeys", {}, "timeout while listing keys")
+            },
+
+            /**
+             * Gets the metadata of a key.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            getKeyMetadata: async function (req) {
+                return invoke(aw, pending, this.pkgname, "GetKeyMetadata", req, "timeout while getting key metadata")
+            },
+
+            /**
+             * Replaces the labels of a key.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            setKeyLabels: async function (req) {
+                return invoke(aw, pending, this.pkgname, "SetKeyLabels", req, "timeout while setting key labels")
+            },
+
+            /**
+             * Deletes a key.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            deleteKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "DeleteKey", req, "timeout while deleting key")
+            },
+
+            /**
+             * Recovers the master key of the agent keys from recovery shares.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            recoverMasterKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "RecoverMasterKey", req, "timeout while recovering master key")
+            },
+
+            /**
+             * Queries the records of the key usage audit log, e.g. the signatures of a key.
+             *
+             * @param req - json document
+             * @returns {Promise<Object>}
+             */
+            queryKeyAudit: async function (req) {
+                return invoke(aw, pending, this.pkgname, "QueryKeyAudit", req, "timeout while querying key audit")
+            },
         }
     }
 
@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package audit records the use of the keys of a kms.KeyManager and a crypto.Crypto into an append-only store, to
+// prove which key signed or decrypted what and when.
+//
+// The KeyManager and Crypto decorators of a Log attribute the operations to the component using them. The framework
+// decorates the KMS and the Crypto of the packers with ComponentPacker, and those of the context with ComponentAgent.
+// The context also provides the Crypto to give the signature suites and the JWT signers, decorated with
+// ComponentSignatureSuite and ComponentJWTSigner, e.g. for a signature suite:
+//
+//	suite.NewCryptoSigner(ctx.SignatureSuiteCrypto(), kh)
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Namespace is the store name of the audit records
+const Namespace = "keyaudit"
+
+// Components of the framework using the keys
+const (
+	// ComponentAgent is the component of the operations of the KMS and the Crypto of the framework context
+	ComponentAgent = "agent"
+	// ComponentLegacyKMS is the component of the operations of the legacy KMS adapter
+	ComponentLegacyKMS = "legacy-kms"
+	// ComponentPacker is the component of the operations of the DIDComm packers
+	ComponentPacker = "packer"
+	// ComponentSignatureSuite is the component of the operations of the linked data signature suites
+	ComponentSignatureSuite = "signature-suite"
+	// ComponentJWTSigner is the component of the operations of the JWT signers
+	ComponentJWTSigner = "jwt-signer"
+)
+
+// Outcomes of the recorded operations
+const (
+	// OutcomeSuccess is the outcome of a successful operation
+	OutcomeSuccess = "success"
+	// OutcomeFailure is the outcome of a failed operation, the error of the record holds its cause
+	OutcomeFailure = "failure"
+)
+
+const (
+	recordKeyPrefix = "record_"
+
+	// maxHandles bounds the number of key handles whose key ID is remembered by a log
+	maxHandles = 1024
+)
+
+// Record is the audit record of an operation using a key
+type Record struct {
+	// ID of the record
+	ID string `json:"id"`
+	// KeyID is the KMS ID of the key, or the key ID of a public key, empty if unknown
+	KeyID string `json:"keyID,omitempty"`
+	// Operation is the KMS or Crypto method, e.g. Sign or Create
+	Operation string `json:"operation"`
+	// Component is the framework component calling the operation
+	Component string `json:"component"`
+	// Timestamp of the operation
+	Timestamp time.Time `json:"timestamp"`
+	// Outcome is OutcomeSuccess or OutcomeFailure
+	Outcome string `json:"outcome"`
+	// Error of a failed operation
+	Error string `json:"error,omitempty"`
+	// Digest is the base64URL encoded SHA-256 digest of the data of the operation, e.g. the signed message or the
+	// decrypted ciphertext
+	Digest string `json:"digest,omitempty"`
+}
+
+// Query filters the audit records, the empty fields match any record
+type Query struct {
+	// KeyID of the records
+	KeyID string `json:"keyID,omitempty"`
+	// Operation of the records
+	Operation string `json:"operation,omitempty"`
+	// Component of the records
+	Component string `json:"component,omitempty"`
+	// Since is the earliest timestamp of the records
+	Since time.Time `json:"since,omitempty"`
+	// Until is the latest timestamp of the records
+	Until time.Time `json:"until,omitempty"`
+	// Limit is the maximum number of records returned, zero means no limit
+	Limit int `json:"limit,omitempty"`
+}
+
+// Log is the audit log of the use of keys.
+// Its records are only ever added, there is no API to modify or delete them.
+type Log struct {
+	store storage.Store
+	// keyIDs maps the key handles returned by the audited key managers to their key IDs
+	keyIDs  map[interface{}]string
+	handles []interface{}
+	mutex   sync.RWMutex
+}
+
+// New returns the audit log stored in the Namespace store of p.
+func New(p storage.Provider) (*Log, error) {
+	store, err := p.OpenStore(Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to OpenStore for '%s', cause: %w", Namespace, err)
+	}
+
+	return &Log{store: store, keyIDs: map[interface{}]string{}}, nil
+}
+
+// Query returns the records matching q in chronological order.
+func (l *Log) Query(q *Query) ([]*Record, error) {
+	if q.Limit < 0 {
+		return nil, errors.New("query limit can't be negative")
+	}
+
+	itr := l.store.Iterator(recordKeyPrefix, recordKeyPrefix+storage.EndKeySuffix)
+	defer itr.Release()
+
+	var records []*Record
+
+	for itr.Next() {
+		record := &Record{}
+
+		if err := json.Unmarshal(itr.Value(), record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit record: %w", err)
+		}
+
+		if q.matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	if itr.Error() != nil {
+		return nil, fmt.Errorf("failed to iterate audit records: %w", itr.Error())
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+
+	return records, nil
+}
+
+func (q *Query) matches(record *Record) bool {
+	switch {
+	case q.KeyID != "" && q.KeyID != record.KeyID,
+		q.Operation != "" && q.Operation != record.Operation,
+		q.Component != "" && q.Component != record.Component,
+		!q.Since.IsZero() && record.Timestamp.Before(q.Since),
+		!q.Until.IsZero() && record.Timestamp.After(q.Until):
+		return false
+	default:
+		return true
+	}
+}
+
+// record appends the record of an operation, opErr being the error of the operation
+func (l *Log) record(component, operation, keyID string, data []byte, opErr error) error {
+	now := time.Now().UTC()
+
+	record := &Record{
+		ID:        uuid.New().String(),
+		KeyID:     keyID,
+		Operation: operation,
+		Component: component,
+		Timestamp: now,
+		Outcome:   OutcomeSuccess,
+	}
+
+	if data != nil {
+		digest := sha256.Sum256(data)
+		record.Digest = base64.RawURLEncoding.EncodeToString(digest[:])
+	}
+
+	if opErr != nil {
+		record.Outcome = OutcomeFailure
+		record.Error = opErr.Error()
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	// the keys of the records sort chronologically and are unique, so that no record is ever overwritten
+	key := fmt.Sprintf("%s%020d_%s", recordKeyPrefix, now.UnixNano(), record.ID)
+
+	err = l.store.Put(key, recordBytes)
+	if err != nil {
+		return fmt.Errorf("failed to store audit record of %s: %w", operation, err)
+	}
+
+	return nil
+}
+
+// registerHandle remembers the key ID of a key handle returned by an audited key manager
+func (l *Log) registerHandle(kh interface{}, keyID string) {
+	if !isPointer(kh) || keyID == "" {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, ok := l.keyIDs[kh]; !ok {
+		if len(l.handles) == maxHandles {
+			delete(l.keyIDs, l.handles[0])
+			l.handles = l.handles[1:]
+		}
+
+		l.handles = append(l.handles, kh)
+	}
+
+	l.keyIDs[kh] = keyID
+}
+
+// keyID returns the key ID of a key handle, or of a public key with a key ID
+func (l *Log) keyID(kh interface{}) string {
+	if kid, ok := publicKeyID(kh); ok {
+		return kid
+	}
+
+	if !isPointer(kh) {
+		return ""
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return l.keyIDs[kh]
+}
+
+// isPointer reports whether a key handle is a pointer, e.g. a *keyset.Handle, so that it can be remembered without
+// keeping a copy of the key
+func isPointer(kh interface{}) bool {
+	return kh != nil && reflect.TypeOf(kh).Kind() == reflect.Ptr
+}
@@ -0,0 +1,136 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNew(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+	require.NotNil(t, log)
+
+	_, err = New(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "open error")
+}
+
+func TestLog_Query(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	require.NoError(t, log.record(ComponentPacker, "Sign", "key1", []byte("msg"), nil))
+	require.NoError(t, log.record(ComponentAgent, "Decrypt", "key2", []byte("ct"), errors.New("decrypt error")))
+	require.NoError(t, log.record(ComponentSignatureSuite, "Sign", "key2", nil, nil))
+
+	records, err := log.Query(&Query{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	require.Equal(t, "key1", records[0].KeyID)
+	require.Equal(t, "Sign", records[0].Operation)
+	require.Equal(t, ComponentPacker, records[0].Component)
+	require.Equal(t, OutcomeSuccess, records[0].Outcome)
+	require.Empty(t, records[0].Error)
+	require.NotEmpty(t, records[0].Digest)
+	require.NotEmpty(t, records[0].ID)
+	require.False(t, records[0].Timestamp.Before(start))
+
+	require.Equal(t, OutcomeFailure, records[1].Outcome)
+	require.Equal(t, "decrypt error", records[1].Error)
+	require.Empty(t, records[2].Digest)
+
+	for _, tc := range []struct {
+		query    Query
+		expected int
+	}{
+		{Query{KeyID: "key2"}, 2},
+		{Query{Operation: "Sign"}, 2},
+		{Query{Component: ComponentAgent}, 1},
+		{Query{KeyID: "key2", Operation: "Sign"}, 1},
+		{Query{Since: start}, 3},
+		{Query{Since: time.Now().Add(time.Hour)}, 0},
+		{Query{Until: start.Add(-time.Hour)}, 0},
+		{Query{Limit: 2}, 2},
+	} {
+		tc := tc
+
+		records, err = log.Query(&tc.query)
+		require.NoError(t, err)
+		require.Len(t, records, tc.expected, fmt.Sprintf("%+v", tc.query))
+	}
+
+	_, err = log.Query(&Query{Limit: -1})
+	require.EqualError(t, err, "query limit can't be negative")
+}
+
+func TestLog_QueryErrors(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{recordKeyPrefix + "1": []byte("{")}}
+
+	log, err := New(mockstorage.NewCustomMockStoreProvider(store))
+	require.NoError(t, err)
+
+	_, err = log.Query(&Query{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal audit record")
+
+	store.Store = map[string][]byte{}
+	store.ErrItr = errors.New("iterator error")
+
+	_, err = log.Query(&Query{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "iterator error")
+}
+
+func TestLog_RecordError(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+
+	log, err := New(mockstorage.NewCustomMockStoreProvider(store))
+	require.NoError(t, err)
+
+	err = log.record(ComponentAgent, "Sign", "key1", nil, nil)
+	require.EqualError(t, err, "failed to store audit record of Sign: put error")
+}
+
+func TestLog_KeyIDs(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	handles := make([]*int, maxHandles+1)
+
+	for i := range handles {
+		handles[i] = new(int)
+		log.registerHandle(handles[i], fmt.Sprintf("key%d", i))
+	}
+
+	// the oldest handle is forgotten
+	require.Empty(t, log.keyID(handles[0]))
+	require.Equal(t, "key1", log.keyID(handles[1]))
+	require.Equal(t, fmt.Sprintf("key%d", maxHandles), log.keyID(handles[maxHandles]))
+	require.Len(t, log.keyIDs, maxHandles)
+
+	// registering a handle again updates its key ID
+	log.registerHandle(handles[1], "rotated")
+	require.Equal(t, "rotated", log.keyID(handles[1]))
+	require.Len(t, log.handles, maxHandles)
+
+	// handles which aren't pointers are not remembered
+	log.registerHandle("handle", "key")
+	require.Empty(t, log.keyID("handle"))
+	require.Empty(t, log.keyID(nil))
+}
@@ -0,0 +1,230 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+// lengthSize is the size of the length prefixes of the messages of multi-message operations
+const lengthSize = 4
+
+var errKeyAgreementNotSupported = errors.New("crypto doesn't support key agreement")
+
+// Crypto is a crypto.Crypto recording its operations in the audit log, with the key IDs of the key handles returned
+// by the audited key managers of the log and the digests of the data of the operations. It also implements
+// crypto.KeyAgreement, failing if the audited Crypto doesn't.
+// The operations fail if they can't be recorded, so that no key is used without audit.
+type Crypto struct {
+	crypto    crypto.Crypto
+	log       *Log
+	component string
+}
+
+// Crypto returns c audited in the log as used by component. An audited Crypto is unwrapped so that its operations
+// are only recorded once, with component.
+func (l *Log) Crypto(c crypto.Crypto, component string) *Crypto {
+	if audited, ok := c.(*Crypto); ok {
+		c = audited.crypto
+	}
+
+	return &Crypto{crypto: c, log: l, component: component}
+}
+
+// Encrypt msg and aad with kh
+func (c *Crypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error) {
+	cipherText, nonce, err := c.crypto.Encrypt(msg, aad, kh)
+
+	if e := c.record("Encrypt", kh, msg, err); e != nil {
+		return nil, nil, e
+	}
+
+	return cipherText, nonce, err
+}
+
+// Decrypt cipher with aad and nonce with kh
+func (c *Crypto) Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error) {
+	plainText, err := c.crypto.Decrypt(cipher, aad, nonce, kh)
+
+	if e := c.record("Decrypt", kh, cipher, err); e != nil {
+		return nil, e
+	}
+
+	return plainText, err
+}
+
+// Sign msg with kh
+func (c *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
+	signature, err := c.crypto.Sign(msg, kh)
+
+	if e := c.record("Sign", kh, msg, err); e != nil {
+		return nil, e
+	}
+
+	return signature, err
+}
+
+// Verify signature of msg with kh
+func (c *Crypto) Verify(signature, msg []byte, kh interface{}) error {
+	err := c.crypto.Verify(signature, msg, kh)
+
+	if e := c.record("Verify", kh, msg, err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// ComputeMAC of data with kh
+func (c *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	mac, err := c.crypto.ComputeMAC(data, kh)
+
+	if e := c.record("ComputeMAC", kh, data, err); e != nil {
+		return nil, e
+	}
+
+	return mac, err
+}
+
+// VerifyMAC of data with kh
+func (c *Crypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	err := c.crypto.VerifyMAC(mac, data, kh)
+
+	if e := c.record("VerifyMAC", kh, data, err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// WrapKey wraps cek for recPubKey, the record has the key ID of recPubKey
+func (c *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *crypto.PublicKey,
+	opts ...crypto.WrapKeyOpts) (*crypto.RecipientWrappedKey, error) {
+	wrappedKey, err := c.crypto.WrapKey(cek, apu, apv, recPubKey, opts...)
+
+	if e := c.record("WrapKey", recPubKey, nil, err); e != nil {
+		return nil, e
+	}
+
+	return wrappedKey, err
+}
+
+// UnwrapKey unwraps the cek of recWK with kh
+func (c *Crypto) UnwrapKey(recWK *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	cek, err := c.crypto.UnwrapKey(recWK, kh, opts...)
+
+	var data []byte
+	if recWK != nil {
+		data = recWK.EncryptedCEK
+	}
+
+	if e := c.record("UnwrapKey", kh, data, err); e != nil {
+		return nil, e
+	}
+
+	return cek, err
+}
+
+// SignMulti messages with kh
+func (c *Crypto) SignMulti(messages [][]byte, kh interface{}) ([]byte, error) {
+	signature, err := c.crypto.SignMulti(messages, kh)
+
+	if e := c.record("SignMulti", kh, joinMessages(messages), err); e != nil {
+		return nil, e
+	}
+
+	return signature, err
+}
+
+// VerifyMulti signature of messages with kh
+func (c *Crypto) VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error {
+	err := c.crypto.VerifyMulti(messages, signature, kh)
+
+	if e := c.record("VerifyMulti", kh, joinMessages(messages), err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// DeriveProof from bbsSignature of messages with kh
+func (c *Crypto) DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int,
+	kh interface{}) ([]byte, error) {
+	proof, err := c.crypto.DeriveProof(messages, bbsSignature, nonce, revealedIndexes, kh)
+
+	if e := c.record("DeriveProof", kh, joinMessages(messages), err); e != nil {
+		return nil, e
+	}
+
+	return proof, err
+}
+
+// VerifyProof of revealedMessages with kh
+func (c *Crypto) VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error {
+	err := c.crypto.VerifyProof(revealedMessages, proof, nonce, kh)
+
+	if e := c.record("VerifyProof", kh, joinMessages(revealedMessages), err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// SharedSecret computes the shared secret of kh and pub
+func (c *Crypto) SharedSecret(pub *crypto.PublicKey, kh interface{}) ([]byte, error) {
+	keyAgreement, ok := c.crypto.(crypto.KeyAgreement)
+	if !ok {
+		return nil, errKeyAgreementNotSupported
+	}
+
+	secret, err := keyAgreement.SharedSecret(pub, kh)
+
+	if e := c.record("SharedSecret", kh, nil, err); e != nil {
+		return nil, e
+	}
+
+	return secret, err
+}
+
+func (c *Crypto) record(operation string, kh interface{}, data []byte, err error) error {
+	return c.log.record(c.component, operation, c.log.keyID(kh), data, err)
+}
+
+// publicKeyID returns the key ID of the public keys used as key handles
+func publicKeyID(kh interface{}) (string, bool) {
+	switch pub := kh.(type) {
+	case *crypto.PublicKey:
+		if pub != nil {
+			return pub.KID, true
+		}
+	case *sigverifier.PublicKey:
+		if pub != nil && pub.JWK != nil {
+			return pub.JWK.KeyID, true
+		}
+	}
+
+	return "", false
+}
+
+// joinMessages returns the data of multi-message operations, each message prefixed with its length so that the
+// digest identifies the messages
+func joinMessages(messages [][]byte) []byte {
+	var data []byte
+
+	for _, msg := range messages {
+		data = append(data, make([]byte, lengthSize)...)
+		binary.BigEndian.PutUint32(data[len(data)-lengthSize:], uint32(len(msg)))
+		data = append(data, msg...)
+	}
+
+	return data
+}
@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestCrypto(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	tinkCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	km := log.KeyManager(localKMS, ComponentAgent)
+	c := log.Crypto(tinkCrypto, ComponentSignatureSuite)
+
+	sigKeyID, sigKH, err := km.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	msg := []byte("message")
+
+	signature, err := c.Sign(msg, sigKH)
+	require.NoError(t, err)
+
+	pubKH, err := sigKH.(*keyset.Handle).Public()
+	require.NoError(t, err)
+
+	// the public key handle isn't known to the log
+	require.NoError(t, c.Verify(signature, msg, pubKH))
+	require.Error(t, c.Verify(signature, []byte("other message"), pubKH))
+
+	aeadKeyID, aeadKH, err := km.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	cipherText, nonce, err := c.Encrypt(msg, nil, aeadKH)
+	require.NoError(t, err)
+
+	_, err = c.Decrypt(cipherText, nonce, nil, aeadKH)
+	require.NoError(t, err)
+
+	macKeyID, macKH, err := km.Create(kms.HMACSHA256Tag256Type)
+	require.NoError(t, err)
+
+	mac, err := c.ComputeMAC(msg, macKH)
+	require.NoError(t, err)
+	require.NoError(t, c.VerifyMAC(mac, msg, macKH))
+
+	records, err := log.Query(&Query{Component: ComponentSignatureSuite})
+	require.NoError(t, err)
+	require.Len(t, records, 7)
+
+	digest := sha256.Sum256(msg)
+
+	for i, expected := range []struct {
+		operation string
+		keyID     string
+		outcome   string
+	}{
+		{"Sign", sigKeyID, OutcomeSuccess},
+		{"Verify", "", OutcomeSuccess},
+		{"Verify", "", OutcomeFailure},
+		{"Encrypt", aeadKeyID, OutcomeSuccess},
+		{"Decrypt", aeadKeyID, OutcomeSuccess},
+		{"ComputeMAC", macKeyID, OutcomeSuccess},
+		{"VerifyMAC", macKeyID, OutcomeSuccess},
+	} {
+		require.Equal(t, expected.operation, records[i].Operation)
+		require.Equal(t, expected.keyID, records[i].KeyID)
+		require.Equal(t, expected.outcome, records[i].Outcome)
+	}
+
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(digest[:]), records[0].Digest)
+	require.NotEqual(t, records[1].Digest, records[2].Digest)
+
+	t.Run("audited crypto is unwrapped", func(t *testing.T) {
+		require.Equal(t, tinkCrypto, log.Crypto(c, ComponentPacker).crypto)
+	})
+
+	t.Run("shared secret", func(t *testing.T) {
+		recKeyID, recKH, e := km.Create(kms.ED25519Type)
+		require.NoError(t, e)
+
+		_, e = c.SharedSecret(&crypto.PublicKey{KID: "public"}, recKH)
+		require.Error(t, e)
+
+		records, e = log.Query(&Query{Operation: "SharedSecret"})
+		require.NoError(t, e)
+		require.Len(t, records, 1)
+		require.Equal(t, recKeyID, records[0].KeyID)
+	})
+}
+
+func TestCrypto_Operations(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	kh := &keyset.Handle{}
+	log.registerHandle(kh, "key")
+
+	c := log.Crypto(&mockcrypto.Crypto{
+		WrapValue:   &crypto.RecipientWrappedKey{},
+		UnwrapError: errors.New("unwrap error"),
+	}, ComponentPacker)
+
+	messages := [][]byte{[]byte("a"), []byte("bc")}
+
+	_, err = c.WrapKey([]byte("cek"), nil, nil, &crypto.PublicKey{KID: "recipient"})
+	require.NoError(t, err)
+
+	_, err = c.UnwrapKey(&crypto.RecipientWrappedKey{EncryptedCEK: []byte("cek")}, kh)
+	require.EqualError(t, err, "unwrap error")
+
+	_, err = c.SignMulti(messages, kh)
+	require.NoError(t, err)
+	require.NoError(t, c.VerifyMulti(messages, nil, &sigverifier.PublicKey{JWK: &jose.JWK{}}))
+
+	_, err = c.DeriveProof(messages, nil, nil, nil, kh)
+	require.NoError(t, err)
+	require.NoError(t, c.VerifyProof([][]byte{[]byte("ab"), []byte("c")}, nil, nil, kh))
+
+	_, err = c.SharedSecret(&crypto.PublicKey{}, kh)
+	require.Equal(t, errKeyAgreementNotSupported, err)
+
+	records, err := log.Query(&Query{Component: ComponentPacker})
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	require.Equal(t, "recipient", records[0].KeyID)
+	require.Empty(t, records[0].Digest)
+	require.Equal(t, "key", records[1].KeyID)
+	require.Equal(t, OutcomeFailure, records[1].Outcome)
+	require.Equal(t, "key", records[2].KeyID)
+	require.Equal(t, records[2].Digest, records[3].Digest)
+	// the messages are length prefixed, so that their digest differs from the one of other messages with the same
+	// concatenation
+	require.NotEqual(t, records[4].Digest, records[5].Digest)
+}
+
+func TestCrypto_RecordError(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+
+	log, err := New(mockstorage.NewCustomMockStoreProvider(store))
+	require.NoError(t, err)
+
+	c := log.Crypto(&mockcrypto.Crypto{SignValue: []byte("signature")}, ComponentAgent)
+
+	// the signature isn't returned if the operation can't be recorded
+	signature, err := c.Sign([]byte("message"), &keyset.Handle{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "put error")
+	require.Nil(t, signature)
+
+	_, _, err = c.Encrypt(nil, nil, nil)
+	require.Error(t, err)
+
+	_, err = c.Decrypt(nil, nil, nil, nil)
+	require.Error(t, err)
+
+	require.Error(t, c.Verify(nil, nil, nil))
+
+	_, err = c.ComputeMAC(nil, nil)
+	require.Error(t, err)
+
+	require.Error(t, c.VerifyMAC(nil, nil, nil))
+
+	_, err = c.WrapKey(nil, nil, nil, nil)
+	require.Error(t, err)
+
+	_, err = c.UnwrapKey(nil, nil)
+	require.Error(t, err)
+
+	_, err = c.SignMulti(nil, nil)
+	require.Error(t, err)
+
+	require.Error(t, c.VerifyMulti(nil, nil, nil))
+
+	_, err = c.DeriveProof(nil, nil, nil, nil, nil)
+	require.Error(t, err)
+
+	require.Error(t, c.VerifyProof(nil, nil, nil, nil))
+}
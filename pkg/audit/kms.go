@@ -0,0 +1,191 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// pubKeyExporter is a kms.KeyManager exporting the public keys of its key pairs, like localkms
+type pubKeyExporter interface {
+	ExportPubKeyBytes(keyID string) ([]byte, error)
+}
+
+// fullKMS is a kms.KeyManager implementing all the optional interfaces of the key managers, like localkms
+type fullKMS interface {
+	kms.KeyManager
+	kms.PrivateKeyImporter
+	kms.PrivateKeyExporter
+	kms.KeyMetadataManager
+	pubKeyExporter
+}
+
+// auditedKeyManager is a key manager returned by Log.KeyManager
+type auditedKeyManager interface {
+	audited() *KeyManager
+}
+
+// KeyManager is a kms.KeyManager recording the creation, rotation, import, export and deletion of keys in the audit
+// log. The key handles it returns are remembered so that the operations of the audited Crypto on them are recorded
+// with their key IDs.
+type KeyManager struct {
+	km        kms.KeyManager
+	log       *Log
+	component string
+}
+
+// pubKeyKeyManager is an audited key manager exporting the public keys of its key pairs, like pkcs11kms and webkms
+type pubKeyKeyManager struct {
+	*KeyManager
+	exporter pubKeyExporter
+}
+
+// fullKeyManager is an audited key manager implementing all the optional interfaces of the key managers
+type fullKeyManager struct {
+	*pubKeyKeyManager
+	manager fullKMS
+}
+
+// KeyManager returns km audited in the log as used by component. An audited key manager is unwrapped so that its
+// operations are only recorded once, with component.
+// The audited key manager only implements the optional interfaces of km, e.g. kms.KeyMetadataManager, if km
+// implements all of them like localkms, or only exports public keys if km does, like pkcs11kms and webkms, so that
+// the users of km checking them aren't given an audited key manager failing.
+func (l *Log) KeyManager(km kms.KeyManager, component string) kms.KeyManager {
+	if audited, ok := km.(auditedKeyManager); ok {
+		km = audited.audited().km
+	}
+
+	k := &KeyManager{km: km, log: l, component: component}
+
+	switch manager := km.(type) {
+	case fullKMS:
+		return &fullKeyManager{pubKeyKeyManager: &pubKeyKeyManager{KeyManager: k, exporter: manager}, manager: manager}
+	case pubKeyExporter:
+		return &pubKeyKeyManager{KeyManager: k, exporter: manager}
+	default:
+		return k
+	}
+}
+
+// Create a new key of type kt
+func (k *KeyManager) Create(kt kms.KeyType) (string, interface{}, error) {
+	keyID, kh, err := k.km.Create(kt)
+
+	return k.recordKey("Create", keyID, kh, err)
+}
+
+// Get the key handle of keyID, the use of the key is recorded by the operations of the audited Crypto on the handle
+func (k *KeyManager) Get(keyID string) (interface{}, error) {
+	kh, err := k.km.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	k.log.registerHandle(kh, keyID)
+
+	return kh, nil
+}
+
+// Rotate keyID to a new key of type kt, the record has the ID of the rotated key
+func (k *KeyManager) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	newKeyID, kh, err := k.km.Rotate(kt, keyID)
+
+	if e := k.log.record(k.component, "Rotate", keyID, nil, err); e != nil {
+		return "", nil, e
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.log.registerHandle(kh, newKeyID)
+
+	return newKeyID, kh, nil
+}
+
+// ImportPrivateKey imports privKey as a key of type kt
+func (k *fullKeyManager) ImportPrivateKey(privKey interface{}, kt kms.KeyType) (string, interface{}, error) {
+	keyID, kh, err := k.manager.ImportPrivateKey(privKey, kt)
+
+	return k.recordKey("ImportPrivateKey", keyID, kh, err)
+}
+
+// ExportEncryptedPrivateKey exports the private key of keyID encrypted with passphrase
+func (k *fullKeyManager) ExportEncryptedPrivateKey(keyID, passphrase string) ([]byte, error) {
+	encryptedKey, err := k.manager.ExportEncryptedPrivateKey(keyID, passphrase)
+
+	if e := k.log.record(k.component, "ExportEncryptedPrivateKey", keyID, nil, err); e != nil {
+		return nil, e
+	}
+
+	return encryptedKey, err
+}
+
+// ImportEncryptedPrivateKey imports a private key exported with ExportEncryptedPrivateKey
+func (k *fullKeyManager) ImportEncryptedPrivateKey(encryptedKey []byte, passphrase string) (string, interface{}, error) {
+	keyID, kh, err := k.manager.ImportEncryptedPrivateKey(encryptedKey, passphrase)
+
+	return k.recordKey("ImportEncryptedPrivateKey", keyID, kh, err)
+}
+
+// List the metadata of the keys, not recorded
+func (k *fullKeyManager) List() ([]*kms.KeyMetadata, error) {
+	return k.manager.List()
+}
+
+// Metadata of keyID, not recorded
+func (k *fullKeyManager) Metadata(keyID string) (*kms.KeyMetadata, error) {
+	return k.manager.Metadata(keyID)
+}
+
+// SetLabels replaces the labels of keyID
+func (k *fullKeyManager) SetLabels(keyID string, labels map[string]string) error {
+	err := k.manager.SetLabels(keyID, labels)
+
+	if e := k.log.record(k.component, "SetLabels", keyID, nil, err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// Delete keyID
+func (k *fullKeyManager) Delete(keyID string) error {
+	err := k.manager.Delete(keyID)
+
+	if e := k.log.record(k.component, "Delete", keyID, nil, err); e != nil {
+		return e
+	}
+
+	return err
+}
+
+// ExportPubKeyBytes exports the public key of keyID, not recorded
+func (k *pubKeyKeyManager) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	return k.exporter.ExportPubKeyBytes(keyID)
+}
+
+// audited returns k, embedded in the audited key managers implementing optional interfaces
+func (k *KeyManager) audited() *KeyManager {
+	return k
+}
+
+// recordKey records an operation returning a new key
+func (k *KeyManager) recordKey(operation, keyID string, kh interface{}, err error) (string, interface{}, error) {
+	if e := k.log.record(k.component, operation, keyID, nil, err); e != nil {
+		return "", nil, e
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.log.registerHandle(kh, keyID)
+
+	return keyID, kh, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestKeyManager(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	km, ok := log.KeyManager(localKMS, ComponentAgent).(fullKMS)
+	require.True(t, ok)
+
+	keyID, kh, err := km.Create(kms.ED25519Type)
+	require.NoError(t, err)
+	require.Equal(t, keyID, log.keyID(kh))
+
+	kh, err = km.Get(keyID)
+	require.NoError(t, err)
+	require.Equal(t, keyID, log.keyID(kh))
+
+	_, err = km.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	require.NoError(t, km.SetLabels(keyID, map[string]string{"purpose": "test"}))
+
+	_, err = km.List()
+	require.NoError(t, err)
+
+	_, err = km.Metadata(keyID)
+	require.NoError(t, err)
+
+	rotatedKeyID, kh, err := km.Rotate(kms.ED25519Type, keyID)
+	require.NoError(t, err)
+	require.Equal(t, rotatedKeyID, log.keyID(kh))
+
+	_, err = km.Get("unknown")
+	require.Error(t, err)
+
+	require.NoError(t, km.Delete(rotatedKeyID))
+	require.Error(t, km.Delete(rotatedKeyID))
+
+	records, err := log.Query(&Query{})
+	require.NoError(t, err)
+
+	var operations []string
+
+	for _, record := range records {
+		require.Equal(t, ComponentAgent, record.Component)
+
+		operations = append(operations, record.Operation)
+	}
+
+	// reading keys and their metadata isn't recorded
+	require.Equal(t, []string{"Create", "SetLabels", "Rotate", "Delete", "Delete"}, operations)
+	require.Equal(t, keyID, records[2].KeyID)
+	require.Equal(t, OutcomeFailure, records[4].Outcome)
+
+	t.Run("audited key manager is unwrapped", func(t *testing.T) {
+		require.Equal(t, localKMS, log.KeyManager(km, ComponentPacker).(auditedKeyManager).audited().km)
+	})
+}
+
+func TestKeyManager_Import(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	handle := &keyset.Handle{}
+
+	km, ok := log.KeyManager(&fullKeyManagerMock{KeyManager: &mockkms.KeyManager{
+		ImportPrivateKeyID:      "imported",
+		ImportPrivateKeyValue:   handle,
+		ExportEncryptedKeyValue: []byte("encrypted"),
+		ImportEncryptedKeyErr:   errors.New("import error"),
+	}}, ComponentLegacyKMS).(fullKMS)
+	require.True(t, ok)
+
+	keyID, kh, err := km.ImportPrivateKey(nil, kms.ED25519Type)
+	require.NoError(t, err)
+	require.Equal(t, "imported", keyID)
+	require.Equal(t, "imported", log.keyID(kh))
+
+	_, err = km.ExportEncryptedPrivateKey("imported", "passphrase")
+	require.NoError(t, err)
+
+	_, _, err = km.ImportEncryptedPrivateKey([]byte("encrypted"), "passphrase")
+	require.EqualError(t, err, "import error")
+
+	records, err := log.Query(&Query{Component: ComponentLegacyKMS})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, "ExportEncryptedPrivateKey", records[1].Operation)
+	require.Equal(t, "imported", records[1].KeyID)
+	require.Equal(t, OutcomeFailure, records[2].Outcome)
+	require.Equal(t, "import error", records[2].Error)
+}
+
+// keyManager is a kms.KeyManager without any of the optional interfaces
+type keyManager struct {
+	kms.KeyManager
+}
+
+// pubKeyKeyManagerMock is a kms.KeyManager only exporting public keys, like pkcs11kms
+type pubKeyKeyManagerMock struct {
+	kms.KeyManager
+}
+
+func (k *pubKeyKeyManagerMock) ExportPubKeyBytes(string) ([]byte, error) {
+	return []byte("public key"), nil
+}
+
+// fullKeyManagerMock is a mock kms.KeyManager implementing all the optional interfaces
+type fullKeyManagerMock struct {
+	*mockkms.KeyManager
+}
+
+func (k *fullKeyManagerMock) ExportPubKeyBytes(string) ([]byte, error) {
+	return []byte("public key"), nil
+}
+
+func TestKeyManager_NotSupported(t *testing.T) {
+	log, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	requireNotImplemented := func(t *testing.T, km kms.KeyManager) {
+		t.Helper()
+
+		_, ok := km.(kms.PrivateKeyImporter)
+		require.False(t, ok)
+
+		_, ok = km.(kms.PrivateKeyExporter)
+		require.False(t, ok)
+
+		_, ok = km.(kms.KeyMetadataManager)
+		require.False(t, ok)
+	}
+
+	t.Run("no optional interface", func(t *testing.T) {
+		km := log.KeyManager(&keyManager{KeyManager: &mockkms.KeyManager{}}, ComponentAgent)
+
+		requireNotImplemented(t, km)
+
+		_, ok := km.(pubKeyExporter)
+		require.False(t, ok)
+	})
+
+	t.Run("public key export only", func(t *testing.T) {
+		km := log.KeyManager(&pubKeyKeyManagerMock{KeyManager: &mockkms.KeyManager{}}, ComponentAgent)
+
+		requireNotImplemented(t, km)
+
+		exporter, ok := km.(pubKeyExporter)
+		require.True(t, ok)
+
+		pubKey, err := exporter.ExportPubKeyBytes("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("public key"), pubKey)
+	})
+
+	t.Run("optional interfaces without public key export", func(t *testing.T) {
+		km := log.KeyManager(&mockkms.KeyManager{}, ComponentAgent)
+
+		requireNotImplemented(t, km)
+
+		_, ok := km.(pubKeyExporter)
+		require.False(t, ok)
+	})
+}
+
+func TestKeyManager_RecordError(t *testing.T) {
+	store := &mockstorage.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+
+	log, err := New(mockstorage.NewCustomMockStoreProvider(store))
+	require.NoError(t, err)
+
+	km, ok := log.KeyManager(&fullKeyManagerMock{
+		KeyManager: &mockkms.KeyManager{CreateKeyID: "key", CreateKeyValue: &keyset.Handle{}},
+	}, ComponentAgent).(fullKMS)
+	require.True(t, ok)
+
+	// the key isn't returned if its creation can't be recorded
+	keyID, kh, err := km.Create(kms.ED25519Type)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "put error")
+	require.Empty(t, keyID)
+	require.Nil(t, kh)
+
+	_, _, err = km.Rotate(kms.ED25519Type, "key")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "put error")
+
+	_, err = km.ExportEncryptedPrivateKey("key", "passphrase")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "put error")
+
+	require.Error(t, km.SetLabels("key", nil))
+	require.Error(t, km.Delete("key"))
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/client/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
 	protocolDidexchange "github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
@@ -54,7 +55,7 @@ func mockContext(agent string, tr map[string]chan payload, done chan struct{}) P
 	outbound := dispatcherMocks.NewMockOutbound(ctrl)
 	outbound.EXPECT().
 		SendToDID(gomock.Any(), gomock.Any(), gomock.Any()).
-		DoAndReturn(func(msg interface{}, myDID, theirDID string) error {
+		DoAndReturn(func(msg interface{}, myDID, theirDID string, _ ...dispatcher.SendOpts) error {
 			src, err := json.Marshal(msg)
 			if err != nil {
 				fmt.Println(err)
@@ -7,13 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package kms
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
 )
 
 var logger = log.New("aries-framework/command/kms")
@@ -22,6 +32,33 @@ var logger = log.New("aries-framework/command/kms")
 const (
 	// CreateKeySetError is for failures while creating key set
 	CreateKeySetError = command.Code(iota + command.KMS)
+
+	// InvalidRequestErrorCode is typically a code for invalid requests
+	InvalidRequestErrorCode
+
+	// ImportKeyError is for failures while importing a key
+	ImportKeyError
+
+	// ExportKeyError is for failures while exporting a key
+	ExportKeyError
+
+	// ListKeysError is for failures while listing keys
+	ListKeysError
+
+	// GetKeyMetadataError is for failures while getting the metadata of a key
+	GetKeyMetadataError
+
+	// SetKeyLabelsError is for failures while setting the labels of a key
+	SetKeyLabelsError
+
+	// DeleteKeyError is for failures while deleting a key
+	DeleteKeyError
+
+	// RecoverMasterKeyError is for failures while recovering the master key from recovery shares
+	RecoverMasterKeyError
+
+	// QueryKeyAuditError is for failures while querying the key usage audit log
+	QueryKeyAuditError
 )
 
 const (
@@ -29,12 +66,38 @@ const (
 	commandName = "kms"
 
 	// command methods
-	createKeySetCommandMethod = "CreateKeySet"
+	createKeySetCommandMethod       = "CreateKeySet"
+	importKeyCommandMethod          = "ImportKey"
+	exportKeyCommandMethod          = "ExportKey"
+	importEncryptedKeyCommandMethod = "ImportEncryptedKey"
+	listKeysCommandMethod           = "ListKeys"
+	getKeyMetadataCommandMethod     = "GetKeyMetadata"
+	setKeyLabelsCommandMethod       = "SetKeyLabels"
+	deleteKeyCommandMethod          = "DeleteKey"
+	recoverMasterKeyCommandMethod   = "RecoverMasterKey"
+	queryKeyAuditCommandMethod      = "QueryKeyAudit"
+
+	// error messages
+	errEmptyKeyType      = "key type is mandatory"
+	errEmptyPrivateKey   = "private key is mandatory"
+	errEmptyKeyID        = "key id is mandatory"
+	errEmptyPassphrase   = "passphrase is mandatory"
+	errEmptyEncryptedKey = "encrypted key is mandatory"
+	errEmptyShares       = "recovery shares are mandatory"
+)
+
+var (
+	errImportNotSupported   = errors.New("kms does not support private key import")
+	errExportNotSupported   = errors.New("kms does not support private key export")
+	errMetadataNotSupported = errors.New("kms does not support key metadata")
+	errKeyAuditNotEnabled   = errors.New("key usage audit is not enabled")
 )
 
 // provider contains dependencies for the kms command and is typically created by using aries.Context().
 type provider interface {
 	LegacyKMS() legacykms.KeyManager
+	KMS() kmsapi.KeyManager
+	KeyAuditLog() *audit.Log
 }
 
 // Command contains command operations provided by verifiable credential controller.
@@ -53,6 +116,15 @@ func New(p provider) *Command {
 func (o *Command) GetHandlers() []command.Handler {
 	return []command.Handler{
 		cmdutil.NewCommandHandler(commandName, createKeySetCommandMethod, o.CreateKeySet),
+		cmdutil.NewCommandHandler(commandName, importKeyCommandMethod, o.ImportKey),
+		cmdutil.NewCommandHandler(commandName, exportKeyCommandMethod, o.ExportKey),
+		cmdutil.NewCommandHandler(commandName, importEncryptedKeyCommandMethod, o.ImportEncryptedKey),
+		cmdutil.NewCommandHandler(commandName, listKeysCommandMethod, o.ListKeys),
+		cmdutil.NewCommandHandler(commandName, getKeyMetadataCommandMethod, o.GetKeyMetadata),
+		cmdutil.NewCommandHandler(commandName, setKeyLabelsCommandMethod, o.SetKeyLabels),
+		cmdutil.NewCommandHandler(commandName, deleteKeyCommandMethod, o.DeleteKey),
+		cmdutil.NewCommandHandler(commandName, recoverMasterKeyCommandMethod, o.RecoverMasterKey),
+		cmdutil.NewCommandHandler(commandName, queryKeyAuditCommandMethod, o.QueryKeyAudit),
 	}
 }
 
@@ -73,3 +145,352 @@ func (o *Command) CreateKeySet(rw io.Writer, req io.Reader) command.Error {
 
 	return nil
 }
+
+// ImportKey imports a private key in JWK format into the KMS.
+func (o *Command) ImportKey(rw io.Writer, req io.Reader) command.Error {
+	var request ImportKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, importKeyCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyType == "" {
+		logutil.LogDebug(logger, commandName, importKeyCommandMethod, errEmptyKeyType)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyType))
+	}
+
+	if request.PrivateKey == nil || request.PrivateKey.IsPublic() {
+		logutil.LogDebug(logger, commandName, importKeyCommandMethod, errEmptyPrivateKey)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPrivateKey))
+	}
+
+	importer, ok := o.ctx.KMS().(kmsapi.PrivateKeyImporter)
+	if !ok {
+		logutil.LogError(logger, commandName, importKeyCommandMethod, errImportNotSupported.Error())
+		return command.NewExecuteError(ImportKeyError, errImportNotSupported)
+	}
+
+	keyID, _, err := importer.ImportPrivateKey(request.PrivateKey.Key, kmsapi.KeyType(request.KeyType))
+	if err != nil {
+		logutil.LogError(logger, commandName, importKeyCommandMethod, err.Error())
+		return command.NewExecuteError(ImportKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ImportKeyResponse{KeyID: keyID}, logger)
+
+	logutil.LogDebug(logger, commandName, importKeyCommandMethod, "success")
+
+	return nil
+}
+
+// ExportKey exports a private key of the KMS encrypted with a passphrase.
+func (o *Command) ExportKey(rw io.Writer, req io.Reader) command.Error {
+	var request ExportKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, exportKeyCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, exportKeyCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, exportKeyCommandMethod, errEmptyPassphrase)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPassphrase))
+	}
+
+	exporter, ok := o.ctx.KMS().(kmsapi.PrivateKeyExporter)
+	if !ok {
+		logutil.LogError(logger, commandName, exportKeyCommandMethod, errExportNotSupported.Error())
+		return command.NewExecuteError(ExportKeyError, errExportNotSupported)
+	}
+
+	encryptedKey, err := exporter.ExportEncryptedPrivateKey(request.KeyID, request.Passphrase)
+	if err != nil {
+		logutil.LogError(logger, commandName, exportKeyCommandMethod, err.Error())
+		return command.NewExecuteError(ExportKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ExportKeyResponse{EncryptedKey: encryptedKey}, logger)
+
+	logutil.LogDebug(logger, commandName, exportKeyCommandMethod, "success")
+
+	return nil
+}
+
+// ImportEncryptedKey imports a private key exported with ExportKey into the KMS.
+func (o *Command) ImportEncryptedKey(rw io.Writer, req io.Reader) command.Error {
+	var request ImportEncryptedKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, importEncryptedKeyCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if len(request.EncryptedKey) == 0 {
+		logutil.LogDebug(logger, commandName, importEncryptedKeyCommandMethod, errEmptyEncryptedKey)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyEncryptedKey))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, importEncryptedKeyCommandMethod, errEmptyPassphrase)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPassphrase))
+	}
+
+	exporter, ok := o.ctx.KMS().(kmsapi.PrivateKeyExporter)
+	if !ok {
+		logutil.LogError(logger, commandName, importEncryptedKeyCommandMethod, errImportNotSupported.Error())
+		return command.NewExecuteError(ImportKeyError, errImportNotSupported)
+	}
+
+	keyID, _, err := exporter.ImportEncryptedPrivateKey(request.EncryptedKey, request.Passphrase)
+	if err != nil {
+		logutil.LogError(logger, commandName, importEncryptedKeyCommandMethod, err.Error())
+		return command.NewExecuteError(ImportKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ImportKeyResponse{KeyID: keyID}, logger)
+
+	logutil.LogDebug(logger, commandName, importEncryptedKeyCommandMethod, "success")
+
+	return nil
+}
+
+// ListKeys lists the keys of the KMS with their metadata.
+func (o *Command) ListKeys(rw io.Writer, req io.Reader) command.Error {
+	manager, ok := o.ctx.KMS().(kmsapi.KeyMetadataManager)
+	if !ok {
+		logutil.LogError(logger, commandName, listKeysCommandMethod, errMetadataNotSupported.Error())
+		return command.NewExecuteError(ListKeysError, errMetadataNotSupported)
+	}
+
+	keys, err := manager.List()
+	if err != nil {
+		logutil.LogError(logger, commandName, listKeysCommandMethod, err.Error())
+		return command.NewExecuteError(ListKeysError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ListKeysResponse{Keys: keys}, logger)
+
+	logutil.LogDebug(logger, commandName, listKeysCommandMethod, "success")
+
+	return nil
+}
+
+// GetKeyMetadata returns the metadata of a key of the KMS.
+func (o *Command) GetKeyMetadata(rw io.Writer, req io.Reader) command.Error {
+	var request GetKeyMetadataRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, getKeyMetadataCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, getKeyMetadataCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	manager, ok := o.ctx.KMS().(kmsapi.KeyMetadataManager)
+	if !ok {
+		logutil.LogError(logger, commandName, getKeyMetadataCommandMethod, errMetadataNotSupported.Error())
+		return command.NewExecuteError(GetKeyMetadataError, errMetadataNotSupported)
+	}
+
+	metadata, err := manager.Metadata(request.KeyID)
+	if err != nil {
+		logutil.LogError(logger, commandName, getKeyMetadataCommandMethod, err.Error())
+		return command.NewExecuteError(GetKeyMetadataError, err)
+	}
+
+	command.WriteNillableResponse(rw, &GetKeyMetadataResponse{Metadata: metadata}, logger)
+
+	logutil.LogDebug(logger, commandName, getKeyMetadataCommandMethod, "success")
+
+	return nil
+}
+
+// SetKeyLabels replaces the labels of a key of the KMS.
+func (o *Command) SetKeyLabels(rw io.Writer, req io.Reader) command.Error {
+	var request SetKeyLabelsRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, setKeyLabelsCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, setKeyLabelsCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	manager, ok := o.ctx.KMS().(kmsapi.KeyMetadataManager)
+	if !ok {
+		logutil.LogError(logger, commandName, setKeyLabelsCommandMethod, errMetadataNotSupported.Error())
+		return command.NewExecuteError(SetKeyLabelsError, errMetadataNotSupported)
+	}
+
+	err = manager.SetLabels(request.KeyID, request.Labels)
+	if err != nil {
+		logutil.LogError(logger, commandName, setKeyLabelsCommandMethod, err.Error())
+		return command.NewExecuteError(SetKeyLabelsError, err)
+	}
+
+	command.WriteNillableResponse(rw, nil, logger)
+
+	logutil.LogDebug(logger, commandName, setKeyLabelsCommandMethod, "success")
+
+	return nil
+}
+
+// DeleteKey deletes a key of the KMS.
+func (o *Command) DeleteKey(rw io.Writer, req io.Reader) command.Error {
+	var request DeleteKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, deleteKeyCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, deleteKeyCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	manager, ok := o.ctx.KMS().(kmsapi.KeyMetadataManager)
+	if !ok {
+		logutil.LogError(logger, commandName, deleteKeyCommandMethod, errMetadataNotSupported.Error())
+		return command.NewExecuteError(DeleteKeyError, errMetadataNotSupported)
+	}
+
+	err = manager.Delete(request.KeyID)
+	if err != nil {
+		logutil.LogError(logger, commandName, deleteKeyCommandMethod, err.Error())
+		return command.NewExecuteError(DeleteKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, nil, logger)
+
+	logutil.LogDebug(logger, commandName, deleteKeyCommandMethod, "success")
+
+	return nil
+}
+
+// RecoverMasterKey recovers the master key of a secret lock from recovery shares generated offline with the
+// aries-agent-rest kms recovery-shares command. The master key is only returned encrypted with the passphrase of the
+// request, as a master key file of the agent, so that it never leaves the agent unprotected.
+func (o *Command) RecoverMasterKey(rw io.Writer, req io.Reader) command.Error {
+	var request RecoverMasterKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, recoverMasterKeyCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if len(request.Shares) == 0 {
+		logutil.LogDebug(logger, commandName, recoverMasterKeyCommandMethod, errEmptyShares)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyShares))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, recoverMasterKeyCommandMethod, errEmptyPassphrase)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPassphrase))
+	}
+
+	masterKey, err := recoverMasterKey(request.Shares, request.Passphrase)
+	if err != nil {
+		logutil.LogError(logger, commandName, recoverMasterKeyCommandMethod, err.Error())
+		return command.NewExecuteError(RecoverMasterKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &RecoverMasterKeyResponse{MasterKey: masterKey}, logger)
+
+	logutil.LogDebug(logger, commandName, recoverMasterKeyCommandMethod, "success")
+
+	return nil
+}
+
+// recoverMasterKey returns the master key of shares encrypted with an Argon2id master lock of passphrase
+func recoverMasterKey(shares []string, passphrase string) (string, error) {
+	r, err := local.MasterKeyFromShares(shares)
+	if err != nil {
+		return "", err
+	}
+
+	encodedMasterKey, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	masterKey, err := base64.URLEncoding.DecodeString(string(encodedMasterKey))
+	if err != nil {
+		return "", err
+	}
+
+	masterLock, err := argon2.NewMasterLock(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	encResponse, err := masterLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(masterKey)})
+	if err != nil {
+		return "", err
+	}
+
+	return encResponse.Ciphertext, nil
+}
+
+// QueryKeyAudit returns the records of the key usage audit log matching the request, in chronological order.
+func (o *Command) QueryKeyAudit(rw io.Writer, req io.Reader) command.Error {
+	var request QueryKeyAuditRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, queryKeyAuditCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	keyAuditLog := o.ctx.KeyAuditLog()
+	if keyAuditLog == nil {
+		logutil.LogError(logger, commandName, queryKeyAuditCommandMethod, errKeyAuditNotEnabled.Error())
+		return command.NewExecuteError(QueryKeyAuditError, errKeyAuditNotEnabled)
+	}
+
+	records, err := keyAuditLog.Query(&audit.Query{
+		KeyID:     request.KeyID,
+		Operation: request.Operation,
+		Component: request.Component,
+		Since:     request.Since,
+		Until:     request.Until,
+		Limit:     request.Limit,
+	})
+	if err != nil {
+		logutil.LogError(logger, commandName, queryKeyAuditCommandMethod, err.Error())
+		return command.NewExecuteError(QueryKeyAuditError, err)
+	}
+
+	command.WriteNillableResponse(rw, &QueryKeyAuditResponse{Records: records}, logger)
+
+	logutil.LogDebug(logger, commandName, queryKeyAuditCommandMethod, "success")
+
+	return nil
+}
@@ -8,32 +8,45 @@ package kms
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	josejwk "github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
-	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
 )
 
 func TestNew(t *testing.T) {
 	t.Run("test new command - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{},
+			KMSValue: &mocklegacykms.CloseableKMS{},
 		})
 		require.NotNil(t, cmd)
 
 		handlers := cmd.GetHandlers()
-		require.Equal(t, 1, len(handlers))
+		require.Equal(t, 10, len(handlers))
 	})
 }
 
 func TestCreateKeySet(t *testing.T) {
 	t.Run("test create key set - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
+			KMSValue: &mocklegacykms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
 				CreateSigningKeyValue: "signingKey"},
 		})
 		require.NotNil(t, cmd)
@@ -53,7 +66,7 @@ func TestCreateKeySet(t *testing.T) {
 
 	t.Run("test create key set - error", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
+			KMSValue: &mocklegacykms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
 		})
 		require.NotNil(t, cmd)
 
@@ -63,3 +76,480 @@ func TestCreateKeySet(t *testing.T) {
 		require.Contains(t, err.Error(), "error create key set")
 	})
 }
+
+// keyManager is a kms.KeyManager which neither imports nor exports keys and doesn't keep key metadata
+type keyManager struct {
+	kmsapi.KeyManager
+}
+
+func TestImportKey(t *testing.T) {
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	request, err := json.Marshal(&ImportKeyRequest{
+		KeyType:    kmsapi.ED25519,
+		PrivateKey: &josejwk.JWK{JSONWebKey: jose.JSONWebKey{Key: privKey}},
+	})
+	require.NoError(t, err)
+
+	t.Run("test import key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportPrivateKeyID: "keyID"}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ImportKey(&b, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response := ImportKeyResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(&response))
+		require.Equal(t, "keyID", response.KeyID)
+	})
+
+	t.Run("test import key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.ImportKey(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Equal(t, command.ValidationError, cmdErr.Type())
+
+		cmdErr = cmd.ImportKey(&b, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyKeyType)
+
+		cmdErr = cmd.ImportKey(&b, bytes.NewBufferString(`{"keyType":"ED25519"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPrivateKey)
+
+		pubKeyRequest, e := json.Marshal(&ImportKeyRequest{
+			KeyType:    kmsapi.ED25519,
+			PrivateKey: &josejwk.JWK{JSONWebKey: jose.JSONWebKey{Key: privKey.Public()}},
+		})
+		require.NoError(t, e)
+
+		cmdErr = cmd.ImportKey(&b, bytes.NewBuffer(pubKeyRequest))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyPrivateKey)
+	})
+
+	t.Run("test import key - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportPrivateKeyErr: fmt.Errorf("import error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ImportKey(&b, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportKeyError, cmdErr.Code())
+		require.Equal(t, command.ExecuteError, cmdErr.Type())
+		require.Contains(t, cmdErr.Error(), "import error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.ImportKey(&b, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportKeyError, cmdErr.Code())
+		require.EqualError(t, cmdErr, errImportNotSupported.Error())
+	})
+}
+
+func TestExportKey(t *testing.T) {
+	request := `{"keyID":"keyID","passphrase":"passphrase"}`
+
+	t.Run("test export key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{ExportEncryptedKeyValue: []byte(`{"keyset":{}}`)},
+		})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ExportKey(&b, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+
+		response := ExportKeyResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(&response))
+		require.JSONEq(t, `{"keyset":{}}`, string(response.EncryptedKey))
+	})
+
+	t.Run("test export key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.ExportKey(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.ExportKey(&b, bytes.NewBufferString(`{"passphrase":"passphrase"}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+
+		cmdErr = cmd.ExportKey(&b, bytes.NewBufferString(`{"keyID":"keyID"}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyPassphrase)
+	})
+
+	t.Run("test export key - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ExportEncryptedKeyErr: fmt.Errorf("export error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ExportKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ExportKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "export error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.ExportKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errExportNotSupported.Error())
+	})
+}
+
+func TestImportEncryptedKey(t *testing.T) {
+	request := `{"encryptedKey":{"keyset":{}},"passphrase":"passphrase"}`
+
+	t.Run("test import encrypted key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportEncryptedKeyID: "keyID"}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ImportEncryptedKey(&b, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+
+		response := ImportKeyResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(&response))
+		require.Equal(t, "keyID", response.KeyID)
+	})
+
+	t.Run("test import encrypted key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.ImportEncryptedKey(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.ImportEncryptedKey(&b, bytes.NewBufferString(`{"passphrase":"passphrase"}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyEncryptedKey)
+
+		cmdErr = cmd.ImportEncryptedKey(&b, bytes.NewBufferString(`{"encryptedKey":{}}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyPassphrase)
+	})
+
+	t.Run("test import encrypted key - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportEncryptedKeyErr: fmt.Errorf("import error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ImportEncryptedKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "import error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.ImportEncryptedKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errImportNotSupported.Error())
+	})
+}
+
+func TestListKeys(t *testing.T) {
+	t.Run("test list keys - success", func(t *testing.T) {
+		keys := []*kmsapi.KeyMetadata{{KeyID: "keyID", KeyType: kmsapi.ED25519Type}}
+
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ListValue: keys}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ListKeys(&b, nil)
+		require.NoError(t, cmdErr)
+
+		response := ListKeysResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(&response))
+		require.Len(t, response.Keys, 1)
+		require.Equal(t, "keyID", response.Keys[0].KeyID)
+		require.Equal(t, kmsapi.ED25519Type, response.Keys[0].KeyType)
+	})
+
+	t.Run("test list keys - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ListErr: fmt.Errorf("list error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.ListKeys(&b, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, ListKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "list error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.ListKeys(&b, nil)
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errMetadataNotSupported.Error())
+	})
+}
+
+func TestGetKeyMetadata(t *testing.T) {
+	request := `{"keyID":"keyID"}`
+
+	t.Run("test get key metadata - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{
+			MetadataValue: &kmsapi.KeyMetadata{KeyID: "keyID", Labels: map[string]string{"k": "v"}},
+		}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.GetKeyMetadata(&b, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+
+		response := GetKeyMetadataResponse{}
+		require.NoError(t, json.NewDecoder(&b).Decode(&response))
+		require.Equal(t, "keyID", response.Metadata.KeyID)
+		require.Equal(t, map[string]string{"k": "v"}, response.Metadata.Labels)
+	})
+
+	t.Run("test get key metadata - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.GetKeyMetadata(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.GetKeyMetadata(&b, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test get key metadata - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{MetadataErr: fmt.Errorf("metadata error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.GetKeyMetadata(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, GetKeyMetadataError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "metadata error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.GetKeyMetadata(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errMetadataNotSupported.Error())
+	})
+}
+
+func TestSetKeyLabels(t *testing.T) {
+	request := `{"keyID":"keyID","labels":{"k":"v"}}`
+
+	t.Run("test set key labels - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.SetKeyLabels(&b, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+	})
+
+	t.Run("test set key labels - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.SetKeyLabels(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.SetKeyLabels(&b, bytes.NewBufferString(`{"labels":{}}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test set key labels - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{SetLabelsErr: fmt.Errorf("labels error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.SetKeyLabels(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, SetKeyLabelsError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "labels error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.SetKeyLabels(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errMetadataNotSupported.Error())
+	})
+}
+
+func TestDeleteKey(t *testing.T) {
+	request := `{"keyID":"keyID"}`
+
+	t.Run("test delete key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.DeleteKey(&b, bytes.NewBufferString(request))
+		require.NoError(t, cmdErr)
+	})
+
+	t.Run("test delete key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.DeleteKey(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.DeleteKey(&b, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test delete key - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{DeleteErr: fmt.Errorf("delete error")}})
+
+		var b bytes.Buffer
+		cmdErr := cmd.DeleteKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, DeleteKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "delete error")
+
+		cmd = New(&mockprovider.Provider{CustomKMS: &keyManager{}})
+
+		cmdErr = cmd.DeleteKey(&b, bytes.NewBufferString(request))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errMetadataNotSupported.Error())
+	})
+}
+
+func TestMasterKeyRecovery(t *testing.T) {
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+
+	encodedMasterKey := base64.URLEncoding.EncodeToString(masterKey)
+
+	secretLock, err := local.NewService(bytes.NewBufferString(encodedMasterKey), nil)
+	require.NoError(t, err)
+
+	// the shares are generated offline by the kms recovery-shares command
+	shares, err := secretLock.(*local.Lock).MasterKeyShares(3, 2)
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{})
+
+	var b bytes.Buffer
+
+	t.Run("test recover master key with passphrase - success", func(t *testing.T) {
+		request, e := json.Marshal(&RecoverMasterKeyRequest{Shares: shares[:2], Passphrase: "secret"})
+		require.NoError(t, e)
+
+		var rb bytes.Buffer
+		cmdErr := cmd.RecoverMasterKey(&rb, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		var response RecoverMasterKeyResponse
+		require.NoError(t, json.Unmarshal(rb.Bytes(), &response))
+
+		masterLock, e := argon2.NewMasterLock("secret")
+		require.NoError(t, e)
+
+		dec, e := masterLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: response.MasterKey})
+		require.NoError(t, e)
+		require.Equal(t, masterKey, []byte(dec.Plaintext))
+
+		// the recovered master key file starts an agent with the Argon2id lock of the passphrase
+		_, e = local.NewService(bytes.NewBufferString(response.MasterKey), masterLock)
+		require.NoError(t, e)
+	})
+
+	t.Run("test recover master key - validation errors", func(t *testing.T) {
+		cmdErr := cmd.RecoverMasterKey(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.RecoverMasterKey(&b, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyShares)
+
+		// the master key isn't returned without a passphrase protecting it
+		request, e := json.Marshal(&RecoverMasterKeyRequest{Shares: shares[1:]})
+		require.NoError(t, e)
+
+		var rb bytes.Buffer
+		cmdErr = cmd.RecoverMasterKey(&rb, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPassphrase)
+		require.NotContains(t, rb.String(), encodedMasterKey)
+	})
+
+	t.Run("test recover master key - execute errors", func(t *testing.T) {
+		request, e := json.Marshal(&RecoverMasterKeyRequest{Shares: shares[:1], Passphrase: "secret"})
+		require.NoError(t, e)
+
+		cmdErr := cmd.RecoverMasterKey(&b, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, RecoverMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "2 master key shares are required, got 1")
+	})
+
+	t.Run("test recovery shares are not generated by the controller", func(t *testing.T) {
+		for _, handler := range cmd.GetHandlers() {
+			require.NotEqual(t, "GenerateRecoveryShares", handler.Method())
+		}
+	})
+}
+
+func TestQueryKeyAudit(t *testing.T) {
+	keyAuditLog, err := audit.New(mockstorage.NewMockStoreProvider())
+	require.NoError(t, err)
+
+	km := keyAuditLog.KeyManager(&mockkms.KeyManager{CreateKeyID: "keyID"}, audit.ComponentAgent)
+
+	_, _, err = km.Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	_, _, err = km.Rotate(kmsapi.ED25519Type, "keyID")
+	require.NoError(t, err)
+
+	t.Run("test query key audit - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KeyAuditLogValue: keyAuditLog})
+
+		var b bytes.Buffer
+		cmdErr := cmd.QueryKeyAudit(&b, bytes.NewBufferString(`{"keyID":"keyID","operation":"Rotate"}`))
+		require.NoError(t, cmdErr)
+
+		var response QueryKeyAuditResponse
+		require.NoError(t, json.Unmarshal(b.Bytes(), &response))
+		require.Len(t, response.Records, 1)
+		require.Equal(t, "Rotate", response.Records[0].Operation)
+		require.Equal(t, audit.ComponentAgent, response.Records[0].Component)
+		require.Equal(t, audit.OutcomeSuccess, response.Records[0].Outcome)
+	})
+
+	t.Run("test query key audit - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KeyAuditLogValue: keyAuditLog})
+
+		var b bytes.Buffer
+		cmdErr := cmd.QueryKeyAudit(&b, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+	})
+
+	t.Run("test query key audit - execute errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KeyAuditLogValue: keyAuditLog})
+
+		var b bytes.Buffer
+		cmdErr := cmd.QueryKeyAudit(&b, bytes.NewBufferString(`{"limit":-1}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, QueryKeyAuditError, cmdErr.Code())
+
+		cmd = New(&mockprovider.Provider{})
+
+		cmdErr = cmd.QueryKeyAudit(&b, bytes.NewBufferString(`{}`))
+		require.Error(t, cmdErr)
+		require.EqualError(t, cmdErr, errKeyAuditNotEnabled.Error())
+	})
+}
@@ -6,6 +6,15 @@ SPDX-License-Identifier: Apache-2.0
 
 package kms
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
 // CreateKeySetResponse for returning key pair
 type CreateKeySetResponse struct {
 	//  encryption public key base58 encoded
@@ -13,3 +22,107 @@ type CreateKeySetResponse struct {
 	//  signature public key base58 encoded
 	SignaturePublicKey string `json:"signaturePublicKey,omitempty"`
 }
+
+// ImportKeyRequest is the request to import a private key
+type ImportKeyRequest struct {
+	// key type of the private key (e.g. ED25519, ECDSAP256)
+	KeyType string `json:"keyType"`
+	// private key in JWK format
+	PrivateKey *jose.JWK `json:"privateKeyJwk"`
+}
+
+// ImportKeyResponse for returning the ID of an imported key
+type ImportKeyResponse struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID"`
+}
+
+// ExportKeyRequest is the request to export a private key encrypted with a passphrase
+type ExportKeyRequest struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID"`
+	// passphrase used to encrypt the exported key
+	Passphrase string `json:"passphrase"`
+}
+
+// ExportKeyResponse for returning an exported key
+type ExportKeyResponse struct {
+	// encrypted key to import with ImportEncryptedKeyRequest
+	EncryptedKey json.RawMessage `json:"encryptedKey"`
+}
+
+// ImportEncryptedKeyRequest is the request to import a private key exported with ExportKeyRequest
+type ImportEncryptedKeyRequest struct {
+	// encrypted key of ExportKeyResponse
+	EncryptedKey json.RawMessage `json:"encryptedKey"`
+	// passphrase used to encrypt the exported key
+	Passphrase string `json:"passphrase"`
+}
+
+// ListKeysResponse for returning the keys of the KMS
+type ListKeysResponse struct {
+	// metadata of the keys
+	Keys []*kmsapi.KeyMetadata `json:"keys"`
+}
+
+// GetKeyMetadataRequest is the request to get the metadata of a key
+type GetKeyMetadataRequest struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID"`
+}
+
+// GetKeyMetadataResponse for returning the metadata of a key
+type GetKeyMetadataResponse struct {
+	// metadata of the key
+	Metadata *kmsapi.KeyMetadata `json:"metadata"`
+}
+
+// SetKeyLabelsRequest is the request to replace the labels of a key
+type SetKeyLabelsRequest struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID"`
+	// labels of the key, replacing the existing ones
+	Labels map[string]string `json:"labels"`
+}
+
+// DeleteKeyRequest is the request to delete a key
+type DeleteKeyRequest struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID"`
+}
+
+// RecoverMasterKeyRequest is the request to recover the master key from recovery shares
+type RecoverMasterKeyRequest struct {
+	// at least threshold recovery shares generated with the aries-agent-rest kms recovery-shares command
+	Shares []string `json:"shares"`
+	// passphrase encrypting the recovered master key
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// RecoverMasterKeyResponse for returning the recovered master key
+type RecoverMasterKeyResponse struct {
+	// master key encrypted with the passphrase, to be stored as master key file of the agent
+	MasterKey string `json:"masterKey"`
+}
+
+// QueryKeyAuditRequest is the request to query the key usage audit log, the empty fields match any record
+type QueryKeyAuditRequest struct {
+	// ID of the key in the KMS
+	KeyID string `json:"keyID,omitempty"`
+	// KMS or crypto operation, e.g. Sign
+	Operation string `json:"operation,omitempty"`
+	// framework component using the key, e.g. packer
+	Component string `json:"component,omitempty"`
+	// earliest time of the records
+	Since time.Time `json:"since,omitempty"`
+	// latest time of the records
+	Until time.Time `json:"until,omitempty"`
+	// maximum number of records (optional)
+	Limit int `json:"limit,omitempty"`
+}
+
+// QueryKeyAuditResponse for returning the key usage audit records
+type QueryKeyAuditResponse struct {
+	// audit records in chronological order
+	Records []*audit.Record `json:"records"`
+}
@@ -20,3 +20,171 @@ type createKeySetRes struct {
 	// in: body
 	kms.CreateKeySetResponse
 }
+
+// importKeyReq model
+//
+// This is used to import a private key
+//
+// swagger:parameters importKey
+type importKeyReq struct { // nolint: unused,deadcode
+	// Params for importing a private key
+	//
+	// in: body
+	Params kms.ImportKeyRequest
+}
+
+// importKeyRes model
+//
+// This is used for returning the ID of an imported key
+//
+// swagger:response importKeyRes
+type importKeyRes struct {
+
+	// in: body
+	kms.ImportKeyResponse
+}
+
+// exportKeyReq model
+//
+// This is used to export a private key
+//
+// swagger:parameters exportKey
+type exportKeyReq struct { // nolint: unused,deadcode
+	// Params for exporting a private key
+	//
+	// in: body
+	Params kms.ExportKeyRequest
+}
+
+// exportKeyRes model
+//
+// This is used for returning an exported private key
+//
+// swagger:response exportKeyRes
+type exportKeyRes struct {
+
+	// in: body
+	kms.ExportKeyResponse
+}
+
+// importEncryptedKeyReq model
+//
+// This is used to import a private key exported with a passphrase
+//
+// swagger:parameters importEncryptedKey
+type importEncryptedKeyReq struct { // nolint: unused,deadcode
+	// Params for importing an exported private key
+	//
+	// in: body
+	Params kms.ImportEncryptedKeyRequest
+}
+
+// emptyRes model
+//
+// swagger:response emptyRes
+type emptyRes struct { // nolint: unused,deadcode
+}
+
+// listKeysRes model
+//
+// This is used for returning the keys of the KMS
+//
+// swagger:response listKeysRes
+type listKeysRes struct {
+
+	// in: body
+	kms.ListKeysResponse
+}
+
+// getKeyMetadataReq model
+//
+// This is used to get the metadata of a key
+//
+// swagger:parameters getKeyMetadata
+type getKeyMetadataReq struct { // nolint: unused,deadcode
+	// Params for getting the metadata of a key
+	//
+	// in: body
+	Params kms.GetKeyMetadataRequest
+}
+
+// getKeyMetadataRes model
+//
+// This is used for returning the metadata of a key
+//
+// swagger:response getKeyMetadataRes
+type getKeyMetadataRes struct {
+
+	// in: body
+	kms.GetKeyMetadataResponse
+}
+
+// setKeyLabelsReq model
+//
+// This is used to replace the labels of a key
+//
+// swagger:parameters setKeyLabels
+type setKeyLabelsReq struct { // nolint: unused,deadcode
+	// Params for setting the labels of a key
+	//
+	// in: body
+	Params kms.SetKeyLabelsRequest
+}
+
+// deleteKeyReq model
+//
+// This is used to delete a key
+//
+// swagger:parameters deleteKey
+type deleteKeyReq struct { // nolint: unused,deadcode
+	// Params for deleting a key
+	//
+	// in: body
+	Params kms.DeleteKeyRequest
+}
+
+// recoverMasterKeyReq model
+//
+// This is used to recover the master key from recovery shares
+//
+// swagger:parameters recoverMasterKey
+type recoverMasterKeyReq struct { // nolint: unused,deadcode
+	// Params for recovering the master key
+	//
+	// in: body
+	Params kms.RecoverMasterKeyRequest
+}
+
+// recoverMasterKeyRes model
+//
+// This is used for returning the recovered master key
+//
+// swagger:response recoverMasterKeyRes
+type recoverMasterKeyRes struct {
+
+	// in: body
+	kms.RecoverMasterKeyResponse
+}
+
+// queryKeyAuditReq model
+//
+// This is used to query the records of the key usage audit log
+//
+// swagger:parameters queryKeyAudit
+type queryKeyAuditReq struct { // nolint: unused,deadcode
+	// Params for querying the key usage audit log
+	//
+	// in: body
+	Params kms.QueryKeyAuditRequest
+}
+
+// queryKeyAuditRes model
+//
+// This is used for returning the records of the key usage audit log
+//
+// swagger:response queryKeyAuditRes
+type queryKeyAuditRes struct {
+
+	// in: body
+	kms.QueryKeyAuditResponse
+}
@@ -9,20 +9,33 @@ package kms
 import (
 	"net/http"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 )
 
 const (
-	kmseOperationID  = "/kms"
-	createKeySetPath = kmseOperationID + "/keyset"
+	kmseOperationID        = "/kms"
+	createKeySetPath       = kmseOperationID + "/keyset"
+	importKeyPath          = kmseOperationID + "/keys/import"
+	exportKeyPath          = kmseOperationID + "/keys/export"
+	importEncryptedKeyPath = kmseOperationID + "/keys/import-encrypted"
+	listKeysPath           = kmseOperationID + "/keys"
+	getKeyMetadataPath     = kmseOperationID + "/keys/metadata"
+	setKeyLabelsPath       = kmseOperationID + "/keys/labels"
+	deleteKeyPath          = kmseOperationID + "/keys/delete"
+	recoverMasterKeyPath   = kmseOperationID + "/recovery/master-key"
+	queryKeyAuditPath      = kmseOperationID + "/audit"
 )
 
 // provider contains dependencies for the kms command and is typically created by using aries.Context().
 type provider interface {
 	LegacyKMS() legacykms.KeyManager
+	KMS() kmsapi.KeyManager
+	KeyAuditLog() *audit.Log
 }
 
 // Operation contains basic common operations provided by controller REST API
@@ -50,6 +63,15 @@ func (o *Operation) GetRESTHandlers() []rest.Handler {
 func (o *Operation) registerHandler() {
 	o.handlers = []rest.Handler{
 		cmdutil.NewHTTPHandler(createKeySetPath, http.MethodPost, o.CreateKeySet),
+		cmdutil.NewHTTPHandler(importKeyPath, http.MethodPost, o.ImportKey),
+		cmdutil.NewHTTPHandler(exportKeyPath, http.MethodPost, o.ExportKey),
+		cmdutil.NewHTTPHandler(importEncryptedKeyPath, http.MethodPost, o.ImportEncryptedKey),
+		cmdutil.NewHTTPHandler(listKeysPath, http.MethodGet, o.ListKeys),
+		cmdutil.NewHTTPHandler(getKeyMetadataPath, http.MethodPost, o.GetKeyMetadata),
+		cmdutil.NewHTTPHandler(setKeyLabelsPath, http.MethodPost, o.SetKeyLabels),
+		cmdutil.NewHTTPHandler(deleteKeyPath, http.MethodPost, o.DeleteKey),
+		cmdutil.NewHTTPHandler(recoverMasterKeyPath, http.MethodPost, o.RecoverMasterKey),
+		cmdutil.NewHTTPHandler(queryKeyAuditPath, http.MethodPost, o.QueryKeyAudit),
 	}
 }
 
@@ -63,3 +85,102 @@ func (o *Operation) registerHandler() {
 func (o *Operation) CreateKeySet(rw http.ResponseWriter, req *http.Request) {
 	rest.Execute(o.command.CreateKeySet, rw, req.Body)
 }
+
+// ImportKey swagger:route POST /kms/keys/import kms importKey
+//
+// Import a private key in JWK format.
+//
+// Responses:
+//    default: genericError
+//        200: importKeyRes
+func (o *Operation) ImportKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ImportKey, rw, req.Body)
+}
+
+// ExportKey swagger:route POST /kms/keys/export kms exportKey
+//
+// Export a private key encrypted with a passphrase.
+//
+// Responses:
+//    default: genericError
+//        200: exportKeyRes
+func (o *Operation) ExportKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ExportKey, rw, req.Body)
+}
+
+// ImportEncryptedKey swagger:route POST /kms/keys/import-encrypted kms importEncryptedKey
+//
+// Import a private key exported with a passphrase.
+//
+// Responses:
+//    default: genericError
+//        200: importKeyRes
+func (o *Operation) ImportEncryptedKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ImportEncryptedKey, rw, req.Body)
+}
+
+// ListKeys swagger:route GET /kms/keys kms listKeys
+//
+// List the keys of the KMS with their metadata.
+//
+// Responses:
+//    default: genericError
+//        200: listKeysRes
+func (o *Operation) ListKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ListKeys, rw, req.Body)
+}
+
+// GetKeyMetadata swagger:route POST /kms/keys/metadata kms getKeyMetadata
+//
+// Get the metadata of a key.
+//
+// Responses:
+//    default: genericError
+//        200: getKeyMetadataRes
+func (o *Operation) GetKeyMetadata(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.GetKeyMetadata, rw, req.Body)
+}
+
+// SetKeyLabels swagger:route POST /kms/keys/labels kms setKeyLabels
+//
+// Replace the labels of a key.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) SetKeyLabels(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.SetKeyLabels, rw, req.Body)
+}
+
+// DeleteKey swagger:route POST /kms/keys/delete kms deleteKey
+//
+// Delete a key.
+//
+// Responses:
+//    default: genericError
+//        200: emptyRes
+func (o *Operation) DeleteKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.DeleteKey, rw, req.Body)
+}
+
+// RecoverMasterKey swagger:route POST /kms/recovery/master-key kms recoverMasterKey
+//
+// Recover the master key from recovery shares.
+//
+// Responses:
+//    default: genericError
+//        200: recoverMasterKeyRes
+func (o *Operation) RecoverMasterKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.RecoverMasterKey, rw, req.Body)
+}
+
+// QueryKeyAudit swagger:route POST /kms/audit kms queryKeyAudit
+//
+// Query the records of the key usage audit log.
+//
+// Responses:
+//    default: genericError
+//        200: queryKeyAuditRes
+func (o *Operation) QueryKeyAudit(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.QueryKeyAudit, rw, req.Body)
+}
@@ -8,6 +8,7 @@ package kms
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,27 +19,34 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
-	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
 )
 
 func TestNew(t *testing.T) {
 	t.Run("test new command - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{},
+			KMSValue: &mocklegacykms.CloseableKMS{},
 		})
 		require.NotNil(t, cmd)
-		require.Equal(t, 1, len(cmd.GetRESTHandlers()))
+		require.Equal(t, 10, len(cmd.GetRESTHandlers()))
 	})
 }
 
 func TestCreateKeySet(t *testing.T) {
 	t.Run("test create key set - success", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
+			KMSValue: &mocklegacykms.CloseableKMS{CreateEncryptionKeyValue: "encryptionKey",
 				CreateSigningKeyValue: "signingKey"},
 		})
 		require.NotNil(t, cmd)
@@ -58,7 +66,7 @@ func TestCreateKeySet(t *testing.T) {
 
 	t.Run("test create key set - error", func(t *testing.T) {
 		cmd := New(&mockprovider.Provider{
-			KMSValue: &mockkms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
+			KMSValue: &mocklegacykms.CloseableKMS{CreateKeyErr: fmt.Errorf("error create key set")},
 		})
 		require.NotNil(t, cmd)
 
@@ -72,6 +80,284 @@ func TestCreateKeySet(t *testing.T) {
 	})
 }
 
+func TestImportKey(t *testing.T) {
+	const jwk = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",` +
+		`"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`
+
+	t.Run("test import key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportPrivateKeyID: "keyID"}})
+
+		handler := lookupHandler(t, cmd, importKeyPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler,
+			bytes.NewBufferString(`{"keyType":"ED25519","privateKeyJwk":`+jwk+`}`), importKeyPath)
+		require.NoError(t, err)
+
+		response := importKeyRes{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Equal(t, "keyID", response.KeyID)
+	})
+
+	t.Run("test import key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		handler := lookupHandler(t, cmd, importKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{}`), importKeyPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, kms.InvalidRequestErrorCode, "key type is mandatory", buf.Bytes())
+	})
+}
+
+func TestExportKey(t *testing.T) {
+	t.Run("test export key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			CustomKMS: &mockkms.KeyManager{ExportEncryptedKeyValue: []byte(`{"keyset":{}}`)},
+		})
+
+		handler := lookupHandler(t, cmd, exportKeyPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler,
+			bytes.NewBufferString(`{"keyID":"keyID","passphrase":"passphrase"}`), exportKeyPath)
+		require.NoError(t, err)
+
+		response := exportKeyRes{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.JSONEq(t, `{"keyset":{}}`, string(response.EncryptedKey))
+	})
+
+	t.Run("test export key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ExportEncryptedKeyErr: fmt.Errorf("export error")}})
+
+		handler := lookupHandler(t, cmd, exportKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler,
+			bytes.NewBufferString(`{"keyID":"keyID","passphrase":"passphrase"}`), exportKeyPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ExportKeyError, "export error", buf.Bytes())
+	})
+}
+
+func TestImportEncryptedKey(t *testing.T) {
+	const request = `{"encryptedKey":{"keyset":{}},"passphrase":"passphrase"}`
+
+	t.Run("test import encrypted key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportEncryptedKeyID: "keyID"}})
+
+		handler := lookupHandler(t, cmd, importEncryptedKeyPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, bytes.NewBufferString(request), importEncryptedKeyPath)
+		require.NoError(t, err)
+
+		response := importKeyRes{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Equal(t, "keyID", response.KeyID)
+	})
+
+	t.Run("test import encrypted key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ImportEncryptedKeyErr: fmt.Errorf("import error")}})
+
+		handler := lookupHandler(t, cmd, importEncryptedKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(request), importEncryptedKeyPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ImportKeyError, "import error", buf.Bytes())
+	})
+}
+
+func TestListKeys(t *testing.T) {
+	t.Run("test list keys - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{
+			ListValue: []*kmsapi.KeyMetadata{{KeyID: "keyID", KeyType: kmsapi.ED25519Type}},
+		}})
+
+		handler := lookupHandler(t, cmd, listKeysPath, http.MethodGet)
+		buf, err := getSuccessResponseFromHandler(handler, nil, listKeysPath)
+		require.NoError(t, err)
+
+		response := listKeysRes{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Len(t, response.Keys, 1)
+		require.Equal(t, "keyID", response.Keys[0].KeyID)
+	})
+
+	t.Run("test list keys - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{ListErr: fmt.Errorf("list error")}})
+
+		handler := lookupHandler(t, cmd, listKeysPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, listKeysPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ListKeysError, "list error", buf.Bytes())
+	})
+}
+
+func TestGetKeyMetadata(t *testing.T) {
+	t.Run("test get key metadata - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{
+			MetadataValue: &kmsapi.KeyMetadata{KeyID: "keyID", RotatedFrom: "oldKeyID"},
+		}})
+
+		handler := lookupHandler(t, cmd, getKeyMetadataPath, http.MethodPost)
+		buf, err := getSuccessResponseFromHandler(handler, bytes.NewBufferString(`{"keyID":"keyID"}`),
+			getKeyMetadataPath)
+		require.NoError(t, err)
+
+		response := getKeyMetadataRes{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Equal(t, "oldKeyID", response.Metadata.RotatedFrom)
+	})
+
+	t.Run("test get key metadata - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		handler := lookupHandler(t, cmd, getKeyMetadataPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{}`), getKeyMetadataPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, kms.InvalidRequestErrorCode, "key id is mandatory", buf.Bytes())
+	})
+}
+
+func TestSetKeyLabels(t *testing.T) {
+	const request = `{"keyID":"keyID","labels":{"k":"v"}}`
+
+	t.Run("test set key labels - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		handler := lookupHandler(t, cmd, setKeyLabelsPath, http.MethodPost)
+		_, err := getSuccessResponseFromHandler(handler, bytes.NewBufferString(request), setKeyLabelsPath)
+		require.NoError(t, err)
+	})
+
+	t.Run("test set key labels - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{SetLabelsErr: fmt.Errorf("labels error")}})
+
+		handler := lookupHandler(t, cmd, setKeyLabelsPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(request), setKeyLabelsPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.SetKeyLabelsError, "labels error", buf.Bytes())
+	})
+}
+
+func TestDeleteKey(t *testing.T) {
+	const request = `{"keyID":"keyID"}`
+
+	t.Run("test delete key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{}})
+
+		handler := lookupHandler(t, cmd, deleteKeyPath, http.MethodPost)
+		_, err := getSuccessResponseFromHandler(handler, bytes.NewBufferString(request), deleteKeyPath)
+		require.NoError(t, err)
+	})
+
+	t.Run("test delete key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{CustomKMS: &mockkms.KeyManager{DeleteErr: fmt.Errorf("delete error")}})
+
+		handler := lookupHandler(t, cmd, deleteKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(request), deleteKeyPath)
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.DeleteKeyError, "delete error", buf.Bytes())
+	})
+}
+
+func TestMasterKeyRecovery(t *testing.T) {
+	secretLock, err := local.NewService(bytes.NewBufferString(base64.URLEncoding.EncodeToString(
+		[]byte("0123456789abcdef0123456789abcdef"))), nil)
+	require.NoError(t, err)
+
+	shares, err := secretLock.(*local.Lock).MasterKeyShares(3, 2)
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{})
+
+	var (
+		handler rest.Handler
+		buf     *bytes.Buffer
+	)
+
+	t.Run("test recover master key - success", func(t *testing.T) {
+		request, e := json.Marshal(&kms.RecoverMasterKeyRequest{Shares: shares[1:], Passphrase: "secret"})
+		require.NoError(t, e)
+
+		handler = lookupHandler(t, cmd, recoverMasterKeyPath, http.MethodPost)
+		buf, e = getSuccessResponseFromHandler(handler, bytes.NewBuffer(request), recoverMasterKeyPath)
+		require.NoError(t, e)
+
+		var response kms.RecoverMasterKeyResponse
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+
+		masterLock, e := argon2.NewMasterLock("secret")
+		require.NoError(t, e)
+
+		dec, e := masterLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: response.MasterKey})
+		require.NoError(t, e)
+		require.Equal(t, "0123456789abcdef0123456789abcdef", dec.Plaintext)
+	})
+
+	t.Run("test recover master key without passphrase - error", func(t *testing.T) {
+		request, e := json.Marshal(&kms.RecoverMasterKeyRequest{Shares: shares[1:]})
+		require.NoError(t, e)
+
+		handler = lookupHandler(t, cmd, recoverMasterKeyPath, http.MethodPost)
+		buf, code, e := sendRequestToHandler(handler, bytes.NewBuffer(request), recoverMasterKeyPath)
+		require.NoError(t, e)
+
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, kms.InvalidRequestErrorCode, "passphrase is mandatory", buf.Bytes())
+	})
+
+	t.Run("test recover master key - error", func(t *testing.T) {
+		handler = lookupHandler(t, cmd, recoverMasterKeyPath, http.MethodPost)
+		buf, code, e := sendRequestToHandler(handler, bytes.NewBufferString(`{"shares":["invalid"],"passphrase":"secret"}`),
+			recoverMasterKeyPath)
+		require.NoError(t, e)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.RecoverMasterKeyError, "failed to decode master key share", buf.Bytes())
+	})
+}
+
+func TestQueryKeyAudit(t *testing.T) {
+	keyAuditLog, err := audit.New(mockstorage.NewMockStoreProvider())
+	require.NoError(t, err)
+
+	_, _, err = keyAuditLog.KeyManager(&mockkms.KeyManager{CreateKeyID: "keyID"}, audit.ComponentAgent).
+		Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	t.Run("test query key audit - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KeyAuditLogValue: keyAuditLog})
+
+		handler := lookupHandler(t, cmd, queryKeyAuditPath, http.MethodPost)
+		buf, e := getSuccessResponseFromHandler(handler, bytes.NewBufferString(`{"keyID":"keyID"}`),
+			queryKeyAuditPath)
+		require.NoError(t, e)
+
+		var response kms.QueryKeyAuditResponse
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Len(t, response.Records, 1)
+		require.Equal(t, "Create", response.Records[0].Operation)
+	})
+
+	t.Run("test query key audit - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{})
+
+		handler := lookupHandler(t, cmd, queryKeyAuditPath, http.MethodPost)
+		buf, code, e := sendRequestToHandler(handler, bytes.NewBufferString(`{}`), queryKeyAuditPath)
+		require.NoError(t, e)
+
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.QueryKeyAuditError, "key usage audit is not enabled", buf.Bytes())
+	})
+}
+
 func lookupHandler(t *testing.T, op *Operation, path, method string) rest.Handler {
 	handlers := op.GetRESTHandlers()
 	require.NotEmpty(t, handlers)
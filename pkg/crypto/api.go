@@ -38,4 +38,118 @@ type Crypto interface {
 	// VerifyMAC determines if mac is a correct authentication code (MAC) for data
 	// using a matching MAC primitive in kh key handle and returns nil if so, otherwise it returns an error.
 	VerifyMAC(mac, data []byte, kh interface{}) error
+	// WrapKey will wrap cek for the recipient public key recPubKey using a key encryption key agreed with
+	// ECDH-ES, or with ECDH-1PU if the sender's private key handle is given with WithSender, and A256KW.
+	// apu and apv are the agreement PartyUInfo and PartyVInfo.
+	// returns:
+	// 		RecipientWrappedKey containing the wrapped cek and the ephemeral public key
+	//		error in case of errors
+	WrapKey(cek, apu, apv []byte, recPubKey *PublicKey, opts ...WrapKeyOpts) (*RecipientWrappedKey, error)
+	// UnwrapKey will unwrap the cek of recWK using the recipient private key in kh key handle. The sender's
+	// public key must be given with WithSender if recWK was wrapped with ECDH-1PU.
+	// returns:
+	// 		unwrapped cek in []byte
+	//		error in case of errors
+	UnwrapKey(recWK *RecipientWrappedKey, kh interface{}, opts ...WrapKeyOpts) ([]byte, error)
+	// SignMulti will create a BBS+ signature of messages using the BBS+ private key in kh key handle
+	// returns:
+	// 		signature in []byte
+	//		error in case of errors
+	SignMulti(messages [][]byte, kh interface{}) ([]byte, error)
+	// VerifyMulti will verify a BBS+ signature of messages using the BBS+ key in kh key handle
+	// returns:
+	// 		error in case of errors or nil if signature verification was successful
+	VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error
+	// DeriveProof will derive from a BBS+ signature of messages a zero-knowledge proof of knowledge of the
+	// signature which only discloses the messages at revealedIndexes, bound to nonce, using the BBS+ key in kh
+	// key handle
+	// returns:
+	// 		proof in []byte
+	//		error in case of errors
+	DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int, kh interface{}) ([]byte, error)
+	// VerifyProof will verify a proof derived with DeriveProof from the revealed messages, in the order of their
+	// indexes, and nonce using the BBS+ key in kh key handle
+	// returns:
+	// 		error in case of errors or nil if proof verification was successful
+	VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error
+}
+
+// KeyAgreement is implemented by Crypto implementations computing the raw ECDH shared secrets of their key handles,
+// needed by the legacy DIDComm envelopes whose crypto box doesn't wrap keys like WrapKey
+type KeyAgreement interface {
+	// SharedSecret computes the ECDH shared secret of the private key in kh key handle and pub. ED25519 key handles
+	// are used as X25519 keys.
+	// returns:
+	// 		shared secret in []byte
+	//		error in case of errors
+	SharedSecret(pub *PublicKey, kh interface{}) ([]byte, error)
+}
+
+const (
+	// ECDHESA256KWAlg is the ECDH-ES key agreement with A256KW key wrapping
+	ECDHESA256KWAlg = "ECDH-ES+A256KW"
+	// ECDH1PUA256KWAlg is the ECDH-1PU key agreement with A256KW key wrapping
+	ECDH1PUA256KWAlg = "ECDH-1PU+A256KW"
+
+	// P256 is the curve name of NIST P-256 keys
+	P256 = "P-256"
+	// P384 is the curve name of NIST P-384 keys
+	P384 = "P-384"
+	// P521 is the curve name of NIST P-521 keys
+	P521 = "P-521"
+	// X25519 is the curve name of X25519 keys
+	X25519 = "X25519"
+
+	// ECKeyType is the type of NIST curves keys
+	ECKeyType = "EC"
+	// OKPKeyType is the type of X25519 keys
+	OKPKeyType = "OKP"
+)
+
+// PublicKey is the public key of a key agreement, its fields are those of a JWK.
+// Y is empty for X25519 keys.
+type PublicKey struct {
+	KID   string `json:"kid,omitempty"`
+	X     []byte `json:"x,omitempty"`
+	Y     []byte `json:"y,omitempty"`
+	Curve string `json:"curve,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// RecipientWrappedKey is a key wrapped for a recipient along with what is needed to unwrap it
+type RecipientWrappedKey struct {
+	KID          string    `json:"kid,omitempty"`
+	EncryptedCEK []byte    `json:"encryptedcek,omitempty"`
+	EPK          PublicKey `json:"epk,omitempty"`
+	Alg          string    `json:"alg,omitempty"`
+	APU          []byte    `json:"apu,omitempty"`
+	APV          []byte    `json:"apv,omitempty"`
+}
+
+// WrapKeyOptions holds the options of WrapKey and UnwrapKey
+type WrapKeyOptions struct {
+	// SenderKey is the sender's private key handle when wrapping, and the sender's public key (a *PublicKey or a
+	// key handle) when unwrapping
+	SenderKey interface{}
+}
+
+// WrapKeyOpts configures WrapKey and UnwrapKey
+type WrapKeyOpts func(opts *WrapKeyOptions)
+
+// WithSender option uses ECDH-1PU with the sender's key instead of ECDH-ES
+func WithSender(senderKey interface{}) WrapKeyOpts {
+	return func(opts *WrapKeyOptions) {
+		opts.SenderKey = senderKey
+	}
+}
+
+// NewWrapKeyOptions applies the given options, it is meant to be used by Crypto implementations
+func NewWrapKeyOptions(opts ...WrapKeyOpts) *WrapKeyOptions {
+	options := &WrapKeyOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
 }
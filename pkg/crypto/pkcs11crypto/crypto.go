@@ -0,0 +1,229 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package pkcs11crypto implements a crypto.Crypto executing its operations with the keys of a PKCS#11 token, with the
+// *pkcs11kms.KeyHandle key handles of the kms.KeyManager of package pkg/kms/pkcs11kms. It signs with ED25519Type keys
+// and ECDSAP256Type keys, the latter signatures being DER encoded like those of tinkcrypto, and wraps keys with ECDH
+// key agreements of ECDSAP256Type keys.
+package pkcs11crypto
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/pkcs11kms"
+)
+
+// p256IntSize is the size of the integers r and s of the P-256 signatures of the token
+const p256IntSize = 32
+
+var (
+	errBadKeyHandleFormat = errors.New("bad key handle format, expected a *pkcs11kms.KeyHandle")
+	errNotSupported       = errors.New("not supported by the PKCS#11 crypto")
+)
+
+// Crypto is a crypto.Crypto of the keys of a PKCS#11 token
+type Crypto struct {
+	module *pkcs11kms.Module
+	// soft executes the operations which don't involve the token's keys
+	soft *tinkcrypto.Crypto
+}
+
+// New creates a new Crypto of the keys of the token of module
+func New(module *pkcs11kms.Module) *Crypto {
+	return &Crypto{module: module, soft: &tinkcrypto.Crypto{}}
+}
+
+// Encrypt is not supported by the PKCS#11 crypto
+func (c *Crypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error) {
+	return nil, nil, fmt.Errorf("encrypt: %w", errNotSupported)
+}
+
+// Decrypt is not supported by the PKCS#11 crypto
+func (c *Crypto) Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("decrypt: %w", errNotSupported)
+}
+
+// Sign will sign msg in the token with the private key of kh
+func (c *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
+	keyHandle, ok := kh.(*pkcs11kms.KeyHandle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	mechanism, data, err := signatureMechanism(keyHandle.KeyType, msg)
+	if err != nil {
+		return nil, fmt.Errorf("sign msg: %w", err)
+	}
+
+	var sig []byte
+
+	err = c.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		privKey, e := pkcs11kms.FindKey(ctx, session, pkcs11.CKO_PRIVATE_KEY, keyHandle.ID)
+		if e != nil {
+			return e
+		}
+
+		if e = ctx.SignInit(session, mechanism, privKey); e != nil {
+			return e
+		}
+
+		sig, e = ctx.Sign(session, data)
+
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign msg: %w", err)
+	}
+
+	if keyHandle.KeyType == kms.ECDSAP256Type {
+		return derSignature(sig)
+	}
+
+	return sig, nil
+}
+
+// Verify will verify the signature of msg in the token with the public key of kh
+func (c *Crypto) Verify(signature, msg []byte, kh interface{}) error {
+	keyHandle, ok := kh.(*pkcs11kms.KeyHandle)
+	if !ok {
+		return errBadKeyHandleFormat
+	}
+
+	mechanism, data, err := signatureMechanism(keyHandle.KeyType, msg)
+	if err != nil {
+		return fmt.Errorf("verify msg: %w", err)
+	}
+
+	if keyHandle.KeyType == kms.ECDSAP256Type {
+		signature, err = rawSignature(signature)
+		if err != nil {
+			return fmt.Errorf("verify msg: %w", err)
+		}
+	}
+
+	err = c.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		pubKey, e := pkcs11kms.FindKey(ctx, session, pkcs11.CKO_PUBLIC_KEY, keyHandle.ID)
+		if e != nil {
+			return e
+		}
+
+		if e = ctx.VerifyInit(session, mechanism, pubKey); e != nil {
+			return e
+		}
+
+		return ctx.Verify(session, data, signature)
+	})
+	if err != nil {
+		return fmt.Errorf("verify msg: %w", err)
+	}
+
+	return nil
+}
+
+// ComputeMAC is not supported by the PKCS#11 crypto
+func (c *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("computeMAC: %w", errNotSupported)
+}
+
+// VerifyMAC is not supported by the PKCS#11 crypto
+func (c *Crypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	return fmt.Errorf("verifyMAC: %w", errNotSupported)
+}
+
+// SignMulti is not supported by the PKCS#11 crypto
+func (c *Crypto) SignMulti(messages [][]byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("signMulti: %w", errNotSupported)
+}
+
+// VerifyMulti is not supported by the PKCS#11 crypto
+func (c *Crypto) VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error {
+	return fmt.Errorf("verifyMulti: %w", errNotSupported)
+}
+
+// DeriveProof is not supported by the PKCS#11 crypto
+func (c *Crypto) DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int,
+	kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("deriveProof: %w", errNotSupported)
+}
+
+// VerifyProof is not supported by the PKCS#11 crypto
+func (c *Crypto) VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error {
+	return fmt.Errorf("verifyProof: %w", errNotSupported)
+}
+
+// signatureMechanism returns the signature mechanism of keys of type kt and the data it signs for msg, the token
+// doesn't hash the messages of ECDSA signatures
+func signatureMechanism(kt kms.KeyType, msg []byte) ([]*pkcs11.Mechanism, []byte, error) {
+	switch kt {
+	case kms.ED25519Type:
+		return []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11kms.CKMEdDSA, nil)}, msg, nil
+	case kms.ECDSAP256Type:
+		digest := sha256.Sum256(msg)
+
+		return []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, digest[:], nil
+	default:
+		return nil, nil, fmt.Errorf("key type %s not supported", kt)
+	}
+}
+
+// ecdsaSignature is the ASN.1 structure of DER encoded ECDSA signatures
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// derSignature DER encodes the r||s ECDSA signature raw of the token
+func derSignature(raw []byte) ([]byte, error) {
+	if len(raw) != 2*p256IntSize {
+		return nil, fmt.Errorf("sign msg: invalid ECDSA signature size %d", len(raw))
+	}
+
+	sig, err := asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(raw[:p256IntSize]),
+		S: new(big.Int).SetBytes(raw[p256IntSize:]),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign msg: %w", err)
+	}
+
+	return sig, nil
+}
+
+// rawSignature decodes the DER encoded ECDSA signature der to the r||s signature verified by the token
+func rawSignature(der []byte) ([]byte, error) {
+	var sig ecdsaSignature
+
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("parse ECDSA signature: %w", err)
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("parse ECDSA signature: trailing data")
+	}
+
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 {
+		return nil, errors.New("parse ECDSA signature: r and s must be positive")
+	}
+
+	r, s := sig.R.Bytes(), sig.S.Bytes()
+	if len(r) > p256IntSize || len(s) > p256IntSize {
+		return nil, errors.New("invalid ECDSA signature size")
+	}
+
+	raw := make([]byte, 2*p256IntSize)
+	copy(raw[p256IntSize-len(r):p256IntSize], r)
+	copy(raw[2*p256IntSize-len(s):], s)
+
+	return raw, nil
+}
@@ -0,0 +1,343 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/pkcs11kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestCrypto_SignVerify(t *testing.T) {
+	module := newTestModule(t)
+
+	defer func() { require.NoError(t, module.Close()) }()
+
+	k := pkcs11kms.New(module)
+	c := New(module)
+
+	msg := []byte("test message")
+
+	for kt, verify := range map[kms.KeyType]func(pubKey, msg, sig []byte) error{
+		kms.ED25519Type: func(pubKey, msg, sig []byte) error {
+			return sigverifier.NewEd25519SignatureVerifier().Verify(&sigverifier.PublicKey{Value: pubKey}, msg, sig)
+		},
+		kms.ECDSAP256Type: verifyDERSignature,
+	} {
+		kt, verify := kt, verify
+
+		t.Run(string(kt), func(t *testing.T) {
+			keyID, kh, err := k.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := suite.NewCryptoSigner(c, kh).Sign(msg)
+			require.NoError(t, err)
+
+			require.NoError(t, c.Verify(sig, msg, kh))
+			require.Error(t, c.Verify(sig, []byte("other message"), kh))
+
+			// the signatures verify with the exported public key outside of the token
+			pubKey, err := k.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			require.NoError(t, verify(pubKey, msg, sig))
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := c.Sign(msg, &pkcs11kms.KeyHandle{ID: []byte("unknown"), KeyType: kms.ED25519Type})
+		require.True(t, errors.Is(err, pkcs11kms.ErrKeyNotFound))
+
+		err = c.Verify(nil, msg, &pkcs11kms.KeyHandle{ID: []byte("unknown"), KeyType: kms.ED25519Type})
+		require.True(t, errors.Is(err, pkcs11kms.ErrKeyNotFound))
+	})
+}
+
+func TestCrypto_WrapUnwrapKey(t *testing.T) {
+	module := newTestModule(t)
+
+	defer func() { require.NoError(t, module.Close()) }()
+
+	k := pkcs11kms.New(module)
+	c := New(module)
+
+	recKeyID, recKH, err := k.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	senderKeyID, senderKH, err := k.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	recPubKey := exportPublicKey(t, k, recKeyID)
+	senderPubKey := exportPublicKey(t, k, senderKeyID)
+
+	cek := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("ECDH-ES", func(t *testing.T) {
+		wk, e := c.WrapKey(cek, []byte("apu"), []byte("apv"), recPubKey)
+		require.NoError(t, e)
+		require.Equal(t, crypto.ECDHESA256KWAlg, wk.Alg)
+
+		unwrapped, e := c.UnwrapKey(wk, recKH)
+		require.NoError(t, e)
+		require.Equal(t, cek, unwrapped)
+	})
+
+	t.Run("ECDH-1PU", func(t *testing.T) {
+		wk, e := c.WrapKey(cek, []byte("apu"), []byte("apv"), recPubKey, crypto.WithSender(senderKH))
+		require.NoError(t, e)
+		require.Equal(t, crypto.ECDH1PUA256KWAlg, wk.Alg)
+
+		unwrapped, e := c.UnwrapKey(wk, recKH, crypto.WithSender(senderPubKey))
+		require.NoError(t, e)
+		require.Equal(t, cek, unwrapped)
+
+		_, e = c.UnwrapKey(wk, recKH)
+		require.EqualError(t, e, "unwrapKey: sender public key is required for ECDH-1PU")
+
+		_, e = c.UnwrapKey(wk, recKH, crypto.WithSender(recPubKey))
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "failed to unwrap key")
+	})
+
+	t.Run("interoperates with tinkcrypto", func(t *testing.T) {
+		localKMS, e := localkms.New("local-lock://test/master/key/",
+			mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+		require.NoError(t, e)
+
+		_, localKH, e := localKMS.Create(kms.ECDSAP256Type)
+		require.NoError(t, e)
+
+		localPubKey, e := tinkcrypto.ExportPublicKey(localKH)
+		require.NoError(t, e)
+
+		wk, e := c.WrapKey(cek, nil, nil, localPubKey, crypto.WithSender(senderKH))
+		require.NoError(t, e)
+
+		unwrapped, e := (&tinkcrypto.Crypto{}).UnwrapKey(wk, localKH, crypto.WithSender(senderPubKey))
+		require.NoError(t, e)
+		require.Equal(t, cek, unwrapped)
+
+		wk, e = (&tinkcrypto.Crypto{}).WrapKey(cek, nil, nil, recPubKey, crypto.WithSender(localKH))
+		require.NoError(t, e)
+
+		unwrapped, e = c.UnwrapKey(wk, recKH, crypto.WithSender(localPubKey))
+		require.NoError(t, e)
+		require.Equal(t, cek, unwrapped)
+	})
+}
+
+func TestCrypto_Framework(t *testing.T) {
+	module := newTestModule(t)
+
+	defer func() { require.NoError(t, module.Close()) }()
+
+	framework, err := aries.New(
+		aries.WithKMS(func(kms.Provider) (kms.KeyManager, error) {
+			return pkcs11kms.New(module), nil
+		}),
+		aries.WithCrypto(New(module)))
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, framework.Close()) }()
+
+	ctx, err := framework.Context()
+	require.NoError(t, err)
+
+	_, kh, err := ctx.KMS().Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	sig, err := suite.NewCryptoSigner(ctx.Crypto(), kh).Sign([]byte("test message"))
+	require.NoError(t, err)
+	require.NoError(t, ctx.Crypto().Verify(sig, []byte("test message"), kh))
+}
+
+func TestCrypto_Errors(t *testing.T) {
+	c := New(nil)
+
+	_, err := c.Sign(nil, "key")
+	require.Equal(t, errBadKeyHandleFormat, err)
+
+	err = c.Verify(nil, nil, "key")
+	require.Equal(t, errBadKeyHandleFormat, err)
+
+	unsupportedKH := &pkcs11kms.KeyHandle{KeyType: kms.BLS12381G2Type}
+
+	_, err = c.Sign(nil, unsupportedKH)
+	require.EqualError(t, err, "sign msg: key type BLS12381G2 not supported")
+
+	err = c.Verify(nil, nil, unsupportedKH)
+	require.EqualError(t, err, "verify msg: key type BLS12381G2 not supported")
+
+	_, err = c.WrapKey(nil, nil, nil, nil)
+	require.EqualError(t, err, "wrapKey: recipient public key is required")
+
+	_, err = c.WrapKey(nil, nil, nil, &crypto.PublicKey{}, crypto.WithSender("key"))
+	require.True(t, errors.Is(err, errBadKeyHandleFormat))
+
+	edKH := &pkcs11kms.KeyHandle{KeyType: kms.ED25519Type}
+	p256KH := &pkcs11kms.KeyHandle{KeyType: kms.ECDSAP256Type}
+
+	_, err = c.WrapKey(nil, nil, nil, &crypto.PublicKey{Curve: crypto.P256}, crypto.WithSender(edKH))
+	require.EqualError(t, err, "wrapKey: key agreement with key type ED25519 not supported")
+
+	_, err = c.WrapKey(nil, nil, nil, &crypto.PublicKey{Curve: crypto.X25519}, crypto.WithSender(p256KH))
+	require.EqualError(t, err, "wrapKey: public key is not on curve P-256")
+
+	_, err = c.WrapKey(nil, nil, nil, &crypto.PublicKey{Curve: crypto.P256, X: []byte{1}, Y: []byte{2}},
+		crypto.WithSender(p256KH))
+	require.EqualError(t, err, "wrapKey: public key is not on curve P-256")
+
+	_, err = c.UnwrapKey(nil, p256KH)
+	require.EqualError(t, err, "unwrapKey: recipient wrapped key is required")
+
+	_, err = c.UnwrapKey(&crypto.RecipientWrappedKey{}, "key")
+	require.Equal(t, errBadKeyHandleFormat, err)
+
+	_, err = c.UnwrapKey(&crypto.RecipientWrappedKey{}, p256KH)
+	require.EqualError(t, err, "unwrapKey: public key is not on curve P-256")
+}
+
+func TestCrypto_ECDSASignatureEncoding(t *testing.T) {
+	raw := make([]byte, 2*p256IntSize)
+	raw[p256IntSize-1] = 1
+	raw[p256IntSize] = 0xff
+
+	der, err := derSignature(raw)
+	require.NoError(t, err)
+
+	sig := ecdsaSignature{}
+	_, err = asn1.Unmarshal(der, &sig)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), sig.R.Int64())
+	require.Equal(t, new(big.Int).SetBytes(raw[p256IntSize:]), sig.S)
+
+	decoded, err := rawSignature(der)
+	require.NoError(t, err)
+	require.Equal(t, raw, decoded)
+
+	_, err = derSignature(raw[1:])
+	require.EqualError(t, err, "sign msg: invalid ECDSA signature size 63")
+
+	_, err = rawSignature(raw)
+	require.Error(t, err)
+
+	_, err = rawSignature(append(der, 0))
+	require.EqualError(t, err, "parse ECDSA signature: trailing data")
+
+	zero, err := asn1.Marshal(ecdsaSignature{R: big.NewInt(0), S: big.NewInt(1)})
+	require.NoError(t, err)
+
+	_, err = rawSignature(zero)
+	require.EqualError(t, err, "parse ECDSA signature: r and s must be positive")
+
+	large, err := asn1.Marshal(ecdsaSignature{R: new(big.Int).Lsh(big.NewInt(1), 8*p256IntSize), S: big.NewInt(1)})
+	require.NoError(t, err)
+
+	_, err = rawSignature(large)
+	require.EqualError(t, err, "invalid ECDSA signature size")
+
+	err = New(nil).Verify(raw, nil, &pkcs11kms.KeyHandle{KeyType: kms.ECDSAP256Type})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "verify msg: parse ECDSA signature: ")
+}
+
+func TestCrypto_NotSupported(t *testing.T) {
+	c := New(nil)
+
+	_, _, err := c.Encrypt(nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = c.Decrypt(nil, nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = c.ComputeMAC(nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = c.VerifyMAC(nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = c.SignMulti(nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = c.VerifyMulti(nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = c.DeriveProof(nil, nil, nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = c.VerifyProof(nil, nil, nil, nil)
+	require.True(t, errors.Is(err, errNotSupported))
+}
+
+// verifyDERSignature verifies the DER encoded P-256 signature, as tinkcrypto produces them, with the exported pubKey
+func verifyDERSignature(pubKey, msg, signature []byte) error {
+	x, y := elliptic.Unmarshal(elliptic.P256(), pubKey)
+	if x == nil {
+		return errors.New("invalid public key")
+	}
+
+	sig := ecdsaSignature{}
+
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(msg)
+
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, digest[:], sig.R, sig.S) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func exportPublicKey(t *testing.T, k *pkcs11kms.KMS, keyID string) *crypto.PublicKey {
+	t.Helper()
+
+	pubKey, err := k.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), pubKey)
+	require.NotNil(t, x)
+
+	return &crypto.PublicKey{X: x.Bytes(), Y: y.Bytes(), Curve: crypto.P256, Type: crypto.ECKeyType}
+}
+
+// newTestModule returns a module of the token configured with the PKCS11_LIBRARY, PKCS11_TOKEN_LABEL and PKCS11_PIN
+// environment variables, skipping the test if they are not set
+func newTestModule(t *testing.T) *pkcs11kms.Module {
+	t.Helper()
+
+	lib := os.Getenv("PKCS11_LIBRARY")
+	if lib == "" {
+		t.Skip("PKCS11_LIBRARY is not set, skipping PKCS#11 token tests")
+	}
+
+	module, err := pkcs11kms.NewModule(lib, os.Getenv("PKCS11_TOKEN_LABEL"), os.Getenv("PKCS11_PIN"))
+	require.NoError(t, err)
+
+	return module
+}
@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11crypto
+
+import (
+	"crypto/aes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miekg/pkcs11"
+	josecipher "github.com/square/go-jose/v3/cipher"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/pkcs11kms"
+)
+
+// p256Size is the size of the coordinates and shared secrets of P-256
+const p256Size = 32
+
+// WrapKey will wrap cek for the recipient public key recPubKey with A256KW using a key encryption key agreed with
+// ECDH-ES, or with ECDH-1PU if the sender's *pkcs11kms.KeyHandle of an ECDSAP256Type key is given with
+// crypto.WithSender. Only the sender's shared secret is computed in the token, ECDH-ES doesn't involve its keys.
+func (c *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *crypto.PublicKey,
+	opts ...crypto.WrapKeyOpts) (*crypto.RecipientWrappedKey, error) {
+	if recPubKey == nil {
+		return nil, errors.New("wrapKey: recipient public key is required")
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	if pOpts.SenderKey == nil {
+		return c.soft.WrapKey(cek, apu, apv, recPubKey)
+	}
+
+	senderKH, ok := pOpts.SenderKey.(*pkcs11kms.KeyHandle)
+	if !ok {
+		return nil, fmt.Errorf("wrapKey: sender key: %w", errBadKeyHandleFormat)
+	}
+
+	zs, err := c.sharedSecret(senderKH, recPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	epk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: failed to generate ephemeral key: %w", err)
+	}
+
+	zx, _ := epk.Curve.ScalarMult(new(big.Int).SetBytes(recPubKey.X), new(big.Int).SetBytes(recPubKey.Y),
+		epk.D.Bytes())
+
+	// the shared secret is the x coordinate, left padded to the size of the curve
+	zBytes := zx.Bytes()
+	ze := make([]byte, p256Size)
+	copy(ze[p256Size-len(zBytes):], zBytes)
+
+	wrapped, err := wrap(cek, cryptoutil.DeriveA256KWKey(append(ze, zs...), crypto.ECDH1PUA256KWAlg, apu, apv))
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	return &crypto.RecipientWrappedKey{
+		KID:          recPubKey.KID,
+		EncryptedCEK: wrapped,
+		EPK:          crypto.PublicKey{X: epk.X.Bytes(), Y: epk.Y.Bytes(), Curve: crypto.P256, Type: crypto.ECKeyType},
+		Alg:          crypto.ECDH1PUA256KWAlg,
+		APU:          apu,
+		APV:          apv,
+	}, nil
+}
+
+// UnwrapKey will unwrap the cek of recWK with the shared secrets computed in the token with the recipient's
+// ECDSAP256Type key of kh. The sender's *crypto.PublicKey must be given with crypto.WithSender if recWK was wrapped
+// with ECDH-1PU.
+func (c *Crypto) UnwrapKey(recWK *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	if recWK == nil {
+		return nil, errors.New("unwrapKey: recipient wrapped key is required")
+	}
+
+	keyHandle, ok := kh.(*pkcs11kms.KeyHandle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	z, err := c.sharedSecret(keyHandle, &recWK.EPK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	switch recWK.Alg {
+	case crypto.ECDHESA256KWAlg:
+	case crypto.ECDH1PUA256KWAlg:
+		senderPubKey, isPubKey := pOpts.SenderKey.(*crypto.PublicKey)
+		if !isPubKey {
+			return nil, errors.New("unwrapKey: sender public key is required for ECDH-1PU")
+		}
+
+		zs, e := c.sharedSecret(keyHandle, senderPubKey)
+		if e != nil {
+			return nil, fmt.Errorf("unwrapKey: %w", e)
+		}
+
+		z = append(z, zs...)
+	default:
+		return nil, fmt.Errorf("unwrapKey: unsupported algorithm %s", recWK.Alg)
+	}
+
+	block, err := aes.NewCipher(cryptoutil.DeriveA256KWKey(z, recWK.Alg, recWK.APU, recWK.APV))
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	cek, err := josecipher.KeyUnwrap(block, recWK.EncryptedCEK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: failed to unwrap key: %w", err)
+	}
+
+	return cek, nil
+}
+
+func wrap(cek, kek []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := josecipher.KeyWrap(block, cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap key: %w", err)
+	}
+
+	return wrapped, nil
+}
+
+// sharedSecret computes in the token the ECDH shared secret of the private key of kh and pub with CKM_ECDH1_DERIVE
+func (c *Crypto) sharedSecret(kh *pkcs11kms.KeyHandle, pub *crypto.PublicKey) ([]byte, error) {
+	if kh.KeyType != kms.ECDSAP256Type {
+		return nil, fmt.Errorf("key agreement with key type %s not supported", kh.KeyType)
+	}
+
+	if pub == nil || pub.Curve != crypto.P256 {
+		return nil, fmt.Errorf("public key is not on curve %s", crypto.P256)
+	}
+
+	x, y := new(big.Int).SetBytes(pub.X), new(big.Int).SetBytes(pub.Y)
+	if !elliptic.P256().IsOnCurve(x, y) {
+		return nil, fmt.Errorf("public key is not on curve %s", crypto.P256)
+	}
+
+	params := pkcs11.NewECDH1DeriveParams(pkcs11.CKD_NULL, nil, elliptic.Marshal(elliptic.P256(), x, y))
+
+	var z []byte
+
+	err := c.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		privKey, e := pkcs11kms.FindKey(ctx, session, pkcs11.CKO_PRIVATE_KEY, kh.ID)
+		if e != nil {
+			return e
+		}
+
+		// the shared secret is derived as a session object readable to get its value
+		secret, e := ctx.DeriveKey(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDH1_DERIVE, params)},
+			privKey, []*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+				pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+				pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, false),
+				pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, p256Size),
+			})
+		if e != nil {
+			return e
+		}
+
+		attrs, e := ctx.GetAttributeValue(session, secret, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+		})
+		if e != nil {
+			return e
+		}
+
+		z = attrs[0].Value
+
+		return ctx.DestroyObject(session, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compute shared secret: %w", err)
+	}
+
+	return z, nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bbs12381g2pub implements BBS+ signatures of multiple messages over the BLS12-381 curve, with public keys
+// in G2, and the zero-knowledge proofs of knowledge of a signature which disclose a subset of the signed messages,
+// as described in section 4.5 of https://eprint.iacr.org/2016/663.pdf
+//
+// A signature (A, e, s) of the messages m1..mL is made of A = (g1 * h0^s * h1^m1 * ... * hL^mL)^(1/(x+e)) where
+// the generators h0..hL of G1 are derived from the public key and the number of messages, and messages are hashed
+// to scalars.
+package bbs12381g2pub
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	frCompressedSize = 32
+	g1CompressedSize = 48
+	g2CompressedSize = 96
+
+	signatureSize = g1CompressedSize + 2*frCompressedSize
+
+	// generatorsDST is the domain separation tag used to hash to the generators of G1
+	generatorsDST = "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_"
+)
+
+//nolint:gochecknoglobals
+var curveOrder = bls12381.NewG1().Q()
+
+// signature is a BBS+ signature
+type signature struct {
+	a *bls12381.PointG1
+	e *big.Int
+	s *big.Int
+}
+
+// Sign signs the messages with the private key marshaled in privKeyBytes
+func Sign(messages [][]byte, privKeyBytes []byte) ([]byte, error) {
+	if len(messages) == 0 {
+		return nil, errors.New("messages are not defined")
+	}
+
+	privKey, err := UnmarshalPrivateKey(privKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal private key: %w", err)
+	}
+
+	e, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	g1 := bls12381.NewG1()
+
+	h, err := generators(privKey.PublicKey(), len(messages))
+	if err != nil {
+		return nil, err
+	}
+
+	b := computeB(g1, h, s, messagesToScalars(messages))
+
+	exp := new(big.Int).Add(privKey.x, e)
+	exp.Mod(exp, curveOrder)
+
+	if exp.ModInverse(exp, curveOrder) == nil {
+		return nil, errors.New("sign: invalid random values")
+	}
+
+	sig := &signature{a: g1.MulScalarBig(g1.New(), b, exp), e: e, s: s}
+
+	return sig.marshal(), nil
+}
+
+// Verify verifies the signature in sigBytes of the messages with the public key marshaled in pubKeyBytes
+func Verify(messages [][]byte, sigBytes, pubKeyBytes []byte) error {
+	if len(messages) == 0 {
+		return errors.New("messages are not defined")
+	}
+
+	sig, err := unmarshalSignature(sigBytes)
+	if err != nil {
+		return fmt.Errorf("unmarshal signature: %w", err)
+	}
+
+	pubKey, err := UnmarshalPublicKey(pubKeyBytes)
+	if err != nil {
+		return fmt.Errorf("unmarshal public key: %w", err)
+	}
+
+	h, err := generators(pubKey, len(messages))
+	if err != nil {
+		return err
+	}
+
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+
+	b := computeB(g1, h, sig.s, messagesToScalars(messages))
+
+	// e(A, w * g2^e) == e(B, g2)
+	we := g2.MulScalarBig(g2.New(), g2.One(), sig.e)
+	g2.Add(we, we, pubKey.w)
+
+	engine := bls12381.NewEngine()
+	engine.AddPair(g1.New().Set(sig.a), we)
+	engine.AddPairInv(b, g2.One())
+
+	if !engine.Check() {
+		return errors.New("invalid BBS+ signature")
+	}
+
+	return nil
+}
+
+// computeB returns g1 * h0^s * h1^m1 * ... * hL^mL
+func computeB(g1 *bls12381.G1, h []*bls12381.PointG1, s *big.Int, messages []*big.Int) *bls12381.PointG1 {
+	b := g1.One()
+
+	g1.Add(b, b, g1.MulScalarBig(g1.New(), h[0], s))
+
+	for i, m := range messages {
+		g1.Add(b, b, g1.MulScalarBig(g1.New(), h[i+1], m))
+	}
+
+	return b
+}
+
+// generators returns the generators h0..hL of G1 for L messages signed with pubKey
+func generators(pubKey *PublicKey, count int) ([]*bls12381.PointG1, error) {
+	g1 := bls12381.NewG1()
+	pubKeyBytes := pubKey.Marshal()
+
+	h := make([]*bls12381.PointG1, count+1)
+
+	for i := range h {
+		data := make([]byte, len(pubKeyBytes)+8) // nolint:gomnd
+		copy(data, pubKeyBytes)
+		binary.BigEndian.PutUint32(data[len(pubKeyBytes):], uint32(i))
+		binary.BigEndian.PutUint32(data[len(pubKeyBytes)+4:], uint32(count))
+
+		p, err := g1.HashToCurve(data, []byte(generatorsDST))
+		if err != nil {
+			return nil, fmt.Errorf("create generator: %w", err)
+		}
+
+		h[i] = p
+	}
+
+	return h, nil
+}
+
+func messagesToScalars(messages [][]byte) []*big.Int {
+	scalars := make([]*big.Int, len(messages))
+
+	for i, m := range messages {
+		scalars[i] = hashToScalar(m)
+	}
+
+	return scalars
+}
+
+// hashToScalar hashes data to a scalar
+func hashToScalar(data []byte) *big.Int {
+	h := blake2b.Sum512(data)
+
+	return new(big.Int).Mod(new(big.Int).SetBytes(h[:]), curveOrder)
+}
+
+func (sig *signature) marshal() []byte {
+	b := make([]byte, 0, signatureSize)
+	b = append(b, bls12381.NewG1().ToCompressed(sig.a)...)
+	b = append(b, scalarToBytes(sig.e)...)
+	b = append(b, scalarToBytes(sig.s)...)
+
+	return b
+}
+
+func unmarshalSignature(b []byte) (*signature, error) {
+	if len(b) != signatureSize {
+		return nil, errors.New("invalid size of signature")
+	}
+
+	g1 := bls12381.NewG1()
+
+	a, err := g1.FromCompressed(b[:g1CompressedSize])
+	if err != nil {
+		return nil, fmt.Errorf("deserialize G1 compressed signature: %w", err)
+	}
+
+	if g1.IsZero(a) {
+		return nil, errors.New("invalid signature")
+	}
+
+	return &signature{
+		a: a,
+		e: new(big.Int).SetBytes(b[g1CompressedSize : g1CompressedSize+frCompressedSize]),
+		s: new(big.Int).SetBytes(b[g1CompressedSize+frCompressedSize:]),
+	}, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	pubKey, privKey, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	pubKeyBytes := pubKey.Marshal()
+	privKeyBytes := privKey.Marshal()
+
+	messages := [][]byte{[]byte("message1"), []byte("message2"), []byte("message3")}
+
+	sig, err := Sign(messages, privKeyBytes)
+	require.NoError(t, err)
+	require.Len(t, sig, signatureSize)
+
+	require.NoError(t, Verify(messages, sig, pubKeyBytes))
+
+	t.Run("tampered messages", func(t *testing.T) {
+		err = Verify([][]byte{[]byte("message1"), []byte("message2"), []byte("message4")}, sig, pubKeyBytes)
+		require.EqualError(t, err, "invalid BBS+ signature")
+
+		err = Verify(messages[:2], sig, pubKeyBytes)
+		require.EqualError(t, err, "invalid BBS+ signature")
+	})
+
+	t.Run("another public key", func(t *testing.T) {
+		otherPubKey, _, e := GenerateKeyPair()
+		require.NoError(t, e)
+
+		err = Verify(messages, sig, otherPubKey.Marshal())
+		require.EqualError(t, err, "invalid BBS+ signature")
+	})
+
+	t.Run("sign errors", func(t *testing.T) {
+		_, err = Sign(nil, privKeyBytes)
+		require.EqualError(t, err, "messages are not defined")
+
+		_, err = Sign(messages, []byte("bad key"))
+		require.EqualError(t, err, "unmarshal private key: invalid size of private key")
+	})
+
+	t.Run("verify errors", func(t *testing.T) {
+		err = Verify(nil, sig, pubKeyBytes)
+		require.EqualError(t, err, "messages are not defined")
+
+		err = Verify(messages, sig[1:], pubKeyBytes)
+		require.EqualError(t, err, "unmarshal signature: invalid size of signature")
+
+		err = Verify(messages, sig, pubKeyBytes[1:])
+		require.EqualError(t, err, "unmarshal public key: invalid size of public key")
+	})
+}
+
+func TestKeysMarshaling(t *testing.T) {
+	pubKey, privKey, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	privKeyBytes := privKey.Marshal()
+	require.Len(t, privKeyBytes, frCompressedSize)
+
+	parsedPrivKey, err := UnmarshalPrivateKey(privKeyBytes)
+	require.NoError(t, err)
+	require.Equal(t, privKey.x, parsedPrivKey.x)
+	require.Equal(t, pubKey.Marshal(), parsedPrivKey.PublicKey().Marshal())
+
+	pubKeyBytes := pubKey.Marshal()
+	require.Len(t, pubKeyBytes, g2CompressedSize)
+
+	parsedPubKey, err := UnmarshalPublicKey(pubKeyBytes)
+	require.NoError(t, err)
+	require.Equal(t, pubKeyBytes, parsedPubKey.Marshal())
+
+	_, err = UnmarshalPrivateKey(make([]byte, frCompressedSize))
+	require.EqualError(t, err, "invalid private key")
+
+	_, err = UnmarshalPrivateKey(scalarToBytes(curveOrder))
+	require.EqualError(t, err, "invalid private key")
+
+	_, err = UnmarshalPublicKey(make([]byte, g2CompressedSize))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "deserialize public key")
+}
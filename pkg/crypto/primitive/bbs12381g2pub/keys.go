@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// PrivateKey is a BBS+ private key, a scalar x
+type PrivateKey struct {
+	x *big.Int
+}
+
+// PublicKey is a BBS+ public key, the point w = g2^x of G2
+type PublicKey struct {
+	w *bls12381.PointG2
+}
+
+// GenerateKeyPair generates a random BBS+ key pair
+func GenerateKeyPair() (*PublicKey, *PrivateKey, error) {
+	x, err := randomScalar()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate private key: %w", err)
+	}
+
+	privKey := &PrivateKey{x: x}
+
+	return privKey.PublicKey(), privKey, nil
+}
+
+// PublicKey returns the public key of the private key
+func (k *PrivateKey) PublicKey() *PublicKey {
+	g2 := bls12381.NewG2()
+
+	return &PublicKey{w: g2.MulScalarBig(g2.New(), g2.One(), k.x)}
+}
+
+// Marshal returns the private key in frCompressedSize bytes
+func (k *PrivateKey) Marshal() []byte {
+	return scalarToBytes(k.x)
+}
+
+// UnmarshalPrivateKey parses a private key marshaled with PrivateKey.Marshal
+func UnmarshalPrivateKey(b []byte) (*PrivateKey, error) {
+	if len(b) != frCompressedSize {
+		return nil, errors.New("invalid size of private key")
+	}
+
+	x := new(big.Int).SetBytes(b)
+	if x.Sign() == 0 || x.Cmp(curveOrder) >= 0 {
+		return nil, errors.New("invalid private key")
+	}
+
+	return &PrivateKey{x: x}, nil
+}
+
+// Marshal returns the public key compressed in g2CompressedSize bytes
+func (k *PublicKey) Marshal() []byte {
+	return bls12381.NewG2().ToCompressed(k.w)
+}
+
+// UnmarshalPublicKey parses a public key marshaled with PublicKey.Marshal
+func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != g2CompressedSize {
+		return nil, errors.New("invalid size of public key")
+	}
+
+	g2 := bls12381.NewG2()
+
+	w, err := g2.FromCompressed(b)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize public key: %w", err)
+	}
+
+	if g2.IsZero(w) {
+		return nil, errors.New("invalid public key")
+	}
+
+	return &PublicKey{w: w}, nil
+}
+
+// randomScalar returns a random non-zero scalar
+func randomScalar() (*big.Int, error) {
+	for {
+		s, err := rand.Int(rand.Reader, curveOrder)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Sign() != 0 {
+			return s, nil
+		}
+	}
+}
+
+// scalarToBytes returns the big-endian representation of s in frCompressedSize bytes
+func scalarToBytes(s *big.Int) []byte {
+	sBytes := s.Bytes()
+	b := make([]byte, frCompressedSize)
+	copy(b[frCompressedSize-len(sBytes):], sBytes)
+
+	return b
+}
@@ -0,0 +1,88 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/rawkeyset"
+)
+
+// BBS+ keys are kept in Tink keysets so that they are stored and used through key handles like the other keys of
+// the KMS. Tink doesn't provide primitives for them: the key value of a private key is its PrivateKey.Marshal bytes
+// and the key value of a public key its PublicKey.Marshal bytes.
+const (
+	// PrivateKeyTypeURL is the type URL of BBS+ private keys in Tink keysets
+	PrivateKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.BBSPrivateKey"
+	// PublicKeyTypeURL is the type URL of BBS+ public keys in Tink keysets
+	PublicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.BBSPublicKey"
+)
+
+// NewKeyHandle generates a BBS+ key pair and returns a key handle of the private key
+func NewKeyHandle() (*keyset.Handle, error) {
+	_, privKey, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return rawkeyset.NewHandle(PrivateKeyTypeURL, privKey.Marshal(), tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+}
+
+// PublicKeyHandle returns a key handle of the BBS+ public key marshaled in pubKeyBytes
+func PublicKeyHandle(pubKeyBytes []byte) (*keyset.Handle, error) {
+	if _, err := UnmarshalPublicKey(pubKeyBytes); err != nil {
+		return nil, err
+	}
+
+	return rawkeyset.NewHandle(PublicKeyTypeURL, pubKeyBytes, tinkpb.KeyData_ASYMMETRIC_PUBLIC)
+}
+
+// IsKeyHandle tells whether kh is a key handle of a BBS+ private or public key
+func IsKeyHandle(kh *keyset.Handle) bool {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+
+	return err == nil && (key.TypeUrl == PrivateKeyTypeURL || key.TypeUrl == PublicKeyTypeURL)
+}
+
+// PrivateKeyBytes returns the marshaled private key of a BBS+ private key handle
+func PrivateKeyBytes(kh *keyset.Handle) ([]byte, error) {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.TypeUrl != PrivateKeyTypeURL {
+		return nil, fmt.Errorf("not a BBS+ private key: %s", key.TypeUrl)
+	}
+
+	return key.Value, nil
+}
+
+// PublicKeyBytes returns the marshaled public key of a BBS+ private or public key handle
+func PublicKeyBytes(kh *keyset.Handle) ([]byte, error) {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.TypeUrl {
+	case PrivateKeyTypeURL:
+		privKey, e := UnmarshalPrivateKey(key.Value)
+		if e != nil {
+			return nil, e
+		}
+
+		return privKey.PublicKey().Marshal(), nil
+	case PublicKeyTypeURL:
+		return key.Value, nil
+	default:
+		return nil, fmt.Errorf("not a BBS+ key: %s", key.TypeUrl)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	tinksignature "github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyHandles(t *testing.T) {
+	kh, err := NewKeyHandle()
+	require.NoError(t, err)
+	require.True(t, IsKeyHandle(kh))
+
+	privKeyBytes, err := PrivateKeyBytes(kh)
+	require.NoError(t, err)
+
+	pubKeyBytes, err := PublicKeyBytes(kh)
+	require.NoError(t, err)
+
+	privKey, err := UnmarshalPrivateKey(privKeyBytes)
+	require.NoError(t, err)
+	require.Equal(t, privKey.PublicKey().Marshal(), pubKeyBytes)
+
+	pubKH, err := PublicKeyHandle(pubKeyBytes)
+	require.NoError(t, err)
+	require.True(t, IsKeyHandle(pubKH))
+
+	pubKeyBytes2, err := PublicKeyBytes(pubKH)
+	require.NoError(t, err)
+	require.Equal(t, pubKeyBytes, pubKeyBytes2)
+
+	_, err = PrivateKeyBytes(pubKH)
+	require.EqualError(t, err, "not a BBS+ private key: "+PublicKeyTypeURL)
+
+	_, err = PublicKeyHandle([]byte("bad key"))
+	require.EqualError(t, err, "invalid size of public key")
+
+	ecKH, err := keyset.NewHandle(tinksignature.ECDSAP256KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+	require.False(t, IsKeyHandle(ecKH))
+	require.False(t, IsKeyHandle(nil))
+
+	_, err = PublicKeyBytes(ecKH)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a BBS+ key")
+
+	_, err = PublicKeyBytes(nil)
+	require.EqualError(t, err, "key handle is nil")
+}
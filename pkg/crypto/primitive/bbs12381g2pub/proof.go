@@ -0,0 +1,404 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// A proof of knowledge of a signature (A, e, s) of the messages m1..mL made of random r1 and r2 contains:
+//
+//	A' = A^r1, Abar = A'^-e * B^r1, d = B^r1 * h0^-r2
+//
+// where B = g1 * h0^s * h1^m1 * ... * hL^mL, along with Schnorr proofs of knowledge of
+//
+//	-e and r2 such that Abar/d = A'^-e * h0^r2
+//	r3 = 1/r1, -s' = -(s - r2*r3) and the hidden messages -mj such that
+//		g1 * (product of hi^mi of the revealed messages) = d^r3 * h0^-s' * (product of hj^-mj of the hidden messages)
+//
+// A verifier checks the Schnorr proofs and that e(A', w) = e(Abar, g2).
+
+const (
+	messageCountSize = 4
+	// proofPointsSize is the size of A', Abar and d
+	proofPointsSize = 3 * g1CompressedSize
+	// proofScalarsCount is the number of scalars of the proof besides the responses for the hidden messages:
+	// the challenge and the responses for -e, r2, r3 and -s'
+	proofScalarsCount = 5
+)
+
+// DeriveProof derives from the signature in sigBytes of the messages a proof of knowledge of the signature which
+// discloses the messages at revealedIndexes, bound to the nonce.
+func DeriveProof(messages [][]byte, sigBytes, nonce, pubKeyBytes []byte, revealedIndexes []int) ([]byte, error) {
+	if err := Verify(messages, sigBytes, pubKeyBytes); err != nil {
+		return nil, fmt.Errorf("verify input signature: %w", err)
+	}
+
+	revealed, err := revealedSet(revealedIndexes, len(messages))
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := unmarshalSignature(sigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := UnmarshalPublicKey(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := generators(pubKey, len(messages))
+	if err != nil {
+		return nil, err
+	}
+
+	g1 := bls12381.NewG1()
+	m := messagesToScalars(messages)
+
+	p, err := newProof(g1, sig, h, m, revealed)
+	if err != nil {
+		return nil, err
+	}
+
+	header := proofHeader(len(messages), revealed)
+
+	c := challenge(g1, header, p, revealedStatement(g1, h, m, revealed), nonce)
+
+	proof := append(header, p.points(g1)...)
+	proof = append(proof, scalarToBytes(c)...)
+
+	for _, z := range p.pok1.responses(c) {
+		proof = append(proof, scalarToBytes(z)...)
+	}
+
+	for _, z := range p.pok2.responses(c) {
+		proof = append(proof, scalarToBytes(z)...)
+	}
+
+	return proof, nil
+}
+
+// VerifyProof verifies a proof derived with DeriveProof from the revealed messages, in the order of their indexes.
+func VerifyProof(revealedMessages [][]byte, proofBytes, nonce, pubKeyBytes []byte) error {
+	pubKey, err := UnmarshalPublicKey(pubKeyBytes)
+	if err != nil {
+		return fmt.Errorf("unmarshal public key: %w", err)
+	}
+
+	p, err := unmarshalProof(proofBytes, len(revealedMessages))
+	if err != nil {
+		return fmt.Errorf("unmarshal proof: %w", err)
+	}
+
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+
+	// e(A', w) == e(Abar, g2)
+	engine := bls12381.NewEngine()
+	engine.AddPair(g1.New().Set(p.aPrime), g2.New().Set(pubKey.w))
+	engine.AddPairInv(p.aBar, g2.One())
+
+	if !engine.Check() {
+		return errors.New("invalid BBS+ proof")
+	}
+
+	h, err := generators(pubKey, len(p.revealed))
+	if err != nil {
+		return err
+	}
+
+	m := make([]*big.Int, len(p.revealed))
+
+	for i, j := 0, 0; i < len(p.revealed); i++ {
+		if p.revealed[i] {
+			m[i] = hashToScalar(revealedMessages[j])
+			j++
+		}
+	}
+
+	statement1 := g1.Sub(g1.New(), p.aBar, p.d)
+	statement2 := revealedStatement(g1, h, m, p.revealed)
+
+	p.pok1 = &pok{
+		bases: []*bls12381.PointG1{p.aPrime, h[0]},
+		t:     commitmentFromResponses(g1, []*bls12381.PointG1{p.aPrime, h[0]}, p.z1, statement1, p.c),
+	}
+	p.pok2 = &pok{
+		bases: pok2Bases(p.d, h, p.revealed),
+		t:     commitmentFromResponses(g1, pok2Bases(p.d, h, p.revealed), p.z2, statement2, p.c),
+	}
+
+	if challenge(g1, proofHeader(len(p.revealed), p.revealed), p, statement2, nonce).Cmp(p.c) != 0 {
+		return errors.New("invalid BBS+ proof")
+	}
+
+	return nil
+}
+
+// proof is a proof of knowledge of a signature
+type proof struct {
+	aPrime *bls12381.PointG1
+	aBar   *bls12381.PointG1
+	d      *bls12381.PointG1
+	pok1   *pok
+	pok2   *pok
+	// the following fields are only set when verifying
+	revealed []bool
+	c        *big.Int
+	z1       []*big.Int
+	z2       []*big.Int
+}
+
+func newProof(g1 *bls12381.G1, sig *signature, h []*bls12381.PointG1, m []*big.Int,
+	revealed []bool) (*proof, error) {
+	r1, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	r2, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+
+	b := computeB(g1, h, sig.s, m)
+	br1 := g1.MulScalarBig(g1.New(), b, r1)
+
+	aPrime := g1.MulScalarBig(g1.New(), sig.a, r1)
+	aBar := g1.Add(g1.New(), g1.MulScalarBig(g1.New(), aPrime, neg(sig.e)), br1)
+	d := g1.Add(g1.New(), br1, g1.MulScalarBig(g1.New(), h[0], neg(r2)))
+
+	r3 := new(big.Int).ModInverse(r1, curveOrder)
+
+	sPrime := new(big.Int).Mul(r2, r3)
+	sPrime.Sub(sig.s, sPrime)
+	sPrime.Mod(sPrime, curveOrder)
+
+	secrets2 := []*big.Int{r3, neg(sPrime)}
+
+	for i := range m {
+		if !revealed[i] {
+			secrets2 = append(secrets2, neg(m[i]))
+		}
+	}
+
+	pok1, err := newPOK(g1, []*bls12381.PointG1{aPrime, h[0]}, []*big.Int{neg(sig.e), r2})
+	if err != nil {
+		return nil, err
+	}
+
+	pok2, err := newPOK(g1, pok2Bases(d, h, revealed), secrets2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proof{aPrime: aPrime, aBar: aBar, d: d, pok1: pok1, pok2: pok2}, nil
+}
+
+func (p *proof) points(g1 *bls12381.G1) []byte {
+	b := make([]byte, 0, proofPointsSize)
+	b = append(b, g1.ToCompressed(p.aPrime)...)
+	b = append(b, g1.ToCompressed(p.aBar)...)
+	b = append(b, g1.ToCompressed(p.d)...)
+
+	return b
+}
+
+func unmarshalProof(b []byte, revealedCount int) (*proof, error) {
+	if len(b) < messageCountSize {
+		return nil, errors.New("invalid size of proof")
+	}
+
+	count := int(binary.BigEndian.Uint32(b))
+	headerSize := messageCountSize + (count+7)/8 // nolint:gomnd
+
+	if count == 0 || len(b) < headerSize {
+		return nil, errors.New("invalid size of proof")
+	}
+
+	p := &proof{revealed: make([]bool, count)}
+	hidden := count
+
+	for i := range p.revealed {
+		p.revealed[i] = b[messageCountSize+i/8]&(1<<(i%8)) != 0 // nolint:gomnd
+		if p.revealed[i] {
+			hidden--
+		}
+	}
+
+	if count-hidden != revealedCount {
+		return nil, errors.New("invalid number of revealed messages")
+	}
+
+	if len(b) != headerSize+proofPointsSize+(proofScalarsCount+hidden)*frCompressedSize {
+		return nil, errors.New("invalid size of proof")
+	}
+
+	g1 := bls12381.NewG1()
+	points := make([]*bls12381.PointG1, 3) // nolint:gomnd
+
+	for i := range points {
+		offset := headerSize + i*g1CompressedSize
+
+		point, err := g1.FromCompressed(b[offset : offset+g1CompressedSize])
+		if err != nil {
+			return nil, fmt.Errorf("deserialize G1 compressed point: %w", err)
+		}
+
+		points[i] = point
+	}
+
+	p.aPrime, p.aBar, p.d = points[0], points[1], points[2]
+
+	if g1.IsZero(p.aPrime) {
+		return nil, errors.New("invalid proof")
+	}
+
+	scalars := make([]*big.Int, proofScalarsCount+hidden)
+
+	for i := range scalars {
+		offset := headerSize + proofPointsSize + i*frCompressedSize
+		scalars[i] = new(big.Int).SetBytes(b[offset : offset+frCompressedSize])
+	}
+
+	p.c, p.z1, p.z2 = scalars[0], scalars[1:3], scalars[3:]
+
+	return p, nil
+}
+
+// pok is a Schnorr proof of knowledge of the secrets of a linear combination of bases
+type pok struct {
+	bases     []*bls12381.PointG1
+	secrets   []*big.Int
+	blindings []*big.Int
+	// t is the commitment, the linear combination of the bases with the blindings
+	t *bls12381.PointG1
+}
+
+func newPOK(g1 *bls12381.G1, bases []*bls12381.PointG1, secrets []*big.Int) (*pok, error) {
+	p := &pok{bases: bases, secrets: secrets, blindings: make([]*big.Int, len(secrets)), t: g1.Zero()}
+
+	for i := range secrets {
+		blinding, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+
+		p.blindings[i] = blinding
+		g1.Add(p.t, p.t, g1.MulScalarBig(g1.New(), bases[i], blinding))
+	}
+
+	return p, nil
+}
+
+// responses returns blinding + c * secret for every secret
+func (p *pok) responses(c *big.Int) []*big.Int {
+	z := make([]*big.Int, len(p.secrets))
+
+	for i, secret := range p.secrets {
+		z[i] = new(big.Int).Mul(c, secret)
+		z[i].Add(z[i], p.blindings[i])
+		z[i].Mod(z[i], curveOrder)
+	}
+
+	return z
+}
+
+// commitmentFromResponses returns the commitment of a proof of knowledge from its responses, the linear combination
+// of the bases with the responses minus c times the statement.
+func commitmentFromResponses(g1 *bls12381.G1, bases []*bls12381.PointG1, z []*big.Int, statement *bls12381.PointG1,
+	c *big.Int) *bls12381.PointG1 {
+	t := g1.MulScalarBig(g1.New(), statement, neg(c))
+
+	for i, base := range bases {
+		g1.Add(t, t, g1.MulScalarBig(g1.New(), base, z[i]))
+	}
+
+	return t
+}
+
+// pok2Bases returns the bases of the second proof of knowledge: d, h0 and the generators of the hidden messages
+func pok2Bases(d *bls12381.PointG1, h []*bls12381.PointG1, revealed []bool) []*bls12381.PointG1 {
+	bases := []*bls12381.PointG1{d, h[0]}
+
+	for i := range revealed {
+		if !revealed[i] {
+			bases = append(bases, h[i+1])
+		}
+	}
+
+	return bases
+}
+
+// revealedStatement returns g1 * (product of hi^mi of the revealed messages)
+func revealedStatement(g1 *bls12381.G1, h []*bls12381.PointG1, m []*big.Int, revealed []bool) *bls12381.PointG1 {
+	statement := g1.One()
+
+	for i := range revealed {
+		if revealed[i] {
+			g1.Add(statement, statement, g1.MulScalarBig(g1.New(), h[i+1], m[i]))
+		}
+	}
+
+	return statement
+}
+
+// challenge computes the Fiat-Shamir challenge of the proof
+func challenge(g1 *bls12381.G1, header []byte, p *proof, statement2 *bls12381.PointG1, nonce []byte) *big.Int {
+	data := append([]byte{}, header...)
+	data = append(data, p.points(g1)...)
+	data = append(data, g1.ToCompressed(statement2)...)
+	data = append(data, g1.ToCompressed(p.pok1.t)...)
+	data = append(data, g1.ToCompressed(p.pok2.t)...)
+	data = append(data, nonce...)
+
+	return hashToScalar(data)
+}
+
+// proofHeader returns the number of messages followed by the bit vector of the revealed messages
+func proofHeader(count int, revealed []bool) []byte {
+	header := make([]byte, messageCountSize+(count+7)/8) // nolint:gomnd
+	binary.BigEndian.PutUint32(header, uint32(count))
+
+	for i := range revealed {
+		if revealed[i] {
+			header[messageCountSize+i/8] |= 1 << (i % 8) // nolint:gomnd
+		}
+	}
+
+	return header
+}
+
+func revealedSet(revealedIndexes []int, count int) ([]bool, error) {
+	revealed := make([]bool, count)
+
+	for _, i := range revealedIndexes {
+		if i < 0 || i >= count {
+			return nil, fmt.Errorf("revealed index %d is out of range", i)
+		}
+
+		if revealed[i] {
+			return nil, fmt.Errorf("revealed index %d is duplicated", i)
+		}
+
+		revealed[i] = true
+	}
+
+	return revealed, nil
+}
+
+func neg(s *big.Int) *big.Int {
+	n := new(big.Int).Neg(s)
+
+	return n.Mod(n, curveOrder)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs12381g2pub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveVerifyProof(t *testing.T) {
+	pubKey, privKey, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	pubKeyBytes := pubKey.Marshal()
+
+	messages := [][]byte{[]byte("message1"), []byte("message2"), []byte("message3"), []byte("message4")}
+
+	sig, err := Sign(messages, privKey.Marshal())
+	require.NoError(t, err)
+
+	nonce := []byte("nonce")
+
+	t.Run("reveal a subset of the messages", func(t *testing.T) {
+		proof, e := DeriveProof(messages, sig, nonce, pubKeyBytes, []int{0, 2})
+		require.NoError(t, e)
+
+		revealed := [][]byte{messages[0], messages[2]}
+		require.NoError(t, VerifyProof(revealed, proof, nonce, pubKeyBytes))
+
+		e = VerifyProof([][]byte{messages[0], messages[3]}, proof, nonce, pubKeyBytes)
+		require.EqualError(t, e, "invalid BBS+ proof")
+
+		e = VerifyProof(revealed, proof, []byte("other nonce"), pubKeyBytes)
+		require.EqualError(t, e, "invalid BBS+ proof")
+
+		otherPubKey, _, e := GenerateKeyPair()
+		require.NoError(t, e)
+
+		e = VerifyProof(revealed, proof, nonce, otherPubKey.Marshal())
+		require.EqualError(t, e, "invalid BBS+ proof")
+
+		e = VerifyProof(revealed[:1], proof, nonce, pubKeyBytes)
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "unmarshal proof")
+	})
+
+	t.Run("reveal all or none of the messages", func(t *testing.T) {
+		proof, e := DeriveProof(messages, sig, nonce, pubKeyBytes, []int{3, 2, 1, 0})
+		require.NoError(t, e)
+		require.NoError(t, VerifyProof(messages, proof, nonce, pubKeyBytes))
+
+		proof, e = DeriveProof(messages, sig, nonce, pubKeyBytes, nil)
+		require.NoError(t, e)
+		require.NoError(t, VerifyProof(nil, proof, nonce, pubKeyBytes))
+	})
+
+	t.Run("proofs are unlinkable", func(t *testing.T) {
+		proof1, e := DeriveProof(messages, sig, nonce, pubKeyBytes, []int{1})
+		require.NoError(t, e)
+
+		proof2, e := DeriveProof(messages, sig, nonce, pubKeyBytes, []int{1})
+		require.NoError(t, e)
+
+		require.NotEqual(t, proof1, proof2)
+	})
+
+	t.Run("derive errors", func(t *testing.T) {
+		_, e := DeriveProof(messages[1:], sig, nonce, pubKeyBytes, []int{0})
+		require.EqualError(t, e, "verify input signature: invalid BBS+ signature")
+
+		_, e = DeriveProof(messages, sig, nonce, pubKeyBytes, []int{4})
+		require.EqualError(t, e, "revealed index 4 is out of range")
+
+		_, e = DeriveProof(messages, sig, nonce, pubKeyBytes, []int{1, 1})
+		require.EqualError(t, e, "revealed index 1 is duplicated")
+	})
+
+	t.Run("verify errors", func(t *testing.T) {
+		e := VerifyProof(nil, []byte("bad proof"), nonce, pubKeyBytes)
+		require.EqualError(t, e, "unmarshal proof: invalid size of proof")
+
+		e = VerifyProof(nil, nil, nonce, []byte("bad key"))
+		require.EqualError(t, e, "unmarshal public key: invalid size of public key")
+	})
+}
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package secp256k1 implements ECDSA signatures over the secp256k1 curve with SHA-256 (ES256K) for keys kept in Tink
+// keysets, which don't support this curve.
+//
+// Signatures are the 64 bytes concatenation of R and S, as expected by ES256K JWS and by the
+// EcdsaSecp256k1Signature2019 suite, and public keys are marshaled in their uncompressed form.
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/rawkeyset"
+)
+
+const (
+	// PrivateKeyTypeURL is the type URL of secp256k1 private keys in Tink keysets
+	PrivateKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1PrivateKey"
+	// PublicKeyTypeURL is the type URL of secp256k1 public keys in Tink keysets
+	PublicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1PublicKey"
+
+	keySize       = 32
+	signatureSize = 2 * keySize
+)
+
+// NewKeyHandle generates a secp256k1 key and returns a key handle of the private key
+func NewKeyHandle() (*keyset.Handle, error) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("generate secp256k1 key: %w", err)
+	}
+
+	return rawkeyset.NewHandle(PrivateKeyTypeURL, privKey.Serialize(), tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+}
+
+// PrivateKeyHandle returns a key handle of the secp256k1 private key of big-endian scalar privKeyBytes
+func PrivateKeyHandle(privKeyBytes []byte) (*keyset.Handle, error) {
+	d := new(big.Int).SetBytes(privKeyBytes)
+	if len(privKeyBytes) > keySize || d.Sign() == 0 || d.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("invalid secp256k1 private key")
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), privKeyBytes)
+
+	return rawkeyset.NewHandle(PrivateKeyTypeURL, privKey.Serialize(), tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+}
+
+// PublicKeyHandle returns a key handle of the secp256k1 public key marshaled in pubKeyBytes, either compressed or
+// uncompressed
+func PublicKeyHandle(pubKeyBytes []byte) (*keyset.Handle, error) {
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("parse secp256k1 public key: %w", err)
+	}
+
+	return rawkeyset.NewHandle(PublicKeyTypeURL, pubKey.SerializeUncompressed(), tinkpb.KeyData_ASYMMETRIC_PUBLIC)
+}
+
+// IsKeyHandle tells whether kh is a key handle of a secp256k1 private or public key
+func IsKeyHandle(kh *keyset.Handle) bool {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+
+	return err == nil && (key.TypeUrl == PrivateKeyTypeURL || key.TypeUrl == PublicKeyTypeURL)
+}
+
+// PublicKeyBytes returns the uncompressed public key of a secp256k1 private or public key handle
+func PublicKeyBytes(kh *keyset.Handle) ([]byte, error) {
+	pubKey, err := publicKey(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	return pubKey.SerializeUncompressed(), nil
+}
+
+// Sign signs the SHA-256 digest of msg with the private key of kh
+func Sign(msg []byte, kh *keyset.Handle) ([]byte, error) {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.TypeUrl != PrivateKeyTypeURL {
+		return nil, fmt.Errorf("not a secp256k1 private key: %s", key.TypeUrl)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), key.Value)
+	digest := sha256.Sum256(msg)
+
+	sig, err := privKey.Sign(digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+
+	s := make([]byte, signatureSize)
+	copyPadded(s[:keySize], sig.R)
+	copyPadded(s[keySize:], sig.S)
+
+	return s, nil
+}
+
+// Verify verifies the signature of msg with the public key of the secp256k1 private or public key handle kh
+func Verify(sig, msg []byte, kh *keyset.Handle) error {
+	pubKey, err := publicKey(kh)
+	if err != nil {
+		return err
+	}
+
+	if len(sig) != signatureSize {
+		return errors.New("invalid signature size")
+	}
+
+	digest := sha256.Sum256(msg)
+
+	signature := &btcec.Signature{
+		R: new(big.Int).SetBytes(sig[:keySize]),
+		S: new(big.Int).SetBytes(sig[keySize:]),
+	}
+
+	if !signature.Verify(digest[:], pubKey) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func publicKey(kh *keyset.Handle) (*btcec.PublicKey, error) {
+	key, err := rawkeyset.PrimaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.TypeUrl {
+	case PrivateKeyTypeURL:
+		_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), key.Value)
+
+		return pubKey, nil
+	case PublicKeyTypeURL:
+		return btcec.ParsePubKey(key.Value, btcec.S256())
+	default:
+		return nil, fmt.Errorf("not a secp256k1 key: %s", key.TypeUrl)
+	}
+}
+
+// copyPadded copies the big-endian bytes of i right aligned in dst
+func copyPadded(dst []byte, i *big.Int) {
+	b := i.Bytes()
+	copy(dst[len(dst)-len(b):], b)
+}
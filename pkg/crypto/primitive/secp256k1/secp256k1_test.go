@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	kh, err := NewKeyHandle()
+	require.NoError(t, err)
+	require.True(t, IsKeyHandle(kh))
+
+	pubKeyBytes, err := PublicKeyBytes(kh)
+	require.NoError(t, err)
+	require.Len(t, pubKeyBytes, 65)
+
+	pubKH, err := PublicKeyHandle(pubKeyBytes)
+	require.NoError(t, err)
+	require.True(t, IsKeyHandle(pubKH))
+
+	msg := []byte("test message")
+
+	sig, err := Sign(msg, kh)
+	require.NoError(t, err)
+	require.Len(t, sig, signatureSize)
+
+	require.NoError(t, Verify(sig, msg, kh))
+	require.NoError(t, Verify(sig, msg, pubKH))
+
+	// the signature is a standard ES256K signature
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(msg)
+	require.True(t, ecdsa.Verify(pubKey.ToECDSA(), digest[:],
+		new(big.Int).SetBytes(sig[:keySize]), new(big.Int).SetBytes(sig[keySize:])))
+
+	require.EqualError(t, Verify(sig, []byte("other message"), pubKH), "invalid signature")
+	require.EqualError(t, Verify(sig[1:], msg, pubKH), "invalid signature size")
+
+	// compressed public keys are accepted
+	compressedKH, err := PublicKeyHandle(pubKey.SerializeCompressed())
+	require.NoError(t, err)
+	require.NoError(t, Verify(sig, msg, compressedKH))
+
+	_, err = Sign(msg, pubKH)
+	require.EqualError(t, err, "not a secp256k1 private key: "+PublicKeyTypeURL)
+}
+
+func TestPrivateKeyHandle(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+
+	kh, err := PrivateKeyHandle(privKey.D.Bytes())
+	require.NoError(t, err)
+
+	pubKeyBytes, err := PublicKeyBytes(kh)
+	require.NoError(t, err)
+	require.Equal(t, privKey.PubKey().SerializeUncompressed(), pubKeyBytes)
+
+	_, err = PrivateKeyHandle(make([]byte, keySize))
+	require.EqualError(t, err, "invalid secp256k1 private key")
+
+	_, err = PrivateKeyHandle(btcec.S256().N.Bytes())
+	require.EqualError(t, err, "invalid secp256k1 private key")
+}
+
+func TestKeyHandleErrors(t *testing.T) {
+	_, err := PublicKeyHandle([]byte("bad key"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parse secp256k1 public key")
+
+	ecKH, err := keyset.NewHandle(signature.ECDSAP256KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+	require.False(t, IsKeyHandle(ecKH))
+
+	_, err = PublicKeyBytes(ecKH)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not a secp256k1 key")
+
+	_, err = Sign([]byte("msg"), nil)
+	require.EqualError(t, err, "key handle is nil")
+
+	err = Verify(nil, []byte("msg"), nil)
+	require.EqualError(t, err, "key handle is nil")
+}
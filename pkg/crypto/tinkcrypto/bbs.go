@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+)
+
+// SignMulti will create a BBS+ signature of messages using the BBS+ private key in kh key handle
+func (t *Crypto) SignMulti(messages [][]byte, kh interface{}) ([]byte, error) {
+	keyHandle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	privKey, err := bbs12381g2pub.PrivateKeyBytes(keyHandle)
+	if err != nil {
+		return nil, fmt.Errorf("signMulti: %w", err)
+	}
+
+	s, err := bbs12381g2pub.Sign(messages, privKey)
+	if err != nil {
+		return nil, fmt.Errorf("signMulti: %w", err)
+	}
+
+	return s, nil
+}
+
+// VerifyMulti will verify a BBS+ signature of messages using the BBS+ private or public key in kh key handle
+func (t *Crypto) VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error {
+	pubKey, err := bbsPublicKey(kh)
+	if err != nil {
+		return fmt.Errorf("verifyMulti: %w", err)
+	}
+
+	err = bbs12381g2pub.Verify(messages, signature, pubKey)
+	if err != nil {
+		return fmt.Errorf("verifyMulti: %w", err)
+	}
+
+	return nil
+}
+
+// DeriveProof will derive from a BBS+ signature of messages a zero-knowledge proof of knowledge of the signature
+// which only discloses the messages at revealedIndexes, bound to nonce, using the BBS+ private or public key in kh
+// key handle
+func (t *Crypto) DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int,
+	kh interface{}) ([]byte, error) {
+	pubKey, err := bbsPublicKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("deriveProof: %w", err)
+	}
+
+	proof, err := bbs12381g2pub.DeriveProof(messages, bbsSignature, nonce, pubKey, revealedIndexes)
+	if err != nil {
+		return nil, fmt.Errorf("deriveProof: %w", err)
+	}
+
+	return proof, nil
+}
+
+// VerifyProof will verify a proof derived with DeriveProof from the revealed messages, in the order of their
+// indexes, and nonce using the BBS+ private or public key in kh key handle
+func (t *Crypto) VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error {
+	pubKey, err := bbsPublicKey(kh)
+	if err != nil {
+		return fmt.Errorf("verifyProof: %w", err)
+	}
+
+	err = bbs12381g2pub.VerifyProof(revealedMessages, proof, nonce, pubKey)
+	if err != nil {
+		return fmt.Errorf("verifyProof: %w", err)
+	}
+
+	return nil
+}
+
+func bbsPublicKey(kh interface{}) ([]byte, error) {
+	keyHandle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	return bbs12381g2pub.PublicKeyBytes(keyHandle)
+}
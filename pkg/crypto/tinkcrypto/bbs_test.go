@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+)
+
+func TestCrypto_BBSSignVerifyProof(t *testing.T) {
+	c := Crypto{}
+
+	kh, err := bbs12381g2pub.NewKeyHandle()
+	require.NoError(t, err)
+
+	pubKeyBytes, err := bbs12381g2pub.PublicKeyBytes(kh)
+	require.NoError(t, err)
+
+	pubKH, err := bbs12381g2pub.PublicKeyHandle(pubKeyBytes)
+	require.NoError(t, err)
+
+	messages := [][]byte{[]byte("name"), []byte("birth date"), []byte("address")}
+	nonce := []byte("nonce")
+
+	sig, err := c.SignMulti(messages, kh)
+	require.NoError(t, err)
+
+	t.Run("verify signature", func(t *testing.T) {
+		require.NoError(t, c.VerifyMulti(messages, sig, kh))
+		require.NoError(t, c.VerifyMulti(messages, sig, pubKH))
+
+		err = c.VerifyMulti(messages[1:], sig, pubKH)
+		require.EqualError(t, err, "verifyMulti: invalid BBS+ signature")
+	})
+
+	t.Run("derive and verify proof", func(t *testing.T) {
+		proof, e := c.DeriveProof(messages, sig, nonce, []int{0, 2}, pubKH)
+		require.NoError(t, e)
+
+		revealed := [][]byte{messages[0], messages[2]}
+		require.NoError(t, c.VerifyProof(revealed, proof, nonce, pubKH))
+		require.NoError(t, c.VerifyProof(revealed, proof, nonce, kh))
+
+		e = c.VerifyProof(messages[:2], proof, nonce, pubKH)
+		require.EqualError(t, e, "verifyProof: invalid BBS+ proof")
+
+		_, e = c.DeriveProof(messages, sig, nonce, []int{3}, kh)
+		require.EqualError(t, e, "deriveProof: revealed index 3 is out of range")
+	})
+
+	t.Run("bad key handles", func(t *testing.T) {
+		_, err = c.SignMulti(messages, "bad key handle")
+		require.EqualError(t, err, "bad key handle format")
+
+		_, err = c.SignMulti(messages, pubKH)
+		require.EqualError(t, err, "signMulti: not a BBS+ private key: "+bbs12381g2pub.PublicKeyTypeURL)
+
+		_, err = c.SignMulti(nil, kh)
+		require.EqualError(t, err, "signMulti: messages are not defined")
+
+		ecKH, e := keyset.NewHandle(signature.ECDSAP256KeyWithoutPrefixTemplate())
+		require.NoError(t, e)
+
+		err = c.VerifyMulti(messages, sig, ecKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verifyMulti: not a BBS+ key")
+
+		_, err = c.DeriveProof(messages, sig, nonce, nil, "bad key handle")
+		require.EqualError(t, err, "deriveProof: bad key handle format")
+
+		err = c.VerifyProof(messages, nil, nonce, "bad key handle")
+		require.EqualError(t, err, "verifyProof: bad key handle format")
+	})
+}
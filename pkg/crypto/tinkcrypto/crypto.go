@@ -21,6 +21,8 @@ import (
 	"github.com/google/tink/go/signature"
 	aeadsubtle "github.com/google/tink/go/subtle/aead"
 	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/secp256k1"
 )
 
 var errBadKeyHandleFormat = errors.New("bad key handle format")
@@ -122,6 +124,16 @@ func (t *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
 		return nil, errBadKeyHandleFormat
 	}
 
+	// Tink doesn't support secp256k1 keys
+	if secp256k1.IsKeyHandle(keyHandle) {
+		s, err := secp256k1.Sign(msg, keyHandle)
+		if err != nil {
+			return nil, fmt.Errorf("sign msg: %w", err)
+		}
+
+		return s, nil
+	}
+
 	signer, err := signature.NewSigner(keyHandle)
 	if err != nil {
 		return nil, fmt.Errorf("create new signer: %w", err)
@@ -142,6 +154,15 @@ func (t *Crypto) Verify(sig, msg []byte, kh interface{}) error {
 		return errBadKeyHandleFormat
 	}
 
+	if secp256k1.IsKeyHandle(keyHandle) {
+		err := secp256k1.Verify(sig, msg, keyHandle)
+		if err != nil {
+			return fmt.Errorf("verify msg: %w", err)
+		}
+
+		return nil
+	}
+
 	verifier, err := signature.NewVerifier(keyHandle)
 	if err != nil {
 		return fmt.Errorf("create new verifier: %w", err)
@@ -18,6 +18,8 @@ import (
 	chacha "golang.org/x/crypto/chacha20poly1305"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/secp256k1"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 )
 
 const testMessage = "test message"
@@ -165,6 +167,40 @@ func TestCrypto_SignVerify(t *testing.T) {
 		err = c.Verify(s, msg, badKH)
 		require.Error(t, err)
 	})
+
+	t.Run("test with secp256k1 signature", func(t *testing.T) {
+		kh, err := secp256k1.NewKeyHandle()
+		require.NoError(t, err)
+
+		c := Crypto{}
+		msg := []byte(testMessage)
+		s, err := c.Sign(msg, kh)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := secp256k1.PublicKeyBytes(kh)
+		require.NoError(t, err)
+
+		pubKH, err := secp256k1.PublicKeyHandle(pubKeyBytes)
+		require.NoError(t, err)
+
+		err = c.Verify(s, msg, pubKH)
+		require.NoError(t, err)
+
+		// ES256K signature is verified by the EcdsaSecp256k1Signature2019 verifier
+		err = verifier.NewECDSASecp256k1SignatureVerifier().Verify(&verifier.PublicKey{
+			Type:  "EcdsaSecp256k1VerificationKey2019",
+			Value: pubKeyBytes,
+		}, msg, s)
+		require.NoError(t, err)
+
+		err = c.Verify(s, []byte("other message"), pubKH)
+		require.EqualError(t, err, "verify msg: invalid signature")
+
+		// sign with public key handle - should fail
+		_, err = c.Sign(msg, pubKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sign msg: not a secp256k1 private key")
+	})
 }
 
 func TestCrypto_ComputeMAC(t *testing.T) {
@@ -0,0 +1,312 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+// ECDH keys are the ECDSA keys of the NIST curves and the ED25519 keys, converted to X25519, created by the KMS.
+const (
+	ecdsaPrivateKeyTypeURL   = "type.googleapis.com/google.crypto.tink.EcdsaPrivateKey"
+	ecdsaPublicKeyTypeURL    = "type.googleapis.com/google.crypto.tink.EcdsaPublicKey"
+	ed25519PrivateKeyTypeURL = "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey"
+	ed25519PublicKeyTypeURL  = "type.googleapis.com/google.crypto.tink.Ed25519PublicKey"
+)
+
+var errUnsupportedCurve = errors.New("unsupported curve")
+
+// ecdhPrivateKey is a private key used for key agreements
+type ecdhPrivateKey struct {
+	public *crypto.PublicKey
+	// d is the big-endian scalar of NIST curves keys, or the X25519 scalar
+	d []byte
+}
+
+// ExportPublicKey returns the public key of the primary key of kh, a private or public key handle of an ECDSA
+// (P-256, P-384 or P-521) or an ED25519 key, for use with WrapKey. ED25519 keys are converted to X25519 keys.
+func ExportPublicKey(kh interface{}) (*crypto.PublicKey, error) {
+	key, err := primaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.TypeUrl {
+	case ecdsaPrivateKeyTypeURL:
+		k, e := ecdsaPrivateKey(key.Value)
+		if e != nil {
+			return nil, e
+		}
+
+		return k.public, nil
+	case ecdsaPublicKeyTypeURL:
+		pubKeyProto := new(ecdsapb.EcdsaPublicKey)
+
+		if err = proto.Unmarshal(key.Value, pubKeyProto); err != nil {
+			return nil, fmt.Errorf("unmarshal ecdsa public key: %w", err)
+		}
+
+		return ecdsaPublicKey(pubKeyProto)
+	case ed25519PrivateKeyTypeURL:
+		k, e := x25519PrivateKey(key.Value)
+		if e != nil {
+			return nil, e
+		}
+
+		return k.public, nil
+	case ed25519PublicKeyTypeURL:
+		pubKeyProto := new(ed25519pb.Ed25519PublicKey)
+
+		if err = proto.Unmarshal(key.Value, pubKeyProto); err != nil {
+			return nil, fmt.Errorf("unmarshal ed25519 public key: %w", err)
+		}
+
+		return x25519PublicKey(pubKeyProto.KeyValue)
+	default:
+		return nil, fmt.Errorf("key type not supported for key agreement: %s", key.TypeUrl)
+	}
+}
+
+// SharedSecret computes the ECDH shared secret of the private key of kh and pub, kh being an ECDSA (P-256, P-384 or
+// P-521) or an ED25519 private key handle, the latter used as an X25519 key
+func (t *Crypto) SharedSecret(pub *crypto.PublicKey, kh interface{}) ([]byte, error) {
+	privKey, err := privateKeyFromHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("sharedSecret: %w", err)
+	}
+
+	z, err := privKey.sharedSecret(pub)
+	if err != nil {
+		return nil, fmt.Errorf("sharedSecret: %w", err)
+	}
+
+	return z, nil
+}
+
+// privateKeyFromHandle returns the private key of the primary key of kh
+func privateKeyFromHandle(kh interface{}) (*ecdhPrivateKey, error) {
+	key, err := primaryKeyData(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.TypeUrl {
+	case ecdsaPrivateKeyTypeURL:
+		return ecdsaPrivateKey(key.Value)
+	case ed25519PrivateKeyTypeURL:
+		return x25519PrivateKey(key.Value)
+	default:
+		return nil, fmt.Errorf("key type not supported for key agreement: %s", key.TypeUrl)
+	}
+}
+
+// publicKey returns a public key given as a *crypto.PublicKey or as a key handle
+func publicKey(k interface{}) (*crypto.PublicKey, error) {
+	if pub, ok := k.(*crypto.PublicKey); ok {
+		return pub, nil
+	}
+
+	return ExportPublicKey(k)
+}
+
+func primaryKeyData(kh interface{}) (*tinkpb.KeyData, error) {
+	keyHandle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(keyHandle)
+
+	for _, key := range ks.Key {
+		if key.KeyId == ks.PrimaryKeyId && key.Status == tinkpb.KeyStatusType_ENABLED {
+			return key.KeyData, nil
+		}
+	}
+
+	return nil, errors.New("primary key not found")
+}
+
+func ecdsaPrivateKey(serializedKey []byte) (*ecdhPrivateKey, error) {
+	keyProto := new(ecdsapb.EcdsaPrivateKey)
+
+	if err := proto.Unmarshal(serializedKey, keyProto); err != nil {
+		return nil, fmt.Errorf("unmarshal ecdsa private key: %w", err)
+	}
+
+	pub, err := ecdsaPublicKey(keyProto.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdhPrivateKey{public: pub, d: keyProto.KeyValue}, nil
+}
+
+func ecdsaPublicKey(keyProto *ecdsapb.EcdsaPublicKey) (*crypto.PublicKey, error) {
+	if keyProto == nil || keyProto.Params == nil {
+		return nil, errors.New("invalid ecdsa public key")
+	}
+
+	var curve string
+
+	switch keyProto.Params.Curve {
+	case commonpb.EllipticCurveType_NIST_P256:
+		curve = crypto.P256
+	case commonpb.EllipticCurveType_NIST_P384:
+		curve = crypto.P384
+	case commonpb.EllipticCurveType_NIST_P521:
+		curve = crypto.P521
+	default:
+		return nil, errUnsupportedCurve
+	}
+
+	return &crypto.PublicKey{X: keyProto.X, Y: keyProto.Y, Curve: curve, Type: crypto.ECKeyType}, nil
+}
+
+func x25519PrivateKey(serializedKey []byte) (*ecdhPrivateKey, error) {
+	keyProto := new(ed25519pb.Ed25519PrivateKey)
+
+	if err := proto.Unmarshal(serializedKey, keyProto); err != nil {
+		return nil, fmt.Errorf("unmarshal ed25519 private key: %w", err)
+	}
+
+	if len(keyProto.KeyValue) != ed25519.SeedSize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+
+	d, err := cryptoutil.SecretEd25519toCurve25519(ed25519.NewKeyFromSeed(keyProto.KeyValue))
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := curve25519.X25519(d, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdhPrivateKey{public: &crypto.PublicKey{X: pub, Curve: crypto.X25519, Type: crypto.OKPKeyType}, d: d}, nil
+}
+
+func x25519PublicKey(edPub []byte) (*crypto.PublicKey, error) {
+	pub, err := cryptoutil.PublicEd25519toCurve25519(edPub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypto.PublicKey{X: pub, Curve: crypto.X25519, Type: crypto.OKPKeyType}, nil
+}
+
+// generateKey generates an ephemeral private key on the given curve
+func generateKey(curve string) (*ecdhPrivateKey, error) {
+	if curve == crypto.X25519 {
+		d := make([]byte, curve25519.ScalarSize)
+
+		if _, err := rand.Read(d); err != nil {
+			return nil, err
+		}
+
+		pub, err := curve25519.X25519(d, curve25519.Basepoint)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdhPrivateKey{public: &crypto.PublicKey{X: pub, Curve: curve, Type: crypto.OKPKeyType}, d: d}, nil
+	}
+
+	c, err := ellipticCurve(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := ecdsa.GenerateKey(c, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecPrivateKey(curve, k), nil
+}
+
+// ecPrivateKey returns the key agreement private key of k, its coordinates are left padded to the size of the curve
+// as required for the EC JWK of the ephemeral public keys (RFC 7518 section 6.2.1.2)
+func ecPrivateKey(curve string, k *ecdsa.PrivateKey) *ecdhPrivateKey {
+	size := curveSize(k.Curve)
+
+	return &ecdhPrivateKey{
+		public: &crypto.PublicKey{X: padBytes(k.X, size), Y: padBytes(k.Y, size), Curve: curve, Type: crypto.ECKeyType},
+		d:      padBytes(k.D, size),
+	}
+}
+
+// sharedSecret computes the ECDH shared secret of the private key and pub
+func (k *ecdhPrivateKey) sharedSecret(pub *crypto.PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve != k.public.Curve {
+		return nil, fmt.Errorf("public key is not on curve %s", k.public.Curve)
+	}
+
+	if pub.Curve == crypto.X25519 {
+		return curve25519.X25519(k.d, pub.X)
+	}
+
+	c, err := ellipticCurve(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := new(big.Int).SetBytes(pub.X), new(big.Int).SetBytes(pub.Y)
+	if !c.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("public key is not on curve %s", pub.Curve)
+	}
+
+	zx, _ := c.ScalarMult(x, y, k.d)
+
+	// the shared secret is the x coordinate, left padded to the size of the curve
+	return padBytes(zx, curveSize(c)), nil
+}
+
+// curveSize returns the size in bytes of the coordinates of the curve
+func curveSize(c elliptic.Curve) int {
+	return (c.Params().BitSize + 7) / 8 // nolint:gomnd
+}
+
+// padBytes returns the big-endian bytes of n, left padded with zeros to size
+func padBytes(n *big.Int, size int) []byte {
+	nBytes := n.Bytes()
+	b := make([]byte, size)
+	copy(b[len(b)-len(nBytes):], nBytes)
+
+	return b
+}
+
+func ellipticCurve(curve string) (elliptic.Curve, error) {
+	switch curve {
+	case crypto.P256:
+		return elliptic.P256(), nil
+	case crypto.P384:
+		return elliptic.P384(), nil
+	case crypto.P521:
+		return elliptic.P521(), nil
+	default:
+		return nil, errUnsupportedCurve
+	}
+}
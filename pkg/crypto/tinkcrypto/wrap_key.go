@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"crypto/aes"
+	"errors"
+	"fmt"
+
+	josecipher "github.com/square/go-jose/v3/cipher"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+// WrapKey will wrap cek for the recipient public key recPubKey with A256KW using a key encryption key agreed with
+// ECDH-ES, or with ECDH-1PU if the sender's private key handle is given with crypto.WithSender.
+// Key handles must be ECDSA (P-256, P-384 or P-521) or ED25519 keys, the latter are used as X25519 keys.
+func (t *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *crypto.PublicKey,
+	opts ...crypto.WrapKeyOpts) (*crypto.RecipientWrappedKey, error) {
+	if recPubKey == nil {
+		return nil, errors.New("wrapKey: recipient public key is required")
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	epk, err := generateKey(recPubKey.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: failed to generate ephemeral key: %w", err)
+	}
+
+	z, err := epk.sharedSecret(recPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	alg := crypto.ECDHESA256KWAlg
+
+	if pOpts.SenderKey != nil {
+		alg = crypto.ECDH1PUA256KWAlg
+
+		senderKey, e := privateKeyFromHandle(pOpts.SenderKey)
+		if e != nil {
+			return nil, fmt.Errorf("wrapKey: failed to get sender key: %w", e)
+		}
+
+		zs, e := senderKey.sharedSecret(recPubKey)
+		if e != nil {
+			return nil, fmt.Errorf("wrapKey: %w", e)
+		}
+
+		z = append(z, zs...)
+	}
+
+	block, err := aes.NewCipher(cryptoutil.DeriveA256KWKey(z, alg, apu, apv))
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	wrapped, err := josecipher.KeyWrap(block, cek)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: failed to wrap key: %w", err)
+	}
+
+	return &crypto.RecipientWrappedKey{
+		KID:          recPubKey.KID,
+		EncryptedCEK: wrapped,
+		EPK:          *epk.public,
+		Alg:          alg,
+		APU:          apu,
+		APV:          apv,
+	}, nil
+}
+
+// UnwrapKey will unwrap the cek of recWK using the recipient private key in kh key handle. The sender's public key,
+// a *crypto.PublicKey or a key handle, must be given with crypto.WithSender if recWK was wrapped with ECDH-1PU.
+func (t *Crypto) UnwrapKey(recWK *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	if recWK == nil {
+		return nil, errors.New("unwrapKey: recipient wrapped key is required")
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	recKey, err := privateKeyFromHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: failed to get recipient key: %w", err)
+	}
+
+	z, err := recKey.sharedSecret(&recWK.EPK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	switch recWK.Alg {
+	case crypto.ECDHESA256KWAlg:
+	case crypto.ECDH1PUA256KWAlg:
+		zs, e := senderSharedSecret(recKey, pOpts.SenderKey)
+		if e != nil {
+			return nil, fmt.Errorf("unwrapKey: %w", e)
+		}
+
+		z = append(z, zs...)
+	default:
+		return nil, fmt.Errorf("unwrapKey: unsupported algorithm %s", recWK.Alg)
+	}
+
+	block, err := aes.NewCipher(cryptoutil.DeriveA256KWKey(z, recWK.Alg, recWK.APU, recWK.APV))
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	cek, err := josecipher.KeyUnwrap(block, recWK.EncryptedCEK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: failed to unwrap key: %w", err)
+	}
+
+	return cek, nil
+}
+
+// senderSharedSecret computes the static shared secret of ECDH-1PU with the sender's public key
+func senderSharedSecret(recKey *ecdhPrivateKey, senderKey interface{}) ([]byte, error) {
+	if senderKey == nil {
+		return nil, errors.New("sender public key is required for ECDH-1PU")
+	}
+
+	senderPubKey, err := publicKey(senderKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender public key: %w", err)
+	}
+
+	return recKey.sharedSecret(senderPubKey)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+)
+
+func TestCrypto_WrapUnwrapKey(t *testing.T) {
+	templates := map[string]*tinkpb.KeyTemplate{
+		crypto.P256:   signature.ECDSAP256KeyWithoutPrefixTemplate(),
+		crypto.P384:   signature.ECDSAP384KeyWithoutPrefixTemplate(),
+		crypto.P521:   signature.ECDSAP521KeyWithoutPrefixTemplate(),
+		crypto.X25519: signature.ED25519KeyWithoutPrefixTemplate(),
+	}
+
+	c := Crypto{}
+	cek := randomBytes(t, 32)
+	apu := []byte("sender")
+	apv := []byte("recipient")
+
+	for curve, template := range templates {
+		recKH, err := keyset.NewHandle(template)
+		require.NoError(t, err)
+
+		recPubKey, err := ExportPublicKey(recKH)
+		require.NoError(t, err)
+		require.Equal(t, curve, recPubKey.Curve)
+
+		recPubKey.KID = "recipient-key"
+
+		senderKH, err := keyset.NewHandle(template)
+		require.NoError(t, err)
+
+		t.Run("ECDH-ES with "+curve, func(t *testing.T) {
+			wk, e := c.WrapKey(cek, apu, apv, recPubKey)
+			require.NoError(t, e)
+			require.Equal(t, crypto.ECDHESA256KWAlg, wk.Alg)
+			require.Equal(t, "recipient-key", wk.KID)
+			require.Equal(t, curve, wk.EPK.Curve)
+			require.Equal(t, apu, wk.APU)
+			require.Equal(t, apv, wk.APV)
+
+			unwrapped, e := c.UnwrapKey(wk, recKH)
+			require.NoError(t, e)
+			require.Equal(t, cek, unwrapped)
+
+			// a different key can't unwrap
+			_, e = c.UnwrapKey(wk, senderKH)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), "failed to unwrap key")
+		})
+
+		t.Run("ECDH-1PU with "+curve, func(t *testing.T) {
+			wk, e := c.WrapKey(cek, apu, apv, recPubKey, crypto.WithSender(senderKH))
+			require.NoError(t, e)
+			require.Equal(t, crypto.ECDH1PUA256KWAlg, wk.Alg)
+
+			senderPubKH, e := senderKH.Public()
+			require.NoError(t, e)
+
+			senderPubKey, e := ExportPublicKey(senderKH)
+			require.NoError(t, e)
+
+			for _, sender := range []interface{}{senderKH, senderPubKH, senderPubKey} {
+				unwrapped, unwrapErr := c.UnwrapKey(wk, recKH, crypto.WithSender(sender))
+				require.NoError(t, unwrapErr)
+				require.Equal(t, cek, unwrapped)
+			}
+
+			_, e = c.UnwrapKey(wk, recKH)
+			require.EqualError(t, e, "unwrapKey: sender public key is required for ECDH-1PU")
+
+			// the key of another sender can't unwrap
+			_, e = c.UnwrapKey(wk, recKH, crypto.WithSender(recPubKey))
+			require.Error(t, e)
+			require.Contains(t, e.Error(), "failed to unwrap key")
+		})
+	}
+}
+
+func TestCrypto_WrapUnwrapKeyErrors(t *testing.T) {
+	c := Crypto{}
+	cek := randomBytes(t, 32)
+
+	p256KH, err := keyset.NewHandle(signature.ECDSAP256KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+
+	p256PubKey, err := ExportPublicKey(p256KH)
+	require.NoError(t, err)
+
+	p384KH, err := keyset.NewHandle(signature.ECDSAP384KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+
+	aeadKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	t.Run("wrap errors", func(t *testing.T) {
+		_, err = c.WrapKey(cek, nil, nil, nil)
+		require.EqualError(t, err, "wrapKey: recipient public key is required")
+
+		_, err = c.WrapKey(cek, nil, nil, &crypto.PublicKey{Curve: "P-192"})
+		require.EqualError(t, err, "wrapKey: failed to generate ephemeral key: unsupported curve")
+
+		_, err = c.WrapKey(cek, nil, nil, &crypto.PublicKey{X: []byte("x"), Y: []byte("y"), Curve: crypto.P256})
+		require.EqualError(t, err, "wrapKey: public key is not on curve P-256")
+
+		_, err = c.WrapKey(cek, nil, nil, p256PubKey, crypto.WithSender("bad key handle"))
+		require.EqualError(t, err, "wrapKey: failed to get sender key: bad key handle format")
+
+		_, err = c.WrapKey(cek, nil, nil, p256PubKey, crypto.WithSender(aeadKH))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key type not supported for key agreement")
+
+		_, err = c.WrapKey(cek, nil, nil, p256PubKey, crypto.WithSender(p384KH))
+		require.EqualError(t, err, "wrapKey: public key is not on curve P-384")
+
+		_, err = c.WrapKey([]byte("bad cek"), nil, nil, p256PubKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wrapKey: failed to wrap key")
+	})
+
+	t.Run("unwrap errors", func(t *testing.T) {
+		wk, e := c.WrapKey(cek, nil, nil, p256PubKey)
+		require.NoError(t, e)
+
+		_, err = c.UnwrapKey(nil, p256KH)
+		require.EqualError(t, err, "unwrapKey: recipient wrapped key is required")
+
+		_, err = c.UnwrapKey(wk, "bad key handle")
+		require.EqualError(t, err, "unwrapKey: failed to get recipient key: bad key handle format")
+
+		pubKH, e := p256KH.Public()
+		require.NoError(t, e)
+
+		_, err = c.UnwrapKey(wk, pubKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key type not supported for key agreement")
+
+		_, err = c.UnwrapKey(wk, p384KH)
+		require.EqualError(t, err, "unwrapKey: public key is not on curve P-384")
+
+		badAlg := *wk
+		badAlg.Alg = "ECDH-ES+A128KW"
+
+		_, err = c.UnwrapKey(&badAlg, p256KH)
+		require.EqualError(t, err, "unwrapKey: unsupported algorithm ECDH-ES+A128KW")
+
+		oneP := *wk
+		oneP.Alg = crypto.ECDH1PUA256KWAlg
+
+		_, err = c.UnwrapKey(&oneP, p256KH, crypto.WithSender(aeadKH))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unwrapKey: failed to get sender public key")
+	})
+
+	t.Run("export public key errors", func(t *testing.T) {
+		_, err = ExportPublicKey("bad key handle")
+		require.EqualError(t, err, "bad key handle format")
+
+		_, err = ExportPublicKey(aeadKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key type not supported for key agreement")
+	})
+}
+
+func TestCrypto_SharedSecret(t *testing.T) {
+	c := Crypto{}
+
+	for _, template := range []*tinkpb.KeyTemplate{
+		signature.ECDSAP256KeyWithoutPrefixTemplate(),
+		signature.ED25519KeyWithoutPrefixTemplate(),
+	} {
+		kh1, err := keyset.NewHandle(template)
+		require.NoError(t, err)
+
+		kh2, err := keyset.NewHandle(template)
+		require.NoError(t, err)
+
+		pubKey1, err := ExportPublicKey(kh1)
+		require.NoError(t, err)
+
+		pubKey2, err := ExportPublicKey(kh2)
+		require.NoError(t, err)
+
+		z1, err := c.SharedSecret(pubKey2, kh1)
+		require.NoError(t, err)
+
+		z2, err := c.SharedSecret(pubKey1, kh2)
+		require.NoError(t, err)
+		require.Equal(t, z1, z2)
+	}
+
+	kh, err := keyset.NewHandle(signature.ED25519KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+
+	_, err = c.SharedSecret(&crypto.PublicKey{Curve: crypto.P256}, kh)
+	require.EqualError(t, err, "sharedSecret: public key is not on curve X25519")
+
+	aeadKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	_, err = c.SharedSecret(&crypto.PublicKey{Curve: crypto.X25519}, aeadKH)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sharedSecret: key type not supported for key agreement")
+}
+
+func TestECPrivateKey_ShortCoordinate(t *testing.T) {
+	// about 1 in 256 P-256 keys have an x coordinate shorter than 32 bytes
+	var k *ecdsa.PrivateKey
+
+	for k == nil || k.X.BitLen() > 248 {
+		var err error
+
+		k, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+	}
+
+	require.Less(t, len(k.X.Bytes()), 32)
+
+	privKey := ecPrivateKey(crypto.P256, k)
+	require.Len(t, privKey.public.X, 32)
+	require.Len(t, privKey.public.Y, 32)
+	require.Equal(t, k.X, new(big.Int).SetBytes(privKey.public.X))
+
+	// the padded ephemeral key agrees on the same secret as the recipient key
+	recipient, err := generateKey(crypto.P256)
+	require.NoError(t, err)
+
+	z1, err := privKey.sharedSecret(recipient.public)
+	require.NoError(t, err)
+
+	z2, err := recipient.sharedSecret(privKey.public)
+	require.NoError(t, err)
+	require.Equal(t, z1, z2)
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	t.Helper()
+
+	b := make([]byte, size)
+
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
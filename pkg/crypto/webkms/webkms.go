@@ -0,0 +1,203 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package webkms implements a crypto.Crypto executing its operations in a remote KMS reached over HTTP, with the key
+// URLs returned by the remote kms.KeyManager of package pkg/kms/webkms as key handles.
+package webkms
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+)
+
+var (
+	errBadKeyHandleFormat = errors.New("bad key handle format, expected a key URL")
+	errNotSupported       = errors.New("not supported by the remote kms")
+)
+
+// RemoteCrypto is a crypto.Crypto of a keystore of a remote KMS
+type RemoteCrypto struct {
+	keystoreURL string
+	httpClient  *http.Client
+}
+
+// Option configures the remote crypto
+type Option func(opts *RemoteCrypto)
+
+// WithHTTPClient option sets the HTTP client sending the requests to the remote KMS, to set its timeout and TLS
+// configuration
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *RemoteCrypto) {
+		opts.httpClient = client
+	}
+}
+
+// New creates a new remote crypto of the keystore at keystoreURL
+func New(keystoreURL string, opts ...Option) (*RemoteCrypto, error) {
+	_, err := url.ParseRequestURI(keystoreURL)
+	if err != nil {
+		return nil, fmt.Errorf("keystore URL invalid: %w", err)
+	}
+
+	r := &RemoteCrypto{keystoreURL: strings.TrimSuffix(keystoreURL, "/"), httpClient: &http.Client{}}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Encrypt is not supported by the remote crypto
+func (r *RemoteCrypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error) {
+	return nil, nil, fmt.Errorf("encrypt: %w", errNotSupported)
+}
+
+// Decrypt is not supported by the remote crypto
+func (r *RemoteCrypto) Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("decrypt: %w", errNotSupported)
+}
+
+// Sign will sign msg with the key of key URL kh in the remote KMS
+func (r *RemoteCrypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
+	keyURL, ok := kh.(string)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	var resp webkms.SignResponse
+
+	err := webkms.DoRequest(r.httpClient, http.MethodPost, keyURL+webkms.SignPath, &webkms.SignRequest{Message: msg},
+		&resp)
+	if err != nil {
+		return nil, fmt.Errorf("sign msg: %w", err)
+	}
+
+	return resp.Signature, nil
+}
+
+// Verify will verify the signature of msg with the key of key URL kh in the remote KMS
+func (r *RemoteCrypto) Verify(signature, msg []byte, kh interface{}) error {
+	keyURL, ok := kh.(string)
+	if !ok {
+		return errBadKeyHandleFormat
+	}
+
+	err := webkms.DoRequest(r.httpClient, http.MethodPost, keyURL+webkms.VerifyPath,
+		&webkms.VerifyRequest{Signature: signature, Message: msg}, nil)
+	if err != nil {
+		return fmt.Errorf("verify msg: %w", err)
+	}
+
+	return nil
+}
+
+// ComputeMAC is not supported by the remote crypto
+func (r *RemoteCrypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("computeMAC: %w", errNotSupported)
+}
+
+// VerifyMAC is not supported by the remote crypto
+func (r *RemoteCrypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	return fmt.Errorf("verifyMAC: %w", errNotSupported)
+}
+
+// WrapKey will wrap cek for the recipient public key recPubKey in the remote KMS with ECDH-ES, or with ECDH-1PU if
+// the key URL of the sender's key is given with crypto.WithSender.
+func (r *RemoteCrypto) WrapKey(cek, apu, apv []byte, recPubKey *crypto.PublicKey,
+	opts ...crypto.WrapKeyOpts) (*crypto.RecipientWrappedKey, error) {
+	if recPubKey == nil {
+		return nil, errors.New("wrapKey: recipient public key is required")
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	wrapURL := r.keystoreURL + webkms.WrapKeyPath
+
+	if pOpts.SenderKey != nil {
+		senderKeyURL, ok := pOpts.SenderKey.(string)
+		if !ok {
+			return nil, fmt.Errorf("wrapKey: sender key: %w", errBadKeyHandleFormat)
+		}
+
+		wrapURL = senderKeyURL + webkms.WrapKeyPath
+	}
+
+	var resp webkms.WrapKeyResponse
+
+	err := webkms.DoRequest(r.httpClient, http.MethodPost, wrapURL, &webkms.WrapKeyRequest{
+		CEK:                cek,
+		APU:                apu,
+		APV:                apv,
+		RecipientPublicKey: recPubKey,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	return resp.WrappedKey, nil
+}
+
+// UnwrapKey will unwrap the cek of recWK with the recipient key of key URL kh in the remote KMS. The sender's
+// *crypto.PublicKey must be given with crypto.WithSender if recWK was wrapped with ECDH-1PU.
+func (r *RemoteCrypto) UnwrapKey(recWK *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	if recWK == nil {
+		return nil, errors.New("unwrapKey: recipient wrapped key is required")
+	}
+
+	keyURL, ok := kh.(string)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	pOpts := crypto.NewWrapKeyOptions(opts...)
+
+	req := &webkms.UnwrapKeyRequest{WrappedKey: recWK}
+
+	if pOpts.SenderKey != nil {
+		req.SenderPublicKey, ok = pOpts.SenderKey.(*crypto.PublicKey)
+		if !ok {
+			return nil, errors.New("unwrapKey: sender key must be a public key")
+		}
+	}
+
+	var resp webkms.UnwrapKeyResponse
+
+	err := webkms.DoRequest(r.httpClient, http.MethodPost, keyURL+webkms.UnwrapKeyPath, req, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	return resp.Key, nil
+}
+
+// SignMulti is not supported by the remote crypto
+func (r *RemoteCrypto) SignMulti(messages [][]byte, kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("signMulti: %w", errNotSupported)
+}
+
+// VerifyMulti is not supported by the remote crypto
+func (r *RemoteCrypto) VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error {
+	return fmt.Errorf("verifyMulti: %w", errNotSupported)
+}
+
+// DeriveProof is not supported by the remote crypto
+func (r *RemoteCrypto) DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int,
+	kh interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("deriveProof: %w", errNotSupported)
+}
+
+// VerifyProof is not supported by the remote crypto
+func (r *RemoteCrypto) VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error {
+	return fmt.Errorf("verifyProof: %w", errNotSupported)
+}
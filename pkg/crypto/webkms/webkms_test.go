@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("invalid url")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "keystore URL invalid")
+
+	client := &http.Client{}
+
+	r, err := New("https://kms.example.com/keystores/123/", WithHTTPClient(client))
+	require.NoError(t, err)
+	require.Equal(t, "https://kms.example.com/keystores/123", r.keystoreURL)
+	require.Equal(t, client, r.httpClient)
+}
+
+func TestRemoteCrypto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response interface{}
+
+		switch req.URL.Path {
+		case webkms.KeysPath + "/keyID" + webkms.SignPath:
+			response = &webkms.SignResponse{Signature: []byte("signature")}
+		case webkms.KeysPath + "/keyID" + webkms.VerifyPath:
+			var request webkms.VerifyRequest
+
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+
+			if string(request.Signature) != "signature" {
+				rw.WriteHeader(http.StatusBadRequest)
+				response = &webkms.ErrorResponse{Message: "invalid signature"}
+			}
+		case webkms.WrapKeyPath:
+			response = &webkms.WrapKeyResponse{WrappedKey: &crypto.RecipientWrappedKey{Alg: crypto.ECDHESA256KWAlg}}
+		case webkms.KeysPath + "/senderKeyID" + webkms.WrapKeyPath:
+			response = &webkms.WrapKeyResponse{WrappedKey: &crypto.RecipientWrappedKey{Alg: crypto.ECDH1PUA256KWAlg}}
+		case webkms.KeysPath + "/keyID" + webkms.UnwrapKeyPath:
+			var request webkms.UnwrapKeyRequest
+
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+			require.Equal(t, request.WrappedKey.Alg == crypto.ECDH1PUA256KWAlg, request.SenderPublicKey != nil)
+
+			response = &webkms.UnwrapKeyResponse{Key: []byte("cek")}
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			response = &webkms.ErrorResponse{Message: "key not found"}
+		}
+
+		require.NoError(t, json.NewEncoder(rw).Encode(response))
+	}))
+	defer srv.Close()
+
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+
+	keyURL := webkms.KeyURL(srv.URL, "keyID")
+
+	t.Run("sign and verify", func(t *testing.T) {
+		sig, e := r.Sign([]byte("msg"), keyURL)
+		require.NoError(t, e)
+		require.Equal(t, []byte("signature"), sig)
+
+		require.NoError(t, r.Verify(sig, []byte("msg"), keyURL))
+
+		e = r.Verify([]byte("other"), []byte("msg"), keyURL)
+		require.EqualError(t, e, "verify msg: remote kms responded with status 400: invalid signature")
+
+		_, e = r.Sign([]byte("msg"), webkms.KeyURL(srv.URL, "unknown"))
+		require.EqualError(t, e, "sign msg: remote kms responded with status 404: key not found")
+
+		_, e = r.Sign([]byte("msg"), []byte("keyID"))
+		require.Equal(t, errBadKeyHandleFormat, e)
+
+		e = r.Verify(sig, []byte("msg"), nil)
+		require.Equal(t, errBadKeyHandleFormat, e)
+	})
+
+	t.Run("wrap and unwrap", func(t *testing.T) {
+		recPubKey := &crypto.PublicKey{Curve: crypto.P256}
+
+		wk, e := r.WrapKey([]byte("cek"), nil, nil, recPubKey)
+		require.NoError(t, e)
+		require.Equal(t, crypto.ECDHESA256KWAlg, wk.Alg)
+
+		cek, e := r.UnwrapKey(wk, keyURL)
+		require.NoError(t, e)
+		require.Equal(t, []byte("cek"), cek)
+
+		wk, e = r.WrapKey([]byte("cek"), nil, nil, recPubKey,
+			crypto.WithSender(webkms.KeyURL(srv.URL, "senderKeyID")))
+		require.NoError(t, e)
+		require.Equal(t, crypto.ECDH1PUA256KWAlg, wk.Alg)
+
+		cek, e = r.UnwrapKey(wk, keyURL, crypto.WithSender(&crypto.PublicKey{Curve: crypto.P256}))
+		require.NoError(t, e)
+		require.Equal(t, []byte("cek"), cek)
+	})
+
+	t.Run("wrap and unwrap errors", func(t *testing.T) {
+		_, e := r.WrapKey([]byte("cek"), nil, nil, nil)
+		require.EqualError(t, e, "wrapKey: recipient public key is required")
+
+		_, e = r.WrapKey([]byte("cek"), nil, nil, &crypto.PublicKey{}, crypto.WithSender(1))
+		require.True(t, errors.Is(e, errBadKeyHandleFormat))
+
+		_, e = r.WrapKey([]byte("cek"), nil, nil, &crypto.PublicKey{},
+			crypto.WithSender(webkms.KeyURL(srv.URL, "unknown")))
+		require.EqualError(t, e, "wrapKey: remote kms responded with status 404: key not found")
+
+		_, e = r.UnwrapKey(nil, keyURL)
+		require.EqualError(t, e, "unwrapKey: recipient wrapped key is required")
+
+		_, e = r.UnwrapKey(&crypto.RecipientWrappedKey{}, 1)
+		require.Equal(t, errBadKeyHandleFormat, e)
+
+		_, e = r.UnwrapKey(&crypto.RecipientWrappedKey{}, keyURL, crypto.WithSender(keyURL))
+		require.EqualError(t, e, "unwrapKey: sender key must be a public key")
+
+		_, e = r.UnwrapKey(&crypto.RecipientWrappedKey{}, webkms.KeyURL(srv.URL, "unknown"))
+		require.EqualError(t, e, "unwrapKey: remote kms responded with status 404: key not found")
+	})
+}
+
+func TestRemoteCrypto_NotSupported(t *testing.T) {
+	r, err := New("https://kms.example.com/keystores/123")
+	require.NoError(t, err)
+
+	_, _, err = r.Encrypt(nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = r.Decrypt(nil, nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = r.ComputeMAC(nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = r.VerifyMAC(nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = r.SignMulti(nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = r.VerifyMulti(nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	_, err = r.DeriveProof(nil, nil, nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+
+	err = r.VerifyProof(nil, nil, nil, "")
+	require.True(t, errors.Is(err, errNotSupported))
+}
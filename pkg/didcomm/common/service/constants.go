@@ -6,5 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 
 package service
 
+import "time"
+
 // ForwardMsgType defines the route forward message type.
 const ForwardMsgType = "https://didcomm.org/routing/1.0/forward"
+
+// TransitionalPayloadTTL is how long the protocol services keep the transitional payload of an action, waiting for
+// the consumer of its DIDCommAction event to continue or stop it, before dropping the action.
+const TransitionalPayloadTTL = 7 * 24 * time.Hour
@@ -16,4 +16,17 @@ type Envelope struct {
 	ToVerKey []byte
 	FromDID  string
 	ToDID    string
+	// FromKID is the key ID, a DID URL such as did:example:123#key-1, of the sender key of a DIDComm v2 message
+	FromKID string
+	// ToKIDs stores the key IDs (DID URLs) of the recipients keys of an outbound DIDComm v2 message
+	ToKIDs []string
+	// ToKID holds the key ID (DID URL) of the key that was used to decrypt an inbound DIDComm v2 message
+	ToKID string
+	// ContentType is the media type of the envelope: it selects the packer of an outbound message, the primary
+	// packer if empty, and holds the type of an inbound message (e.g. application/didcomm-encrypted+json)
+	ContentType string
+	// InnerContentType is the media type of the envelope nested in the envelope of ContentType: an outbound message
+	// is first packed in it, e.g. application/didcomm-signed+json to sign then encrypt the message, and it holds
+	// the type of the nested envelope of an inbound message, if any
+	InnerContentType string
 }
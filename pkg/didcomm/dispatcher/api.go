@@ -29,11 +29,38 @@ type MessageService interface {
 // Outbound interface
 type Outbound interface {
 	// Sends the message after packing with the sender key and recipient keys.
-	Send(interface{}, string, *service.Destination) error
+	Send(interface{}, string, *service.Destination, ...SendOpts) error
 
 	// Sends the message after packing with the keys derived from DIDs.
-	SendToDID(msg interface{}, myDID, theirDID string) error
+	SendToDID(msg interface{}, myDID, theirDID string, opts ...SendOpts) error
 
 	// Forward forwards the message without packing to the destination.
 	Forward(interface{}, *service.Destination) error
 }
+
+// MessagePacking selects the envelopes an Outbound dispatcher packs the messages of a type in, instead of the envelope
+// of the primary packer
+type MessagePacking struct {
+	// ContentType is the media type of the envelope of the messages, the one of the primary packer if empty. Messages
+	// sent through routers must be packed in encrypted envelopes.
+	ContentType string
+	// InnerContentType is the media type of the envelope the messages are first packed in, if any, before being
+	// packed in the envelope of ContentType, e.g. application/didcomm-signed+json to sign then encrypt the messages
+	InnerContentType string
+}
+
+// SendOptions holds the options of the messages sent by an Outbound dispatcher
+type SendOptions struct {
+	// Packing is the packing of the message, instead of the MessagePacking of its type
+	Packing *MessagePacking
+}
+
+// SendOpts is an option of the messages sent by an Outbound dispatcher
+type SendOpts func(opts *SendOptions)
+
+// WithPacking packs the message sent in the envelopes of packing, instead of those of the MessagePacking of its type
+func WithPacking(packing MessagePacking) SendOpts {
+	return func(opts *SendOptions) {
+		opts.Packing = &packing
+	}
+}
@@ -28,6 +28,7 @@ type provider interface {
 	Packager() commontransport.Packager
 	OutboundTransports() []transport.OutboundTransport
 	TransportReturnRoute() string
+	MessagePacking() map[string]MessagePacking
 	VDRIRegistry() vdri.Registry
 	LegacyKMS() legacykms.KeyManager
 }
@@ -37,6 +38,7 @@ type OutboundDispatcher struct {
 	outboundTransports   []transport.OutboundTransport
 	packager             commontransport.Packager
 	transportReturnRoute string
+	messagePacking       map[string]MessagePacking
 	vdRegistry           vdri.Registry
 	kms                  legacykms.KeyManager
 }
@@ -47,13 +49,14 @@ func NewOutbound(prov provider) *OutboundDispatcher {
 		outboundTransports:   prov.OutboundTransports(),
 		packager:             prov.Packager(),
 		transportReturnRoute: prov.TransportReturnRoute(),
+		messagePacking:       prov.MessagePacking(),
 		vdRegistry:           prov.VDRIRegistry(),
 		kms:                  prov.LegacyKMS(),
 	}
 }
 
 // SendToDID sends a message from myDID to the agent who owns theirDID
-func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string) error {
+func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string, opts ...SendOpts) error {
 	dest, err := service.GetDestination(theirDID, o.vdRegistry)
 	if err != nil {
 		return err
@@ -69,11 +72,20 @@ func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string)
 	// TODO: relies on hardcoded key type
 	key := src.RecipientKeys[0]
 
-	return o.Send(msg, key, dest)
+	return o.Send(msg, key, dest, opts...)
 }
 
-// Send sends the message after packing with the sender key and recipient keys.
-func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *service.Destination) error {
+// Send sends the message after packing with the sender key and recipient keys. The message is packed in the envelopes
+// of the WithPacking option, if any, otherwise of the MessagePacking of its type, if any, otherwise in the envelope of
+// the primary packer.
+func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *service.Destination,
+	opts ...SendOpts) error {
+	sendOpts := &SendOptions{}
+
+	for _, opt := range opts {
+		opt(sendOpts)
+	}
+
 	for _, v := range o.outboundTransports {
 		// check if outbound accepts routing keys, else use recipient keys
 		keys := des.RecipientKeys
@@ -98,8 +110,15 @@ func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *ser
 			return fmt.Errorf("add transport route options : %w", err)
 		}
 
-		packedMsg, err := o.packager.PackMessage(
-			&commontransport.Envelope{Message: req, FromVerKey: base58.Decode(senderVerKey), ToVerKeys: des.RecipientKeys})
+		packing := o.packing(req, sendOpts)
+
+		packedMsg, err := o.packager.PackMessage(&commontransport.Envelope{
+			Message:          req,
+			FromVerKey:       base58.Decode(senderVerKey),
+			ToVerKeys:        des.RecipientKeys,
+			ContentType:      packing.ContentType,
+			InnerContentType: packing.InnerContentType,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to pack msg: %w", err)
 		}
@@ -148,6 +167,29 @@ func (o *OutboundDispatcher) Forward(msg interface{}, des *service.Destination)
 	return fmt.Errorf("no outbound transport found for serviceEndpoint: %s", des.ServiceEndpoint)
 }
 
+// packing returns the MessagePacking of the options of the message req, or of its type, the packing of the primary
+// packer if none
+func (o *OutboundDispatcher) packing(req []byte, opts *SendOptions) MessagePacking {
+	if opts.Packing != nil {
+		return *opts.Packing
+	}
+
+	if len(o.messagePacking) == 0 {
+		return MessagePacking{}
+	}
+
+	msg := struct {
+		Type string `json:"@type"`
+	}{}
+
+	// messages which aren't JSON objects have no type
+	if err := json.Unmarshal(req, &msg); err != nil {
+		return MessagePacking{}
+	}
+
+	return o.messagePacking[msg.Type]
+}
+
 func (o *OutboundDispatcher) createForwardMessage(msg []byte, des *service.Destination) ([]byte, error) {
 	if len(des.RoutingKeys) == 0 {
 		return msg, nil
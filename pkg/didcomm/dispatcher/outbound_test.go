@@ -141,6 +141,28 @@ func TestOutboundDispatcher_SendToDID(t *testing.T) {
 		require.NoError(t, o.SendToDID("data", "", ""))
 	})
 
+	t.Run("success with send options", func(t *testing.T) {
+		packager := &mockPackager{}
+
+		o := NewOutbound(&mockProvider{
+			packagerValue: packager,
+			vdriRegistry: &mockvdri.MockVDRIRegistry{
+				ResolveValue: mockDoc,
+			},
+			outboundTransportsValue: []transport.OutboundTransport{
+				&mockdidcomm.MockOutboundTransport{AcceptValue: true},
+			},
+		})
+
+		require.NoError(t, o.SendToDID(&service.DIDCommMsgMap{"@type": "type"}, "", "",
+			WithPacking(MessagePacking{InnerContentType: "application/didcomm-signed+json"})))
+
+		// the message is packed with the option, then the forward message of its router with the primary packer
+		require.Len(t, packager.envelopes, 2)
+		require.Equal(t, "application/didcomm-signed+json", packager.envelopes[0].InnerContentType)
+		require.Empty(t, packager.envelopes[1].InnerContentType)
+	})
+
 	t.Run("resolve err", func(t *testing.T) {
 		o := NewOutbound(&mockProvider{
 			packagerValue: &mockpackager.Packager{},
@@ -250,6 +272,78 @@ func TestOutboundDispatcherTransportReturnRoute(t *testing.T) {
 	})
 }
 
+func TestOutboundDispatcher_MessagePacking(t *testing.T) {
+	const offerType = "https://didcomm.org/issue-credential/1.0/offer-credential"
+
+	newOutbound := func(packager *mockPackager) *OutboundDispatcher {
+		return NewOutbound(&mockProvider{
+			packagerValue:           packager,
+			outboundTransportsValue: []transport.OutboundTransport{&mockdidcomm.MockOutboundTransport{AcceptValue: true}},
+			messagePacking: map[string]MessagePacking{
+				offerType: {InnerContentType: "application/didcomm-signed+json"},
+				"consent": {ContentType: "application/didcomm-signed+json"},
+			},
+		})
+	}
+
+	t.Run("message packed in the envelopes of its type", func(t *testing.T) {
+		packager := &mockPackager{}
+
+		err := newOutbound(packager).Send(&service.DIDCommMsgMap{"@type": offerType}, "",
+			&service.Destination{ServiceEndpoint: "url"})
+		require.NoError(t, err)
+		require.Len(t, packager.envelopes, 1)
+		require.Empty(t, packager.envelopes[0].ContentType)
+		require.Equal(t, "application/didcomm-signed+json", packager.envelopes[0].InnerContentType)
+
+		err = newOutbound(packager).Send(&service.DIDCommMsgMap{"@type": "consent"}, "",
+			&service.Destination{ServiceEndpoint: "url"})
+		require.NoError(t, err)
+		require.Len(t, packager.envelopes, 2)
+		require.Equal(t, "application/didcomm-signed+json", packager.envelopes[1].ContentType)
+		require.Empty(t, packager.envelopes[1].InnerContentType)
+	})
+
+	t.Run("message packed in the envelopes of the send option", func(t *testing.T) {
+		packager := &mockPackager{}
+
+		// the option overrides the packing of the type of the message
+		err := newOutbound(packager).Send(&service.DIDCommMsgMap{"@type": offerType}, "",
+			&service.Destination{ServiceEndpoint: "url"},
+			WithPacking(MessagePacking{ContentType: "application/didcomm-encrypted+json"}))
+		require.NoError(t, err)
+
+		err = newOutbound(packager).Send("data", "", &service.Destination{ServiceEndpoint: "url"},
+			WithPacking(MessagePacking{InnerContentType: "application/didcomm-signed+json"}))
+		require.NoError(t, err)
+
+		require.Len(t, packager.envelopes, 2)
+		require.Equal(t, "application/didcomm-encrypted+json", packager.envelopes[0].ContentType)
+		require.Empty(t, packager.envelopes[0].InnerContentType)
+		require.Empty(t, packager.envelopes[1].ContentType)
+		require.Equal(t, "application/didcomm-signed+json", packager.envelopes[1].InnerContentType)
+	})
+
+	t.Run("message packed in the envelope of the primary packer", func(t *testing.T) {
+		packager := &mockPackager{}
+
+		err := newOutbound(packager).Send(&service.DIDCommMsgMap{"@type": "https://didcomm.org/trust_ping/1.0/ping"},
+			"", &service.Destination{ServiceEndpoint: "url"})
+		require.NoError(t, err)
+
+		// messages which aren't JSON objects have no type
+		err = newOutbound(packager).Send("data", "", &service.Destination{ServiceEndpoint: "url"})
+		require.NoError(t, err)
+
+		require.Len(t, packager.envelopes, 2)
+
+		for _, e := range packager.envelopes {
+			require.Empty(t, e.ContentType)
+			require.Empty(t, e.InnerContentType)
+		}
+	})
+}
+
 func TestOutboundDispatcher_Forward(t *testing.T) {
 	t.Run("test forward - success", func(t *testing.T) {
 		o := NewOutbound(&mockProvider{
@@ -291,6 +385,7 @@ type mockProvider struct {
 	packagerValue           commontransport.Packager
 	outboundTransportsValue []transport.OutboundTransport
 	transportReturnRoute    string
+	messagePacking          map[string]MessagePacking
 	vdriRegistry            vdri.Registry
 	legacyKMS               legacykms.KMS
 }
@@ -307,6 +402,10 @@ func (p *mockProvider) TransportReturnRoute() string {
 	return p.transportReturnRoute
 }
 
+func (p *mockProvider) MessagePacking() map[string]MessagePacking {
+	return p.messagePacking
+}
+
 func (p *mockProvider) VDRIRegistry() vdri.Registry {
 	return p.vdriRegistry
 }
@@ -347,9 +446,12 @@ func (o *mockOutboundTransport) Accept(url string) bool {
 
 // mockPackager mock packager
 type mockPackager struct {
+	envelopes []*commontransport.Envelope
 }
 
 func (m *mockPackager) PackMessage(e *commontransport.Envelope) ([]byte, error) {
+	m.envelopes = append(m.envelopes, e)
+
 	return e.Message, nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -29,6 +30,9 @@ const (
 	jsonThreadID       = "thid"
 	jsonParentThreadID = "pthid"
 	jsonMetadata       = "_internal_metadata"
+
+	// recordTTL is how long the payload of inbound messages is kept to be able to reply to them
+	recordTTL = 30 * 24 * time.Hour
 )
 
 // record is an internal structure and keeps payload about inbound message
@@ -242,5 +246,5 @@ func (m *Messenger) saveRecord(msgID string, rec record) error {
 		return fmt.Errorf("marshal record: %w", err)
 	}
 
-	return m.store.Put(msgID, src)
+	return storage.PutWithTTL(m.store, msgID, src, recordTTL)
 }
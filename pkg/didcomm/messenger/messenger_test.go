@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	dispatcherMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/dispatcher"
 	messengerMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/messenger"
@@ -186,8 +187,9 @@ func TestMessenger_HandleInbound(t *testing.T) {
 	})
 }
 
-func sendToDIDCheck(t *testing.T, checks ...string) func(msg service.DIDCommMsgMap, myDID, theirDID string) error {
-	return func(msg service.DIDCommMsgMap, myDID, theirDID string) error {
+func sendToDIDCheck(t *testing.T, checks ...string) func(msg service.DIDCommMsgMap, myDID, theirDID string,
+	_ ...dispatcher.SendOpts) error {
+	return func(msg service.DIDCommMsgMap, myDID, theirDID string, _ ...dispatcher.SendOpts) error {
 		v := struct {
 			ID     string           `json:"@id"`
 			Thread decorator.Thread `json:"~thread"`
@@ -8,6 +8,7 @@ package packager_test
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -17,7 +18,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	. "github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/anoncrypt"
 	jwe "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jws"
 	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm"
@@ -253,6 +256,101 @@ func TestBaseKMSInPackager_UnpackMessage(t *testing.T) {
 		require.Equal(t, unpackedMsg.Message, []byte("msg2"))
 	})
 
+	t.Run("test Pack/Unpack anoncrypt success", func(t *testing.T) {
+		w, err := legacykms.New(newMockKMSProvider(mockstorage.NewMockStoreProvider()))
+		require.NoError(t, err)
+		mockedProviders := &mockProvider{
+			storage: mockstorage.NewMockStoreProvider(),
+			kms:     w,
+		}
+
+		anonPacker, err := anoncrypt.New(mockedProviders, anoncrypt.XC20P)
+		require.NoError(t, err)
+
+		authPacker, err := jwe.New(mockedProviders, jwe.XC20P)
+		require.NoError(t, err)
+
+		mockedProviders.primaryPacker = anonPacker
+		mockedProviders.packers = []packer.Packer{authPacker}
+
+		packager, err := New(mockedProviders)
+		require.NoError(t, err)
+
+		_, base58ToVerKey, err := w.CreateKeySet()
+		require.NoError(t, err)
+
+		// pack an anonymous envelope, without sender key
+		packMsg, err := packager.PackMessage(&transport.Envelope{Message: []byte("msg1"),
+			ToVerKeys: []string{base58ToVerKey}})
+		require.NoError(t, err)
+
+		unpackedMsg, err := packager.UnpackMessage(packMsg)
+		require.NoError(t, err)
+		require.Equal(t, []byte("msg1"), unpackedMsg.Message)
+		require.Empty(t, unpackedMsg.FromVerKey)
+		require.Empty(t, unpackedMsg.FromDID)
+	})
+
+	t.Run("test Pack/Unpack sign then encrypt success", func(t *testing.T) {
+		w, err := legacykms.New(newMockKMSProvider(mockstorage.NewMockStoreProvider()))
+		require.NoError(t, err)
+		mockedProviders := &mockProvider{
+			storage: mockstorage.NewMockStoreProvider(),
+			kms:     w,
+		}
+
+		authPacker, err := jwe.New(mockedProviders, jwe.XC20P)
+		require.NoError(t, err)
+
+		signedPacker, err := jws.New(mockedProviders)
+		require.NoError(t, err)
+
+		mockedProviders.primaryPacker = authPacker
+		mockedProviders.packers = []packer.Packer{signedPacker}
+
+		packager, err := New(mockedProviders)
+		require.NoError(t, err)
+
+		_, base58FromVerKey, err := w.CreateKeySet()
+		require.NoError(t, err)
+
+		_, base58ToVerKey, err := w.CreateKeySet()
+		require.NoError(t, err)
+
+		// the message is signed by the sender, then the signed envelope is encrypted for the recipient
+		packMsg, err := packager.PackMessage(&transport.Envelope{Message: []byte("msg1"),
+			FromVerKey:       base58.Decode(base58FromVerKey),
+			ToVerKeys:        []string{base58ToVerKey},
+			InnerContentType: jws.SignedMediaType})
+		require.NoError(t, err)
+
+		unpackedMsg, err := packager.UnpackMessage(packMsg)
+		require.NoError(t, err)
+		require.Equal(t, []byte("msg1"), unpackedMsg.Message)
+		require.Equal(t, authPacker.EncodingType(), unpackedMsg.ContentType)
+		require.Equal(t, jws.SignedMediaType, unpackedMsg.InnerContentType)
+		require.Equal(t, base58.Decode(base58FromVerKey), unpackedMsg.FromVerKey)
+
+		// signed only
+		packMsg, err = packager.PackMessage(&transport.Envelope{Message: []byte("msg2"),
+			FromVerKey:  base58.Decode(base58FromVerKey),
+			ToVerKeys:   []string{base58ToVerKey},
+			ContentType: jws.SignedMediaType})
+		require.NoError(t, err)
+
+		unpackedMsg, err = packager.UnpackMessage(packMsg)
+		require.NoError(t, err)
+		require.Equal(t, []byte("msg2"), unpackedMsg.Message)
+		require.Equal(t, jws.SignedMediaType, unpackedMsg.ContentType)
+		require.Empty(t, unpackedMsg.InnerContentType)
+		require.Equal(t, base58.Decode(base58FromVerKey), unpackedMsg.FromVerKey)
+
+		_, err = packager.PackMessage(&transport.Envelope{Message: []byte("msg3"),
+			ToVerKeys:        []string{base58ToVerKey},
+			InnerContentType: jws.SignedMediaType})
+		require.EqualError(t, err, "inner envelope: pack: failed to pack message: empty sender key")
+	})
+
 	t.Run("test success - dids not found", func(t *testing.T) {
 		// create a mock LegacyKMS with storage as a map
 		w, err := legacykms.New(newMockKMSProvider(mockstorage.NewMockStoreProvider()))
@@ -340,6 +438,197 @@ func TestBaseKMSInPackager_UnpackMessage(t *testing.T) {
 	})
 }
 
+func TestPackager_KeyIDs(t *testing.T) {
+	primaryPacker := &didcomm.MockAuthCrypt{
+		EncryptValue: func(payload, _ []byte, _ [][]byte) ([]byte, error) {
+			return payload, nil
+		},
+		Type: "prs.hyperledger.aries-auth-message",
+	}
+
+	var packedKIDs []string
+
+	v2Packer := &mockKeyIDPacker{
+		MockAuthCrypt: didcomm.MockAuthCrypt{
+			DecryptValue: func(envelope []byte) (*transport.Envelope, error) {
+				return &transport.Envelope{
+					Message: []byte("msg"),
+					FromKID: "did:example:alice#key-1",
+					ToKID:   "did:example:bob#key-2",
+				}, nil
+			},
+			Type: "application/didcomm-encrypted+json",
+		},
+		packValue: func(payload []byte, senderKID string, recipientKIDs []string) ([]byte, error) {
+			packedKIDs = append([]string{senderKID}, recipientKIDs...)
+
+			return []byte("v2 envelope"), nil
+		},
+	}
+
+	signedPacker := &didcomm.MockAuthCrypt{
+		DecryptValue: func(envelope []byte) (*transport.Envelope, error) {
+			return &transport.Envelope{Message: []byte("signed msg")}, nil
+		},
+		Type: "application/didcomm-signed+json",
+	}
+
+	mockedProviders := &mockProvider{
+		storage:       mockstorage.NewMockStoreProvider(),
+		primaryPacker: primaryPacker,
+		packers:       []packer.Packer{v2Packer, signedPacker},
+	}
+
+	packager, err := New(mockedProviders)
+	require.NoError(t, err)
+
+	t.Run("pack with the packer of the content type", func(t *testing.T) {
+		packed, err := packager.PackMessage(&transport.Envelope{
+			Message:     []byte("msg"),
+			FromKID:     "did:example:alice#key-1",
+			ToKIDs:      []string{"did:example:bob#key-2"},
+			ContentType: "application/didcomm-encrypted+json",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2 envelope"), packed)
+		require.Equal(t, []string{"did:example:alice#key-1", "did:example:bob#key-2"}, packedKIDs)
+
+		_, err = packager.PackMessage(&transport.Envelope{Message: []byte("msg"), ContentType: "text/plain"})
+		require.EqualError(t, err, "no packer for content type 'text/plain'")
+
+		_, err = packager.PackMessage(&transport.Envelope{Message: []byte("msg"), ToKIDs: []string{"did:example:bob#1"}})
+		require.EqualError(t, err, "packer 'prs.hyperledger.aries-auth-message' doesn't support key IDs")
+
+		packed, err = packager.PackMessage(&transport.Envelope{Message: []byte("msg")})
+		require.NoError(t, err)
+		require.Equal(t, []byte("msg"), packed)
+	})
+
+	t.Run("unpack a DIDComm v2 envelope without typ header", func(t *testing.T) {
+		protected := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ECDH-1PU+A256KW","enc":"A256CBC-HS512"}`))
+
+		env, err := packager.UnpackMessage([]byte(fmt.Sprintf(`{"protected":"%s"}`, protected)))
+		require.NoError(t, err)
+		require.Equal(t, "application/didcomm-encrypted+json", env.ContentType)
+		require.Equal(t, "did:example:alice", env.FromDID)
+		require.Equal(t, "did:example:bob", env.ToDID)
+	})
+
+	t.Run("unpack a signed envelope nested in a DIDComm v2 envelope", func(t *testing.T) {
+		protected := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"application/didcomm-signed+json"}`))
+		signed := fmt.Sprintf(`{"payload":"bXNn","signatures":[{"protected":"%s"}]}`, protected)
+
+		nestedPacker := &mockKeyIDPacker{
+			MockAuthCrypt: didcomm.MockAuthCrypt{
+				DecryptValue: func(envelope []byte) (*transport.Envelope, error) {
+					return &transport.Envelope{Message: []byte(signed), ToKID: "did:example:bob#key-2"}, nil
+				},
+				Type: "application/didcomm-encrypted+json",
+			},
+		}
+
+		nestedSignedPacker := &didcomm.MockAuthCrypt{
+			DecryptValue: func(envelope []byte) (*transport.Envelope, error) {
+				return &transport.Envelope{Message: []byte("signed msg"), FromKID: "did:example:alice#key-3"}, nil
+			},
+			Type: "application/didcomm-signed+json",
+		}
+
+		p, err := New(&mockProvider{
+			storage:       mockstorage.NewMockStoreProvider(),
+			primaryPacker: primaryPacker,
+			packers:       []packer.Packer{nestedPacker, nestedSignedPacker},
+		})
+		require.NoError(t, err)
+
+		v2Protected := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ECDH-ES+A256KW","enc":"A256GCM"}`))
+
+		env, err := p.UnpackMessage([]byte(fmt.Sprintf(`{"protected":"%s"}`, v2Protected)))
+		require.NoError(t, err)
+		require.Equal(t, []byte("signed msg"), env.Message)
+		require.Equal(t, "application/didcomm-encrypted+json", env.ContentType)
+		require.Equal(t, "application/didcomm-signed+json", env.InnerContentType)
+		require.Equal(t, "did:example:alice#key-3", env.FromKID)
+		require.Equal(t, "did:example:alice", env.FromDID)
+		require.Equal(t, "did:example:bob", env.ToDID)
+
+		nestedSignedPacker.DecryptValue = func(envelope []byte) (*transport.Envelope, error) {
+			return nil, errors.New("bad signature")
+		}
+
+		_, err = p.UnpackMessage([]byte(fmt.Sprintf(`{"protected":"%s"}`, v2Protected)))
+		require.EqualError(t, err, "inner envelope: unpack: bad signature")
+
+		// the signer of the nested envelope must be the sender of an authenticated envelope
+		for _, tc := range []struct {
+			outer, inner *transport.Envelope
+			errMsg       string
+		}{
+			{
+				outer: &transport.Envelope{FromKID: "did:example:alice#key-1"},
+				inner: &transport.Envelope{FromKID: "did:example:alice#key-3"},
+			},
+			{
+				outer:  &transport.Envelope{FromKID: "did:example:alice#key-1"},
+				inner:  &transport.Envelope{FromKID: "did:example:mallory#key-3"},
+				errMsg: "inner envelope: signer doesn't match the sender of the envelope",
+			},
+			{
+				outer:  &transport.Envelope{FromKID: "did:example:alice#key-1"},
+				inner:  &transport.Envelope{FromVerKey: []byte("alice")},
+				errMsg: "inner envelope: signer doesn't match the sender of the envelope",
+			},
+			{
+				outer: &transport.Envelope{FromVerKey: []byte("alice")},
+				inner: &transport.Envelope{FromVerKey: []byte("alice")},
+			},
+			{
+				outer:  &transport.Envelope{FromVerKey: []byte("alice")},
+				inner:  &transport.Envelope{FromVerKey: []byte("mallory")},
+				errMsg: "inner envelope: signer doesn't match the sender of the envelope",
+			},
+		} {
+			outer, inner := tc.outer, tc.inner
+			outer.Message = []byte(signed)
+			inner.Message = []byte("signed msg")
+
+			nestedPacker.DecryptValue = func(envelope []byte) (*transport.Envelope, error) {
+				return outer, nil
+			}
+			nestedSignedPacker.DecryptValue = func(envelope []byte) (*transport.Envelope, error) {
+				return inner, nil
+			}
+
+			_, err = p.UnpackMessage([]byte(fmt.Sprintf(`{"protected":"%s"}`, v2Protected)))
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.errMsg)
+			}
+		}
+	})
+
+	t.Run("unpack a JWS in its general JSON serialization", func(t *testing.T) {
+		protected := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"application/didcomm-signed+json"}`))
+
+		env, err := packager.UnpackMessage([]byte(fmt.Sprintf(`{"payload":"bXNn","signatures":[{"protected":"%s"}]}`,
+			protected)))
+		require.NoError(t, err)
+		require.Equal(t, []byte("signed msg"), env.Message)
+		require.Equal(t, "application/didcomm-signed+json", env.ContentType)
+	})
+}
+
+// mockKeyIDPacker is a mock packer.KeyIDPacker
+type mockKeyIDPacker struct {
+	didcomm.MockAuthCrypt
+	packValue func(payload []byte, senderKID string, recipientKIDs []string) ([]byte, error)
+}
+
+func (m *mockKeyIDPacker) PackWithKeyIDs(payload []byte, senderKID string, recipientKIDs []string) ([]byte, error) {
+	return m.packValue(payload, senderKID, recipientKIDs)
+}
+
 func newMockKMSProvider(storagePvdr *mockstorage.MockStoreProvider) *mockProvider {
 	return &mockProvider{storagePvdr, nil, nil, nil, nil}
 }
@@ -347,7 +636,7 @@ func newMockKMSProvider(storagePvdr *mockstorage.MockStoreProvider) *mockProvide
 // mockProvider mocks provider for LegacyKMS
 type mockProvider struct {
 	storage       *mockstorage.MockStoreProvider
-	kms           legacykms.KeyManager
+	kms           legacykms.KMS
 	packers       []packer.Packer
 	primaryPacker packer.Packer
 	vdriRegistry  vdriapi.Registry
@@ -361,6 +650,10 @@ func (m *mockProvider) LegacyKMS() legacykms.KeyManager {
 	return m.kms
 }
 
+func (m *mockProvider) Signer() legacykms.Signer {
+	return m.kms
+}
+
 func (m *mockProvider) StorageProvider() storage.Provider {
 	return m.storage
 }
@@ -7,16 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package packager
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/did"
 )
@@ -29,6 +33,9 @@ type Provider interface {
 	VDRIRegistry() vdri.Registry
 }
 
+// errSenderMismatch is returned when the signer of a nested envelope isn't the sender of the outer envelope
+var errSenderMismatch = errors.New("inner envelope: signer doesn't match the sender of the envelope")
+
 // Creator method to create new packager service
 type Creator func(prov Provider) (transport.Packager, error)
 
@@ -79,11 +86,58 @@ func (bp *Packager) addPacker(pack packer.Packer) {
 }
 
 // PackMessage Pack a message for one or more recipients.
+// The message is packed with the packer of its ContentType, or with the primary packer if empty. The recipients keys
+// are messageEnvelope.ToKIDs if set, their key IDs (DID URLs), otherwise messageEnvelope.ToVerKeys.
+// If the InnerContentType is set, the message is first packed with its packer, then the resulting envelope is packed
+// with the packer of the ContentType, e.g. to sign then encrypt the message.
 func (bp *Packager) PackMessage(messageEnvelope *transport.Envelope) ([]byte, error) {
 	if messageEnvelope == nil {
 		return nil, errors.New("envelope argument is nil")
 	}
 
+	if messageEnvelope.InnerContentType == "" {
+		return bp.pack(messageEnvelope, messageEnvelope.ContentType)
+	}
+
+	inner, err := bp.pack(messageEnvelope, messageEnvelope.InnerContentType)
+	if err != nil {
+		return nil, fmt.Errorf("inner envelope: %w", err)
+	}
+
+	outer := *messageEnvelope
+	outer.Message = inner
+
+	return bp.pack(&outer, messageEnvelope.ContentType)
+}
+
+// pack packs the message of messageEnvelope with the packer of contentType, the primary packer if empty
+func (bp *Packager) pack(messageEnvelope *transport.Envelope, contentType string) ([]byte, error) {
+	p := bp.primaryPacker
+
+	if contentType != "" {
+		var ok bool
+
+		p, ok = bp.packers[contentType]
+		if !ok {
+			return nil, fmt.Errorf("no packer for content type '%s'", contentType)
+		}
+	}
+
+	if len(messageEnvelope.ToKIDs) > 0 {
+		kidPacker, ok := p.(packer.KeyIDPacker)
+		if !ok {
+			return nil, fmt.Errorf("packer '%s' doesn't support key IDs", p.EncodingType())
+		}
+
+		bytes, err := kidPacker.PackWithKeyIDs(messageEnvelope.Message, messageEnvelope.FromKID,
+			messageEnvelope.ToKIDs)
+		if err != nil {
+			return nil, fmt.Errorf("pack: %w", err)
+		}
+
+		return bytes, nil
+	}
+
 	var recipients [][]byte
 
 	for _, verKey := range messageEnvelope.ToVerKeys {
@@ -98,7 +152,7 @@ func (bp *Packager) PackMessage(messageEnvelope *transport.Envelope) ([]byte, er
 		recipients = append(recipients, verKeyBytes)
 	}
 	// pack message
-	bytes, err := bp.primaryPacker.Pack(messageEnvelope.Message, messageEnvelope.FromVerKey, recipients)
+	bytes, err := p.Pack(messageEnvelope.Message, messageEnvelope.FromVerKey, recipients)
 	if err != nil {
 		return nil, fmt.Errorf("pack: %w", err)
 	}
@@ -106,12 +160,18 @@ func (bp *Packager) PackMessage(messageEnvelope *transport.Envelope) ([]byte, er
 	return bytes, nil
 }
 
+// didCommV2EncryptedType is the media type of DIDComm v2 encrypted envelopes, whose `typ` header is optional
+const didCommV2EncryptedType = "application/didcomm-encrypted+json"
+
 type envelopeStub struct {
 	Protected string `json:"protected,omitempty"`
+	// Signatures are the signatures of JWS envelopes in their general JSON serialization
+	Signatures []envelopeStub `json:"signatures,omitempty"`
 }
 
 type headerStub struct {
 	Type string `json:"typ,omitempty"`
+	Alg  string `json:"alg,omitempty"`
 }
 
 func getEncodingType(encMessage []byte) (string, error) {
@@ -122,10 +182,17 @@ func getEncodingType(encMessage []byte) (string, error) {
 		return "", fmt.Errorf("parse envelope: %w", err)
 	}
 
+	protected := env.Protected
+
+	// the headers of a JWS in its general JSON serialization are those of its signatures
+	if protected == "" && len(env.Signatures) > 0 {
+		protected = env.Signatures[0].Protected
+	}
+
 	var protBytes []byte
 
-	protBytes1, err1 := base64.URLEncoding.DecodeString(env.Protected)
-	protBytes2, err2 := base64.RawURLEncoding.DecodeString(env.Protected)
+	protBytes1, err1 := base64.URLEncoding.DecodeString(protected)
+	protBytes2, err2 := base64.RawURLEncoding.DecodeString(protected)
 
 	switch {
 	case err1 == nil:
@@ -143,11 +210,55 @@ func getEncodingType(encMessage []byte) (string, error) {
 		return "", fmt.Errorf("parse header: %w", err)
 	}
 
+	if prot.Type == "" && isDIDCommV2KeyAgreement(prot.Alg) {
+		return didCommV2EncryptedType, nil
+	}
+
 	return prot.Type, nil
 }
 
+// isDIDCommV2KeyAgreement tells if alg is a key agreement algorithm of DIDComm v2 encrypted envelopes
+func isDIDCommV2KeyAgreement(alg string) bool {
+	return alg == crypto.ECDHESA256KWAlg || alg == crypto.ECDH1PUA256KWAlg
+}
+
 // UnpackMessage Unpack a message.
+// An envelope nested in the envelope of the message, e.g. a signed envelope in an encrypted envelope, is unpacked too:
+// the sender of the message is then the one of the nested envelope, its signer, and the recipient the one of the
+// outer envelope. The signer must be the sender of the outer envelope if it's authenticated.
 func (bp *Packager) UnpackMessage(encMessage []byte) (*transport.Envelope, error) {
+	envelope, err := bp.unpack(encMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	innerType, err := getEncodingType(envelope.Message)
+	if _, ok := bp.packers[innerType]; err == nil && ok {
+		inner, e := bp.unpack(envelope.Message)
+		if e != nil {
+			return nil, fmt.Errorf("inner envelope: %w", e)
+		}
+
+		if !isSameSender(envelope, inner) {
+			return nil, errSenderMismatch
+		}
+
+		envelope.Message = inner.Message
+		envelope.InnerContentType = inner.ContentType
+		envelope.FromVerKey = inner.FromVerKey
+		envelope.FromKID = inner.FromKID
+	}
+
+	err = bp.resolveDIDs(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope, nil
+}
+
+// unpack unpacks encMessage with the packer of its encoding type
+func (bp *Packager) unpack(encMessage []byte) (*transport.Envelope, error) {
 	encType, err := getEncodingType(encMessage)
 	if err != nil {
 		return nil, fmt.Errorf("getEncodingType: %w", err)
@@ -163,22 +274,64 @@ func (bp *Packager) UnpackMessage(encMessage []byte) (*transport.Envelope, error
 		return nil, fmt.Errorf("unpack: %w", err)
 	}
 
-	//	ignore error - agents can communicate without using DIDs - for example, in DIDExchange
-	theirDID, err := bp.connectionStore.GetDID(base58.Encode(envelope.FromVerKey))
-	if errors.Is(err, did.ErrNotFound) {
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to get their did: %w", err)
+	envelope.ContentType = encType
+
+	return envelope, nil
+}
+
+// resolveDIDs sets the DIDs of the sender and the recipient of envelope
+func (bp *Packager) resolveDIDs(envelope *transport.Envelope) error {
+	// the keys of DIDComm v2 envelopes are identified by DID URLs, the DIDs are those of the key IDs
+	theirDID := didOfKID(envelope.FromKID)
+	myDID := didOfKID(envelope.ToKID)
+
+	var err error
+
+	if envelope.FromKID == "" && len(envelope.FromVerKey) > 0 {
+		//	ignore error - agents can communicate without using DIDs - for example, in DIDExchange
+		theirDID, err = bp.connectionStore.GetDID(base58.Encode(envelope.FromVerKey))
+		if errors.Is(err, did.ErrNotFound) {
+		} else if err != nil {
+			return fmt.Errorf("failed to get their did: %w", err)
+		}
 	}
 
-	// ignore error - at beginning of DIDExchange, you might be about to generate a DID
-	myDID, err := bp.connectionStore.GetDID(base58.Encode(envelope.ToVerKey))
-	if errors.Is(err, did.ErrNotFound) {
-	} else if err != nil {
-		return nil, fmt.Errorf("failed to get my did: %w", err)
+	if envelope.ToKID == "" && len(envelope.ToVerKey) > 0 {
+		// ignore error - at beginning of DIDExchange, you might be about to generate a DID
+		myDID, err = bp.connectionStore.GetDID(base58.Encode(envelope.ToVerKey))
+		if errors.Is(err, did.ErrNotFound) {
+		} else if err != nil {
+			return fmt.Errorf("failed to get my did: %w", err)
+		}
 	}
 
 	envelope.ToDID = myDID
 	envelope.FromDID = theirDID
 
-	return envelope, nil
+	return nil
+}
+
+// isSameSender tells if the signer of the inner envelope is the sender of the outer envelope, any signer being
+// accepted for anonymous outer envelopes. The senders of envelopes with key IDs are their DIDs, the outer sender key
+// of envelopes with verification keys may also be the X25519 encryption key of the signer's Ed25519 key.
+func isSameSender(outer, inner *transport.Envelope) bool {
+	switch {
+	case outer.FromKID != "":
+		return inner.FromKID != "" && didOfKID(outer.FromKID) == didOfKID(inner.FromKID)
+	case len(outer.FromVerKey) > 0:
+		if bytes.Equal(outer.FromVerKey, inner.FromVerKey) {
+			return true
+		}
+
+		encKey, err := cryptoutil.PublicEd25519toCurve25519(inner.FromVerKey)
+
+		return err == nil && bytes.Equal(outer.FromVerKey, encKey)
+	default:
+		return true
+	}
+}
+
+// didOfKID returns the DID of the DID URL kid
+func didOfKID(kid string) string {
+	return strings.SplitN(kid, "#", 2)[0] // nolint:gomnd
 }
@@ -41,3 +41,15 @@ type Packer interface {
 	// Encoding returns the type of the encoding, as found in the header `Typ` field
 	EncodingType() string
 }
+
+// KeyIDPacker is a Packer of envelopes whose keys are identified by key IDs, DID URLs such as did:example:123#key-1,
+// instead of raw public keys, like the DIDComm v2 envelopes
+type KeyIDPacker interface {
+	Packer
+	// PackWithKeyIDs packs payload for the recipients keys identified by recipientKIDs, authenticated by the sender
+	// key identified by senderKID if not empty
+	// returns:
+	// 		[]byte containing the encrypted envelope
+	//		error if encryption failed
+	PackWithKeyIDs(payload []byte, senderKID string, recipientKIDs []string) ([]byte, error)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcommv2
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/internal/kidutil"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+const (
+	ed25519KeyType = "Ed25519VerificationKey2018"
+	x25519KeyType  = "X25519KeyAgreementKey2019"
+)
+
+// pubKeyExporter is a kms.KeyManager exporting the public keys of its key pairs, like localkms
+type pubKeyExporter interface {
+	ExportPubKeyBytes(keyID string) ([]byte, error)
+}
+
+// jwk is the JSON Web Key of an ephemeral public key
+type jwk struct {
+	Kty string `json:"kty,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// publicKey resolves the public key identified by the DID URL kid, as a key agreement public key
+func (p *Packer) publicKey(kid string) (*crypto.PublicKey, error) {
+	didID, fragment, err := kidutil.SplitKID(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := p.vdriRegistry.Resolve(didID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve DID %s: %w", didID, err)
+	}
+
+	pk, err := kidutil.FindPublicKey(doc, fragment, doc.KeyAgreement)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	pub, err := agreementKey(pk)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	pub.KID = kid
+
+	return pub, nil
+}
+
+// recipientKey returns the KMS key handle of the recipient key identified by the DID URL kid. Only the keys of the
+// agent's DIDs are accepted: the key of the DID document identified by kid must be the KMS key with the ID of its
+// fragment, so that the sender of an envelope can't select any KMS key with the fragment.
+func (p *Packer) recipientKey(kid string) (interface{}, error) {
+	exporter, ok := p.kms.(pubKeyExporter)
+	if !ok {
+		return nil, errPubKeyNotSupported
+	}
+
+	pub, err := p.publicKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	_, keyID, err := kidutil.SplitKID(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	kmsPub, err := exporter.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	if !isPublicKey(pub, kmsPub) {
+		return nil, fmt.Errorf("key %s isn't a key of the agent", kid)
+	}
+
+	return p.kms.Get(keyID)
+}
+
+// isPublicKey tells if the key agreement public key pub is the public key kmsPub exported by the KMS, an ED25519
+// public key or an uncompressed elliptic curve point
+func isPublicKey(pub *crypto.PublicKey, kmsPub []byte) bool {
+	switch pub.Type {
+	case crypto.OKPKeyType:
+		x, err := cryptoutil.PublicEd25519toCurve25519(kmsPub)
+
+		return err == nil && bytes.Equal(x, pub.X)
+	case crypto.ECKeyType:
+		curve := curveByName(pub.Curve)
+		if curve == nil {
+			return false
+		}
+
+		x, y := elliptic.Unmarshal(curve, kmsPub)
+
+		return x != nil && x.Cmp(new(big.Int).SetBytes(pub.X)) == 0 && y.Cmp(new(big.Int).SetBytes(pub.Y)) == 0
+	default:
+		return false
+	}
+}
+
+func curveByName(name string) elliptic.Curve {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		if curve.Params().Name == name {
+			return curve
+		}
+	}
+
+	return nil
+}
+
+// agreementKey converts the DID document public key pk to a key agreement public key, ED25519 keys are converted
+// to X25519 keys
+func agreementKey(pk *did.PublicKey) (*crypto.PublicKey, error) {
+	if jsonWebKey := pk.JSONWebKey(); jsonWebKey != nil {
+		switch key := jsonWebKey.Key.(type) {
+		case *ecdsa.PublicKey:
+			return &crypto.PublicKey{
+				X:     key.X.Bytes(),
+				Y:     key.Y.Bytes(),
+				Curve: key.Curve.Params().Name,
+				Type:  crypto.ECKeyType,
+			}, nil
+		case ed25519.PublicKey:
+			return x25519Key(key)
+		default:
+			return nil, fmt.Errorf("unsupported JWK key type %T", jsonWebKey.Key)
+		}
+	}
+
+	switch pk.Type {
+	case ed25519KeyType:
+		return x25519Key(pk.Value)
+	case x25519KeyType:
+		return &crypto.PublicKey{X: pk.Value, Curve: crypto.X25519, Type: crypto.OKPKeyType}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %s", pk.Type)
+	}
+}
+
+func x25519Key(edPub []byte) (*crypto.PublicKey, error) {
+	pub, err := cryptoutil.PublicEd25519toCurve25519(edPub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypto.PublicKey{X: pub, Curve: crypto.X25519, Type: crypto.OKPKeyType}, nil
+}
+
+// toJWK converts the ephemeral public key epk to its JWK
+func toJWK(epk *crypto.PublicKey) *jwk {
+	key := &jwk{
+		Kty: epk.Type,
+		Crv: epk.Curve,
+		X:   base64.RawURLEncoding.EncodeToString(epk.X),
+	}
+
+	if len(epk.Y) > 0 {
+		key.Y = base64.RawURLEncoding.EncodeToString(epk.Y)
+	}
+
+	return key
+}
+
+// fromJWK converts the JWK of an ephemeral public key to a key agreement public key
+func fromJWK(key *jwk) (*crypto.PublicKey, error) {
+	if key.Kty != crypto.ECKeyType && key.Kty != crypto.OKPKeyType {
+		return nil, fmt.Errorf("unsupported key type '%s'", key.Kty)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(key.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypto.PublicKey{X: x, Y: y, Curve: key.Crv, Type: key.Kty}, nil
+}
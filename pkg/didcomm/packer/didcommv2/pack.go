@@ -0,0 +1,220 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcommv2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	josecipher "github.com/square/go-jose/v3/cipher"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/internal/kidutil"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+const (
+	// cbcHMACKeySize is the size of the A256CBC-HS512 keys, an HMAC-SHA512 key followed by an AES-256 key
+	cbcHMACKeySize = 64
+	// gcmKeySize is the size of the A256GCM keys
+	gcmKeySize = 32
+)
+
+// Pack isn't supported by DIDComm v2 envelopes whose keys are identified by key IDs, use PackWithKeyIDs instead
+func (p *Packer) Pack(_, _ []byte, _ [][]byte) ([]byte, error) {
+	return nil, errRawKeysNotSupported
+}
+
+// PackWithKeyIDs will pack payload in a DIDComm v2 encrypted envelope for the recipients keys identified by the DID
+// URLs recipientKIDs. The envelope is authenticated with ECDH-1PU by the sender key identified by the DID URL
+// senderKID, if not empty, otherwise it's anonymous (ECDH-ES).
+func (p *Packer) PackWithKeyIDs(payload []byte, senderKID string, recipientKIDs []string) ([]byte, error) {
+	if len(recipientKIDs) == 0 {
+		return nil, errors.New("failed to pack message: empty recipients")
+	}
+
+	var opts []crypto.WrapKeyOpts
+
+	headers := &jweHeaders{
+		Typ: EncryptedMediaType,
+		Alg: crypto.ECDHESA256KWAlg,
+		Enc: A256GCM,
+		APV: base64.RawURLEncoding.EncodeToString(hashKIDs(recipientKIDs)),
+	}
+
+	if senderKID != "" {
+		senderKH, err := kidutil.PrivateKey(p.kms, senderKID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack message: sender key: %w", err)
+		}
+
+		opts = append(opts, crypto.WithSender(senderKH))
+
+		headers.Alg = crypto.ECDH1PUA256KWAlg
+		headers.Enc = A256CBCHS512
+		headers.SKID = senderKID
+		headers.APU = base64.RawURLEncoding.EncodeToString([]byte(senderKID))
+	}
+
+	protected, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	encHeaders := base64.RawURLEncoding.EncodeToString(protected)
+
+	cek, err := newCEK(headers.Enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: %w", err)
+	}
+
+	recipients, err := p.wrapCEK(cek, headers, recipientKIDs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: %w", err)
+	}
+
+	// the protected headers are the AAD of the payload encryption
+	cipherText, tag, iv, err := encrypt(headers.Enc, cek, payload, []byte(encHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: %w", err)
+	}
+
+	return json.Marshal(&Envelope{
+		Protected:  encHeaders,
+		Recipients: recipients,
+		IV:         base64.RawURLEncoding.EncodeToString(iv),
+		CipherText: base64.RawURLEncoding.EncodeToString(cipherText),
+		Tag:        base64.RawURLEncoding.EncodeToString(tag),
+	})
+}
+
+// wrapCEK wraps cek for each recipient key identified by recipientKIDs
+func (p *Packer) wrapCEK(cek []byte, headers *jweHeaders, recipientKIDs []string,
+	opts ...crypto.WrapKeyOpts) ([]jose.Recipient, error) {
+	apu, err := base64.RawURLEncoding.DecodeString(headers.APU)
+	if err != nil {
+		return nil, err
+	}
+
+	apv, err := base64.RawURLEncoding.DecodeString(headers.APV)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []jose.Recipient
+
+	for _, kid := range recipientKIDs {
+		recPubKey, e := p.publicKey(kid)
+		if e != nil {
+			return nil, fmt.Errorf("recipient key: %w", e)
+		}
+
+		wrapped, e := p.crypto.WrapKey(cek, apu, apv, recPubKey, opts...)
+		if e != nil {
+			return nil, e
+		}
+
+		epk, e := json.Marshal(toJWK(&wrapped.EPK))
+		if e != nil {
+			return nil, e
+		}
+
+		recipients = append(recipients, jose.Recipient{
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(wrapped.EncryptedCEK),
+			Header: jose.RecipientHeaders{
+				KID: kid,
+				EPK: epk,
+			},
+		})
+	}
+
+	return recipients, nil
+}
+
+// hashKIDs returns the SHA-256 hash of the sorted key IDs concatenated by '.', the apv of the envelopes
+func hashKIDs(kids []string) []byte {
+	sorted := append([]string(nil), kids...)
+	sort.Strings(sorted)
+
+	h := sha256.Sum256([]byte(strings.Join(sorted, ".")))
+
+	return h[:]
+}
+
+// newCEK generates a content encryption key for the content encryption enc
+func newCEK(enc string) ([]byte, error) {
+	size := gcmKeySize
+	if enc == A256CBCHS512 {
+		size = cbcHMACKeySize
+	}
+
+	cek := make([]byte, size)
+
+	_, err := rand.Read(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	return cek, nil
+}
+
+// createCipher creates the AEAD cipher of the content encryption enc
+func createCipher(enc string, cek []byte) (cipher.AEAD, error) {
+	switch enc {
+	case A256GCM:
+		block, err := aes.NewCipher(cek)
+		if err != nil {
+			return nil, err
+		}
+
+		return cipher.NewGCM(block)
+	case A256CBCHS512:
+		return josecipher.NewCBCHMAC(cek, aes.NewCipher)
+	default:
+		return nil, fmt.Errorf("unsupported content encryption %s", enc)
+	}
+}
+
+// encrypt will encrypt msg with the cek, aad and a newly generated iv
+// returns:
+//
+//	cipherText of msg
+//	tag of the encryption
+//	iv used by the encryption
+//	error in case of failure
+func encrypt(enc string, cek, msg, aad []byte) ([]byte, []byte, []byte, error) {
+	aead, err := createCipher(enc, cek)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(iv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the output is a []byte containing the cipherText + tag
+	symOutput := aead.Seal(nil, iv, msg, aad)
+	tagStart := len(symOutput) - aead.Overhead()
+
+	// the overhead of A256CBC-HS512 is the size of its padding and its tag
+	if enc == A256CBCHS512 {
+		tagStart = len(symOutput) - cbcHMACKeySize/2
+	}
+
+	return symOutput[:tagStart], symOutput[tagStart:], iv, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcommv2
+
+import (
+	"errors"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// This package deals with the encrypted envelopes of DIDComm Messaging v2, JWEs of media type
+// application/didcomm-encrypted+json. The keys of the envelopes are identified by DID URLs
+// (e.g. did:example:123#key-1): the public keys are resolved from the DID documents of the parties and the private
+// keys are found in the KMS, the fragment of the DID URL of a key being its KMS key ID.
+// Envelopes authenticated by their sender use ECDH-1PU+A256KW and A256CBC-HS512, anonymous envelopes use
+// ECDH-ES+A256KW and A256GCM.
+
+const (
+	// EncryptedMediaType is the media type of DIDComm v2 encrypted envelopes, the `typ` of the envelopes
+	EncryptedMediaType = "application/didcomm-encrypted+json"
+
+	// A256CBCHS512 is the content encryption of authenticated envelopes
+	A256CBCHS512 = "A256CBC-HS512"
+	// A256GCM is the content encryption of anonymous envelopes
+	A256GCM = "A256GCM"
+)
+
+var (
+	errProviderNotSupported = errors.New("packer provider doesn't support KMS, Crypto and VDRI registry")
+	errRawKeysNotSupported  = errors.New("didcomm v2 envelopes need key IDs instead of raw keys")
+	errPubKeyNotSupported   = errors.New("kms doesn't support public key export")
+)
+
+// Provider contains the dependencies of the DIDComm v2 Packer, a packer.Provider typically created by aries.Context()
+type Provider interface {
+	KMS() kms.KeyManager
+	Crypto() crypto.Crypto
+	VDRIRegistry() vdri.Registry
+}
+
+// Packer represents a DIDComm v2 Packer/Unpacker of encrypted envelopes
+type Packer struct {
+	kms          kms.KeyManager
+	crypto       crypto.Crypto
+	vdriRegistry vdri.Registry
+}
+
+// Envelope represents a DIDComm v2 encrypted envelope, a JWE in its general JSON serialization
+type Envelope struct {
+	Protected  string           `json:"protected,omitempty"`
+	Recipients []jose.Recipient `json:"recipients,omitempty"`
+	IV         string           `json:"iv,omitempty"`
+	CipherText string           `json:"ciphertext,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+}
+
+// jweHeaders are the protected headers of the envelope
+type jweHeaders struct {
+	Typ  string `json:"typ,omitempty"`
+	Alg  string `json:"alg,omitempty"`
+	Enc  string `json:"enc,omitempty"`
+	SKID string `json:"skid,omitempty"`
+	APU  string `json:"apu,omitempty"`
+	APV  string `json:"apv,omitempty"`
+}
+
+// New will create a Packer of DIDComm v2 encrypted envelopes. ctx must also be a Provider, giving the KMS, the Crypto
+// and the VDRI registry to find the keys of the envelopes.
+func New(ctx packer.Provider) (*Packer, error) {
+	p, ok := ctx.(Provider)
+	if !ok {
+		return nil, errProviderNotSupported
+	}
+
+	return &Packer{
+		kms:          p.KMS(),
+		crypto:       p.Crypto(),
+		vdriRegistry: p.VDRIRegistry(),
+	}, nil
+}
+
+// EncodingType returns the type of the encoding, as in the `Typ` field of the envelope header
+func (p *Packer) EncodingType() string {
+	return EncryptedMediaType
+}
@@ -0,0 +1,438 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcommv2
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	josejwk "github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+// agent holds the packer of a party and its DID document
+type agent struct {
+	packer *Packer
+	kms    *localkms.LocalKMS
+	doc    *did.Doc
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&mockprovider.Provider{})
+	require.Equal(t, errProviderNotSupported, err)
+
+	a := newAgent(t, "did:example:alice", newDIDs())
+	require.Equal(t, EncryptedMediaType, a.packer.EncodingType())
+
+	_, err = a.packer.Pack([]byte("payload"), nil, [][]byte{[]byte("key")})
+	require.Equal(t, errRawKeysNotSupported, err)
+}
+
+func TestPackUnpack(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+	carol := newAgent(t, "did:example:carol", dids)
+
+	for _, keyType := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type} {
+		keyType := keyType
+
+		t.Run(fmt.Sprintf("%s authcrypt", keyType), func(t *testing.T) {
+			senderKID := alice.addKey(t, keyType)
+			bobKID := bob.addKey(t, keyType)
+			carolKID := carol.addKey(t, keyType)
+
+			payload := []byte(`{"id":"1234","type":"https://didcomm.org/basicmessage/2.0/message"}`)
+
+			packed, err := alice.packer.PackWithKeyIDs(payload, senderKID, []string{bobKID, carolKID})
+			require.NoError(t, err)
+
+			headers := envelopeHeaders(t, packed)
+			require.Equal(t, EncryptedMediaType, headers.Typ)
+			require.Equal(t, crypto.ECDH1PUA256KWAlg, headers.Alg)
+			require.Equal(t, A256CBCHS512, headers.Enc)
+			require.Equal(t, senderKID, headers.SKID)
+
+			for _, recipient := range []struct {
+				agent *agent
+				kid   string
+			}{{bob, bobKID}, {carol, carolKID}} {
+				env, e := recipient.agent.packer.Unpack(packed)
+				require.NoError(t, e)
+				require.Equal(t, payload, env.Message)
+				require.Equal(t, senderKID, env.FromKID)
+				require.Equal(t, recipient.kid, env.ToKID)
+			}
+
+			_, err = alice.packer.Unpack(packed)
+			require.EqualError(t, err, "unpack: no recipient key found")
+		})
+
+		t.Run(fmt.Sprintf("%s anoncrypt", keyType), func(t *testing.T) {
+			bobKID := bob.addKey(t, keyType)
+
+			packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), "", []string{bobKID})
+			require.NoError(t, err)
+
+			headers := envelopeHeaders(t, packed)
+			require.Equal(t, crypto.ECDHESA256KWAlg, headers.Alg)
+			require.Equal(t, A256GCM, headers.Enc)
+			require.Empty(t, headers.SKID)
+
+			env, err := bob.packer.Unpack(packed)
+			require.NoError(t, err)
+			require.Equal(t, []byte("payload"), env.Message)
+			require.Empty(t, env.FromKID)
+			require.Equal(t, bobKID, env.ToKID)
+		})
+	}
+
+	t.Run("sender and recipient keys on different curves", func(t *testing.T) {
+		senderKID := alice.addKey(t, kms.ED25519Type)
+		bobKID := bob.addKey(t, kms.ECDSAP256Type)
+
+		_, err := alice.packer.PackWithKeyIDs([]byte("payload"), senderKID, []string{bobKID})
+		require.Error(t, err)
+	})
+
+	t.Run("tampered envelope", func(t *testing.T) {
+		senderKID := alice.addKey(t, kms.ED25519Type)
+		bobKID := bob.addKey(t, kms.ED25519Type)
+
+		packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), senderKID, []string{bobKID})
+		require.NoError(t, err)
+
+		jwe := &Envelope{}
+		require.NoError(t, json.Unmarshal(packed, jwe))
+
+		// the sender can't be changed, the protected headers authenticate the payload
+		otherSenderKID := alice.addKey(t, kms.ED25519Type)
+
+		headers := envelopeHeaders(t, packed)
+		headers.SKID = otherSenderKID
+
+		protected, err := json.Marshal(headers)
+		require.NoError(t, err)
+
+		jwe.Protected = base64.RawURLEncoding.EncodeToString(protected)
+
+		tampered, err := json.Marshal(jwe)
+		require.NoError(t, err)
+
+		_, err = bob.packer.Unpack(tampered)
+		require.Error(t, err)
+	})
+}
+
+func TestPack_Errors(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+
+	bobKID := bob.addKey(t, kms.ED25519Type)
+
+	_, err := alice.packer.PackWithKeyIDs([]byte("payload"), "", nil)
+	require.EqualError(t, err, "failed to pack message: empty recipients")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice", []string{bobKID})
+	require.EqualError(t, err, "failed to pack message: sender key: key ID 'did:example:alice' is not a DID URL "+
+		"with a fragment")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice#unknown", []string{bobKID})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to pack message: sender key")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "", []string{"did:example:bob#unknown"})
+	require.EqualError(t, err, "failed to pack message: recipient key: key did:example:bob#unknown: "+
+		"public key not found in DID document")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "", []string{"did:example:dave#key"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "resolve DID did:example:dave")
+
+	bob.doc.PublicKey = append(bob.doc.PublicKey, did.PublicKey{ID: "#rsa", Type: "RsaVerificationKey2018"})
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "", []string{"did:example:bob#rsa"})
+	require.EqualError(t, err, "failed to pack message: recipient key: key did:example:bob#rsa: "+
+		"unsupported public key type RsaVerificationKey2018")
+}
+
+func TestUnpack_Errors(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+
+	senderKID := alice.addKey(t, kms.ED25519Type)
+	bobKID := bob.addKey(t, kms.ED25519Type)
+
+	packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), senderKID, []string{bobKID})
+	require.NoError(t, err)
+
+	_, err = bob.packer.Unpack([]byte("{"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unpack json")
+
+	tests := []struct {
+		name   string
+		mutate func(jwe *Envelope, headers *jweHeaders)
+		errMsg string
+	}{
+		{
+			name:   "unsupported key agreement",
+			mutate: func(_ *Envelope, headers *jweHeaders) { headers.Alg = "ECDH-ES" },
+			errMsg: "unpack: unsupported key agreement ECDH-ES",
+		},
+		{
+			name:   "missing skid",
+			mutate: func(_ *Envelope, headers *jweHeaders) { headers.SKID = "" },
+			errMsg: "unpack: missing skid header of ECDH-1PU envelope",
+		},
+		{
+			name:   "unknown sender",
+			mutate: func(_ *Envelope, headers *jweHeaders) { headers.SKID = "did:example:alice#unknown" },
+			errMsg: "unpack: unwrap key: sender key: key did:example:alice#unknown: public key not found",
+		},
+		{
+			name:   "unsupported content encryption",
+			mutate: func(_ *Envelope, headers *jweHeaders) { headers.Enc = "XC20P" },
+			errMsg: "unpack: unsupported content encryption XC20P",
+		},
+		{
+			name:   "content encryption of another key agreement",
+			mutate: func(_ *Envelope, headers *jweHeaders) { headers.Enc = A256GCM },
+			errMsg: "unpack: unsupported content encryption A256GCM for key agreement ECDH-1PU+A256KW",
+		},
+		{
+			name:   "bad ephemeral key",
+			mutate: func(jwe *Envelope, _ *jweHeaders) { jwe.Recipients[0].Header.EPK = json.RawMessage(`{"kty":"RSA"}`) },
+			errMsg: "unpack: unwrap key: ephemeral key: unsupported key type 'RSA'",
+		},
+		{
+			name:   "bad iv",
+			mutate: func(jwe *Envelope, _ *jweHeaders) { jwe.IV = base64.RawURLEncoding.EncodeToString([]byte("iv")) },
+			errMsg: "unpack: bad iv size",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			jwe := &Envelope{}
+			require.NoError(t, json.Unmarshal(packed, jwe))
+
+			headers := envelopeHeaders(t, packed)
+
+			tc.mutate(jwe, headers)
+
+			protected, e := json.Marshal(headers)
+			require.NoError(t, e)
+
+			jwe.Protected = base64.RawURLEncoding.EncodeToString(protected)
+
+			mutated, e := json.Marshal(jwe)
+			require.NoError(t, e)
+
+			_, e = bob.packer.Unpack(mutated)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestUnpack_RecipientKey(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+	mallory := newAgent(t, "did:example:mallory", dids)
+
+	bobKID := bob.addKey(t, kms.ED25519Type)
+
+	kh, err := bob.packer.recipientKey(bobKID)
+	require.NoError(t, err)
+	require.NotNil(t, kh)
+
+	// the DID of mallory lists a key of its own under the fragment of the key of bob, bob must not select its key
+	keyID := bobKID[len(bob.doc.ID)+1:]
+	malloryKID := mallory.doc.ID + "#" + keyID
+
+	malloryKeyID, _, err := mallory.kms.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	pub, err := mallory.kms.ExportPubKeyBytes(malloryKeyID)
+	require.NoError(t, err)
+
+	mallory.doc.PublicKey = append(mallory.doc.PublicKey,
+		*did.NewPublicKeyFromBytes("#"+keyID, ed25519KeyType, mallory.doc.ID, pub))
+
+	_, err = bob.packer.recipientKey(malloryKID)
+	require.EqualError(t, err, "key "+malloryKID+" isn't a key of the agent")
+
+	packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), "", []string{malloryKID})
+	require.NoError(t, err)
+
+	_, err = bob.packer.Unpack(packed)
+	require.EqualError(t, err, "unpack: no recipient key found")
+
+	_, err = bob.packer.recipientKey("did:example:dave#" + keyID)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "resolve DID did:example:dave")
+
+	ctx, err := context.New(context.WithKMS(&mockkms.KeyManager{}), context.WithCrypto(&tinkcrypto.Crypto{}),
+		context.WithVDRIRegistry(&mockvdri.MockVDRIRegistry{}))
+	require.NoError(t, err)
+
+	p, err := New(ctx)
+	require.NoError(t, err)
+
+	_, err = p.recipientKey(bobKID)
+	require.Equal(t, errPubKeyNotSupported, err)
+}
+
+func TestIsPublicKey(t *testing.T) {
+	x, y := elliptic.P256().ScalarBaseMult(big.NewInt(42).Bytes())
+	kmsPub := elliptic.Marshal(elliptic.P256(), x, y)
+
+	pub := &crypto.PublicKey{X: x.Bytes(), Y: y.Bytes(), Curve: crypto.P256, Type: crypto.ECKeyType}
+	require.True(t, isPublicKey(pub, kmsPub))
+
+	otherX, otherY := elliptic.P256().ScalarBaseMult(big.NewInt(43).Bytes())
+	require.False(t, isPublicKey(pub, elliptic.Marshal(elliptic.P256(), otherX, otherY)))
+	require.False(t, isPublicKey(pub, []byte("key")))
+	require.False(t, isPublicKey(&crypto.PublicKey{X: pub.X, Y: pub.Y, Curve: "secp256k1", Type: crypto.ECKeyType},
+		kmsPub))
+	require.False(t, isPublicKey(&crypto.PublicKey{X: pub.X, Type: crypto.OKPKeyType}, kmsPub))
+	require.False(t, isPublicKey(&crypto.PublicKey{Type: "RSA"}, kmsPub))
+}
+
+func TestAgreementKey_JWK(t *testing.T) {
+	x, y := elliptic.P256().ScalarBaseMult(big.NewInt(42).Bytes())
+
+	pk, err := did.NewPublicKeyFromJWK("#key-1", "JsonWebKey2020", "did:example:alice", &josejwk.JWK{
+		JSONWebKey: jose.JSONWebKey{Key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}},
+		Kty:        "EC",
+		Crv:        "P-256",
+	})
+	require.NoError(t, err)
+
+	pub, err := agreementKey(pk)
+	require.NoError(t, err)
+	require.Equal(t, crypto.P256, pub.Curve)
+	require.Equal(t, x.Bytes(), pub.X)
+	require.Equal(t, y.Bytes(), pub.Y)
+}
+
+func newDIDs() map[string]*did.Doc {
+	return map[string]*did.Doc{}
+}
+
+// newAgent creates the packer of a party with its own KMS, resolving the DIDs of dids
+func newAgent(t *testing.T, didID string, dids map[string]*did.Doc) *agent {
+	t.Helper()
+
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	tinkCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	registry := &mockvdri.MockVDRIRegistry{
+		ResolveFunc: func(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			doc, ok := dids[didID]
+			if !ok {
+				return nil, vdriapi.ErrNotFound
+			}
+
+			return doc, nil
+		},
+	}
+
+	ctx, err := context.New(context.WithKMS(localKMS), context.WithCrypto(tinkCrypto),
+		context.WithVDRIRegistry(registry))
+	require.NoError(t, err)
+
+	p, err := New(ctx)
+	require.NoError(t, err)
+
+	doc := &did.Doc{ID: didID}
+	dids[didID] = doc
+
+	return &agent{packer: p, kms: localKMS, doc: doc}
+}
+
+// addKey creates a key of the agent and adds it to its DID document, returning its key ID
+func (a *agent) addKey(t *testing.T, keyType kms.KeyType) string {
+	t.Helper()
+
+	keyID, kh, err := a.kms.Create(keyType)
+	require.NoError(t, err)
+
+	switch keyType {
+	case kms.ED25519Type:
+		pub, e := a.kms.ExportPubKeyBytes(keyID)
+		require.NoError(t, e)
+
+		a.doc.PublicKey = append(a.doc.PublicKey,
+			*did.NewPublicKeyFromBytes("#"+keyID, ed25519KeyType, a.doc.ID, pub))
+	default:
+		pub, e := tinkcrypto.ExportPublicKey(kh)
+		require.NoError(t, e)
+
+		pk, e := did.NewPublicKeyFromJWK(a.doc.ID+"#"+keyID, "JsonWebKey2020", a.doc.ID, &josejwk.JWK{
+			JSONWebKey: jose.JSONWebKey{Key: &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub.X),
+				Y:     new(big.Int).SetBytes(pub.Y),
+			}},
+			Kty: "EC",
+			Crv: "P-256",
+		})
+		require.NoError(t, e)
+
+		a.doc.KeyAgreement = append(a.doc.KeyAgreement, did.VerificationMethod{PublicKey: *pk})
+	}
+
+	return a.doc.ID + "#" + keyID
+}
+
+func envelopeHeaders(t *testing.T, packed []byte) *jweHeaders {
+	t.Helper()
+
+	jwe := &Envelope{}
+	require.NoError(t, json.Unmarshal(packed, jwe))
+
+	protected, err := base64.RawURLEncoding.DecodeString(jwe.Protected)
+	require.NoError(t, err)
+
+	headers := &jweHeaders{}
+	require.NoError(t, json.Unmarshal(protected, headers))
+
+	return headers
+}
@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didcommv2
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// Unpack will decrypt the DIDComm v2 encrypted envelope for the first of its recipients keys that is a key of the
+// agent, found in the KMS and in the DID document of its DID URL. The sender key of authenticated envelopes is
+// resolved from its DID URL, the `skid` header.
+// The returned envelope holds the key IDs of the sender, if any, and of the recipient.
+func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
+	jwe := &Envelope{}
+
+	err := json.Unmarshal(envelope, jwe)
+	if err != nil {
+		return nil, fmt.Errorf("unpack json: %w", err)
+	}
+
+	headers, err := decodeHeaders(jwe.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	recipient, recipientKH, err := p.findRecipient(jwe.Recipients)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	cek, err := p.unwrapCEK(headers, recipient, recipientKH)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: unwrap key: %w", err)
+	}
+
+	payload, err := decrypt(headers.Enc, cek, jwe)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	return &transport.Envelope{
+		Message: payload,
+		FromKID: headers.SKID,
+		ToKID:   recipient.Header.KID,
+	}, nil
+}
+
+func decodeHeaders(protected string) (*jweHeaders, error) {
+	h, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := &jweHeaders{}
+
+	err = json.Unmarshal(h, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// the content encryption is the one of the key agreement, so that an authenticated envelope can't be downgraded
+	enc := A256GCM
+
+	switch headers.Alg {
+	case crypto.ECDHESA256KWAlg:
+	case crypto.ECDH1PUA256KWAlg:
+		if headers.SKID == "" {
+			return nil, errors.New("missing skid header of ECDH-1PU envelope")
+		}
+
+		enc = A256CBCHS512
+	default:
+		return nil, fmt.Errorf("unsupported key agreement %s", headers.Alg)
+	}
+
+	if headers.Enc != enc {
+		return nil, fmt.Errorf("unsupported content encryption %s for key agreement %s", headers.Enc, headers.Alg)
+	}
+
+	return headers, nil
+}
+
+// findRecipient returns the first recipient of jweRecipients whose key is a key of the agent found in the KMS, with
+// its key handle
+func (p *Packer) findRecipient(jweRecipients []jose.Recipient) (*jose.Recipient, interface{}, error) {
+	for i := range jweRecipients {
+		kh, err := p.recipientKey(jweRecipients[i].Header.KID)
+		if err == nil {
+			return &jweRecipients[i], kh, nil
+		}
+	}
+
+	return nil, nil, errors.New("no recipient key found")
+}
+
+// unwrapCEK unwraps the cek of recipient with the recipient key handle, and the sender's public key for ECDH-1PU
+func (p *Packer) unwrapCEK(headers *jweHeaders, recipient *jose.Recipient, recipientKH interface{}) ([]byte, error) {
+	epkJWK := &jwk{}
+
+	err := json.Unmarshal(recipient.Header.EPK, epkJWK)
+	if err != nil {
+		return nil, fmt.Errorf("ephemeral key: %w", err)
+	}
+
+	epk, err := fromJWK(epkJWK)
+	if err != nil {
+		return nil, fmt.Errorf("ephemeral key: %w", err)
+	}
+
+	encryptedCEK, err := base64.RawURLEncoding.DecodeString(recipient.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	apu, err := base64.RawURLEncoding.DecodeString(headers.APU)
+	if err != nil {
+		return nil, err
+	}
+
+	apv, err := base64.RawURLEncoding.DecodeString(headers.APV)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []crypto.WrapKeyOpts
+
+	if headers.Alg == crypto.ECDH1PUA256KWAlg {
+		senderPubKey, e := p.publicKey(headers.SKID)
+		if e != nil {
+			return nil, fmt.Errorf("sender key: %w", e)
+		}
+
+		opts = append(opts, crypto.WithSender(senderPubKey))
+	}
+
+	return p.crypto.UnwrapKey(&crypto.RecipientWrappedKey{
+		KID:          recipient.Header.KID,
+		EncryptedCEK: encryptedCEK,
+		EPK:          *epk,
+		Alg:          headers.Alg,
+		APU:          apu,
+		APV:          apv,
+	}, recipientKH, opts...)
+}
+
+// decrypt will decrypt the payload of jwe with cek, the protected headers being the AAD
+func decrypt(enc string, cek []byte, jwe *Envelope) ([]byte, error) {
+	aead, err := createCipher(enc, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := base64.RawURLEncoding.DecodeString(jwe.CipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := base64.RawURLEncoding.DecodeString(jwe.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(jwe.IV)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != aead.NonceSize() {
+		return nil, errors.New("bad iv size")
+	}
+
+	return aead.Open(nil, iv, append(cipherText, tag...), []byte(jwe.Protected))
+}
@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kidutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// SplitKID splits the DID URL kid in its DID and its fragment, the KMS key ID of the key.
+// This function is to be used by the packers only
+func SplitKID(kid string) (string, string, error) {
+	i := strings.Index(kid, "#")
+	if i <= 0 || i == len(kid)-1 {
+		return "", "", fmt.Errorf("key ID '%s' is not a DID URL with a fragment", kid)
+	}
+
+	return kid[:i], kid[i+1:], nil
+}
+
+// PrivateKey returns the key handle in km of the key identified by the DID URL kid
+func PrivateKey(km kms.KeyManager, kid string) (interface{}, error) {
+	_, keyID, err := SplitKID(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return km.Get(keyID)
+}
+
+// FindPublicKey finds the public key of the DID document whose ID, absolute or relative, has the given fragment,
+// among the public keys of the document and those of the verification methods
+func FindPublicKey(doc *did.Doc, fragment string, methods ...[]did.VerificationMethod) (*did.PublicKey, error) {
+	keys := doc.PublicKey
+
+	for _, vms := range methods {
+		for _, vm := range vms {
+			keys = append(keys, vm.PublicKey)
+		}
+	}
+
+	for i := range keys {
+		if keys[i].ID == "#"+fragment || keys[i].ID == doc.ID+"#"+fragment {
+			return &keys[i], nil
+		}
+	}
+
+	return nil, errors.New("public key not found in DID document")
+}
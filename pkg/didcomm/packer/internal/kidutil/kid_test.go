@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kidutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+)
+
+func TestSplitKID(t *testing.T) {
+	didID, fragment, err := SplitKID("did:example:alice#key-1")
+	require.NoError(t, err)
+	require.Equal(t, "did:example:alice", didID)
+	require.Equal(t, "key-1", fragment)
+
+	for _, kid := range []string{"did:example:alice", "#key-1", "did:example:alice#"} {
+		_, _, err = SplitKID(kid)
+		require.EqualError(t, err, "key ID '"+kid+"' is not a DID URL with a fragment")
+	}
+}
+
+func TestPrivateKey(t *testing.T) {
+	kh, err := PrivateKey(&mockkms.KeyManager{}, "did:example:alice#key-1")
+	require.NoError(t, err)
+	require.Nil(t, kh)
+
+	_, err = PrivateKey(&mockkms.KeyManager{}, "key-1")
+	require.Error(t, err)
+
+	_, err = PrivateKey(&mockkms.KeyManager{GetKeyErr: errors.New("get error")}, "did:example:alice#key-1")
+	require.EqualError(t, err, "get error")
+}
+
+func TestFindPublicKey(t *testing.T) {
+	doc := &did.Doc{
+		ID:        "did:example:alice",
+		PublicKey: []did.PublicKey{{ID: "#key-1"}},
+		KeyAgreement: []did.VerificationMethod{
+			{PublicKey: did.PublicKey{ID: "did:example:alice#key-2"}},
+		},
+	}
+
+	pk, err := FindPublicKey(doc, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, "#key-1", pk.ID)
+
+	pk, err = FindPublicKey(doc, "key-2", doc.KeyAgreement)
+	require.NoError(t, err)
+	require.Equal(t, "did:example:alice#key-2", pk.ID)
+
+	_, err = FindPublicKey(doc, "key-2")
+	require.EqualError(t, err, "public key not found in DID document")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anoncrypt
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	chacha "golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+)
+
+// This package deals with Anoncrypt encryption for Packing/Unpacking DID Comm exchange
+// Using Chacha20Poly1305 encryption/authentication and an ECDH-ES key agreement with an ephemeral key per recipient,
+// so that the envelopes don't reveal the sender key (e.g. forward messages to mediators, connectionless requests)
+
+// ContentEncryption represents a content encryption algorithm.
+type ContentEncryption string
+
+const (
+	// C20P Chacha20Poly1305 algorithm
+	C20P = ContentEncryption("C20P") // Chacha20 encryption + Poly1305 authenticator cipher (96 bits nonce)
+	// XC20P XChacha20Poly1305 algorithm
+	XC20P = ContentEncryption("XC20P") // XChacha20 encryption + Poly1305 authenticator cipher (192 bits nonce)
+	// encodingType is the `typ` string identifier in a message that identifies the format as being anonymous JWE
+	encodingType string = "prs.hyperledger.aries-anon-message"
+)
+
+// errUnsupportedAlg is used when a bad encryption algorithm is used
+var errUnsupportedAlg = errors.New("algorithm not supported")
+
+// Packer represents an Anoncrypt Packer/Unpacker that outputs/reads JWE envelopes without sender
+type Packer struct {
+	alg        ContentEncryption
+	nonceSize  int
+	legacyKMS  legacykms.KeyManager
+	randReader io.Reader
+}
+
+// Envelope represents a JWE envelope as per the Aries Encryption envelope specs
+type Envelope struct {
+	Protected  string           `json:"protected,omitempty"`
+	Recipients []jose.Recipient `json:"recipients,omitempty"`
+	AAD        string           `json:"aad,omitempty"`
+	IV         string           `json:"iv,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+	CipherText string           `json:"ciphertext,omitempty"`
+}
+
+// jweHeaders are the Protected JWE headers in a map format
+type jweHeaders struct {
+	Typ string `json:"typ,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Enc string `json:"enc,omitempty"`
+}
+
+// jwk formatted key, the ephemeral public key of a recipient
+type jwk struct {
+	Kty string `json:"kty,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// New will create a Packer instance to 'AnonCrypt' payloads for the given recipients arguments
+// and the encryption alg argument. Possible algorithms supported are:
+// C20P (chacha20-poly1305 ietf)
+// XC20P (xchacha20-poly1305 ietf)
+// The returned Packer contains all the information required to pack and unpack payloads.
+func New(ctx packer.Provider, alg ContentEncryption) (*Packer, error) {
+	var nonceSize int
+
+	switch alg {
+	case C20P:
+		nonceSize = chacha.NonceSize
+	case XC20P:
+		nonceSize = chacha.NonceSizeX
+	default:
+		return nil, errUnsupportedAlg
+	}
+
+	return &Packer{
+		alg:        alg,
+		nonceSize:  nonceSize,
+		legacyKMS:  ctx.LegacyKMS(),
+		randReader: rand.Reader,
+	}, nil
+}
+
+// EncodingType returns the type of the encoding, as in the `Typ` field of the envelope header
+func (p *Packer) EncodingType() string {
+	return encodingType
+}
+
+// keyAlg returns the `alg` header of the envelopes, the algorithm ID of the key agreement of the recipients
+func (p *Packer) keyAlg() string {
+	return "ECDH-ES+" + string(p.alg) + "KW"
+}
@@ -0,0 +1,250 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anoncrypt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
+)
+
+func TestEncodingType(t *testing.T) {
+	kmsProvider, err := mockkms.NewMockProvider()
+	require.NoError(t, err)
+
+	packer, err := New(kmsProvider, XC20P)
+	require.NoError(t, err)
+	require.NotEmpty(t, packer)
+
+	require.Equal(t, encodingType, packer.EncodingType())
+
+	_, err = New(kmsProvider, "BAD")
+	require.EqualError(t, err, errUnsupportedAlg.Error())
+}
+
+func TestPackUnpack(t *testing.T) {
+	rec1 := newMessagingKeys(t)
+	rec2 := newMessagingKeys(t)
+	other := newMessagingKeys(t)
+
+	senderKMSProvider, err := mockkms.NewMockProvider()
+	require.NoError(t, err)
+
+	recipient2KMSProvider, err := mockkms.NewMockProvider(rec2)
+	require.NoError(t, err)
+
+	otherKMSProvider, err := mockkms.NewMockProvider(other)
+	require.NoError(t, err)
+
+	payload := []byte("lorem ipsum dolor sit amet")
+	recipients := [][]byte{rec1.SigKeyPair.Pub, rec2.SigKeyPair.Pub}
+
+	for _, alg := range []ContentEncryption{C20P, XC20P} {
+		sender, err := New(senderKMSProvider, alg)
+		require.NoError(t, err)
+
+		// the sender key isn't needed to pack an anonymous envelope
+		enc, err := sender.Pack(payload, nil, recipients)
+		require.NoError(t, err)
+
+		jwe := &Envelope{}
+		require.NoError(t, json.Unmarshal(enc, jwe))
+		require.Len(t, jwe.Recipients, 2)
+
+		for _, r := range jwe.Recipients {
+			require.Empty(t, r.Header.SPK)
+			require.Empty(t, r.Header.APU)
+			require.NotEmpty(t, r.Header.EPK)
+		}
+
+		// each recipient gets its own ephemeral key
+		require.NotEqual(t, jwe.Recipients[0].Header.EPK, jwe.Recipients[1].Header.EPK)
+
+		recipient, err := New(recipient2KMSProvider, alg)
+		require.NoError(t, err)
+
+		env, err := recipient.Unpack(enc)
+		require.NoError(t, err)
+		require.Equal(t, payload, env.Message)
+		require.Equal(t, rec2.EncKeyPair.Pub, env.ToVerKey)
+		require.Empty(t, env.FromVerKey)
+
+		otherRecipient, err := New(otherKMSProvider, alg)
+		require.NoError(t, err)
+
+		_, err = otherRecipient.Unpack(enc)
+		require.Error(t, err)
+	}
+
+	t.Run("unpack with a different content encryption", func(t *testing.T) {
+		sender, err := New(senderKMSProvider, C20P)
+		require.NoError(t, err)
+
+		enc, err := sender.Pack(payload, nil, recipients)
+		require.NoError(t, err)
+
+		recipient, err := New(recipient2KMSProvider, XC20P)
+		require.NoError(t, err)
+
+		_, err = recipient.Unpack(enc)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errUnsupportedAlg))
+	})
+}
+
+func TestPack_Errors(t *testing.T) {
+	kmsProvider, err := mockkms.NewMockProvider()
+	require.NoError(t, err)
+
+	packer, err := New(kmsProvider, XC20P)
+	require.NoError(t, err)
+
+	_, err = packer.Pack([]byte("payload"), nil, nil)
+	require.EqualError(t, err, "failed to pack message: empty recipients")
+
+	_, err = packer.Pack([]byte("payload"), nil, [][]byte{[]byte("bad key")})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, cryptoutil.ErrInvalidKey))
+
+	rec := newMessagingKeys(t)
+
+	packer.randReader = bytes.NewReader(nil)
+
+	_, err = packer.Pack([]byte("payload"), nil, [][]byte{rec.SigKeyPair.Pub})
+	require.Error(t, err)
+
+	// enough randomness for the cek and the payload nonce, but not for the ephemeral key
+	packer.randReader = bytes.NewReader(make([]byte, 32+packer.nonceSize))
+
+	_, err = packer.Pack([]byte("payload"), nil, [][]byte{rec.SigKeyPair.Pub})
+	require.Error(t, err)
+}
+
+func TestUnpack_Errors(t *testing.T) {
+	rec := newMessagingKeys(t)
+
+	kmsProvider, err := mockkms.NewMockProvider(rec)
+	require.NoError(t, err)
+
+	packer, err := New(kmsProvider, XC20P)
+	require.NoError(t, err)
+
+	enc, err := packer.Pack([]byte("payload"), nil, [][]byte{rec.SigKeyPair.Pub})
+	require.NoError(t, err)
+
+	_, err = packer.Unpack([]byte("{"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unpack json")
+
+	tests := []struct {
+		name   string
+		mutate func(jwe *Envelope)
+		errMsg string
+	}{
+		{
+			name:   "bad protected headers",
+			mutate: func(jwe *Envelope) { jwe.Protected = "!" },
+			errMsg: "unpack: illegal base64",
+		},
+		{
+			name:   "missing epk",
+			mutate: func(jwe *Envelope) { jwe.Recipients[0].Header.EPK = nil },
+			errMsg: "unpack: ephemeral key: missing epk header",
+		},
+		{
+			name: "bad epk curve",
+			mutate: func(jwe *Envelope) {
+				jwe.Recipients[0].Header.EPK = json.RawMessage(`{"kty":"EC","crv":"P-256"}`)
+			},
+			errMsg: "unpack: ephemeral key: unsupported key type 'EC' curve 'P-256'",
+		},
+		{
+			name: "bad epk size",
+			mutate: func(jwe *Envelope) {
+				jwe.Recipients[0].Header.EPK = json.RawMessage(`{"kty":"OKP","crv":"X25519","x":"AQID"}`)
+			},
+			errMsg: "unpack: ephemeral key: bad key size",
+		},
+		{
+			name:   "bad recipient nonce",
+			mutate: func(jwe *Envelope) { jwe.Recipients[0].Header.IV = base64.RawURLEncoding.EncodeToString([]byte("iv")) },
+			errMsg: "unpack: decrypt shared key: bad nonce size",
+		},
+		{
+			name: "bad encrypted key",
+			mutate: func(jwe *Envelope) {
+				jwe.Recipients[0].EncryptedKey = base64.RawURLEncoding.EncodeToString([]byte("k"))
+			},
+			errMsg: "unpack: decrypt shared key: chacha20poly1305: message authentication failed",
+		},
+		{
+			name:   "bad payload nonce",
+			mutate: func(jwe *Envelope) { jwe.IV = base64.RawURLEncoding.EncodeToString([]byte("iv")) },
+			errMsg: "unpack: bad nonce size",
+		},
+		{
+			name:   "bad aad",
+			mutate: func(jwe *Envelope) { jwe.AAD = base64.RawURLEncoding.EncodeToString([]byte("aad")) },
+			errMsg: "unpack: chacha20poly1305: message authentication failed",
+		},
+		{
+			name:   "unknown recipient",
+			mutate: func(jwe *Envelope) { jwe.Recipients[0].Header.KID = base58.Encode([]byte("unknown")) },
+			errMsg: "unpack: ",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			jwe := &Envelope{}
+			require.NoError(t, json.Unmarshal(enc, jwe))
+
+			tc.mutate(jwe)
+
+			mutated, e := json.Marshal(jwe)
+			require.NoError(t, e)
+
+			_, e = packer.Unpack(mutated)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), tc.errMsg)
+		})
+	}
+}
+
+func newMessagingKeys(t *testing.T) *cryptoutil.MessagingKeys {
+	sigPubKey, sigPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// convert signing keys to encryption keys
+	encPubKey, err := cryptoutil.PublicEd25519toCurve25519(sigPubKey)
+	require.NoError(t, err)
+
+	encPrivKey, err := cryptoutil.SecretEd25519toCurve25519(sigPrivKey)
+	require.NoError(t, err)
+
+	return &cryptoutil.MessagingKeys{
+		SigKeyPair: &cryptoutil.SigKeyPair{
+			KeyPair: cryptoutil.KeyPair{Pub: sigPubKey, Priv: sigPrivKey},
+			Alg:     cryptoutil.EdDSA,
+		},
+		EncKeyPair: &cryptoutil.EncKeyPair{
+			KeyPair: cryptoutil.KeyPair{Pub: encPubKey, Priv: encPrivKey},
+			Alg:     cryptoutil.Curve25519,
+		},
+	}
+}
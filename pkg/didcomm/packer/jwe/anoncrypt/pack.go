@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anoncrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	chacha "golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+// Pack will JWE encode the payload argument for the recipients
+// Using (X)Chacha20 encryption algorithm and Poly1305 authenticator
+// The sender key is not used, the cek is encrypted for each recipient with a key derived from a new ephemeral key
+// and the recipient's encryption key, converted from its verification key in recipientsVerKeys
+func (p *Packer) Pack(payload, _ []byte, recipientsVerKeys [][]byte) ([]byte, error) {
+	if len(recipientsVerKeys) == 0 {
+		return nil, errors.New("failed to pack message: empty recipients")
+	}
+
+	recipients, err := convertRecipients(recipientsVerKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: %w", err)
+	}
+
+	h, err := json.Marshal(jweHeaders{
+		Typ: encodingType,
+		Alg: p.keyAlg(),
+		Enc: string(p.alg),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encHeaders := base64.RawURLEncoding.EncodeToString(h)
+	aadEncoded := base64.RawURLEncoding.EncodeToString(buildAAD(recipients))
+
+	cek := &[chacha.KeySize]byte{}
+
+	// generate a cek for encryption (it will be treated as a symmetric key)
+	_, err = p.randReader.Read(cek[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// encrypt payload with the cek and its AAD, the protected headers and the recipients keys
+	cipherText, tag, nonce, err := p.encrypt(cek[:], payload, []byte(encHeaders+"."+aadEncoded))
+	if err != nil {
+		return nil, err
+	}
+
+	encRec, err := p.encodeRecipients(cek, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&Envelope{
+		Protected:  encHeaders,
+		Recipients: encRec,
+		AAD:        aadEncoded,
+		IV:         nonce,
+		Tag:        tag,
+		CipherText: cipherText,
+	})
+}
+
+// convertRecipients is a utility function that converts keys from signature keys ([][]byte type)
+// into encryption keys ([]*[chacha.KeySize]byte type)
+func convertRecipients(recipients [][]byte) ([]*[chacha.KeySize]byte, error) {
+	var chachaRecipients []*[chacha.KeySize]byte
+
+	for i, rVer := range recipients {
+		if !cryptoutil.IsChachaKeyValid(rVer) {
+			return nil, fmt.Errorf("%w - for recipient %d", cryptoutil.ErrInvalidKey, i+1)
+		}
+
+		rEnc, err := cryptoutil.PublicEd25519toCurve25519(rVer)
+		if err != nil {
+			return nil, err
+		}
+
+		chachaRec := new([chacha.KeySize]byte)
+		copy(chachaRec[:], rEnc)
+		chachaRecipients = append(chachaRecipients, chachaRec)
+	}
+
+	return chachaRecipients, nil
+}
+
+// buildAAD is a utility function to build the Additional Authentication Data for the AEAD (chach20poly1305) cipher.
+// the build takes the list of recipients keys base58 encoded and sorted then SHA256 hash
+// the concatenation of these keys with a '.' separator
+func buildAAD(recipients []*[chacha.KeySize]byte) []byte {
+	var keys []string
+	for _, r := range recipients {
+		keys = append(keys, base58.Encode(r[:]))
+	}
+
+	return jweutil.HashAAD(keys)
+}
+
+// encodeRecipients is a utility function that will encrypt the cek (content encryption key) for each recipient
+// and return a list of encoded recipient keys in a JWE compliant format ([]Recipient)
+func (p *Packer) encodeRecipients(cek *[chacha.KeySize]byte, recipients []*[chacha.KeySize]byte) ([]jose.Recipient, error) { //nolint:lll
+	var encodedRecipients []jose.Recipient
+
+	for _, r := range recipients {
+		rec, err := p.encodeRecipient(cek, r)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedRecipients = append(encodedRecipients, *rec)
+	}
+
+	return encodedRecipients, nil
+}
+
+// encodeRecipient will encrypt the cek (content encryption key) with a key derived from a new ephemeral key and
+// recipientPubKey, the ephemeral public key is added to the headers of the returned JWE compliant Recipient
+func (p *Packer) encodeRecipient(cek, recipientPubKey *[chacha.KeySize]byte) (*jose.Recipient, error) {
+	// generate ephemeral asymmetric keys
+	epk, esk, err := box.GenerateKey(p.randReader)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := cryptoutil.Derive25519KEK([]byte(p.keyAlg()), nil, esk, recipientPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedCEK, tag, nonce, err := p.encrypt(kek, cek[:], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	epkJSON, err := json.Marshal(&jwk{
+		Kty: "OKP",
+		Crv: "X25519",
+		X:   base64.RawURLEncoding.EncodeToString(epk[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &jose.Recipient{
+		EncryptedKey: encryptedCEK,
+		Header: jose.RecipientHeaders{
+			IV:  nonce,
+			Tag: tag,
+			KID: base58.Encode(recipientPubKey[:]),
+			EPK: epkJSON,
+		},
+	}, nil
+}
+
+// encrypt will encrypt msg with key, aad and a newly generated nonce
+// returns:
+// 		base64URL encoded cipher of msg
+//		base64URL encoded tag of the encryption
+//		base64URL encoded nonce used by the encryption
+//		error in case of failure
+func (p *Packer) encrypt(key, msg, aad []byte) (string, string, string, error) {
+	cipher, err := jweutil.CreateCipher(p.nonceSize, key)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	nonce := make([]byte, p.nonceSize)
+
+	_, err = p.randReader.Read(nonce)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// the output is a []byte containing the cipherText + tag
+	symOutput := cipher.Seal(nil, nonce, msg, aad)
+
+	cipherTextEncoded := jweutil.ExtractCipherText(symOutput)
+	tagEncoded := jweutil.ExtractTag(symOutput)
+
+	return cipherTextEncoded, tagEncoded, base64.RawURLEncoding.EncodeToString(nonce), nil
+}
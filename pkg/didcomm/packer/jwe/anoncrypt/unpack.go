@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anoncrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	chacha "golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// Unpack will JWE decode the envelope argument for the recipient found in the legacyKMS.
+// Using (X)Chacha20 cipher and Poly1305 authenticator for the encrypted payload and
+// encrypted CEK.
+// The CEK of the recipient is decrypted with a key derived from the recipient's private key and the
+// ephemeral public key found in the recipient's headers. The returned envelope has no sender key.
+func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
+	jwe := &Envelope{}
+
+	err := json.Unmarshal(envelope, jwe)
+	if err != nil {
+		return nil, fmt.Errorf("unpack json: %w", err)
+	}
+
+	err = p.checkHeaders(jwe.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	recipientPubKey, recipient, err := p.findRecipient(jwe.Recipients)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	epk, err := decodeEPK(recipient.Header.EPK)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: ephemeral key: %w", err)
+	}
+
+	cek, err := p.decryptCEK(recipientPubKey, epk, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: decrypt shared key: %w", err)
+	}
+
+	symOutput, err := p.decryptPayload(cek, jwe)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	return &transport.Envelope{
+		Message:  symOutput,
+		ToVerKey: recipientPubKey[:],
+	}, nil
+}
+
+// checkHeaders verifies the protected headers of the envelope match the algorithms of the Packer
+func (p *Packer) checkHeaders(protected string) error {
+	h, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return err
+	}
+
+	headers := &jweHeaders{}
+
+	err = json.Unmarshal(h, headers)
+	if err != nil {
+		return err
+	}
+
+	if headers.Enc != string(p.alg) || headers.Alg != p.keyAlg() {
+		return fmt.Errorf("%w: alg '%s' enc '%s'", errUnsupportedAlg, headers.Alg, headers.Enc)
+	}
+
+	return nil
+}
+
+// decodeEPK will decode the X25519 ephemeral public key found in the JWK epk header of a recipient
+func decodeEPK(epkJSON json.RawMessage) (*[chacha.KeySize]byte, error) {
+	if len(epkJSON) == 0 {
+		return nil, errors.New("missing epk header")
+	}
+
+	key := &jwk{}
+
+	err := json.Unmarshal(epkJSON, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Kty != "OKP" || key.Crv != "X25519" {
+		return nil, fmt.Errorf("unsupported key type '%s' curve '%s'", key.Kty, key.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != chacha.KeySize {
+		return nil, errors.New("bad key size")
+	}
+
+	epk := new([chacha.KeySize]byte)
+	copy(epk[:], x)
+
+	return epk, nil
+}
+
+func (p *Packer) decryptPayload(cek []byte, jwe *Envelope) ([]byte, error) {
+	cipher, err := jweutil.CreateCipher(p.nonceSize, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	pldAAD := jwe.Protected + "." + jwe.AAD
+
+	payload, err := base64.RawURLEncoding.DecodeString(jwe.CipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := base64.RawURLEncoding.DecodeString(jwe.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(jwe.IV)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != p.nonceSize {
+		return nil, errors.New("bad nonce size")
+	}
+
+	payload = append(payload, tag...)
+
+	return cipher.Open(nil, nonce, payload, []byte(pldAAD))
+}
+
+// findRecipient will loop through jweRecipients and returns the first matching key from the legacyKMS
+func (p *Packer) findRecipient(jweRecipients []jose.Recipient) (*[chacha.KeySize]byte, *jose.Recipient, error) {
+	var recipientsKeys []string
+	for _, recipient := range jweRecipients {
+		recipientsKeys = append(recipientsKeys, recipient.Header.KID)
+	}
+
+	i, err := p.legacyKMS.FindVerKey(recipientsKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pubK := new([chacha.KeySize]byte)
+	copy(pubK[:], base58.Decode(recipientsKeys[i]))
+
+	return pubK, &jweRecipients[i], nil
+}
+
+// decryptCEK will decrypt the CEK found in recipient using the private key of recipientPubKey and the ephemeral
+// public key epk
+func (p *Packer) decryptCEK(recipientPubKey, epk *[chacha.KeySize]byte, recipient *jose.Recipient) ([]byte, error) {
+	nonce, err := base64.RawURLEncoding.DecodeString(recipient.Header.IV)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != p.nonceSize {
+		return nil, errors.New("bad nonce size")
+	}
+
+	tag, err := base64.RawURLEncoding.DecodeString(recipient.Header.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedCEK, err := base64.RawURLEncoding.DecodeString(recipient.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// derive the key encryption key agreed with the ephemeral key of the sender
+	kek, err := p.legacyKMS.DeriveKEK([]byte(p.keyAlg()), nil, recipientPubKey[:], epk[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// create a new (chacha20poly1305) cipher with this new key to decrypt the cek
+	cipher, err := jweutil.CreateCipher(p.nonceSize, kek)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText := encryptedCEK
+	cipherText = append(cipherText, tag...)
+
+	return cipher.Open(nil, nonce, cipherText, nil)
+}
@@ -601,11 +601,6 @@ func prettyPrint(msg []byte) (string, error) {
 	return prettyJSON.String(), nil
 }
 
-func TestBadCreateCipher(t *testing.T) {
-	_, err := createCipher(0, nil)
-	require.Error(t, err)
-}
-
 func TestRefEncrypt(t *testing.T) {
 	// reference php crypto material similar to
 	// https://github.com/hyperledger/aries-rfcs/issues/133#issuecomment-518922447
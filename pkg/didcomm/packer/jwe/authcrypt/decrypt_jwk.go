@@ -13,6 +13,8 @@ import (
 	"strings"
 
 	chacha "golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
 )
 
 // decryptSPK will decrypt a recipient's encrypted SPK (in the case of this package, it is represented as
@@ -86,7 +88,7 @@ func (p *Packer) decryptJWKSharedKey(cipherKEK []byte, headersJSON *recipientSPK
 
 	// create a cipher for the given nonceSize and generated kek above
 	// to decrypt the symmetric shared key (by decrypting cipherKEK)
-	cipher, err := createCipher(p.nonceSize, kek)
+	cipher, err := jweutil.CreateCipher(p.nonceSize, kek)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +119,7 @@ func (p *Packer) decryptJWKSharedKey(cipherKEK []byte, headersJSON *recipientSPK
 // and headersEncoded as AAD for the aead (chacha20poly1305) cipher
 func (p *Packer) decryptSenderJWK(nonce, symKey, headersEncoded, cipherJWK, tag []byte) ([]byte, error) {
 	// now that we have symKey, let's decrypt the sender JWK (cipherJWK)
-	jwkCipher, err := createCipher(p.nonceSize, symKey)
+	jwkCipher, err := jweutil.CreateCipher(p.nonceSize, symKey)
 	if err != nil {
 		return nil, err
 	}
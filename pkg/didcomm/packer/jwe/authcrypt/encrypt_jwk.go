@@ -13,6 +13,7 @@ import (
 	chacha "golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/nacl/box"
 
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
@@ -102,7 +103,7 @@ func (p *Packer) encryptSenderJWK(encKey, headers string, senderJWKJSON, cek []b
 	}
 
 	// create a cipher for the given nonceSize and cek
-	cipher, err := createCipher(p.nonceSize, cek)
+	cipher, err := jweutil.CreateCipher(p.nonceSize, cek)
 	if err != nil {
 		return "", err
 	}
@@ -111,8 +112,8 @@ func (p *Packer) encryptSenderJWK(encKey, headers string, senderJWKJSON, cek []b
 	// the output is a []byte containing the cipherText + tag
 	symOutput := cipher.Seal(nil, nonce, senderJWKJSON, []byte(headers))
 
-	tagEncoded := extractTag(symOutput)
-	cipherJWKEncoded := extractCipherText(symOutput)
+	tagEncoded := jweutil.ExtractTag(symOutput)
+	cipherJWKEncoded := jweutil.ExtractCipherText(symOutput)
 
 	return headers + "." +
 			encKey + "." +
@@ -7,17 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package authcrypt
 
 import (
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"sort"
-	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 	chacha "golang.org/x/crypto/chacha20poly1305"
-	"golang.org/x/crypto/poly1305"
 
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
@@ -77,7 +74,7 @@ func (p *Packer) Pack(payload, senderVerKey []byte, recipientsVerKeys [][]byte)
 	}
 
 	// create a cipher for the given nonceSize and generated cek above
-	cipher, err := createCipher(p.nonceSize, cek[:])
+	cipher, err := jweutil.CreateCipher(p.nonceSize, cek[:])
 	if err != nil {
 		return nil, err
 	}
@@ -86,8 +83,8 @@ func (p *Packer) Pack(payload, senderVerKey []byte, recipientsVerKeys [][]byte)
 	// the output is a []byte containing the cipherText + tag
 	symOutput := cipher.Seal(nil, nonce, payload, []byte(pldAAD))
 
-	tagEncoded := extractTag(symOutput)
-	cipherTextEncoded := extractCipherText(symOutput)
+	tagEncoded := jweutil.ExtractTag(symOutput)
+	cipherTextEncoded := jweutil.ExtractCipherText(symOutput)
 
 	// now build, encode recipients and include the encrypted cek (with a recipient's ephemeral key)
 	encRec, err := p.encodeRecipients(cek, chachaRecipients, senderPubKey)
@@ -142,26 +139,6 @@ func (p *Packer) convertRecipients(recipients [][]byte) ([]*[chacha.KeySize]byte
 	return chachaRecipients, nil
 }
 
-// extractTag is a utility function that extracts base64UrlEncoded tag sub-slice from symOutput returned by cipher.Seal
-func extractTag(symOutput []byte) string {
-	// symOutput has a length of len(clear msg) + poly1305.TagSize
-	// fetch the tag from the tail of symOutput
-	tag := symOutput[len(symOutput)-poly1305.TagSize:]
-
-	// base64 encode the tag
-	return base64.RawURLEncoding.EncodeToString(tag)
-}
-
-// extractCipherText is a utility function that extracts base64UrlEncoded cipherText sub-slice
-// from symOutput returned by cipher.Seal
-func extractCipherText(symOutput []byte) string {
-	// fetch the cipherText from the head of symOutput (0:up to the trailing tag)
-	cipherText := symOutput[0 : len(symOutput)-poly1305.TagSize]
-
-	// base64 encode the cipherText
-	return base64.RawURLEncoding.EncodeToString(cipherText)
-}
-
 // buildJWE builds the JSON object representing the JWE output of the encryption
 // and returns its marshaled []byte representation
 func (p *Packer) buildJWE(headers string, recipients []jose.Recipient, aad, iv, tag, cipherText string) ([]byte, error) { //nolint:lll
@@ -191,16 +168,7 @@ func buildAAD(recipients []*[chacha.KeySize]byte) []byte {
 		keys = append(keys, base58.Encode(r[:]))
 	}
 
-	return hashAAD(keys)
-}
-
-// hashAAD will string sort keys and return sha256 hash of the string representation
-// of keys concatenated by '.'
-func hashAAD(keys []string) []byte {
-	sort.Strings(keys)
-	sha := sha256.Sum256([]byte(strings.Join(keys, ".")))
-
-	return sha[:]
+	return jweutil.HashAAD(keys)
 }
 
 // encodeRecipients is a utility function that will encrypt the cek (content encryption key) for each recipient
@@ -276,7 +244,7 @@ func (p *Packer) buildRecipient(key string, apu []byte, spkEncoded, nonceEncoded
 //		generated nonce used by the encryption
 //		error in case of failure
 func (p *Packer) encryptCEK(kek, cek []byte) (string, string, string, error) {
-	cipher, err := createCipher(p.nonceSize, kek)
+	cipher, err := jweutil.CreateCipher(p.nonceSize, kek)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -292,8 +260,8 @@ func (p *Packer) encryptCEK(kek, cek []byte) (string, string, string, error) {
 	// encrypt symmetric shared key using the key encryption key (kek)
 	kekOutput := cipher.Seal(nil, nonce, cek, nil)
 
-	symKeyCipherEncoded := extractCipherText(kekOutput)
-	tagEncoded := extractTag(kekOutput)
+	symKeyCipherEncoded := jweutil.ExtractCipherText(kekOutput)
+	tagEncoded := jweutil.ExtractTag(kekOutput)
 	nonceEncoded := base64.RawURLEncoding.EncodeToString(nonce)
 
 	return symKeyCipherEncoded, tagEncoded, nonceEncoded, nil
@@ -16,6 +16,7 @@ import (
 	chacha "golang.org/x/crypto/chacha20poly1305"
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/internal/jweutil"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 )
 
@@ -69,7 +70,7 @@ func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
 }
 
 func (p *Packer) decryptPayload(cek []byte, jwe *Envelope) ([]byte, error) {
-	cipher, err := createCipher(p.nonceSize, cek)
+	cipher, err := jweutil.CreateCipher(p.nonceSize, cek)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +148,7 @@ func (p *Packer) decryptCEK(recipientPubKey, senderPubKey *[chacha.KeySize]byte,
 	}
 
 	// create a new (chacha20poly1305) cipher with this new key to decrypt the cek
-	cipher, err := createCipher(p.nonceSize, kek)
+	cipher, err := jweutil.CreateCipher(p.nonceSize, kek)
 	if err != nil {
 		return nil, err
 	}
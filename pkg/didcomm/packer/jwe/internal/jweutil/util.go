@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jweutil
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	chacha "golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/poly1305"
+)
+
+// CreateCipher will create and return a new Chacha20Poly1305 cipher for the given nonceSize and symmetric key.
+// This function is to be used by the jwe packers only
+func CreateCipher(nonceSize int, symKey []byte) (cipher.AEAD, error) {
+	switch nonceSize {
+	case chacha.NonceSize:
+		return chacha.New(symKey)
+	case chacha.NonceSizeX:
+		return chacha.NewX(symKey)
+	default:
+		return nil, errors.New("cipher cannot be created with bad nonce size and shared symmetric Key combo")
+	}
+}
+
+// ExtractTag is a utility function that extracts base64UrlEncoded tag sub-slice from symOutput returned by cipher.Seal
+func ExtractTag(symOutput []byte) string {
+	// symOutput has a length of len(clear msg) + poly1305.TagSize
+	// fetch the tag from the tail of symOutput
+	return base64.RawURLEncoding.EncodeToString(symOutput[len(symOutput)-poly1305.TagSize:])
+}
+
+// ExtractCipherText is a utility function that extracts base64UrlEncoded cipherText sub-slice
+// from symOutput returned by cipher.Seal
+func ExtractCipherText(symOutput []byte) string {
+	// fetch the cipherText from the head of symOutput (0:up to the trailing tag)
+	return base64.RawURLEncoding.EncodeToString(symOutput[0 : len(symOutput)-poly1305.TagSize])
+}
+
+// HashAAD will string sort keys and return sha256 hash of the string representation
+// of keys concatenated by '.'
+func HashAAD(keys []string) []byte {
+	sort.Strings(keys)
+	sha := sha256.Sum256([]byte(strings.Join(keys, ".")))
+
+	return sha[:]
+}
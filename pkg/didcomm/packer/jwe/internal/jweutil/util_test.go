@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jweutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	chacha "golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/poly1305"
+)
+
+func TestCreateCipher(t *testing.T) {
+	key := make([]byte, chacha.KeySize)
+
+	for _, nonceSize := range []int{chacha.NonceSize, chacha.NonceSizeX} {
+		c, err := CreateCipher(nonceSize, key)
+		require.NoError(t, err)
+		require.Equal(t, nonceSize, c.NonceSize())
+	}
+
+	_, err := CreateCipher(0, nil)
+	require.Error(t, err)
+}
+
+func TestExtractCipherTextAndTag(t *testing.T) {
+	c, err := CreateCipher(chacha.NonceSize, make([]byte, chacha.KeySize))
+	require.NoError(t, err)
+
+	symOutput := c.Seal(nil, make([]byte, chacha.NonceSize), []byte("message"), nil)
+
+	cipherText, err := base64.RawURLEncoding.DecodeString(ExtractCipherText(symOutput))
+	require.NoError(t, err)
+
+	tag, err := base64.RawURLEncoding.DecodeString(ExtractTag(symOutput))
+	require.NoError(t, err)
+	require.Len(t, tag, poly1305.TagSize)
+	require.Equal(t, symOutput, append(cipherText, tag...))
+}
+
+func TestHashAAD(t *testing.T) {
+	sha := sha256.Sum256([]byte("a.b.c"))
+
+	require.Equal(t, sha[:], HashAAD([]string{"c", "a", "b"}))
+}
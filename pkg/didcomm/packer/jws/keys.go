@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/internal/kidutil"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+const (
+	ed25519KeyType = "Ed25519VerificationKey2018"
+	// p256KeySize is the size of the integers r and s of P-256 signatures
+	p256KeySize = 32
+)
+
+// isKeyID tells if the `kid` header kid is a key ID, a DID URL, rather than a base58 verification key
+func isKeyID(kid string) bool {
+	return strings.HasPrefix(kid, "did:")
+}
+
+// verificationKey resolves the public key identified by the DID URL kid, with its signature algorithm
+func (p *Packer) verificationKey(kid string) (*sigverifier.PublicKey, string, error) {
+	didID, fragment, err := kidutil.SplitKID(kid)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc, err := p.vdriRegistry.Resolve(didID)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolve DID %s: %w", didID, err)
+	}
+
+	pk, err := kidutil.FindPublicKey(doc, fragment, doc.Authentication, doc.AssertionMethod)
+	if err != nil {
+		return nil, "", fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	pubKey, alg, err := signatureKey(pk)
+	if err != nil {
+		return nil, "", fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	return pubKey, alg, nil
+}
+
+// signatureKey converts the DID document public key pk to a verifier public key, with its signature algorithm
+func signatureKey(pk *did.PublicKey) (*sigverifier.PublicKey, string, error) {
+	if jsonWebKey := pk.JSONWebKey(); jsonWebKey != nil {
+		switch key := jsonWebKey.Key.(type) {
+		case *ecdsa.PublicKey:
+			if key.Curve != elliptic.P256() {
+				return nil, "", fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+			}
+
+			return &sigverifier.PublicKey{Type: pk.Type, JWK: jsonWebKey}, ES256, nil
+		case ed25519.PublicKey:
+			return &sigverifier.PublicKey{Type: pk.Type, Value: key}, EdDSA, nil
+		default:
+			return nil, "", fmt.Errorf("unsupported JWK key type %T", jsonWebKey.Key)
+		}
+	}
+
+	if pk.Type != ed25519KeyType {
+		return nil, "", fmt.Errorf("unsupported public key type %s", pk.Type)
+	}
+
+	return &sigverifier.PublicKey{Type: pk.Type, Value: pk.Value}, EdDSA, nil
+}
+
+// signatureVerifier returns the verifier of the signatures of the algorithm alg
+func signatureVerifier(alg string) (*sigverifier.PublicKeyVerifier, error) {
+	switch alg {
+	case EdDSA:
+		return sigverifier.NewPublicKeyVerifier(sigverifier.NewEd25519SignatureVerifier()), nil
+	case ES256:
+		return sigverifier.NewPublicKeyVerifier(sigverifier.NewECDSAES256SignatureVerifier()), nil
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+}
+
+// rawECDSASignature converts the ECDSA signature of a Crypto to its JWS format, r and s concatenated. A signature of
+// the size of the JWS format is already in this format, otherwise it's ASN.1 DER encoded: a DER signature of P-256 is
+// longer except for negligibly small r and s, while a raw signature may happen to be valid DER.
+func rawECDSASignature(signature []byte) ([]byte, error) {
+	if len(signature) == 2*p256KeySize {
+		return signature, nil
+	}
+
+	sig := struct {
+		R, S *big.Int
+	}{}
+
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("parse ECDSA signature: %w", err)
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("parse ECDSA signature: trailing data")
+	}
+
+	r, s := sig.R.Bytes(), sig.S.Bytes()
+	if len(r) > p256KeySize || len(s) > p256KeySize {
+		return nil, errors.New("invalid ECDSA signature size")
+	}
+
+	raw := make([]byte, 2*p256KeySize)
+	copy(raw[p256KeySize-len(r):p256KeySize], r)
+	copy(raw[2*p256KeySize-len(s):], s)
+
+	return raw, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/internal/kidutil"
+)
+
+// Pack will sign payload in a signed envelope with the Ed25519 legacy KMS key of the verification key senderKey.
+// Signed envelopes aren't encrypted, recipients are ignored.
+func (p *Packer) Pack(payload, senderKey []byte, _ [][]byte) ([]byte, error) {
+	if p.signer == nil {
+		return nil, errVerKeysNotSupported
+	}
+
+	if len(senderKey) == 0 {
+		return nil, errors.New("failed to pack message: empty sender key")
+	}
+
+	verKey := base58.Encode(senderKey)
+
+	headers := &jwsHeaders{Typ: SignedMediaType, Alg: EdDSA, KID: verKey}
+
+	return sign(payload, headers, func(signingInput []byte) ([]byte, error) {
+		return p.signer.SignMessage(signingInput, verKey)
+	})
+}
+
+// PackWithKeyIDs will sign payload in a signed envelope with the KMS key identified by the DID URL senderKID, the
+// signature algorithm being the one of its public key in the DID document of the sender. Signed envelopes aren't
+// encrypted, recipientKIDs are ignored.
+func (p *Packer) PackWithKeyIDs(payload []byte, senderKID string, _ []string) ([]byte, error) {
+	if p.kms == nil || p.crypto == nil || p.vdriRegistry == nil {
+		return nil, errKeyIDsNotSupported
+	}
+
+	if senderKID == "" {
+		return nil, errors.New("failed to pack message: empty sender key ID")
+	}
+
+	_, alg, err := p.verificationKey(senderKID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: sender key: %w", err)
+	}
+
+	kh, err := kidutil.PrivateKey(p.kms, senderKID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: sender key: %w", err)
+	}
+
+	headers := &jwsHeaders{Typ: SignedMediaType, Alg: alg, KID: senderKID}
+
+	return sign(payload, headers, func(signingInput []byte) ([]byte, error) {
+		sig, e := p.crypto.Sign(signingInput, kh)
+		if e != nil {
+			return nil, e
+		}
+
+		// the KMS ECDSA signatures are usually ASN.1 DER encoded
+		if alg == ES256 {
+			return rawECDSASignature(sig)
+		}
+
+		return sig, nil
+	})
+}
+
+// sign signs payload with headers as protected headers using signFn, returning the signed envelope
+func sign(payload []byte, headers *jwsHeaders, signFn func(signingInput []byte) ([]byte, error)) ([]byte, error) {
+	protected, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	encHeaders := base64.RawURLEncoding.EncodeToString(protected)
+	encPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := signFn([]byte(encHeaders + "." + encPayload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack message: sign: %w", err)
+	}
+
+	return json.Marshal(&Envelope{
+		Payload: encPayload,
+		Signatures: []Signature{{
+			Protected: encHeaders,
+			Signature: base64.RawURLEncoding.EncodeToString(signature),
+		}},
+	})
+}
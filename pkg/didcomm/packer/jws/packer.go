@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"errors"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+)
+
+// This package deals with signed envelopes, JWSs in their general JSON serialization of media type
+// application/didcomm-signed+json. Unlike authcrypt envelopes, whose authentication is repudiable, the signature of
+// a signed envelope can be verified by any third party to prove the sender sent the message.
+// The signing key of an envelope is its `kid` header: either the base58 verification key of an Ed25519 legacy KMS key,
+// or a DID URL (e.g. did:example:123#key-1) whose public key is resolved from the DID document of the sender and
+// whose private key is found in the KMS, the fragment of the DID URL being its KMS key ID.
+// Signed envelopes aren't encrypted, they can be packed in an encrypted envelope to sign then encrypt messages.
+
+const (
+	// SignedMediaType is the media type of signed envelopes, the `typ` of the envelopes
+	SignedMediaType = "application/didcomm-signed+json"
+
+	// EdDSA is the signature algorithm of Ed25519 keys
+	EdDSA = "EdDSA"
+	// ES256 is the signature algorithm of P-256 keys
+	ES256 = "ES256"
+)
+
+var (
+	errVerKeysNotSupported = errors.New("packer provider doesn't support legacy KMS signer for verification keys")
+	errKeyIDsNotSupported  = errors.New("packer provider doesn't support KMS, Crypto and VDRI registry for key IDs")
+)
+
+// signerProvider contains the dependency of the Packer to sign with the legacy KMS keys of verification keys
+type signerProvider interface {
+	Signer() legacykms.Signer
+}
+
+// Provider contains the dependencies of the Packer to sign with keys identified by key IDs, a packer.Provider
+// typically created by aries.Context()
+type Provider interface {
+	KMS() kms.KeyManager
+	Crypto() crypto.Crypto
+	VDRIRegistry() vdri.Registry
+}
+
+// Packer represents a Packer/Unpacker of signed envelopes
+type Packer struct {
+	signer       legacykms.Signer
+	kms          kms.KeyManager
+	crypto       crypto.Crypto
+	vdriRegistry vdri.Registry
+}
+
+// Envelope represents a signed envelope, a JWS in its general JSON serialization
+type Envelope struct {
+	Payload    string      `json:"payload,omitempty"`
+	Signatures []Signature `json:"signatures,omitempty"`
+}
+
+// Signature represents a signature of a signed envelope with its protected headers
+type Signature struct {
+	Protected string `json:"protected,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// jwsHeaders are the protected headers of a signature
+type jwsHeaders struct {
+	Typ string `json:"typ,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	KID string `json:"kid,omitempty"`
+}
+
+// New will create a Packer of signed envelopes. The Packer signs with the legacy KMS keys of verification keys if ctx
+// also gives a legacy KMS Signer, and with the keys identified by key IDs if ctx is also a Provider.
+func New(ctx packer.Provider) (*Packer, error) {
+	p := &Packer{}
+
+	if sCtx, ok := ctx.(signerProvider); ok {
+		p.signer = sCtx.Signer()
+	}
+
+	if kidCtx, ok := ctx.(Provider); ok {
+		p.kms = kidCtx.KMS()
+		p.crypto = kidCtx.Crypto()
+		p.vdriRegistry = kidCtx.VDRIRegistry()
+	}
+
+	return p, nil
+}
+
+// EncodingType returns the type of the encoding, as in the `Typ` field of the envelope header
+func (p *Packer) EncodingType() string {
+	return SignedMediaType
+}
@@ -0,0 +1,457 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	josejwk "github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+// agent holds the packer of a party and its DID document
+type agent struct {
+	packer    *Packer
+	kms       *localkms.LocalKMS
+	legacyKMS *legacykms.BaseKMS
+	doc       *did.Doc
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(&mockprovider.Provider{})
+	require.NoError(t, err)
+	require.Equal(t, SignedMediaType, p.EncodingType())
+
+	_, err = p.Pack([]byte("payload"), []byte("key"), nil)
+	require.Equal(t, errVerKeysNotSupported, err)
+
+	_, err = p.PackWithKeyIDs([]byte("payload"), "did:example:alice#key-1", nil)
+	require.Equal(t, errKeyIDsNotSupported, err)
+
+	// envelopes signed with key IDs can't be verified without VDRI registry
+	alice := newAgent(t, "did:example:alice", newDIDs())
+	kid := alice.addKey(t, kms.ED25519Type)
+
+	packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), kid, nil)
+	require.NoError(t, err)
+
+	_, err = p.Unpack(packed)
+	require.EqualError(t, err, "unpack: "+errKeyIDsNotSupported.Error())
+}
+
+func TestPackUnpack_VerKey(t *testing.T) {
+	alice := newAgent(t, "did:example:alice", newDIDs())
+
+	_, verKey, err := alice.legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	payload := []byte(`{"@id":"1234","@type":"https://didcomm.org/issue-credential/1.0/offer-credential"}`)
+
+	// the recipients are ignored, signed envelopes aren't encrypted
+	packed, err := alice.packer.Pack(payload, base58.Decode(verKey), [][]byte{[]byte("recipient")})
+	require.NoError(t, err)
+
+	headers := envelopeHeaders(t, packed)
+	require.Equal(t, SignedMediaType, headers.Typ)
+	require.Equal(t, EdDSA, headers.Alg)
+	require.Equal(t, verKey, headers.KID)
+
+	// anyone can verify the envelope
+	bob := newAgent(t, "did:example:bob", newDIDs())
+
+	env, err := bob.packer.Unpack(packed)
+	require.NoError(t, err)
+	require.Equal(t, payload, env.Message)
+	require.Equal(t, base58.Decode(verKey), env.FromVerKey)
+	require.Empty(t, env.FromKID)
+
+	_, err = alice.packer.Pack(payload, nil, nil)
+	require.EqualError(t, err, "failed to pack message: empty sender key")
+
+	_, err = alice.packer.Pack(payload, []byte("unknown"), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to pack message: sign")
+}
+
+func TestPackUnpack_KeyID(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+
+	for _, tc := range []struct {
+		keyType kms.KeyType
+		alg     string
+	}{{kms.ED25519Type, EdDSA}, {kms.ECDSAP256Type, ES256}} {
+		tc := tc
+
+		t.Run(string(tc.keyType), func(t *testing.T) {
+			kid := alice.addKey(t, tc.keyType)
+
+			payload := []byte(`{"id":"1234","type":"https://didcomm.org/basicmessage/2.0/message"}`)
+
+			packed, err := alice.packer.PackWithKeyIDs(payload, kid, []string{"did:example:bob#key-1"})
+			require.NoError(t, err)
+
+			headers := envelopeHeaders(t, packed)
+			require.Equal(t, SignedMediaType, headers.Typ)
+			require.Equal(t, tc.alg, headers.Alg)
+			require.Equal(t, kid, headers.KID)
+
+			env, err := bob.packer.Unpack(packed)
+			require.NoError(t, err)
+			require.Equal(t, payload, env.Message)
+			require.Equal(t, kid, env.FromKID)
+			require.Empty(t, env.FromVerKey)
+		})
+	}
+
+	t.Run("Ed25519 JWK", func(t *testing.T) {
+		keyID, _, err := alice.kms.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pub, err := alice.kms.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		pk, err := did.NewPublicKeyFromJWK("#"+keyID, "JsonWebKey2020", alice.doc.ID, &josejwk.JWK{
+			JSONWebKey: jose.JSONWebKey{Key: ed25519.PublicKey(pub)},
+			Kty:        "OKP",
+			Crv:        "Ed25519",
+		})
+		require.NoError(t, err)
+
+		alice.doc.AssertionMethod = append(alice.doc.AssertionMethod, did.VerificationMethod{PublicKey: *pk})
+
+		packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), alice.doc.ID+"#"+keyID, nil)
+		require.NoError(t, err)
+
+		env, err := bob.packer.Unpack(packed)
+		require.NoError(t, err)
+		require.Equal(t, []byte("payload"), env.Message)
+	})
+
+	t.Run("tampered envelope", func(t *testing.T) {
+		kid := alice.addKey(t, kms.ED25519Type)
+
+		packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), kid, nil)
+		require.NoError(t, err)
+
+		jws := &Envelope{}
+		require.NoError(t, json.Unmarshal(packed, jws))
+
+		jws.Payload = base64.RawURLEncoding.EncodeToString([]byte("tampered"))
+
+		tampered, err := json.Marshal(jws)
+		require.NoError(t, err)
+
+		_, err = bob.packer.Unpack(tampered)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unpack: invalid signature")
+	})
+}
+
+func TestPack_Errors(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+
+	_, err := alice.packer.PackWithKeyIDs([]byte("payload"), "", nil)
+	require.EqualError(t, err, "failed to pack message: empty sender key ID")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice", nil)
+	require.EqualError(t, err, "failed to pack message: sender key: key ID 'did:example:alice' is not a DID URL "+
+		"with a fragment")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice#unknown", nil)
+	require.EqualError(t, err, "failed to pack message: sender key: key did:example:alice#unknown: "+
+		"public key not found in DID document")
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:dave#key", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "resolve DID did:example:dave")
+
+	alice.doc.PublicKey = append(alice.doc.PublicKey, did.PublicKey{ID: "#rsa", Type: "RsaVerificationKey2018"})
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice#rsa", nil)
+	require.EqualError(t, err, "failed to pack message: sender key: key did:example:alice#rsa: "+
+		"unsupported public key type RsaVerificationKey2018")
+
+	// the public key is in the DID document but the private key isn't in the KMS
+	alice.doc.PublicKey = append(alice.doc.PublicKey,
+		*did.NewPublicKeyFromBytes("#other", ed25519KeyType, alice.doc.ID, make([]byte, ed25519.PublicKeySize)))
+
+	_, err = alice.packer.PackWithKeyIDs([]byte("payload"), "did:example:alice#other", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to pack message: sender key")
+}
+
+func TestUnpack_Errors(t *testing.T) {
+	dids := newDIDs()
+
+	alice := newAgent(t, "did:example:alice", dids)
+	bob := newAgent(t, "did:example:bob", dids)
+
+	kid := alice.addKey(t, kms.ED25519Type)
+
+	packed, err := alice.packer.PackWithKeyIDs([]byte("payload"), kid, nil)
+	require.NoError(t, err)
+
+	_, err = bob.packer.Unpack([]byte("{"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unpack json")
+
+	_, err = bob.packer.Unpack([]byte(`{"payload":"cGF5bG9hZA"}`))
+	require.EqualError(t, err, "unpack: missing signature")
+
+	tests := []struct {
+		name   string
+		mutate func(jws *Envelope, headers *jwsHeaders)
+		errMsg string
+	}{
+		{
+			name:   "missing kid",
+			mutate: func(_ *Envelope, headers *jwsHeaders) { headers.KID = "" },
+			errMsg: "unpack: missing kid header",
+		},
+		{
+			name:   "unknown signer",
+			mutate: func(_ *Envelope, headers *jwsHeaders) { headers.KID = "did:example:alice#unknown" },
+			errMsg: "unpack: signer key: key did:example:alice#unknown: public key not found",
+		},
+		{
+			name:   "algorithm mismatch",
+			mutate: func(_ *Envelope, headers *jwsHeaders) { headers.Alg = ES256 },
+			errMsg: "unpack: signature algorithm ES256 doesn't match the key algorithm EdDSA",
+		},
+		{
+			name:   "bad verification key",
+			mutate: func(_ *Envelope, headers *jwsHeaders) { headers.KID = "abc" },
+			errMsg: "unpack: unsupported verification key abc with algorithm EdDSA",
+		},
+		{
+			name:   "bad payload",
+			mutate: func(jws *Envelope, _ *jwsHeaders) { jws.Payload = "!" },
+			errMsg: "unpack: payload",
+		},
+		{
+			name:   "bad signature",
+			mutate: func(jws *Envelope, _ *jwsHeaders) { jws.Signatures[0].Signature = "!" },
+			errMsg: "unpack: signature",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			jws := &Envelope{}
+			require.NoError(t, json.Unmarshal(packed, jws))
+
+			headers := envelopeHeaders(t, packed)
+
+			tc.mutate(jws, headers)
+
+			protected, e := json.Marshal(headers)
+			require.NoError(t, e)
+
+			jws.Signatures[0].Protected = base64.RawURLEncoding.EncodeToString(protected)
+
+			mutated, e := json.Marshal(jws)
+			require.NoError(t, e)
+
+			_, e = bob.packer.Unpack(mutated)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), tc.errMsg)
+		})
+	}
+}
+
+func TestSignatureKey_Errors(t *testing.T) {
+	x, y := elliptic.P384().ScalarBaseMult(big.NewInt(42).Bytes())
+
+	pk, err := did.NewPublicKeyFromJWK("#key-1", "JsonWebKey2020", "did:example:alice", &josejwk.JWK{
+		JSONWebKey: jose.JSONWebKey{Key: &ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}},
+		Kty:        "EC",
+		Crv:        "P-384",
+	})
+	require.NoError(t, err)
+
+	_, _, err = signatureKey(pk)
+	require.EqualError(t, err, "unsupported curve P-384")
+
+	_, err = signatureVerifier("RS256")
+	require.EqualError(t, err, "unsupported signature algorithm RS256")
+
+	_, err = rawECDSASignature([]byte("not DER"))
+	require.Error(t, err)
+}
+
+func TestRawECDSASignature(t *testing.T) {
+	r, s := big.NewInt(42), new(big.Int).Lsh(big.NewInt(1), 255)
+
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	require.NoError(t, err)
+
+	raw := make([]byte, 2*p256KeySize)
+	raw[p256KeySize-1] = 42
+	raw[p256KeySize] = 0x80
+
+	sig, err := rawECDSASignature(der)
+	require.NoError(t, err)
+	require.Equal(t, raw, sig)
+
+	// raw signatures are already in the JWS format
+	sig, err = rawECDSASignature(raw)
+	require.NoError(t, err)
+	require.Equal(t, raw, sig)
+
+	// even when they happen to be valid DER
+	derLike, err := asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).Lsh(big.NewInt(1), 225), new(big.Int).Lsh(big.NewInt(1), 225),
+	})
+	require.NoError(t, err)
+	require.Len(t, derLike, 2*p256KeySize)
+
+	sig, err = rawECDSASignature(derLike)
+	require.NoError(t, err)
+	require.Equal(t, derLike, sig)
+
+	_, err = rawECDSASignature(append(der, 0))
+	require.EqualError(t, err, "parse ECDSA signature: trailing data")
+
+	_, err = rawECDSASignature(raw[1:])
+	require.Error(t, err)
+
+	der, err = asn1.Marshal(struct{ R, S *big.Int }{r, new(big.Int).Lsh(big.NewInt(1), 256)})
+	require.NoError(t, err)
+
+	_, err = rawECDSASignature(der)
+	require.EqualError(t, err, "invalid ECDSA signature size")
+}
+
+func newDIDs() map[string]*did.Doc {
+	return map[string]*did.Doc{}
+}
+
+func newLegacyKMS(t *testing.T) *legacykms.BaseKMS {
+	t.Helper()
+
+	legacyKMS, err := legacykms.New(&mockprovider.Provider{StorageProviderValue: mockstorage.NewMockStoreProvider()})
+	require.NoError(t, err)
+
+	return legacyKMS
+}
+
+// newAgent creates the packer of a party with its own KMSs, resolving the DIDs of dids
+func newAgent(t *testing.T, didID string, dids map[string]*did.Doc) *agent {
+	t.Helper()
+
+	legacyKMS := newLegacyKMS(t)
+
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	tinkCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	registry := &mockvdri.MockVDRIRegistry{
+		ResolveFunc: func(didID string, _ ...vdriapi.ResolveOpts) (*did.Doc, error) {
+			doc, ok := dids[didID]
+			if !ok {
+				return nil, vdriapi.ErrNotFound
+			}
+
+			return doc, nil
+		},
+	}
+
+	ctx, err := context.New(context.WithLegacyKMS(legacyKMS), context.WithKMS(localKMS),
+		context.WithCrypto(tinkCrypto), context.WithVDRIRegistry(registry))
+	require.NoError(t, err)
+
+	p, err := New(ctx)
+	require.NoError(t, err)
+
+	doc := &did.Doc{ID: didID}
+	dids[didID] = doc
+
+	return &agent{packer: p, kms: localKMS, legacyKMS: legacyKMS, doc: doc}
+}
+
+// addKey creates a signing key of the agent and adds it to its DID document, returning its key ID
+func (a *agent) addKey(t *testing.T, keyType kms.KeyType) string {
+	t.Helper()
+
+	keyID, kh, err := a.kms.Create(keyType)
+	require.NoError(t, err)
+
+	switch keyType {
+	case kms.ED25519Type:
+		pub, e := a.kms.ExportPubKeyBytes(keyID)
+		require.NoError(t, e)
+
+		a.doc.PublicKey = append(a.doc.PublicKey,
+			*did.NewPublicKeyFromBytes("#"+keyID, ed25519KeyType, a.doc.ID, pub))
+	default:
+		pub, e := tinkcrypto.ExportPublicKey(kh)
+		require.NoError(t, e)
+
+		pk, e := did.NewPublicKeyFromJWK(a.doc.ID+"#"+keyID, "JsonWebKey2020", a.doc.ID, &josejwk.JWK{
+			JSONWebKey: jose.JSONWebKey{Key: &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(pub.X),
+				Y:     new(big.Int).SetBytes(pub.Y),
+			}},
+			Kty: "EC",
+			Crv: "P-256",
+		})
+		require.NoError(t, e)
+
+		a.doc.Authentication = append(a.doc.Authentication, did.VerificationMethod{PublicKey: *pk})
+	}
+
+	return fmt.Sprintf("%s#%s", a.doc.ID, keyID)
+}
+
+func envelopeHeaders(t *testing.T, packed []byte) *jwsHeaders {
+	t.Helper()
+
+	jws := &Envelope{}
+	require.NoError(t, json.Unmarshal(packed, jws))
+	require.Len(t, jws.Signatures, 1)
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Signatures[0].Protected)
+	require.NoError(t, err)
+
+	headers := &jwsHeaders{}
+	require.NoError(t, json.Unmarshal(protected, headers))
+
+	return headers
+}
@@ -0,0 +1,123 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jws
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+)
+
+// Unpack will verify the first signature of the signed envelope with the key of its `kid` header, a DID URL
+// resolved from the DID document of the sender or a base58 Ed25519 verification key.
+// The returned envelope holds the signing key, as a key ID or a verification key.
+func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
+	jws := &Envelope{}
+
+	err := json.Unmarshal(envelope, jws)
+	if err != nil {
+		return nil, fmt.Errorf("unpack json: %w", err)
+	}
+
+	if len(jws.Signatures) == 0 {
+		return nil, errors.New("unpack: missing signature")
+	}
+
+	sig := jws.Signatures[0]
+
+	headers, err := decodeHeaders(sig.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: %w", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: payload: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("unpack: signature: %w", err)
+	}
+
+	signingInput := []byte(sig.Protected + "." + jws.Payload)
+
+	if isKeyID(headers.KID) {
+		err = p.verifyWithKeyID(headers, signingInput, signature)
+		if err != nil {
+			return nil, fmt.Errorf("unpack: %w", err)
+		}
+
+		return &transport.Envelope{Message: payload, FromKID: headers.KID}, nil
+	}
+
+	verKey := base58.Decode(headers.KID)
+
+	if headers.Alg != EdDSA || len(verKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("unpack: unsupported verification key %s with algorithm %s", headers.KID, headers.Alg)
+	}
+
+	if !ed25519.Verify(verKey, signingInput, signature) {
+		return nil, errors.New("unpack: invalid signature")
+	}
+
+	return &transport.Envelope{Message: payload, FromVerKey: verKey}, nil
+}
+
+func decodeHeaders(protected string) (*jwsHeaders, error) {
+	h, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := &jwsHeaders{}
+
+	err = json.Unmarshal(h, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if headers.KID == "" {
+		return nil, errors.New("missing kid header")
+	}
+
+	return headers, nil
+}
+
+// verifyWithKeyID verifies the signature of signingInput with the public key of the DID URL `kid` header
+func (p *Packer) verifyWithKeyID(headers *jwsHeaders, signingInput, signature []byte) error {
+	if p.vdriRegistry == nil {
+		return errKeyIDsNotSupported
+	}
+
+	pubKey, alg, err := p.verificationKey(headers.KID)
+	if err != nil {
+		return fmt.Errorf("signer key: %w", err)
+	}
+
+	if headers.Alg != alg {
+		return fmt.Errorf("signature algorithm %s doesn't match the key algorithm %s", headers.Alg, alg)
+	}
+
+	verifier, err := signatureVerifier(alg)
+	if err != nil {
+		return err
+	}
+
+	err = verifier.Verify(pubKey, signingInput, signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
@@ -88,6 +88,9 @@ type metaData struct {
 	participants []*participant
 	rejected     bool
 	inbound      bool
+	// fromAction is set for the message of a continued or stopped action, its transitional payload is deleted once
+	// the state it led to is saved
+	fromAction bool
 	// err is used to determine whether callback was stopped
 	// e.g the user received an action event and executes Stop(err) function
 	// in that case `err` is equal to `err` which was passing to Stop function
@@ -169,6 +172,8 @@ func (s *Service) startInternalListener() {
 			if err := s.handle(msg); err != nil {
 				logger.Errorf("listener handle: %s", err)
 			}
+
+			s.deleteTransitionalPayload(msg)
 		case event := <-s.didEvent:
 			if err := s.InvitationReceived(event); err != nil {
 				logger.Errorf("listener invitation received: %s", err)
@@ -177,6 +182,22 @@ func (s *Service) startInternalListener() {
 	}
 }
 
+// deleteTransitionalPayload deletes the transitional payload of the action md comes from once the action failed,
+// even to be abandoned, since it can't be continued anymore
+func (s *Service) deleteTransitionalPayload(md *metaData) {
+	if !md.fromAction {
+		return
+	}
+
+	if err := s.store.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID)); err != nil {
+		logger.Errorf("delete transitional payload: %s", err)
+
+		return
+	}
+
+	md.fromAction = false
+}
+
 func logInternalError(err error) {
 	if _, ok := err.(customError); !ok {
 		logger.Errorf("go to abandoning: %v", err)
@@ -325,6 +346,7 @@ func (s *Service) newDIDCommActionMsg(md *metaData) service.DIDCommAction {
 		}
 
 		md.err = err
+		md.fromAction = true
 		s.processCallback(md)
 	}
 
@@ -342,10 +364,7 @@ func (s *Service) newDIDCommActionMsg(md *metaData) service.DIDCommAction {
 				}
 			}
 
-			if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-				logger.Errorf("delete transitional payload", err)
-			}
-
+			md.fromAction = true
 			s.processCallback(md)
 		},
 		Stop: func(err error) { actionStop(customError{error: err}) },
@@ -363,6 +382,7 @@ func (s *Service) Continue(piID string, opt Opt) error {
 		transitionalPayload: *tPayload,
 		state:               stateFromName(tPayload.StateName),
 		msgClone:            tPayload.Msg.Clone(),
+		fromAction:          true,
 		inbound:             true,
 	}
 
@@ -376,10 +396,6 @@ func (s *Service) Continue(piID string, opt Opt) error {
 		}
 	}
 
-	if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-		logger.Errorf("delete transitional payload", err)
-	}
-
 	s.processCallback(md)
 
 	return nil
@@ -451,17 +467,13 @@ func (s *Service) Actions() ([]Action, error) {
 	return actions, nil
 }
 
-func (s *Service) deleteTransitionalPayload(id string) error {
-	return s.store.Delete(fmt.Sprintf(transitionalPayloadKey, id))
-}
-
 func (s *Service) saveTransitionalPayload(id string, data transitionalPayload) error {
 	src, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal transitional payload: %w", err)
 	}
 
-	return s.store.Put(fmt.Sprintf(transitionalPayloadKey, id), src)
+	return storage.PutWithTTL(s.store, fmt.Sprintf(transitionalPayloadKey, id), src, service.TransitionalPayloadTTL)
 }
 
 func (s *Service) getTransitionalPayload(id string) (*transitionalPayload, error) {
@@ -484,6 +496,26 @@ func (s *Service) saveStateName(piID, stateName string) error {
 	return s.store.Put(stateNameKey+piID, []byte(stateName))
 }
 
+// saveState saves the state of the protocol instance of md, along with the removal of the transitional payload of
+// the action md comes from in one batch
+func (s *Service) saveState(md *metaData, stateName string) error {
+	if !md.fromAction {
+		return s.saveStateName(md.PIID, stateName)
+	}
+
+	batch := storage.NewBatch(s.store)
+	batch.Put(stateNameKey+md.PIID, []byte(stateName))
+	batch.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID))
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	md.fromAction = false
+
+	return nil
+}
+
 // nolint: gocyclo
 // stateFromName returns the state by given name.
 func stateFromName(name string) state {
@@ -565,7 +597,7 @@ func (s *Service) handle(md *metaData) error {
 		current = next
 	}
 
-	if err := s.saveStateName(md.PIID, stateName); err != nil {
+	if err := s.saveState(md, stateName); err != nil {
 		return fmt.Errorf("failed to persist state %s: %w", stateName, err)
 	}
 
@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
@@ -56,7 +57,7 @@ func agentSetup(agent string, t *testing.T, ctrl *gomock.Controller, tr map[stri
 	outbound := dispatcherMocks.NewMockOutbound(ctrl)
 	outbound.EXPECT().
 		SendToDID(gomock.Any(), gomock.Any(), gomock.Any()).
-		DoAndReturn(func(msg interface{}, myDID, theirDID string) error {
+		DoAndReturn(func(msg interface{}, myDID, theirDID string, _ ...dispatcher.SendOpts) error {
 			src, err := json.Marshal(msg)
 			require.NoError(t, err)
 
@@ -381,6 +382,98 @@ func TestService_ProposalContinue(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestService_FailedActionDeletesTransitionalPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deleted := make(chan struct{})
+
+	store := storageMocks.NewMockStore(ctrl)
+	store.EXPECT().Get("transitionalPayload_piID").
+		Return([]byte(`{"PIID":"piID","StateName":"done","Msg":{"@id":"ID"}}`), nil)
+	// the state of the action can't be saved, even to abandon it
+	store.EXPECT().Put(gomock.Any(), gomock.Any()).Return(errors.New("put error")).AnyTimes()
+	store.EXPECT().Delete("transitionalPayload_piID").DoAndReturn(func(string) error {
+		close(deleted)
+
+		return nil
+	})
+
+	storageProvider := storageMocks.NewMockProvider(ctrl)
+	storageProvider.EXPECT().OpenStore(introduce.Introduce).Return(store, nil)
+
+	didService := serviceMocks.NewMockDIDComm(ctrl)
+	didService.EXPECT().RegisterMsgEvent(gomock.Any()).Return(nil)
+
+	provider := introduceMocks.NewMockProvider(ctrl)
+	provider.EXPECT().StorageProvider().Return(storageProvider)
+	provider.EXPECT().Messenger().Return(serviceMocks.NewMockMessenger(ctrl))
+	provider.EXPECT().Service(didexchange.DIDExchange).Return(didService, nil)
+
+	svc, err := introduce.New(provider)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Continue("piID", nil))
+
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		require.Fail(t, "transitional payload not deleted")
+	}
+}
+
+func TestService_ContinueRemovesTransitionalPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	didService := serviceMocks.NewMockDIDComm(ctrl)
+	didService.EXPECT().RegisterMsgEvent(gomock.Any()).Return(nil)
+
+	replied := make(chan struct{})
+
+	provider := introduceMocks.NewMockProvider(ctrl)
+	provider.EXPECT().StorageProvider().Return(mem.NewProvider())
+	provider.EXPECT().Service(didexchange.DIDExchange).Return(didService, nil)
+
+	var svc *introduce.Service
+
+	messenger := serviceMocks.NewMockMessenger(ctrl)
+	messenger.EXPECT().ReplyTo(gomock.Any(), gomock.Any()).DoAndReturn(func(string, service.DIDCommMsgMap) error {
+		defer close(replied)
+
+		// the transitional payload was removed along with the saving of the state of the continued action
+		actions, err := svc.Actions()
+		require.NoError(t, err)
+		require.Empty(t, actions)
+
+		return nil
+	})
+	provider.EXPECT().Messenger().Return(messenger)
+
+	svc, err := introduce.New(provider)
+	require.NoError(t, err)
+
+	ch := make(chan service.DIDCommAction, 1)
+	require.NoError(t, svc.RegisterActionEvent(ch))
+
+	piID, err := svc.HandleInbound(introduce.CreateProposal(&introduce.To{Name: Carol}), Bob, Alice)
+	require.NoError(t, err)
+
+	actions, err := svc.Actions()
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+
+	require.NoError(t, svc.Continue(piID, introduce.WithInvitation(&didexchange.Invitation{
+		Type: didexchange.InvitationMsgType,
+	})))
+
+	select {
+	case <-replied:
+	case <-time.After(time.Second):
+		require.Fail(t, "response not sent")
+	}
+}
+
 // Bob received proposal from Alice
 // Carol received proposal from Alice
 // Alice received response from Bob
@@ -73,6 +73,9 @@ type metaData struct {
 	offerCredential   *OfferCredential
 	proposeCredential *ProposeCredential
 	issueCredential   *IssueCredential
+	// fromAction is set for the message of a continued or stopped action, its transitional payload is deleted once
+	// the state it led to is saved
+	fromAction bool
 	// err is used to determine whether callback was stopped
 	// e.g the user received an action event and executes Stop(err) function
 	// in that case `err` is equal to `err` which was passing to Stop function
@@ -278,7 +281,25 @@ func (s *Service) startInternalListener() {
 		if err := s.handle(msg); err != nil {
 			logger.Errorf("listener handle: %s", err)
 		}
+
+		s.deleteTransitionalPayload(msg)
+	}
+}
+
+// deleteTransitionalPayload deletes the transitional payload of the action md comes from once the action failed,
+// even to be abandoned, since it can't be continued anymore
+func (s *Service) deleteTransitionalPayload(md *metaData) {
+	if !md.fromAction {
+		return
+	}
+
+	if err := s.store.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID)); err != nil {
+		logger.Errorf("delete transitional payload: %s", err)
+
+		return
 	}
+
+	md.fromAction = false
 }
 
 func isNoOp(s state) bool {
@@ -310,7 +331,7 @@ func (s *Service) handle(md *metaData) error {
 		current = next
 	}
 
-	if err := s.saveStateName(md.PIID, stateName); err != nil {
+	if err := s.saveState(md, stateName); err != nil {
 		return fmt.Errorf("failed to persist state %s: %w", stateName, err)
 	}
 
@@ -335,6 +356,26 @@ func (s *Service) saveStateName(piID, stateName string) error {
 	return s.store.Put(stateNameKey+piID, []byte(stateName))
 }
 
+// saveState saves the state of the protocol instance of md, along with the removal of the transitional payload of
+// the action md comes from in one batch
+func (s *Service) saveState(md *metaData, stateName string) error {
+	if !md.fromAction {
+		return s.saveStateName(md.PIID, stateName)
+	}
+
+	batch := storage.NewBatch(s.store)
+	batch.Put(stateNameKey+md.PIID, []byte(stateName))
+	batch.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID))
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	md.fromAction = false
+
+	return nil
+}
+
 func (s *Service) currentStateName(piID string) (string, error) {
 	src, err := s.store.Get(stateNameKey + piID)
 	if errors.Is(err, storage.ErrDataNotFound) {
@@ -413,7 +454,7 @@ func (s *Service) saveTransitionalPayload(id string, data transitionalPayload) e
 		return fmt.Errorf("marshal transitional payload: %w", err)
 	}
 
-	return s.store.Put(fmt.Sprintf(transitionalPayloadKey, id), src)
+	return storage.PutWithTTL(s.store, fmt.Sprintf(transitionalPayloadKey, id), src, service.TransitionalPayloadTTL)
 }
 
 // canTriggerActionEvents checks if the incoming message can trigger an action event
@@ -440,10 +481,6 @@ func (s *Service) getTransitionalPayload(id string) (*transitionalPayload, error
 	return t, err
 }
 
-func (s *Service) deleteTransitionalPayload(id string) error {
-	return s.store.Delete(fmt.Sprintf(transitionalPayloadKey, id))
-}
-
 // ActionContinue allows proceeding with the action by the piID
 func (s *Service) ActionContinue(piID string, opt Opt) error {
 	tPayload, err := s.getTransitionalPayload(piID)
@@ -455,6 +492,7 @@ func (s *Service) ActionContinue(piID string, opt Opt) error {
 		transitionalPayload: *tPayload,
 		state:               stateFromName(tPayload.StateName),
 		msgClone:            tPayload.Msg.Clone(),
+		fromAction:          true,
 		verifiable:          s.verifiable,
 		inbound:             true,
 	}
@@ -463,10 +501,6 @@ func (s *Service) ActionContinue(piID string, opt Opt) error {
 		opt(md)
 	}
 
-	if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-		return fmt.Errorf("delete transitional payload: %w", err)
-	}
-
 	s.processCallback(md)
 
 	return nil
@@ -483,14 +517,11 @@ func (s *Service) ActionStop(piID string, cErr error) error {
 		transitionalPayload: *tPayload,
 		state:               stateFromName(tPayload.StateName),
 		msgClone:            tPayload.Msg.Clone(),
+		fromAction:          true,
 		verifiable:          s.verifiable,
 		inbound:             true,
 	}
 
-	if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-		return fmt.Errorf("delete transitional payload: %w", err)
-	}
-
 	md.err = customError{error: cErr}
 	s.processCallback(md)
 
@@ -541,17 +572,11 @@ func (s *Service) newDIDCommActionMsg(md *metaData) service.DIDCommAction {
 				fn(md)
 			}
 
-			if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-				logger.Errorf("delete transitional payload", err)
-			}
-
+			md.fromAction = true
 			s.processCallback(md)
 		},
 		Stop: func(cErr error) {
-			if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-				logger.Errorf("delete transitional payload", err)
-			}
-
+			md.fromAction = true
 			md.err = customError{error: cErr}
 			s.processCallback(md)
 		},
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -32,6 +33,9 @@ const (
 
 	// TODO channel size - https://github.com/hyperledger/aries-framework-go/issues/246
 	callbackChannelSize = 10
+
+	// stateTTL is how long the state of a request is kept while waiting for its connection to complete
+	stateTTL = 7 * 24 * time.Hour
 )
 
 var logger = log.New(fmt.Sprintf("aries-framework/%s/service", Name))
@@ -334,7 +338,7 @@ func (s *Service) save(state *myState) error {
 		return fmt.Errorf("failed to save state=%+v : %w", state, err)
 	}
 
-	err = s.store.Put(state.ID, bytes)
+	err = storage.PutWithTTL(s.store, state.ID, bytes, stateTTL)
 	if err != nil {
 		return fmt.Errorf("failed to save state : %w", err)
 	}
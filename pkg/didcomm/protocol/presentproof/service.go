@@ -67,6 +67,9 @@ type metaData struct {
 	proposePresentation *ProposePresentation
 	request             *RequestPresentation
 	registryVDRI        vdri.Registry
+	// fromAction is set for the message of a continued or stopped action, its transitional payload is deleted once
+	// the state it led to is saved
+	fromAction bool
 	// err is used to determine whether callback was stopped
 	// e.g the user received an action event and executes Stop(err) function
 	// in that case `err` is equal to `err` which was passing to Stop function
@@ -255,7 +258,25 @@ func (s *Service) startInternalListener() {
 		if err := s.handle(msg); err != nil {
 			logger.Errorf("listener handle: %s", err)
 		}
+
+		s.deleteTransitionalPayload(msg)
+	}
+}
+
+// deleteTransitionalPayload deletes the transitional payload of the action md comes from once the action failed,
+// even to be abandoned, since it can't be continued anymore
+func (s *Service) deleteTransitionalPayload(md *metaData) {
+	if !md.fromAction {
+		return
+	}
+
+	if err := s.store.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID)); err != nil {
+		logger.Errorf("delete transitional payload: %s", err)
+
+		return
 	}
+
+	md.fromAction = false
 }
 
 func isNoOp(s state) bool {
@@ -276,7 +297,7 @@ func (s *Service) handle(md *metaData) error {
 			return fmt.Errorf("invalid state transition: %s --> %s", current.Name(), next.Name())
 		}
 
-		if err := s.saveStateName(md.PIID, current.Name()); err != nil {
+		if err := s.saveState(md, current.Name()); err != nil {
 			return fmt.Errorf("failed to persist state %s: %w", current.Name(), err)
 		}
 
@@ -302,6 +323,26 @@ func (s *Service) saveStateName(piID, stateName string) error {
 	return s.store.Put(stateNameKey+piID, []byte(stateName))
 }
 
+// saveState saves the state of the protocol instance of md, along with the removal of the transitional payload of
+// the action md comes from in one batch
+func (s *Service) saveState(md *metaData, stateName string) error {
+	if !md.fromAction {
+		return s.saveStateName(md.PIID, stateName)
+	}
+
+	batch := storage.NewBatch(s.store)
+	batch.Put(stateNameKey+md.PIID, []byte(stateName))
+	batch.Delete(fmt.Sprintf(transitionalPayloadKey, md.PIID))
+
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	md.fromAction = false
+
+	return nil
+}
+
 func (s *Service) currentStateName(piID string) (string, error) {
 	src, err := s.store.Get(stateNameKey + piID)
 	if errors.Is(err, storage.ErrDataNotFound) {
@@ -372,7 +413,7 @@ func (s *Service) saveTransitionalPayload(id string, data transitionalPayload) e
 		return fmt.Errorf("marshal transitional payload: %w", err)
 	}
 
-	return s.store.Put(fmt.Sprintf(transitionalPayloadKey, id), src)
+	return storage.PutWithTTL(s.store, fmt.Sprintf(transitionalPayloadKey, id), src, service.TransitionalPayloadTTL)
 }
 
 // canTriggerActionEvents checks if the incoming message can trigger an action event
@@ -398,10 +439,6 @@ func (s *Service) getTransitionalPayload(id string) (*transitionalPayload, error
 	return t, err
 }
 
-func (s *Service) deleteTransitionalPayload(id string) error {
-	return s.store.Delete(fmt.Sprintf(transitionalPayloadKey, id))
-}
-
 // Actions returns actions for the async usage
 func (s *Service) Actions() ([]Action, error) {
 	records := s.store.Iterator(
@@ -439,6 +476,7 @@ func (s *Service) ActionContinue(piID string, opt Opt) error {
 		transitionalPayload: *tPayload,
 		state:               stateFromName(tPayload.StateName),
 		msgClone:            tPayload.Msg.Clone(),
+		fromAction:          true,
 		registryVDRI:        s.registryVDRI,
 	}
 
@@ -446,10 +484,6 @@ func (s *Service) ActionContinue(piID string, opt Opt) error {
 		opt(md)
 	}
 
-	if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-		return fmt.Errorf("delete transitional payload: %w", err)
-	}
-
 	s.processCallback(md)
 
 	return nil
@@ -466,13 +500,10 @@ func (s *Service) ActionStop(piID string, cErr error) error {
 		transitionalPayload: *tPayload,
 		state:               stateFromName(tPayload.StateName),
 		msgClone:            tPayload.Msg.Clone(),
+		fromAction:          true,
 		registryVDRI:        s.registryVDRI,
 	}
 
-	if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-		return fmt.Errorf("delete transitional payload: %w", err)
-	}
-
 	md.err = customError{error: cErr}
 	s.processCallback(md)
 
@@ -497,17 +528,11 @@ func (s *Service) newDIDCommActionMsg(md *metaData) service.DIDCommAction {
 				fn(md)
 			}
 
-			if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-				logger.Errorf("continue: delete transitional payload: %v", err)
-			}
-
+			md.fromAction = true
 			s.processCallback(md)
 		},
 		Stop: func(cErr error) {
-			if err := s.deleteTransitionalPayload(md.PIID); err != nil {
-				logger.Errorf("stop: delete transitional payload: %v", err)
-			}
-
+			md.fromAction = true
 			md.err = customError{error: cErr}
 			s.processCallback(md)
 		},
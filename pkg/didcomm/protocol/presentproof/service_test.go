@@ -88,31 +88,43 @@ func TestService_ActionContinue(t *testing.T) {
 		err = svc.ActionContinue("piID", nil)
 		require.Contains(t, fmt.Sprintf("%v", err), "get transitional payload: store get: "+errMsg)
 	})
+}
 
-	t.Run("Error transitional payload (delete)", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
+func TestService_FailedActionDeletesTransitionalPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-		const errMsg = "error"
+	deleted := make(chan struct{})
 
-		store := storageMocks.NewMockStore(ctrl)
-		store.EXPECT().Get(gomock.Any()).Return([]byte(`{}`), nil)
-		store.EXPECT().Delete(gomock.Any()).Return(errors.New(errMsg))
+	store := storageMocks.NewMockStore(ctrl)
+	store.EXPECT().Get(fmt.Sprintf(transitionalPayloadKey, "piID")).
+		Return([]byte(`{"PIID":"piID","StateName":"done","Msg":{"@id":"ID"}}`), nil)
+	// the state of the action can't be saved, even to abandon it
+	store.EXPECT().Put(gomock.Any(), gomock.Any()).Return(errors.New("put error")).AnyTimes()
+	store.EXPECT().Delete(fmt.Sprintf(transitionalPayloadKey, "piID")).DoAndReturn(func(string) error {
+		close(deleted)
+
+		return nil
+	})
 
-		storeProvider := storageMocks.NewMockProvider(ctrl)
-		storeProvider.EXPECT().OpenStore(Name).Return(store, nil).AnyTimes()
+	storeProvider := storageMocks.NewMockProvider(ctrl)
+	storeProvider.EXPECT().OpenStore(gomock.Any()).Return(store, nil).AnyTimes()
 
-		provider := presentproofMocks.NewMockProvider(ctrl)
-		provider.EXPECT().Messenger().Return(nil)
-		provider.EXPECT().StorageProvider().Return(storeProvider)
-		provider.EXPECT().VDRIRegistry().Return(nil).AnyTimes()
+	provider := presentproofMocks.NewMockProvider(ctrl)
+	provider.EXPECT().Messenger().Return(serviceMocks.NewMockMessenger(ctrl))
+	provider.EXPECT().StorageProvider().Return(storeProvider).AnyTimes()
+	provider.EXPECT().VDRIRegistry().Return(nil)
 
-		svc, err := New(provider)
-		require.NoError(t, err)
+	svc, err := New(provider)
+	require.NoError(t, err)
 
-		err = svc.ActionContinue("piID", nil)
-		require.Contains(t, fmt.Sprintf("%v", err), "delete transitional payload: "+errMsg)
-	})
+	require.NoError(t, svc.ActionContinue("piID", nil))
+
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		require.Fail(t, "transitional payload not deleted")
+	}
 }
 
 func TestService_ActionStop(t *testing.T) {
@@ -139,31 +151,6 @@ func TestService_ActionStop(t *testing.T) {
 		err = svc.ActionStop("piID", nil)
 		require.Contains(t, fmt.Sprintf("%v", err), "get transitional payload: store get: "+errMsg)
 	})
-
-	t.Run("Error transitional payload (delete)", func(t *testing.T) {
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
-
-		const errMsg = "error"
-
-		store := storageMocks.NewMockStore(ctrl)
-		store.EXPECT().Get(gomock.Any()).Return([]byte(`{}`), nil)
-		store.EXPECT().Delete(gomock.Any()).Return(errors.New(errMsg))
-
-		storeProvider := storageMocks.NewMockProvider(ctrl)
-		storeProvider.EXPECT().OpenStore(Name).Return(store, nil).AnyTimes()
-
-		provider := presentproofMocks.NewMockProvider(ctrl)
-		provider.EXPECT().Messenger().Return(nil)
-		provider.EXPECT().StorageProvider().Return(storeProvider)
-		provider.EXPECT().VDRIRegistry().Return(nil).AnyTimes()
-
-		svc, err := New(provider)
-		require.NoError(t, err)
-
-		err = svc.ActionStop("piID", nil)
-		require.Contains(t, fmt.Sprintf("%v", err), "delete transitional payload: "+errMsg)
-	})
 }
 
 // nolint: gocyclo
@@ -276,7 +263,7 @@ func TestService_HandleInbound(t *testing.T) {
 
 			return nil
 		})
-		store.EXPECT().Delete(gomock.Any()).Return(errors.New(errMsg))
+		store.EXPECT().Delete(gomock.Any()).Return(nil)
 		store.EXPECT().Put(gomock.Any(), gomock.Any()).Do(func(_ string, name []byte) error {
 			defer close(done)
 
@@ -832,6 +819,40 @@ func Test_getTransitionalPayload(t *testing.T) {
 	require.Contains(t, fmt.Sprintf("%v", err), "unmarshal transitional payload")
 }
 
+func Test_saveState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := presentproofMocks.NewMockProvider(ctrl)
+	provider.EXPECT().Messenger().Return(nil).AnyTimes()
+	provider.EXPECT().StorageProvider().Return(mem.NewProvider()).AnyTimes()
+	provider.EXPECT().VDRIRegistry().Return(nil).AnyTimes()
+
+	svc, err := New(provider)
+	require.NoError(t, err)
+
+	payload := transitionalPayload{PIID: "ID", StateName: "state"}
+	require.NoError(t, svc.saveTransitionalPayload("ID", payload))
+
+	// the transitional payload is kept until the state of its action is saved
+	md := &metaData{transitionalPayload: payload}
+	require.NoError(t, svc.saveState(md, "state"))
+
+	_, err = svc.getTransitionalPayload("ID")
+	require.NoError(t, err)
+
+	md.fromAction = true
+	require.NoError(t, svc.saveState(md, "next"))
+	require.False(t, md.fromAction)
+
+	_, err = svc.getTransitionalPayload("ID")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	stateName, err := svc.currentStateName("ID")
+	require.NoError(t, err)
+	require.Equal(t, "next", stateName)
+}
+
 func Test_nextState(t *testing.T) {
 	next, err := nextState(service.NewDIDCommMsgMap(RequestPresentation{
 		Type: RequestPresentationMsgType,
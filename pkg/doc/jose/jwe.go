@@ -36,6 +36,8 @@ type RecipientHeaders struct {
 	Tag string `json:"tag,omitempty"`
 	KID string `json:"kid,omitempty"`
 	SPK string `json:"spk,omitempty"`
+	// EPK is the ephemeral public key of the ECDH-ES key agreement of the recipient, as a JWK
+	EPK json.RawMessage `json:"epk,omitempty"`
 }
 
 // rawJSONWebEncryption represents a RAW JWE that is used for serialization/deserialization.
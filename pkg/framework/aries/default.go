@@ -15,7 +15,10 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/didcommv2"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/anoncrypt"
 	jwe "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jwe/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/jws"
 	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
@@ -139,6 +142,15 @@ func setAdditionalDefaultOpts(frameworkOpts *Aries) error {
 			func(provider packer.Provider) (packer.Packer, error) {
 				return jwe.New(provider, jwe.XC20P)
 			},
+			func(provider packer.Provider) (packer.Packer, error) {
+				return anoncrypt.New(provider, anoncrypt.XC20P)
+			},
+			func(provider packer.Provider) (packer.Packer, error) {
+				return didcommv2.New(provider)
+			},
+			func(provider packer.Provider) (packer.Packer, error) {
+				return jws.New(provider)
+			},
 		}
 	}
 
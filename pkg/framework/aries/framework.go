@@ -14,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
@@ -57,6 +58,8 @@ type Aries struct {
 	kmsCreator             kms.Creator
 	secretLock             secretlock.Service
 	crypto                 crypto.Crypto
+	keyUsageAudit          bool
+	keyAuditLog            *audit.Log
 	packagerCreator        packager.Creator
 	packager               commontransport.Packager
 	packerCreator          packer.Creator
@@ -66,6 +69,7 @@ type Aries struct {
 	vdriRegistry           vdriapi.Registry
 	vdri                   []vdriapi.VDRI
 	transportReturnRoute   string
+	messagePacking         map[string]dispatcher.MessagePacking
 	id                     string
 }
 
@@ -106,12 +110,17 @@ func New(opts ...Option) (*Aries, error) {
 
 func initializeServices(frameworkOpts *Aries) (*Aries, error) {
 	// Order of initializing service is important
-	// Create legacyKMS
-	if e := createLegacyKMS(frameworkOpts); e != nil {
+	if e := createKMS(frameworkOpts); e != nil {
 		return nil, e
 	}
 
-	if e := createKMS(frameworkOpts); e != nil {
+	// Create key audit log (must be done after KMS, it audits the KMS)
+	if e := createKeyAuditLog(frameworkOpts); e != nil {
+		return nil, e
+	}
+
+	// Create legacyKMS (must be done after KMS, it may be a legacykms.KMSAdapter of the KMS)
+	if e := createLegacyKMS(frameworkOpts); e != nil {
 		return nil, e
 	}
 
@@ -189,6 +198,22 @@ func WithTransportReturnRoute(transportReturnRoute string) Option {
 	}
 }
 
+// WithMessagePacking injects the packing of the outbound messages of type msgType to the Aries framework, e.g. to
+// sign the messages which must be verifiable by a third party with the signing packer
+// (application/didcomm-signed+json), in packing.ContentType or in packing.InnerContentType to sign then encrypt them.
+// A single message can also be packed differently with the dispatcher.WithPacking option of the outbound dispatcher.
+func WithMessagePacking(msgType string, packing dispatcher.MessagePacking) Option {
+	return func(opts *Aries) error {
+		if opts.messagePacking == nil {
+			opts.messagePacking = map[string]dispatcher.MessagePacking{}
+		}
+
+		opts.messagePacking[msgType] = packing
+
+		return nil
+	}
+}
+
 // WithStoreProvider injects a storage provider to the Aries framework.
 func WithStoreProvider(prov storage.Provider) Option {
 	return func(opts *Aries) error {
@@ -213,7 +238,8 @@ func WithProtocols(protocolSvcCreator ...api.ProtocolSvcCreator) Option {
 	}
 }
 
-// WithLegacyKMS injects a LegacyKMS service to the Aries framework.
+// WithLegacyKMS injects a LegacyKMS service to the Aries framework. The KMS and the crypto of the framework are
+// available to k, e.g. to create a legacykms.KMSAdapter running the legacy DIDComm key operations on them.
 func WithLegacyKMS(k api.KMSCreator) Option {
 	return func(opts *Aries) error {
 		opts.legacyKMSCreator = k
@@ -245,6 +271,15 @@ func WithCrypto(c crypto.Crypto) Option {
 	}
 }
 
+// WithKeyUsageAudit enables the key usage audit log of the Aries framework: the operations of its KMS and crypto are
+// recorded in the audit.Namespace store of the store provider, with the framework component using the keys.
+func WithKeyUsageAudit() Option {
+	return func(opts *Aries) error {
+		opts.keyUsageAudit = true
+		return nil
+	}
+}
+
 // WithVDRI injects a VDRI service to the Aries framework.
 func WithVDRI(v vdriapi.VDRI) Option {
 	return func(opts *Aries) error {
@@ -283,8 +318,10 @@ func (a *Aries) Context() (*context.Provider, error) {
 		context.WithOutboundTransports(a.outboundTransports...),
 		context.WithProtocolServices(a.services...),
 		context.WithLegacyKMS(a.legacyKMS),
+		context.WithKMS(a.kms),
 		context.WithSecretLock(a.secretLock),
 		context.WithCrypto(a.crypto),
+		context.WithKeyAuditLog(a.keyAuditLog),
 		context.WithServiceEndpoint(serviceEndpoint(a)),
 		context.WithRouterEndpoint(routingEndpoint(a)),
 		context.WithStorageProvider(a.storeProvider),
@@ -293,6 +330,7 @@ func (a *Aries) Context() (*context.Provider, error) {
 		context.WithPackager(a.packager),
 		context.WithVDRIRegistry(a.vdriRegistry),
 		context.WithTransportReturnRoute(a.transportReturnRoute),
+		context.WithMessagePacking(a.messagePacking),
 		context.WithAriesFrameworkID(a.id),
 		context.WithMessageServiceProvider(a.msgSvcProvider),
 	)
@@ -349,6 +387,8 @@ func (a *Aries) closeVDRI() error {
 func createLegacyKMS(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithStorageProvider(frameworkOpts.storeProvider),
+		context.WithKMS(frameworkOpts.auditedKMS(audit.ComponentLegacyKMS)),
+		context.WithCrypto(frameworkOpts.auditedCrypto(audit.ComponentLegacyKMS)),
 	)
 	if err != nil {
 		return fmt.Errorf("create context failed: %w", err)
@@ -379,6 +419,41 @@ func createKMS(frameworkOpts *Aries) error {
 	return nil
 }
 
+func createKeyAuditLog(frameworkOpts *Aries) error {
+	if !frameworkOpts.keyUsageAudit {
+		return nil
+	}
+
+	log, err := audit.New(frameworkOpts.storeProvider)
+	if err != nil {
+		return fmt.Errorf("create key audit log failed: %w", err)
+	}
+
+	frameworkOpts.keyAuditLog = log
+	frameworkOpts.kms = log.KeyManager(frameworkOpts.kms, audit.ComponentAgent)
+	frameworkOpts.crypto = log.Crypto(frameworkOpts.crypto, audit.ComponentAgent)
+
+	return nil
+}
+
+// auditedKMS returns the KMS of the framework audited as used by component, if the use of the keys is audited
+func (a *Aries) auditedKMS(component string) kms.KeyManager {
+	if a.keyAuditLog == nil {
+		return a.kms
+	}
+
+	return a.keyAuditLog.KeyManager(a.kms, component)
+}
+
+// auditedCrypto returns the crypto of the framework audited as used by component, if the use of the keys is audited
+func (a *Aries) auditedCrypto(component string) crypto.Crypto {
+	if a.keyAuditLog == nil {
+		return a.crypto
+	}
+
+	return a.keyAuditLog.Crypto(a.crypto, component)
+}
+
 func createVDRI(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithLegacyKMS(frameworkOpts.legacyKMS),
@@ -436,6 +511,7 @@ func createOutboundDispatcher(frameworkOpts *Aries) error {
 		context.WithOutboundTransports(frameworkOpts.outboundTransports...),
 		context.WithPackager(frameworkOpts.packager),
 		context.WithTransportReturnRoute(frameworkOpts.transportReturnRoute),
+		context.WithMessagePacking(frameworkOpts.messagePacking),
 		context.WithVDRIRegistry(frameworkOpts.vdriRegistry),
 	)
 	if err != nil {
@@ -516,7 +592,9 @@ func loadServices(frameworkOpts *Aries) error {
 func createPackersAndPackager(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithLegacyKMS(frameworkOpts.legacyKMS),
-		context.WithCrypto(frameworkOpts.crypto),
+		context.WithKMS(frameworkOpts.auditedKMS(audit.ComponentPacker)),
+		context.WithCrypto(frameworkOpts.auditedCrypto(audit.ComponentPacker)),
+		context.WithVDRIRegistry(frameworkOpts.vdriRegistry),
 	)
 	if err != nil {
 		return fmt.Errorf("create packer context failed: %w", err)
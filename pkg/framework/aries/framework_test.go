@@ -21,10 +21,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
@@ -39,6 +42,7 @@ import (
 	mockdidexchange "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/protocol/generic"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
@@ -49,6 +53,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
 
@@ -392,8 +397,9 @@ func TestFramework(t *testing.T) {
 	})
 
 	t.Run("test error from legacy kms svc", func(t *testing.T) {
-		// with custom legacy kms
+		// with custom legacy kms, created after the KMS opened its store
 		_, err := New(WithInboundTransport(&mockInboundTransport{}),
+			WithStoreProvider(storage.NewMockStoreProvider()),
 			WithLegacyKMS(func(ctx api.Provider) (api.CloseableKMS, error) {
 				return nil, fmt.Errorf("error from legacyKMS")
 			}))
@@ -539,6 +545,10 @@ func TestFramework(t *testing.T) {
 		require.NotEmpty(t, a)
 		require.Equal(t, customKMS, a.kms)
 
+		ctx, err := a.Context()
+		require.NoError(t, err)
+		require.Equal(t, customKMS, ctx.KMS())
+
 		err = a.Close()
 		require.NoError(t, err)
 	})
@@ -611,6 +621,24 @@ func TestFramework(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid transport return route option : "+transportReturnRoute)
 	})
 
+	t.Run("test new with message packing", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		offerPacking := dispatcher.MessagePacking{InnerContentType: "application/didcomm-signed+json"}
+
+		aries, err := New(WithMessagePacking("https://didcomm.org/issue-credential/1.0/offer-credential", offerPacking))
+		require.NoError(t, err)
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+		require.Equal(t, map[string]dispatcher.MessagePacking{
+			"https://didcomm.org/issue-credential/1.0/offer-credential": offerPacking,
+		}, ctx.MessagePacking())
+		require.NoError(t, aries.Close())
+	})
+
 	t.Run("test message service provider option", func(t *testing.T) {
 		path, cleanup := generateTempDir(t)
 		defer cleanup()
@@ -668,6 +696,92 @@ func Test_Packager(t *testing.T) {
 	})
 }
 
+func Test_LegacyKMSAdapter(t *testing.T) {
+	f, err := New(WithInboundTransport(&mockInboundTransport{}),
+		WithStoreProvider(storage.NewMockStoreProvider()),
+		WithLegacyKMS(func(ctx api.Provider) (api.CloseableKMS, error) {
+			return legacykms.NewKMSAdapter(ctx.KMS(), ctx.Crypto())
+		}))
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, f.Close()) }()
+
+	ctx, err := f.Context()
+	require.NoError(t, err)
+
+	_, senderVerKey, err := ctx.LegacyKMS().CreateKeySet()
+	require.NoError(t, err)
+
+	_, recVerKey, err := ctx.LegacyKMS().CreateKeySet()
+	require.NoError(t, err)
+
+	packed, err := ctx.Packager().PackMessage(&commontransport.Envelope{
+		Message:    []byte("test message"),
+		FromVerKey: base58.Decode(senderVerKey),
+		ToVerKeys:  []string{recVerKey},
+	})
+	require.NoError(t, err)
+
+	env, err := ctx.Packager().UnpackMessage(packed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("test message"), env.Message)
+	require.Equal(t, base58.Decode(senderVerKey), env.FromVerKey)
+}
+
+func Test_KeyUsageAudit(t *testing.T) {
+	f, err := New(WithInboundTransport(&mockInboundTransport{}),
+		WithStoreProvider(mem.NewProvider()),
+		WithKeyUsageAudit(),
+		WithLegacyKMS(func(ctx api.Provider) (api.CloseableKMS, error) {
+			return legacykms.NewKMSAdapter(ctx.KMS(), ctx.Crypto())
+		}))
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, f.Close()) }()
+
+	ctx, err := f.Context()
+	require.NoError(t, err)
+	require.NotNil(t, ctx.KeyAuditLog())
+
+	keyID, _, err := ctx.KMS().Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	_, senderVerKey, err := ctx.LegacyKMS().CreateKeySet()
+	require.NoError(t, err)
+
+	_, recVerKey, err := ctx.LegacyKMS().CreateKeySet()
+	require.NoError(t, err)
+
+	_, err = ctx.Packager().PackMessage(&commontransport.Envelope{
+		Message:    []byte("test message"),
+		FromVerKey: base58.Decode(senderVerKey),
+		ToVerKeys:  []string{recVerKey},
+	})
+	require.NoError(t, err)
+
+	records, err := ctx.KeyAuditLog().Query(&audit.Query{Component: audit.ComponentAgent})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, keyID, records[0].KeyID)
+	require.Equal(t, "Create", records[0].Operation)
+
+	records, err = ctx.KeyAuditLog().Query(&audit.Query{Component: audit.ComponentLegacyKMS, Operation: "Create"})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	records, err = ctx.KeyAuditLog().Query(&audit.Query{Component: audit.ComponentLegacyKMS, Operation: "SharedSecret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+
+	t.Run("test audit log store failure", func(t *testing.T) {
+		_, err = New(WithInboundTransport(&mockInboundTransport{}),
+			WithStoreProvider(&storage.MockStoreProvider{FailNamespace: audit.Namespace}),
+			WithKeyUsageAudit())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create key audit log failed")
+	})
+}
+
 func generateTempDir(t testing.TB) (string, func()) {
 	path, err := ioutil.TempDir("", "db")
 	if err != nil {
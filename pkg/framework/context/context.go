@@ -9,6 +9,7 @@ package context
 import (
 	"fmt"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
@@ -33,6 +34,7 @@ type Provider struct {
 	kms                    kms.KeyManager
 	secretLock             secretlock.Service
 	crypto                 crypto.Crypto
+	keyAuditLog            *audit.Log
 	packager               commontransport.Packager
 	primaryPacker          packer.Packer
 	packers                []packer.Packer
@@ -43,6 +45,7 @@ type Provider struct {
 	outboundTransports     []transport.OutboundTransport
 	vdriRegistry           vdriapi.Registry
 	transportReturnRoute   string
+	messagePacking         map[string]dispatcher.MessagePacking
 	frameworkID            string
 }
 
@@ -101,6 +104,30 @@ func (p *Provider) Crypto() crypto.Crypto {
 	return p.crypto
 }
 
+// KeyAuditLog returns the key usage audit log, nil if the use of the keys isn't audited
+func (p *Provider) KeyAuditLog() *audit.Log {
+	return p.keyAuditLog
+}
+
+// SignatureSuiteCrypto returns the Crypto to give the linked data signature suites, audited as used by them if the
+// use of the keys is audited
+func (p *Provider) SignatureSuiteCrypto() crypto.Crypto {
+	return p.auditedCrypto(audit.ComponentSignatureSuite)
+}
+
+// JWTSignerCrypto returns the Crypto to give the JWT signers, audited as used by them if the use of the keys is audited
+func (p *Provider) JWTSignerCrypto() crypto.Crypto {
+	return p.auditedCrypto(audit.ComponentJWTSigner)
+}
+
+func (p *Provider) auditedCrypto(component string) crypto.Crypto {
+	if p.keyAuditLog == nil {
+		return p.crypto
+	}
+
+	return p.keyAuditLog.Crypto(p.crypto, component)
+}
+
 // Packager returns a packager service.
 func (p *Provider) Packager() commontransport.Packager {
 	return p.packager
@@ -206,6 +233,11 @@ func (p *Provider) TransportReturnRoute() string {
 	return p.transportReturnRoute
 }
 
+// MessagePacking returns the packing of the outbound messages by message type
+func (p *Provider) MessagePacking() map[string]dispatcher.MessagePacking {
+	return p.messagePacking
+}
+
 // AriesFrameworkID returns an inbound transport endpoint.
 func (p *Provider) AriesFrameworkID() string {
 	return p.frameworkID
@@ -246,6 +278,14 @@ func WithTransportReturnRoute(transportReturnRoute string) ProviderOption {
 	}
 }
 
+// WithMessagePacking injects the packing of the outbound messages by message type into the context.
+func WithMessagePacking(messagePacking map[string]dispatcher.MessagePacking) ProviderOption {
+	return func(opts *Provider) error {
+		opts.messagePacking = messagePacking
+		return nil
+	}
+}
+
 // WithProtocolServices injects a protocol services into the context.
 func WithProtocolServices(services ...dispatcher.ProtocolService) ProviderOption {
 	return func(opts *Provider) error {
@@ -286,6 +326,14 @@ func WithCrypto(c crypto.Crypto) ProviderOption {
 	}
 }
 
+// WithKeyAuditLog injects a key usage audit log into the context
+func WithKeyAuditLog(l *audit.Log) ProviderOption {
+	return func(opts *Provider) error {
+		opts.keyAuditLog = l
+		return nil
+	}
+}
+
 // WithVDRIRegistry injects a vdri service into the context.
 func WithVDRIRegistry(vdri vdriapi.Registry) ProviderOption {
 	return func(opts *Provider) error {
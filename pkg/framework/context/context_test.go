@@ -16,8 +16,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
 	mockdidcomm "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm"
 	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/internal/mock/didcomm/dispatcher"
@@ -268,6 +271,43 @@ func TestNewProvider(t *testing.T) {
 		require.Equal(t, mSecLck, prov.SecretLock())
 	})
 
+	t.Run("test new with key audit log", func(t *testing.T) {
+		log, err := audit.New(storage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		prov, err := New(WithKeyAuditLog(log))
+		require.NoError(t, err)
+		require.Equal(t, log, prov.KeyAuditLog())
+	})
+
+	t.Run("test signature suite and JWT signer crypto", func(t *testing.T) {
+		mCrypto := &mockcrypto.Crypto{SignValue: []byte("signature")}
+
+		prov, err := New(WithCrypto(mCrypto))
+		require.NoError(t, err)
+		require.Equal(t, mCrypto, prov.SignatureSuiteCrypto())
+		require.Equal(t, mCrypto, prov.JWTSignerCrypto())
+
+		log, err := audit.New(storage.NewMockStoreProvider())
+		require.NoError(t, err)
+
+		prov, err = New(WithCrypto(mCrypto), WithKeyAuditLog(log))
+		require.NoError(t, err)
+
+		for component, c := range map[string]crypto.Crypto{
+			audit.ComponentSignatureSuite: prov.SignatureSuiteCrypto(),
+			audit.ComponentJWTSigner:      prov.JWTSignerCrypto(),
+		} {
+			_, err = c.Sign([]byte("msg"), nil)
+			require.NoError(t, err)
+
+			records, e := log.Query(&audit.Query{Component: component})
+			require.NoError(t, e)
+			require.Len(t, records, 1)
+			require.Equal(t, "Sign", records[0].Operation)
+		}
+	})
+
 	t.Run("test new with kms service", func(t *testing.T) {
 		mKMS := &mockkms.KeyManager{}
 		prov, err := New(WithKMS(mKMS))
@@ -328,6 +368,17 @@ func TestNewProvider(t *testing.T) {
 		require.Equal(t, transportReturnRoute, prov.TransportReturnRoute())
 	})
 
+	t.Run("test new with message packing", func(t *testing.T) {
+		messagePacking := map[string]dispatcher.MessagePacking{
+			"https://didcomm.org/issue-credential/1.0/offer-credential": {
+				InnerContentType: "application/didcomm-signed+json",
+			},
+		}
+		prov, err := New(WithMessagePacking(messagePacking))
+		require.NoError(t, err)
+		require.Equal(t, messagePacking, prov.MessagePacking())
+	})
+
 	t.Run("test new with framework id", func(t *testing.T) {
 		frameworkID := "aries-framework-1"
 		prov, err := New(WithAriesFrameworkID(frameworkID))
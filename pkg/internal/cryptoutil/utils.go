@@ -148,11 +148,6 @@ func Derive25519KEK(alg, apu []byte, fromPrivKey, toPubKey *[chacha.KeySize]byte
 		return nil, ErrInvalidKey
 	}
 
-	const (
-		numBitsPerByte = 8
-		supPubInfoLen  = 4
-	)
-
 	// do ScalarMult of the sender's private key with the recipient key to get a derived Z point
 	// ( equivalent to derive an EC key )
 	z, err := curve25519.X25519(fromPrivKey[:], toPubKey[:])
@@ -160,6 +155,17 @@ func Derive25519KEK(alg, apu []byte, fromPrivKey, toPubKey *[chacha.KeySize]byte
 		return nil, err
 	}
 
+	return Derive25519KEKFromSharedSecret(alg, apu, z)
+}
+
+// Derive25519KEKFromSharedSecret derives the ephemeral symmetric key (kek) of Derive25519KEK from the X25519 shared
+// secret z of the sender's private key and the recipient key, for keys whose private part is not at hand
+func Derive25519KEKFromSharedSecret(alg, apu, z []byte) ([]byte, error) {
+	const (
+		numBitsPerByte = 8
+		supPubInfoLen  = 4
+	)
+
 	// inspired by: github.com/square/go-jose/v3@v3.0.0-20190722231519-723929d55157/cipher/ecdh_es.go
 	// -> DeriveECDHES() call
 	// suppPubInfo is the encoded length of the recipient shared key output size in bits
@@ -185,7 +191,7 @@ func Derive25519KEK(alg, apu []byte, fromPrivKey, toPubKey *[chacha.KeySize]byte
 	kek := make([]byte, chacha.KeySize)
 
 	// Read on the KDF will never fail
-	_, err = reader.Read(kek)
+	_, err := reader.Read(kek)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +199,29 @@ func Derive25519KEK(alg, apu []byte, fromPrivKey, toPubKey *[chacha.KeySize]byte
 	return kek, nil
 }
 
+// A256KWKeySize is the size of the A256KW key encryption keys derived by DeriveA256KWKey
+const A256KWKeySize = 32
+
+// DeriveA256KWKey derives the A256KW key encryption key of alg from the ECDH shared secret z with the Concat KDF,
+// as per https://tools.ietf.org/html/rfc7518#section-4.6.2
+func DeriveA256KWKey(z []byte, alg string, apu, apv []byte) []byte {
+	const numBitsPerByte = 8
+
+	// suppPubInfo is the key size in bits
+	supPubInfo := make([]byte, 4) // nolint:gomnd
+	binary.BigEndian.PutUint32(supPubInfo, A256KWKeySize*numBitsPerByte)
+
+	reader := josecipher.NewConcatKDF(crypto.SHA256, z, lengthPrefix([]byte(alg)), lengthPrefix(apu),
+		lengthPrefix(apv), supPubInfo, []byte{})
+
+	kek := make([]byte, A256KWKeySize)
+
+	// Read on the KDF never fails
+	_, _ = reader.Read(kek)
+
+	return kek
+}
+
 // lengthPrefix array with a bigEndian uint32 value of array's length
 func lengthPrefix(array []byte) []byte {
 	const prefixLen = 4
@@ -7,6 +7,7 @@ package mocks
 import (
 	gomock "github.com/golang/mock/gomock"
 	service "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	dispatcher "github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	reflect "reflect"
 )
 
@@ -48,29 +49,39 @@ func (mr *MockOutboundMockRecorder) Forward(arg0, arg1 interface{}) *gomock.Call
 }
 
 // Send mocks base method
-func (m *MockOutbound) Send(arg0 interface{}, arg1 string, arg2 *service.Destination) error {
+func (m *MockOutbound) Send(arg0 interface{}, arg1 string, arg2 *service.Destination, arg3 ...dispatcher.SendOpts) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", arg0, arg1, arg2)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Send", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Send indicates an expected call of Send
-func (mr *MockOutboundMockRecorder) Send(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockOutboundMockRecorder) Send(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockOutbound)(nil).Send), arg0, arg1, arg2)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockOutbound)(nil).Send), varargs...)
 }
 
 // SendToDID mocks base method
-func (m *MockOutbound) SendToDID(arg0 interface{}, arg1, arg2 string) error {
+func (m *MockOutbound) SendToDID(arg0 interface{}, arg1, arg2 string, arg3 ...dispatcher.SendOpts) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendToDID", arg0, arg1, arg2)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendToDID", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SendToDID indicates an expected call of SendToDID
-func (mr *MockOutboundMockRecorder) SendToDID(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockOutboundMockRecorder) SendToDID(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToDID", reflect.TypeOf((*MockOutbound)(nil).SendToDID), arg0, arg1, arg2)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToDID", reflect.TypeOf((*MockOutbound)(nil).SendToDID), varargs...)
 }
@@ -7,6 +7,7 @@ package dispatcher
 
 import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 )
 
 // MockOutbound mock outbound dispatcher
@@ -18,7 +19,8 @@ type MockOutbound struct {
 }
 
 // Send msg
-func (m *MockOutbound) Send(msg interface{}, senderVerKey string, des *service.Destination) error {
+func (m *MockOutbound) Send(msg interface{}, senderVerKey string, des *service.Destination,
+	_ ...dispatcher.SendOpts) error {
 	if m.ValidateSend != nil {
 		return m.ValidateSend(msg, senderVerKey, des)
 	}
@@ -27,7 +29,7 @@ func (m *MockOutbound) Send(msg interface{}, senderVerKey string, des *service.D
 }
 
 // SendToDID msg
-func (m *MockOutbound) SendToDID(msg interface{}, myDID, theirDID string) error {
+func (m *MockOutbound) SendToDID(msg interface{}, myDID, theirDID string, _ ...dispatcher.SendOpts) error {
 	if m.ValidateSendToDID != nil {
 		return m.ValidateSendToDID(msg, myDID, theirDID)
 	}
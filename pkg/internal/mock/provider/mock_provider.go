@@ -6,10 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 package provider
 
 import (
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
@@ -19,6 +22,7 @@ type Provider struct {
 	ServiceErr                    error
 	ServiceMap                    map[string]interface{}
 	KMSValue                      legacykms.KeyManager
+	CustomKMS                     kms.KeyManager
 	ServiceEndpointValue          string
 	StorageProviderValue          storage.Provider
 	TransientStorageProviderValue storage.Provider
@@ -26,6 +30,8 @@ type Provider struct {
 	PackerValue                   packer.Packer
 	OutboundDispatcherValue       dispatcher.Outbound
 	VDRIRegistryValue             vdriapi.Registry
+	SecretLockValue               secretlock.Service
+	KeyAuditLogValue              *audit.Log
 }
 
 // Service return service
@@ -46,6 +52,21 @@ func (p *Provider) LegacyKMS() legacykms.KeyManager {
 	return p.KMSValue
 }
 
+// KMS returns a KMS instance
+func (p *Provider) KMS() kms.KeyManager {
+	return p.CustomKMS
+}
+
+// SecretLock returns a SecretLock instance
+func (p *Provider) SecretLock() secretlock.Service {
+	return p.SecretLockValue
+}
+
+// KeyAuditLog returns a key usage audit log instance
+func (p *Provider) KeyAuditLog() *audit.Log {
+	return p.KeyAuditLogValue
+}
+
 // ServiceEndpoint returns the service endpoint
 func (p *Provider) ServiceEndpoint() string {
 	return p.ServiceEndpointValue
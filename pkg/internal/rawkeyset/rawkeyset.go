@@ -0,0 +1,61 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package rawkeyset creates and reads Tink keysets of keys not supported by Tink. Such keysets hold a single key of a
+// custom type URL with its raw value, they are stored like the keysets of other keys but Tink can't create their
+// primitives.
+package rawkeyset
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+)
+
+// NewHandle returns a key handle of a keyset with the key of typeURL and raw value as primary key
+func NewHandle(typeURL string, value []byte, materialType tinkpb.KeyData_KeyMaterialType) (*keyset.Handle, error) {
+	ks := &tinkpb.Keyset{
+		Key: []*tinkpb.Keyset_Key{
+			{
+				KeyData: &tinkpb.KeyData{
+					TypeUrl:         typeURL,
+					Value:           value,
+					KeyMaterialType: materialType,
+				},
+				Status:           tinkpb.KeyStatusType_ENABLED,
+				KeyId:            1,
+				OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+			}},
+		PrimaryKeyId: 1,
+	}
+
+	kh, err := insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key handle: %w", err)
+	}
+
+	return kh, nil
+}
+
+// PrimaryKeyData returns the key data of the primary key of kh
+func PrimaryKeyData(kh *keyset.Handle) (*tinkpb.KeyData, error) {
+	if kh == nil {
+		return nil, errors.New("key handle is nil")
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(kh)
+
+	for _, key := range ks.Key {
+		if key.KeyId == ks.PrimaryKeyId && key.Status == tinkpb.KeyStatusType_ENABLED {
+			return key.KeyData, nil
+		}
+	}
+
+	return nil, errors.New("primary key not found")
+}
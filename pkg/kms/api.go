@@ -7,6 +7,8 @@
 package kms
 
 import (
+	"time"
+
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -22,6 +24,49 @@ type KeyManager interface {
 	Rotate(kt KeyType, keyID string) (string, interface{}, error)
 }
 
+// PrivateKeyImporter is a KeyManager able to import existing private keys
+type PrivateKeyImporter interface {
+	// ImportPrivateKey stores privKey as a new key of type kt and returns its keyID and key handle.
+	// Supported private keys are ed25519.PrivateKey and *ecdsa.PrivateKey
+	ImportPrivateKey(privKey interface{}, kt KeyType) (string, interface{}, error)
+}
+
+// PrivateKeyExporter is a KeyManager able to export private keys encrypted with a passphrase, to move them to
+// another KeyManager, and to import them back
+type PrivateKeyExporter interface {
+	// ExportEncryptedPrivateKey returns the key referenced by keyID encrypted with passphrase
+	ExportEncryptedPrivateKey(keyID, passphrase string) ([]byte, error)
+	// ImportEncryptedPrivateKey stores a key exported by ExportEncryptedPrivateKey and returns its keyID and
+	// key handle
+	ImportEncryptedPrivateKey(encryptedKey []byte, passphrase string) (string, interface{}, error)
+}
+
+// KeyMetadata is the metadata of a key of a KeyManager
+type KeyMetadata struct {
+	// KeyID is the ID of the key
+	KeyID string `json:"keyID"`
+	// KeyType is the type of the key, empty if unknown
+	KeyType KeyType `json:"keyType,omitempty"`
+	// Created is the creation time of the key, zero if unknown
+	Created time.Time `json:"created"`
+	// RotatedFrom is the ID of the key this key was rotated from
+	RotatedFrom string `json:"rotatedFrom,omitempty"`
+	// Labels are free-form labels set with SetLabels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// KeyMetadataManager is a KeyManager keeping the metadata of its keys, able to list and delete them
+type KeyMetadataManager interface {
+	// List returns the metadata of all keys of the KeyManager
+	List() ([]*KeyMetadata, error)
+	// Metadata returns the metadata of the key referenced by keyID
+	Metadata(keyID string) (*KeyMetadata, error)
+	// SetLabels replaces the labels of the key referenced by keyID
+	SetLabels(keyID string, labels map[string]string) error
+	// Delete deletes the key referenced by keyID along with its metadata
+	Delete(keyID string) error
+}
+
 // Provider for KeyManager builder/constructor
 type Provider interface {
 	StorageProvider() storage.Provider
@@ -52,6 +97,10 @@ const (
 	ED25519 = "ED25519"
 	// RSA key type value
 	RSA = "RSA"
+	// ECDSASecp256k1 key type value
+	ECDSASecp256k1 = "ECDSASecp256k1"
+	// BLS12381G2 BBS+ key type value
+	BLS12381G2 = "BLS12381G2"
 )
 
 // KeyType represents a key type supported by the KMS
@@ -80,4 +129,8 @@ const (
 	RSAType = KeyType(RSA)
 	// HMACSHA256Tag256Type key type value
 	HMACSHA256Tag256Type = KeyType("HMACSHA256Tag256")
+	// ECDSASecp256k1Type key type value
+	ECDSASecp256k1Type = KeyType(ECDSASecp256k1)
+	// BLS12381G2Type BBS+ key type value
+	BLS12381G2Type = KeyType(BLS12381G2)
 )
@@ -0,0 +1,268 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// VerKeyLabel is the label of the keys of the kms.KeyManager of a KMSAdapter holding their base58 encoded
+// verification key, the legacy key ID of their key pairs
+const VerKeyLabel = "legacyVerKey"
+
+// keyReloadInterval is the minimum interval between two reloads of the labeled keys of the key manager on unknown keys
+const keyReloadInterval = 10 * time.Second
+
+// adapterKeyManager is the kms.KeyManager of a KMSAdapter, able to export public keys and label its keys
+type adapterKeyManager interface {
+	kms.KeyManager
+	kms.KeyMetadataManager
+	ExportPubKeyBytes(keyID string) ([]byte, error)
+}
+
+// adapterCrypto is the crypto.Crypto of a KMSAdapter, computing the shared secrets of the crypto box
+type adapterCrypto interface {
+	crypto.Crypto
+	crypto.KeyAgreement
+}
+
+// KMSAdapter is a LegacyKMS running on a kms.KeyManager and a crypto.Crypto, typically localkms and tinkcrypto, so
+// that the legacy packer, the route service and the DID exchange no longer need a BaseKMS. Its key pairs are ED25519
+// keys of the key manager labeled with VerKeyLabel, created by CreateKeySet or migrated from a BaseKMS by
+// MigrateKeys, the encryption keys being their X25519 conversion.
+type KMSAdapter struct {
+	km     adapterKeyManager
+	crypto adapterCrypto
+	// keyIDs maps the base58 encoded verification and encryption keys to the key IDs of the key manager
+	keyIDs map[string]string
+	// loaded is the time of the last load of the labeled keys, unknown keys don't reload them more often than
+	// reloadInterval so that looking up the keys of other agents doesn't list the keys of the key manager each time
+	loaded         time.Time
+	reloadInterval time.Duration
+	mutex          sync.RWMutex
+}
+
+// NewKMSAdapter returns a LegacyKMS using the keys of km and the operations of c. km must implement
+// kms.KeyMetadataManager and export public keys and c must implement crypto.KeyAgreement, like localkms and
+// tinkcrypto.
+func NewKMSAdapter(km kms.KeyManager, c crypto.Crypto) (*KMSAdapter, error) {
+	adapterKM, ok := km.(adapterKeyManager)
+	if !ok {
+		return nil, errors.New("new kms adapter: key manager doesn't support key metadata or public key export")
+	}
+
+	ac, ok := c.(adapterCrypto)
+	if !ok {
+		return nil, errors.New("new kms adapter: crypto doesn't support key agreement")
+	}
+
+	a := &KMSAdapter{
+		km:             adapterKM,
+		crypto:         ac,
+		keyIDs:         map[string]string{},
+		loaded:         time.Now(),
+		reloadInterval: keyReloadInterval,
+	}
+
+	if err := a.loadKeyIDs(); err != nil {
+		return nil, fmt.Errorf("new kms adapter: %w", err)
+	}
+
+	return a, nil
+}
+
+// CreateKeySet creates a new ED25519 key in the key manager and returns the base58 encoded encryption and
+// verification keys of its key pairs
+func (a *KMSAdapter) CreateKeySet() (string, string, error) {
+	keyID, _, err := a.km.Create(kms.ED25519Type)
+	if err != nil {
+		return "", "", fmt.Errorf("create key set: %w", err)
+	}
+
+	verKey, err := a.km.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return "", "", fmt.Errorf("create key set: %w", err)
+	}
+
+	err = a.km.SetLabels(keyID, map[string]string{VerKeyLabel: base58.Encode(verKey)})
+	if err != nil {
+		return "", "", fmt.Errorf("create key set: %w", err)
+	}
+
+	encKey, err := a.addKeyID(verKey, keyID)
+	if err != nil {
+		return "", "", fmt.Errorf("create key set: %w", err)
+	}
+
+	return base58.Encode(encKey), base58.Encode(verKey), nil
+}
+
+// ConvertToEncryptionKey returns the encryption key of the key pairs of verKey, the key manager keys being used as
+// encryption keys as is
+func (a *KMSAdapter) ConvertToEncryptionKey(verKey []byte) ([]byte, error) {
+	return a.GetEncryptionKey(verKey)
+}
+
+// GetEncryptionKey will return the public encryption key corresponding to the public verKey argument
+func (a *KMSAdapter) GetEncryptionKey(verKey []byte) ([]byte, error) {
+	if _, err := a.keyID(base58.Encode(verKey)); err != nil {
+		return nil, err
+	}
+
+	return cryptoutil.PublicEd25519toCurve25519(verKey)
+}
+
+// DeriveKEK will derive an ephemeral symmetric key (kek) using the private key of the encryption key fromPubKey and
+// toPubKey, both curve25519 keys
+func (a *KMSAdapter) DeriveKEK(alg, apu, fromPubKey, toPubKey []byte) ([]byte, error) {
+	if fromPubKey == nil || toPubKey == nil {
+		return nil, cryptoutil.ErrInvalidKey
+	}
+
+	z, err := a.sharedSecret(toPubKey, fromPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute shared secret: %w", err)
+	}
+
+	return cryptoutil.Derive25519KEKFromSharedSecret(alg, apu, z)
+}
+
+// FindVerKey selects a signing key which is present in candidateKeys that is present in the key manager
+func (a *KMSAdapter) FindVerKey(candidateKeys []string) (int, error) {
+	for i, key := range candidateKeys {
+		if _, err := a.keyID(key); err == nil {
+			return i, nil
+		}
+	}
+
+	return -1, cryptoutil.ErrKeyNotFound
+}
+
+// SignMessage signs a message with the crypto using the private key of the verification key fromVerKey
+func (a *KMSAdapter) SignMessage(message []byte, fromVerKey string) ([]byte, error) {
+	kh, err := a.keyHandle(fromVerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	return a.crypto.Sign(message, kh)
+}
+
+// Close the KMSAdapter, the key manager and the crypto are not closed
+func (a *KMSAdapter) Close() error {
+	return nil
+}
+
+// sharedSecret computes the X25519 shared secret of theirPub and the private key of the encryption key myPub
+func (a *KMSAdapter) sharedSecret(theirPub, myPub []byte) ([]byte, error) {
+	kh, err := a.keyHandle(base58.Encode(myPub))
+	if err != nil {
+		return nil, err
+	}
+
+	return a.crypto.SharedSecret(&crypto.PublicKey{X: theirPub, Curve: crypto.X25519, Type: crypto.OKPKeyType}, kh)
+}
+
+// keyHandle returns the key handle of the key of a base58 encoded verification or encryption key
+func (a *KMSAdapter) keyHandle(key string) (interface{}, error) {
+	keyID, err := a.keyID(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.km.Get(keyID)
+}
+
+// keyID returns the key ID of a base58 encoded verification or encryption key, reloading the labeled keys of the key
+// manager if it is unknown as keys may have been migrated since the last load, at most once per reload interval
+func (a *KMSAdapter) keyID(key string) (string, error) {
+	a.mutex.RLock()
+	keyID, ok := a.keyIDs[key]
+	a.mutex.RUnlock()
+
+	if ok {
+		return keyID, nil
+	}
+
+	if !a.claimReload() {
+		return "", cryptoutil.ErrKeyNotFound
+	}
+
+	if err := a.loadKeyIDs(); err != nil {
+		return "", err
+	}
+
+	a.mutex.RLock()
+	keyID, ok = a.keyIDs[key]
+	a.mutex.RUnlock()
+
+	if !ok {
+		return "", cryptoutil.ErrKeyNotFound
+	}
+
+	return keyID, nil
+}
+
+// claimReload tells if the labeled keys may be reloaded, the reload interval having elapsed since the last load, and
+// records the reload so that concurrent lookups of unknown keys don't reload them too
+func (a *KMSAdapter) claimReload() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.loaded) < a.reloadInterval {
+		return false
+	}
+
+	a.loaded = now
+
+	return true
+}
+
+func (a *KMSAdapter) loadKeyIDs() error {
+	keys, err := a.km.List()
+	if err != nil {
+		return fmt.Errorf("list keys: %w", err)
+	}
+
+	for _, key := range keys {
+		verKey, ok := key.Labels[VerKeyLabel]
+		if !ok {
+			continue
+		}
+
+		if _, err = a.addKeyID(base58.Decode(verKey), key.KeyID); err != nil {
+			return fmt.Errorf("key %s: %w", key.KeyID, err)
+		}
+	}
+
+	return nil
+}
+
+// addKeyID maps the verification key verKey and its encryption key to keyID, returning the encryption key
+func (a *KMSAdapter) addKeyID(verKey []byte, keyID string) ([]byte, error) {
+	encKey, err := cryptoutil.PublicEd25519toCurve25519(verKey)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	a.keyIDs[base58.Encode(verKey)] = keyID
+	a.keyIDs[base58.Encode(encKey)] = keyID
+	a.mutex.Unlock()
+
+	return encKey, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestNewKMSAdapter(t *testing.T) {
+	localKMS := newLocalKMS(t)
+
+	_, err := NewKMSAdapter(struct{ kms.KeyManager }{&mockkms.KeyManager{}}, &tinkcrypto.Crypto{})
+	require.EqualError(t, err, "new kms adapter: key manager doesn't support key metadata or public key export")
+
+	_, err = NewKMSAdapter(localKMS, struct{ crypto.Crypto }{})
+	require.EqualError(t, err, "new kms adapter: crypto doesn't support key agreement")
+
+	_, err = NewKMSAdapter(&listErrKMS{LocalKMS: localKMS}, &tinkcrypto.Crypto{})
+	require.EqualError(t, err, "new kms adapter: list keys: list error")
+}
+
+func TestKMSAdapter(t *testing.T) {
+	a, err := NewKMSAdapter(newLocalKMS(t), &tinkcrypto.Crypto{})
+	require.NoError(t, err)
+
+	encKey, verKey, err := a.CreateKeySet()
+	require.NoError(t, err)
+
+	t.Run("encryption key", func(t *testing.T) {
+		expected, e := cryptoutil.PublicEd25519toCurve25519(base58.Decode(verKey))
+		require.NoError(t, e)
+		require.Equal(t, base58.Encode(expected), encKey)
+
+		key, e := a.GetEncryptionKey(base58.Decode(verKey))
+		require.NoError(t, e)
+		require.Equal(t, expected, key)
+
+		key, e = a.ConvertToEncryptionKey(base58.Decode(verKey))
+		require.NoError(t, e)
+		require.Equal(t, expected, key)
+	})
+
+	t.Run("find verification key", func(t *testing.T) {
+		i, e := a.FindVerKey([]string{"unknown", verKey})
+		require.NoError(t, e)
+		require.Equal(t, 1, i)
+
+		i, e = a.FindVerKey([]string{"unknown"})
+		require.Equal(t, cryptoutil.ErrKeyNotFound, e)
+		require.Equal(t, -1, i)
+	})
+
+	t.Run("sign message", func(t *testing.T) {
+		sig, e := a.SignMessage([]byte("test message"), verKey)
+		require.NoError(t, e)
+		require.True(t, ed25519.Verify(base58.Decode(verKey), []byte("test message"), sig))
+	})
+
+	t.Run("derive KEK", func(t *testing.T) {
+		otherEncKey, _, e := a.CreateKeySet()
+		require.NoError(t, e)
+
+		kek1, e := a.DeriveKEK([]byte("alg"), []byte("apu"), base58.Decode(encKey), base58.Decode(otherEncKey))
+		require.NoError(t, e)
+
+		kek2, e := a.DeriveKEK([]byte("alg"), []byte("apu"), base58.Decode(otherEncKey), base58.Decode(encKey))
+		require.NoError(t, e)
+		require.Equal(t, kek1, kek2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		unknownKey := base58.Encode(make([]byte, ed25519.PublicKeySize))
+
+		_, e := a.GetEncryptionKey(base58.Decode(unknownKey))
+		require.Equal(t, cryptoutil.ErrKeyNotFound, e)
+
+		_, e = a.SignMessage([]byte("test message"), unknownKey)
+		require.True(t, errors.Is(e, cryptoutil.ErrKeyNotFound))
+
+		_, e = a.DeriveKEK(nil, nil, nil, base58.Decode(encKey))
+		require.Equal(t, cryptoutil.ErrInvalidKey, e)
+
+		_, e = a.DeriveKEK(nil, nil, base58.Decode(unknownKey), base58.Decode(encKey))
+		require.True(t, errors.Is(e, cryptoutil.ErrKeyNotFound))
+
+		require.NoError(t, a.Close())
+	})
+}
+
+func TestKMSAdapter_KeyReload(t *testing.T) {
+	localKMS := newLocalKMS(t)
+	countingKMS := &listCountKMS{LocalKMS: localKMS}
+
+	a, err := NewKMSAdapter(countingKMS, &tinkcrypto.Crypto{})
+	require.NoError(t, err)
+	require.Equal(t, 1, countingKMS.lists)
+
+	// a key labeled after the adapter loaded the keys, as migrated keys
+	other, err := NewKMSAdapter(localKMS, &tinkcrypto.Crypto{})
+	require.NoError(t, err)
+
+	_, verKey, err := other.CreateKeySet()
+	require.NoError(t, err)
+
+	t.Run("unknown keys don't reload the keys within the reload interval", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, e := a.FindVerKey([]string{"unknown", verKey})
+			require.Equal(t, cryptoutil.ErrKeyNotFound, e)
+		}
+
+		require.Equal(t, 1, countingKMS.lists)
+	})
+
+	t.Run("unknown keys reload the keys once the reload interval elapsed", func(t *testing.T) {
+		a.mutex.Lock()
+		a.loaded = a.loaded.Add(-keyReloadInterval)
+		a.mutex.Unlock()
+
+		i, e := a.FindVerKey([]string{"unknown", "other unknown", verKey})
+		require.NoError(t, e)
+		require.Equal(t, 2, i)
+		require.Equal(t, 2, countingKMS.lists)
+
+		_, e = a.FindVerKey([]string{"unknown"})
+		require.Equal(t, cryptoutil.ErrKeyNotFound, e)
+		require.Equal(t, 2, countingKMS.lists)
+	})
+
+	t.Run("reload error", func(t *testing.T) {
+		listErr, e := NewKMSAdapter(localKMS, &tinkcrypto.Crypto{})
+		require.NoError(t, e)
+
+		listErr.km = &listErrKMS{LocalKMS: localKMS}
+		listErr.reloadInterval = 0
+
+		_, e = listErr.GetEncryptionKey(make([]byte, ed25519.PublicKeySize))
+		require.EqualError(t, e, "list keys: list error")
+	})
+}
+
+func TestKMSAdapter_CryptoBox(t *testing.T) {
+	legacyKMS, _ := newKMS(t)
+
+	legacyEncKey, _, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	a, err := NewKMSAdapter(newLocalKMS(t), &tinkcrypto.Crypto{})
+	require.NoError(t, err)
+
+	encKey, _, err := a.CreateKeySet()
+	require.NoError(t, err)
+
+	legacyBox, err := NewCryptoBox(legacyKMS)
+	require.NoError(t, err)
+
+	adapterBox, err := NewCryptoBox(a)
+	require.NoError(t, err)
+
+	nonce := make([]byte, cryptoutil.NonceSize)
+
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	t.Run("easy", func(t *testing.T) {
+		sealed, e := legacyBox.Easy(msg, nonce, base58.Decode(encKey), base58.Decode(legacyEncKey))
+		require.NoError(t, e)
+
+		opened, e := adapterBox.EasyOpen(sealed, nonce, base58.Decode(legacyEncKey), base58.Decode(encKey))
+		require.NoError(t, e)
+		require.Equal(t, msg, opened)
+
+		sealed, e = adapterBox.Easy(msg, nonce, base58.Decode(legacyEncKey), base58.Decode(encKey))
+		require.NoError(t, e)
+
+		opened, e = legacyBox.EasyOpen(sealed, nonce, base58.Decode(encKey), base58.Decode(legacyEncKey))
+		require.NoError(t, e)
+		require.Equal(t, msg, opened)
+	})
+
+	t.Run("seal", func(t *testing.T) {
+		sealed, e := legacyBox.Seal(msg, base58.Decode(encKey), rand.Reader)
+		require.NoError(t, e)
+
+		opened, e := adapterBox.SealOpen(sealed, base58.Decode(encKey))
+		require.NoError(t, e)
+		require.Equal(t, msg, opened)
+
+		_, e = adapterBox.SealOpen(sealed, base58.Decode(legacyEncKey))
+		require.Equal(t, cryptoutil.ErrKeyNotFound, e)
+	})
+}
+
+func newLocalKMS(t *testing.T) *localkms.LocalKMS {
+	t.Helper()
+
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	return localKMS
+}
+
+type listErrKMS struct {
+	*localkms.LocalKMS
+}
+
+func (k *listErrKMS) List() ([]*kms.KeyMetadata, error) {
+	return nil, errors.New("list error")
+}
+
+type listCountKMS struct {
+	*localkms.LocalKMS
+	lists int
+}
+
+func (k *listCountKMS) List() ([]*kms.KeyMetadata, error) {
+	k.lists++
+
+	return k.LocalKMS.List()
+}
@@ -11,8 +11,8 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/btcsuite/btcutil/base58"
 	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/salsa20/salsa"
 
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
@@ -26,16 +26,22 @@ import (
 // using a shared key derived from a shared secret created by
 //   Curve25519 Elliptic Curve Diffie-Hellman key exchange.
 //
-// CryptoBox is created by a LegacyKMS, and reads secret keys from the LegacyKMS
+// CryptoBox is created by a LegacyKMS, and has the LegacyKMS compute the shared secrets of its secret keys
 //   for encryption/decryption, so clients do not need to see
 //   the secrets themselves.
 type CryptoBox struct {
-	km *BaseKMS
+	km keyAgreement
+}
+
+// keyAgreement is implemented by the LegacyKMS implementations of this package (BaseKMS and KMSAdapter)
+type keyAgreement interface {
+	// sharedSecret computes the X25519 shared secret of theirPub and the private key of the encryption key myPub
+	sharedSecret(theirPub, myPub []byte) ([]byte, error)
 }
 
 // NewCryptoBox creates a CryptoBox which provides crypto box encryption using the given LegacyKMS's keypairs
 func NewCryptoBox(w KeyManager) (*CryptoBox, error) {
-	wa, ok := w.(*BaseKMS)
+	wa, ok := w.(keyAgreement)
 	if !ok {
 		return nil, fmt.Errorf("cannot use parameter as LegacyKMS")
 	}
@@ -51,20 +57,16 @@ func (b *CryptoBox) Easy(payload, nonce, theirPub, myPub []byte) ([]byte, error)
 	copy(recPubBytes[:], theirPub)
 
 	//	 myPub is used to get the sender private key for encryption
-	kp, err := b.km.getKeyPairSet(base58.Encode(myPub))
+	z, err := b.km.sharedSecret(recPubBytes[:], myPub)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		priv       [cryptoutil.Curve25519KeySize]byte
-		nonceBytes [cryptoutil.NonceSize]byte
-	)
+	var nonceBytes [cryptoutil.NonceSize]byte
 
-	copy(priv[:], kp.EncKeyPair.Priv)
 	copy(nonceBytes[:], nonce)
 
-	ret := box.Seal(nil, payload, &nonceBytes, &recPubBytes, &priv)
+	ret := box.SealAfterPrecomputation(nil, payload, &nonceBytes, sharedKey(z))
 
 	return ret, nil
 }
@@ -77,20 +79,16 @@ func (b *CryptoBox) EasyOpen(cipherText, nonce, theirPub, myPub []byte) ([]byte,
 
 	copy(sendPubBytes[:], theirPub)
 
-	kp, err := b.km.getKeyPairSet(base58.Encode(myPub))
+	z, err := b.km.sharedSecret(sendPubBytes[:], myPub)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		priv       [cryptoutil.Curve25519KeySize]byte
-		nonceBytes [cryptoutil.NonceSize]byte
-	)
+	var nonceBytes [cryptoutil.NonceSize]byte
 
-	copy(priv[:], kp.EncKeyPair.Priv)
 	copy(nonceBytes[:], nonce)
 
-	out, success := box.Open(nil, cipherText, &nonceBytes, &sendPubBytes, &priv)
+	out, success := box.OpenAfterPrecomputation(nil, cipherText, &nonceBytes, sharedKey(z))
 	if !success {
 		return nil, errors.New("failed to unpack")
 	}
@@ -133,28 +131,36 @@ func (b *CryptoBox) SealOpen(cipherText, myPub []byte) ([]byte, error) {
 		return nil, errors.New("message too short")
 	}
 
-	kp, err := b.km.getKeyPairSet(base58.Encode(myPub))
+	epk := cipherText[:cryptoutil.Curve25519KeySize]
+
+	z, err := b.km.sharedSecret(epk, myPub)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		epk  [cryptoutil.Curve25519KeySize]byte
-		priv [cryptoutil.Curve25519KeySize]byte
-	)
-
-	copy(epk[:], cipherText[:cryptoutil.Curve25519KeySize])
-	copy(priv[:], kp.EncKeyPair.Priv)
-
-	nonce, err := cryptoutil.Nonce(epk[:], myPub)
+	nonce, err := cryptoutil.Nonce(epk, myPub)
 	if err != nil {
 		return nil, err
 	}
 
-	out, success := box.Open(nil, cipherText[cryptoutil.Curve25519KeySize:], nonce, &epk, &priv)
+	out, success := box.OpenAfterPrecomputation(nil, cipherText[cryptoutil.Curve25519KeySize:], nonce, sharedKey(z))
 	if !success {
 		return nil, errors.New("failed to unpack")
 	}
 
 	return out, nil
 }
+
+// sharedKey derives the crypto box key of the X25519 shared secret z, as done by box.Precompute
+func sharedKey(z []byte) *[cryptoutil.Curve25519KeySize]byte {
+	var (
+		zeros [16]byte
+		key   [cryptoutil.Curve25519KeySize]byte
+	)
+
+	copy(key[:], z)
+
+	salsa.HSalsa20(&key, &zeros, &key, &salsa.Sigma)
+
+	return &key
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/btcsuite/btcutil/base58"
 	chacha "golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
@@ -224,6 +225,16 @@ func (w *BaseKMS) DeriveKEK(alg, apu, fromPubKey, toPubKey []byte) ([]byte, erro
 	return cryptoutil.Derive25519KEK(alg, apu, fromPrivKey, toKey)
 }
 
+// sharedSecret computes the X25519 shared secret of theirPub and the private key of the encryption key myPub
+func (w *BaseKMS) sharedSecret(theirPub, myPub []byte) ([]byte, error) {
+	kpc, err := w.getKeyPairSet(base58.Encode(myPub))
+	if err != nil {
+		return nil, err
+	}
+
+	return curve25519.X25519(kpc.EncKeyPair.Priv, theirPub)
+}
+
 // FindVerKey selects a signing key which is present in candidateKeys that is present in the LegacyKMS
 func (w *BaseKMS) FindVerKey(candidateKeys []string) (int, error) {
 	for i, key := range candidateKeys {
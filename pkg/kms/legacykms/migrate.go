@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// keyImporter is the kms.KeyManager the legacy key pairs are migrated to
+type keyImporter interface {
+	kms.PrivateKeyImporter
+	kms.KeyMetadataManager
+	ExportPubKeyBytes(keyID string) ([]byte, error)
+}
+
+// MigrateKeys imports the signature key pairs stored by the BaseKMS in the storage provider of ctx into km, e.g. a
+// localkms, as ED25519 keys labeled with VerKeyLabel to be used by a KMSAdapter. The encryption key pairs are not
+// imported as they are converted from the signature key pairs. Key pairs already migrated are skipped and keys imported
+// but not labeled yet are labeled, so that an interrupted migration can be run again, and the legacy keystore is left
+// untouched.
+// Returns the base58 encoded verification keys of the migrated key pairs.
+func MigrateKeys(ctx provider, km kms.KeyManager) ([]string, error) {
+	importer, ok := km.(keyImporter)
+	if !ok {
+		return nil, errors.New("migrate keys: key manager doesn't support private key import, public key export " +
+			"or key metadata")
+	}
+
+	store, err := ctx.StorageProvider().OpenStore(KeyStoreNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("migrate keys: failed to OpenStore for '%s', cause: %w", KeyStoreNamespace, err)
+	}
+
+	migrated, unlabeled, err := migratedVerKeys(importer)
+	if err != nil {
+		return nil, fmt.Errorf("migrate keys: %w", err)
+	}
+
+	keyPairs, err := legacyKeyPairs(store)
+	if err != nil {
+		return nil, fmt.Errorf("migrate keys: %w", err)
+	}
+
+	var verKeys []string
+
+	for _, kp := range keyPairs {
+		verKey := base58.Encode(kp.Pub)
+		if migrated[verKey] {
+			continue
+		}
+
+		keyID, ok := unlabeled[verKey]
+		if !ok {
+			keyID, _, err = importer.ImportPrivateKey(ed25519.PrivateKey(kp.Priv), kms.ED25519Type)
+			if err != nil {
+				return verKeys, fmt.Errorf("migrate keys: import key %s: %w", verKey, err)
+			}
+		}
+
+		err = importer.SetLabels(keyID, map[string]string{VerKeyLabel: verKey})
+		if err != nil {
+			return verKeys, fmt.Errorf("migrate keys: label key %s: %w", verKey, err)
+		}
+
+		migrated[verKey] = true
+		verKeys = append(verKeys, verKey)
+	}
+
+	return verKeys, nil
+}
+
+// migratedVerKeys returns the base58 encoded verification keys of the key pairs already migrated to km, and the IDs of
+// the ED25519 keys of km without VerKeyLabel by their base58 encoded public key, which an interrupted migration may have
+// imported without labeling them
+func migratedVerKeys(km keyImporter) (map[string]bool, map[string]string, error) {
+	keys, err := km.List()
+	if err != nil {
+		return nil, nil, fmt.Errorf("list keys: %w", err)
+	}
+
+	verKeys := map[string]bool{}
+	unlabeled := map[string]string{}
+
+	for _, key := range keys {
+		if verKey, ok := key.Labels[VerKeyLabel]; ok {
+			verKeys[verKey] = true
+
+			continue
+		}
+
+		if key.KeyType != kms.ED25519Type {
+			continue
+		}
+
+		pub, e := km.ExportPubKeyBytes(key.KeyID)
+		if e != nil {
+			return nil, nil, fmt.Errorf("export public key %s: %w", key.KeyID, e)
+		}
+
+		unlabeled[base58.Encode(pub)] = key.KeyID
+	}
+
+	return verKeys, unlabeled, nil
+}
+
+// legacyKeyPairs returns the signature key pairs of the legacy keystore, each stored under both its verification
+// key and its encryption key
+func legacyKeyPairs(store storage.Store) ([]*cryptoutil.SigKeyPair, error) {
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	var keyPairs []*cryptoutil.SigKeyPair
+
+	seen := map[string]bool{}
+
+	for itr.Next() {
+		var kpc cryptoutil.MessagingKeys
+
+		if err := json.Unmarshal(itr.Value(), &kpc); err != nil {
+			return nil, fmt.Errorf("failed unmarshal to key struct: %w", err)
+		}
+
+		if kpc.SigKeyPair == nil || len(kpc.SigKeyPair.Priv) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid signature key pair of key %s", itr.Key())
+		}
+
+		verKey := string(kpc.SigKeyPair.Pub)
+		if seen[verKey] {
+			continue
+		}
+
+		seen[verKey] = true
+		keyPairs = append(keyPairs, kpc.SigKeyPair)
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("iterate keystore: %w", err)
+	}
+
+	return keyPairs, nil
+}
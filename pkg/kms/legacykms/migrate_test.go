@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+
+	legacyKMS, err := New(newMockKMSProvider(storeProvider))
+	require.NoError(t, err)
+
+	encKey1, verKey1, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	_, verKey2, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	localKMS := newLocalKMS(t)
+
+	verKeys, err := MigrateKeys(newMockKMSProvider(storeProvider), localKMS)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{verKey1, verKey2}, verKeys)
+
+	// migrated keys are skipped
+	verKeys, err = MigrateKeys(newMockKMSProvider(storeProvider), localKMS)
+	require.NoError(t, err)
+	require.Empty(t, verKeys)
+
+	a, err := NewKMSAdapter(localKMS, &tinkcrypto.Crypto{})
+	require.NoError(t, err)
+
+	t.Run("migrated keys sign like the legacy keys", func(t *testing.T) {
+		sig, e := a.SignMessage([]byte("test message"), verKey2)
+		require.NoError(t, e)
+
+		legacySig, e := legacyKMS.SignMessage([]byte("test message"), verKey2)
+		require.NoError(t, e)
+		require.Equal(t, legacySig, sig)
+	})
+
+	t.Run("migrated keys open the legacy crypto boxes", func(t *testing.T) {
+		legacyBox, e := NewCryptoBox(legacyKMS)
+		require.NoError(t, e)
+
+		sealed, e := legacyBox.Seal([]byte("test message"), base58.Decode(encKey1), rand.Reader)
+		require.NoError(t, e)
+
+		adapterBox, e := NewCryptoBox(a)
+		require.NoError(t, e)
+
+		opened, e := adapterBox.SealOpen(sealed, base58.Decode(encKey1))
+		require.NoError(t, e)
+		require.Equal(t, []byte("test message"), opened)
+	})
+}
+
+func TestMigrateKeys_Interrupted(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+
+	legacyKMS, err := New(newMockKMSProvider(storeProvider))
+	require.NoError(t, err)
+
+	_, verKey, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	store, err := storeProvider.OpenStore(KeyStoreNamespace)
+	require.NoError(t, err)
+
+	keyPairs, err := legacyKeyPairs(store)
+	require.NoError(t, err)
+	require.Len(t, keyPairs, 1)
+
+	// the migration was interrupted after importing the key, before labeling it
+	localKMS := newLocalKMS(t)
+
+	keyID, _, err := localKMS.ImportPrivateKey(ed25519.PrivateKey(keyPairs[0].Priv), kms.ED25519Type)
+	require.NoError(t, err)
+
+	verKeys, err := MigrateKeys(newMockKMSProvider(storeProvider), localKMS)
+	require.NoError(t, err)
+	require.Equal(t, []string{verKey}, verKeys)
+
+	keys, err := localKMS.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, keyID, keys[0].KeyID)
+	require.Equal(t, map[string]string{VerKeyLabel: verKey}, keys[0].Labels)
+}
+
+func TestMigrateKeys_Errors(t *testing.T) {
+	t.Run("key manager without private key import", func(t *testing.T) {
+		_, err := MigrateKeys(newMockKMSProvider(mockstorage.NewMockStoreProvider()),
+			struct{ kms.KeyManager }{&mockkms.KeyManager{}})
+		require.EqualError(t, err, "migrate keys: key manager doesn't support private key import, public key export "+
+			"or key metadata")
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		_, err := MigrateKeys(newMockKMSProvider(
+			&mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open store error")}), newLocalKMS(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store error")
+	})
+
+	t.Run("list keys error", func(t *testing.T) {
+		_, err := MigrateKeys(newMockKMSProvider(mockstorage.NewMockStoreProvider()),
+			&listErrKMS{LocalKMS: newLocalKMS(t)})
+		require.EqualError(t, err, "migrate keys: list keys: list error")
+	})
+
+	t.Run("invalid legacy key pairs", func(t *testing.T) {
+		for value, errMsg := range map[string]string{
+			"{":  "failed unmarshal to key struct",
+			"{}": "invalid signature key pair of key",
+		} {
+			storeProvider := mockstorage.NewMockStoreProvider()
+
+			store, err := storeProvider.OpenStore(KeyStoreNamespace)
+			require.NoError(t, err)
+			require.NoError(t, store.Put("key", []byte(value)))
+
+			_, err = MigrateKeys(newMockKMSProvider(storeProvider), newLocalKMS(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), errMsg)
+		}
+	})
+
+	t.Run("import error", func(t *testing.T) {
+		storeProvider := mockstorage.NewMockStoreProvider()
+
+		legacyKMS, err := New(newMockKMSProvider(storeProvider))
+		require.NoError(t, err)
+
+		_, _, err = legacyKMS.CreateKeySet()
+		require.NoError(t, err)
+
+		_, err = MigrateKeys(newMockKMSProvider(storeProvider), &importErrKMS{LocalKMS: newLocalKMS(t)})
+		require.True(t, errors.Is(err, errImport))
+	})
+}
+
+var errImport = errors.New("import error")
+
+type importErrKMS struct {
+	*localkms.LocalKMS
+}
+
+func (k *importErrKMS) ImportPrivateKey(interface{}, kms.KeyType) (string, interface{}, error) {
+	return "", nil, errImport
+}
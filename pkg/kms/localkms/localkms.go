@@ -16,8 +16,9 @@ import (
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/signature"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/secp256k1"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
-	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -46,14 +47,12 @@ func New(masterKeyURI string, p kms.Provider) (*LocalKMS, error) {
 
 	secretLock := p.SecretLock()
 
-	kw, err := keywrapper.New(secretLock, masterKeyURI)
+	// create a KMSEnvelopeAEAD instance to wrap/unwrap keys managed by LocalKMS
+	masterKeyEnvAEAD, err := envelopeAEAD(secretLock, masterKeyURI)
 	if err != nil {
 		return nil, err
 	}
 
-	// create a KMSEnvelopeAEAD instance to wrap/unwrap keys managed by LocalKMS
-	masterKeyEnvAEAD := aead.NewKMSEnvelopeAEAD(*aead.AES256GCMKeyTemplate(), kw)
-
 	return &LocalKMS{
 			store:            store,
 			secretLock:       secretLock,
@@ -68,17 +67,12 @@ func (l *LocalKMS) Create(kt kms.KeyType) (string, interface{}, error) {
 		return "", nil, fmt.Errorf("failed to create new key, missing key type")
 	}
 
-	keyTemplate, err := getKeyTemplate(kt)
-	if err != nil {
-		return "", nil, err
-	}
-
-	kh, err := keyset.NewHandle(keyTemplate)
+	kh, err := newKeyHandle(kt)
 	if err != nil {
 		return "", nil, err
 	}
 
-	kID, err := l.storeKeySet(kh)
+	kID, err := l.storeNewKey(kh, kt, nil)
 	if err != nil {
 		return "", nil, err
 	}
@@ -91,42 +85,75 @@ func (l *LocalKMS) Get(keyID string) (interface{}, error) {
 	return l.getKeySet(keyID)
 }
 
-// Rotate a key referenced by keyID and return its updated handle
+// Rotate a key referenced by keyID and return its updated handle.
+// The metadata of the rotated key references keyID and keeps its labels.
 func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
 	kh, err := l.getKeySet(keyID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	keyTemplate, err := getKeyTemplate(kt)
+	updatedKH, err := rotateKeyHandle(kh, kt)
 	if err != nil {
 		return "", nil, err
 	}
 
-	km := keyset.NewManagerFromHandle(kh)
-
-	err = km.Rotate(keyTemplate)
+	md, err := l.getMetadata(keyID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	updatedKH, err := km.Handle()
-
+	err = l.Delete(keyID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	err = l.store.Delete(keyID)
+	newID, err := l.storeNewKey(updatedKH, kt, md)
 	if err != nil {
 		return "", nil, err
 	}
 
-	newID, err := l.storeKeySet(updatedKH)
+	return newID, updatedKH, nil
+}
+
+// newKeyHandle creates a key handle of a new key of type kt
+func newKeyHandle(kt kms.KeyType) (*keyset.Handle, error) {
+	// BBS+ and secp256k1 keys are not supported by Tink key templates
+	switch kt {
+	case kms.BLS12381G2Type:
+		return bbs12381g2pub.NewKeyHandle()
+	case kms.ECDSASecp256k1Type:
+		return secp256k1.NewKeyHandle()
+	}
+
+	keyTemplate, err := getKeyTemplate(kt)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return newID, updatedKH, nil
+	return keyset.NewHandle(keyTemplate)
+}
+
+// rotateKeyHandle returns a key handle of kh with a new primary key of type kt
+func rotateKeyHandle(kh *keyset.Handle, kt kms.KeyType) (*keyset.Handle, error) {
+	// BBS+ and secp256k1 keysets hold a single key, rotating them replaces the key
+	if kt == kms.BLS12381G2Type || kt == kms.ECDSASecp256k1Type {
+		return newKeyHandle(kt)
+	}
+
+	keyTemplate, err := getKeyTemplate(kt)
+	if err != nil {
+		return nil, err
+	}
+
+	km := keyset.NewManagerFromHandle(kh)
+
+	err = km.Rotate(keyTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return km.Handle()
 }
 
 // nolint:gocyclo
@@ -186,6 +213,10 @@ func (l *LocalKMS) getKeySet(id string) (*keyset.Handle, error) {
 	// and decrypts it using masterKeyEnvAEAD.
 	kh, err := keyset.Read(jsonKeysetReader, l.masterKeyEnvAEAD)
 	if err != nil {
+		if rotationErr := checkRotation(l.store, l.masterKeyURI); rotationErr != nil {
+			return nil, fmt.Errorf("failed to read keyset %s: %w", id, rotationErr)
+		}
+
 		return nil, err
 	}
 
@@ -202,6 +233,16 @@ func (l *LocalKMS) ExportPubKeyBytes(id string) ([]byte, error) {
 		return nil, err
 	}
 
+	// BBS+ public keys are exported in their compressed G2 point form
+	if bbs12381g2pub.IsKeyHandle(kh) {
+		return bbs12381g2pub.PublicKeyBytes(kh)
+	}
+
+	// secp256k1 public keys are exported in their uncompressed form, like other ECDSA keys
+	if secp256k1.IsKeyHandle(kh) {
+		return secp256k1.PublicKeyBytes(kh)
+	}
+
 	// kh must be a private asymmetric key in order to extract its public key
 	pubKH, err := kh.Public()
 	if err != nil {
@@ -224,5 +265,12 @@ func (l *LocalKMS) ExportPubKeyBytes(id string) ([]byte, error) {
 // Note: The key handle created is not stored in the KMS, it's only useful to execute the crypto primitive
 // associated with it.
 func (l *LocalKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (*keyset.Handle, error) {
+	switch kt {
+	case kms.BLS12381G2Type:
+		return bbs12381g2pub.PublicKeyHandle(pubKey)
+	case kms.ECDSASecp256k1Type:
+		return secp256k1.PublicKeyHandle(pubKey)
+	}
+
 	return publicKeyBytesToHandle(pubKey, kt)
 }
@@ -19,6 +19,9 @@ import (
 	"github.com/google/tink/go/subtle/random"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/bbs12381g2pub"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/secp256k1"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
 	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
@@ -266,6 +269,103 @@ func TestLocalKMS_Success(t *testing.T) {
 	}
 }
 
+func TestLocalKMS_BBSKey(t *testing.T) {
+	storeDB := make(map[string][]byte)
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage: mockstorage.NewCustomMockStoreProvider(
+			&mockstorage.MockStore{
+				Store: storeDB,
+			}),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keyID, kh, err := kmsService.Create(kms.BLS12381G2Type)
+	require.NoError(t, err)
+	require.NotEmpty(t, keyID)
+	require.NotEmpty(t, storeDB[keyID])
+
+	privKeyBytes, err := bbs12381g2pub.PrivateKeyBytes(kh.(*keyset.Handle))
+	require.NoError(t, err)
+
+	// the key is read back decrypted from the store
+	loadedKH, err := kmsService.Get(keyID)
+	require.NoError(t, err)
+
+	loadedPrivKeyBytes, err := bbs12381g2pub.PrivateKeyBytes(loadedKH.(*keyset.Handle))
+	require.NoError(t, err)
+	require.Equal(t, privKeyBytes, loadedPrivKeyBytes)
+
+	pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	messages := [][]byte{[]byte("message1"), []byte("message2")}
+
+	sig, err := bbs12381g2pub.Sign(messages, privKeyBytes)
+	require.NoError(t, err)
+
+	pubKH, err := kmsService.PubKeyBytesToHandle(pubKeyBytes, kms.BLS12381G2Type)
+	require.NoError(t, err)
+
+	pubKHBytes, err := bbs12381g2pub.PublicKeyBytes(pubKH)
+	require.NoError(t, err)
+	require.NoError(t, bbs12381g2pub.Verify(messages, sig, pubKHBytes))
+
+	_, err = kmsService.PubKeyBytesToHandle([]byte("bad key"), kms.BLS12381G2Type)
+	require.Error(t, err)
+
+	newKeyID, rotatedKH, err := kmsService.Rotate(kms.BLS12381G2Type, keyID)
+	require.NoError(t, err)
+	require.NotEqual(t, keyID, newKeyID)
+
+	rotatedPrivKeyBytes, err := bbs12381g2pub.PrivateKeyBytes(rotatedKH.(*keyset.Handle))
+	require.NoError(t, err)
+	require.NotEqual(t, privKeyBytes, rotatedPrivKeyBytes)
+}
+
+func TestLocalKMS_Secp256k1Key(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keyID, _, err := kmsService.Create(kms.ECDSASecp256k1Type)
+	require.NoError(t, err)
+
+	loadedKH, err := kmsService.Get(keyID)
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	sig, err := secp256k1.Sign(msg, loadedKH.(*keyset.Handle))
+	require.NoError(t, err)
+
+	pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	// exported public keys are used as EcdsaSecp256k1VerificationKey2019 keys
+	err = verifier.NewECDSASecp256k1SignatureVerifier().Verify(&verifier.PublicKey{Value: pubKeyBytes}, msg, sig)
+	require.NoError(t, err)
+
+	pubKH, err := kmsService.PubKeyBytesToHandle(pubKeyBytes, kms.ECDSASecp256k1Type)
+	require.NoError(t, err)
+	require.NoError(t, secp256k1.Verify(sig, msg, pubKH))
+
+	_, err = kmsService.PubKeyBytesToHandle([]byte("bad key"), kms.ECDSASecp256k1Type)
+	require.Error(t, err)
+
+	newKeyID, _, err := kmsService.Rotate(kms.ECDSASecp256k1Type, keyID)
+	require.NoError(t, err)
+
+	newPubKeyBytes, err := kmsService.ExportPubKeyBytes(newKeyID)
+	require.NoError(t, err)
+	require.NotEqual(t, pubKeyBytes, newPubKeyBytes)
+
+	_, err = kmsService.Get(keyID)
+	require.Error(t, err)
+}
+
 func TestLocalKMS_getKeyTemplate(t *testing.T) {
 	keyTemplate, err := getKeyTemplate(kms.HMACSHA256Tag256Type)
 	require.NoError(t, err)
@@ -329,7 +429,7 @@ func createMasterKeyAndSecretLock(t *testing.T) secretlock.Service {
 
 // mockProvider mocks a provider for KMS storage
 type mockProvider struct {
-	storage    *mockstorage.MockStoreProvider
+	storage    storage.Provider
 	secretLock secretlock.Service
 }
 
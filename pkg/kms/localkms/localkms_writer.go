@@ -18,15 +18,19 @@ import (
 
 // newWriter creates a new instance of local storage key storeWriter in the given store and for masterKeyURI
 func newWriter(kmsStore storage.Store, masterKeyURI string) *storeWriter {
-	mkURI := masterKeyURI
-	if strings.LastIndex(mkURI, "/") < len(mkURI)-1 {
-		mkURI += "/"
-	}
-
 	return &storeWriter{
 		storage:      kmsStore,
-		masterKeyURI: mkURI,
+		masterKeyURI: keysetIDPrefix(masterKeyURI),
+	}
+}
+
+// keysetIDPrefix returns the prefix of the IDs of the keysets wrapped with masterKeyURI
+func keysetIDPrefix(masterKeyURI string) string {
+	if strings.LastIndex(masterKeyURI, "/") < len(masterKeyURI)-1 {
+		return masterKeyURI + "/"
 	}
+
+	return masterKeyURI
 }
 
 // storeWriter struct to store a keyset in a local store
@@ -0,0 +1,213 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// rotationKeyPrefix prefixes the store key of the marker of a master key rotation in progress
+const rotationKeyPrefix = "kmsmasterkeyrotation_"
+
+// ErrMasterKeyRotationInProgress is returned by a LocalKMS failing to decrypt a keyset when a master key rotation of
+// its master key URI was interrupted, RotateMasterKey must be run again to complete it before the keys can be used.
+var ErrMasterKeyRotationInProgress = errors.New("master key rotation in progress")
+
+// RotateMasterKey re-encrypts every keyset stored by a LocalKMS of masterKeyURI in the storage provider of p, from the
+// secret lock of p to newSecretLock, e.g. local secret locks of the old and new master keys. Key IDs and metadata are
+// unchanged. Every keyset is re-encrypted and verified before any of them is written, and they are all replaced
+// atomically with one storage batch when the store is a storage.BatchableStore. Other stores get each keyset replaced
+// with its own write, so it can always be decrypted with exactly one of the locks: an interrupted rotation is resumed
+// by running it again with the same locks, keysets already encrypted with newSecretLock being skipped, and until it
+// completes a LocalKMS of masterKeyURI returns ErrMasterKeyRotationInProgress for the keysets it can't decrypt.
+// Returns the number of keysets re-encrypted.
+func RotateMasterKey(masterKeyURI string, p kms.Provider, newSecretLock secretlock.Service) (int, error) {
+	store, err := p.StorageProvider().OpenStore(Namespace)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: failed to open store: %w", err)
+	}
+
+	oldAEAD, err := envelopeAEAD(p.SecretLock(), masterKeyURI)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	newAEAD, err := envelopeAEAD(newSecretLock, masterKeyURI)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	keysetIDs, err := storedKeysetIDs(store, masterKeyURI)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	keysets, err := reencryptKeysets(store, keysetIDs, oldAEAD, newAEAD)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	if batchable, ok := store.(storage.BatchableStore); ok {
+		err = writeKeysetsBatch(batchable, keysets)
+	} else {
+		err = writeKeysets(store, masterKeyURI, keysets)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	return len(keysets), nil
+}
+
+// rotatedKeyset is a keyset encrypted with the new master key of a rotation
+type rotatedKeyset struct {
+	id     string
+	keyset []byte
+}
+
+// reencryptKeysets returns the keysets of keysetIDs encrypted with newAEAD, skipping the ones already encrypted with
+// newAEAD by an interrupted or completed rotation. It fails without any write if oldAEAD doesn't decrypt one of the
+// other keysets, so that a wrong current master key doesn't leave the keys unusable.
+func reencryptKeysets(store storage.Store, keysetIDs []string, oldAEAD,
+	newAEAD *aead.KMSEnvelopeAEAD) ([]*rotatedKeyset, error) {
+	var keysets []*rotatedKeyset
+
+	for _, id := range keysetIDs {
+		encrypted, err := reencryptKeyset(store, id, oldAEAD, newAEAD)
+		if err != nil {
+			return nil, fmt.Errorf("keyset %s: %w", id, err)
+		}
+
+		if encrypted != nil {
+			keysets = append(keysets, &rotatedKeyset{id: id, keyset: encrypted})
+		}
+	}
+
+	return keysets, nil
+}
+
+// reencryptKeyset returns the keyset id encrypted with newAEAD, or nil if it is already encrypted with newAEAD
+func reencryptKeyset(store storage.Store, id string, oldAEAD, newAEAD *aead.KMSEnvelopeAEAD) ([]byte, error) {
+	kh, err := keyset.Read(keyset.NewJSONReader(newReader(store, id)), oldAEAD)
+	if err != nil {
+		// keysets of an interrupted rotation may already be encrypted with the new master key
+		if _, e := keyset.Read(keyset.NewJSONReader(newReader(store, id)), newAEAD); e == nil {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("current master key doesn't decrypt keyset: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = kh.Write(keyset.NewJSONWriter(buf), newAEAD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt keyset: %w", err)
+	}
+
+	// ensure the new master key decrypts the keyset before replacing it
+	_, err = keyset.Read(keyset.NewJSONReader(bytes.NewReader(buf.Bytes())), newAEAD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify keyset encryption: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeKeysetsBatch replaces the keysets of store with their new encryption in one batch
+func writeKeysetsBatch(store storage.BatchableStore, keysets []*rotatedKeyset) error {
+	if len(keysets) == 0 {
+		return nil
+	}
+
+	batch := store.NewBatch()
+
+	for _, k := range keysets {
+		batch.Put(k.id, k.keyset)
+	}
+
+	err := batch.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to store keysets: %w", err)
+	}
+
+	return nil
+}
+
+// writeKeysets replaces the keysets of store with their new encryption one by one, with the rotation of masterKeyURI
+// marked in progress until they are all written
+func writeKeysets(store storage.Store, masterKeyURI string, keysets []*rotatedKeyset) error {
+	err := store.Put(rotationKeyPrefix+masterKeyURI, []byte(masterKeyURI))
+	if err != nil {
+		return fmt.Errorf("failed to mark rotation: %w", err)
+	}
+
+	for _, k := range keysets {
+		err = store.Put(k.id, k.keyset)
+		if err != nil {
+			return fmt.Errorf("keyset %s: failed to store keyset: %w", k.id, err)
+		}
+	}
+
+	err = store.Delete(rotationKeyPrefix + masterKeyURI)
+	if err != nil {
+		return fmt.Errorf("failed to unmark rotation: %w", err)
+	}
+
+	return nil
+}
+
+// storedKeysetIDs returns the IDs of the keysets of masterKeyURI in store
+func storedKeysetIDs(store storage.Store, masterKeyURI string) ([]string, error) {
+	prefix := keysetIDPrefix(masterKeyURI)
+
+	itr := store.Iterator(prefix, prefix+storage.EndKeySuffix)
+	defer itr.Release()
+
+	var ids []string
+
+	for itr.Next() {
+		ids = append(ids, string(itr.Key()))
+	}
+
+	if itr.Error() != nil {
+		return nil, fmt.Errorf("failed to iterate keysets: %w", itr.Error())
+	}
+
+	return ids, nil
+}
+
+// checkRotation returns ErrMasterKeyRotationInProgress if a master key rotation of masterKeyURI was interrupted. Store
+// errors are not returned, the error of the keyset read is reported instead.
+func checkRotation(store storage.Store, masterKeyURI string) error {
+	if _, err := store.Get(rotationKeyPrefix + masterKeyURI); err == nil {
+		return ErrMasterKeyRotationInProgress
+	}
+
+	return nil
+}
+
+// envelopeAEAD returns the KMSEnvelopeAEAD wrapping the keys of masterKeyURI with secretLock
+func envelopeAEAD(secretLock secretlock.Service, masterKeyURI string) (*aead.KMSEnvelopeAEAD, error) {
+	kw, err := keywrapper.New(secretLock, masterKeyURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.NewKMSEnvelopeAEAD(*aead.AES256GCMKeyTemplate(), kw), nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestRotateMasterKey(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+	oldLock := newLocalLock(t)
+	newLock := newLocalLock(t)
+
+	oldKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: oldLock})
+	require.NoError(t, err)
+
+	sigKeyID, _, err := oldKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	require.NoError(t, oldKMS.SetLabels(sigKeyID, map[string]string{"name": "signing key"}))
+
+	aeadKeyID, aeadKH, err := oldKMS.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	c := &tinkcrypto.Crypto{}
+
+	ct, nonce, err := c.Encrypt([]byte("test message"), nil, aeadKH)
+	require.NoError(t, err)
+
+	rotated, err := RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: oldLock},
+		newLock)
+	require.NoError(t, err)
+	require.Equal(t, 2, rotated)
+
+	_, err = oldKMS.Get(sigKeyID)
+	require.Error(t, err)
+
+	newKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock})
+	require.NoError(t, err)
+
+	kh, err := newKMS.Get(aeadKeyID)
+	require.NoError(t, err)
+
+	pt, err := c.Decrypt(ct, nonce, nil, kh)
+	require.NoError(t, err)
+	require.Equal(t, []byte("test message"), pt)
+
+	md, err := newKMS.Metadata(sigKeyID)
+	require.NoError(t, err)
+	require.Equal(t, kms.ED25519Type, md.KeyType)
+	require.Equal(t, map[string]string{"name": "signing key"}, md.Labels)
+
+	t.Run("resume interrupted rotation", func(t *testing.T) {
+		thirdLock := newLocalLock(t)
+		store := storeProvider.Store
+
+		oldKeyset := store.Store[sigKeyID]
+
+		rotated, err = RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock},
+			thirdLock)
+		require.NoError(t, err)
+		require.Equal(t, 2, rotated)
+
+		// interrupt the rotation before the signing keyset is replaced
+		store.Store[sigKeyID] = oldKeyset
+		store.Store[rotationKeyPrefix+testMasterKeyURI] = []byte(testMasterKeyURI)
+
+		interruptedKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: thirdLock})
+		require.NoError(t, err)
+
+		_, err = interruptedKMS.Get(aeadKeyID)
+		require.NoError(t, err)
+
+		_, err = interruptedKMS.Get(sigKeyID)
+		require.True(t, errors.Is(err, ErrMasterKeyRotationInProgress))
+
+		rotated, err = RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock},
+			thirdLock)
+		require.NoError(t, err)
+		require.Equal(t, 1, rotated)
+
+		resumedKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: thirdLock})
+		require.NoError(t, err)
+
+		_, err = resumedKMS.Get(sigKeyID)
+		require.NoError(t, err)
+	})
+}
+
+func TestRotateMasterKey_Batch(t *testing.T) {
+	storeProvider := mem.NewProvider()
+	oldLock := newLocalLock(t)
+	newLock := newLocalLock(t)
+
+	oldKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: oldLock})
+	require.NoError(t, err)
+
+	sigKeyID, _, err := oldKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	aeadKeyID, _, err := oldKMS.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	t.Run("failed batch leaves every keyset with the old master key", func(t *testing.T) {
+		_, err = RotateMasterKey(testMasterKeyURI, &mockProvider{
+			storage:    &failingBatchProvider{Provider: storeProvider},
+			secretLock: oldLock,
+		}, newLock)
+		require.EqualError(t, err, "rotate master key: failed to store keysets: commit error")
+
+		_, err = oldKMS.Get(sigKeyID)
+		require.NoError(t, err)
+
+		_, err = oldKMS.Get(aeadKeyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("keysets are replaced in one batch", func(t *testing.T) {
+		rotated, err := RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: oldLock},
+			newLock)
+		require.NoError(t, err)
+		require.Equal(t, 2, rotated)
+
+		newKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock})
+		require.NoError(t, err)
+
+		_, err = newKMS.Get(sigKeyID)
+		require.NoError(t, err)
+
+		_, err = newKMS.Get(aeadKeyID)
+		require.NoError(t, err)
+
+		// no rotation marker is needed for an atomic rotation
+		store, err := storeProvider.OpenStore(Namespace)
+		require.NoError(t, err)
+
+		_, err = store.Get(rotationKeyPrefix + testMasterKeyURI)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+func TestRotateMasterKey_Failure(t *testing.T) {
+	t.Run("open store error", func(t *testing.T) {
+		_, err := RotateMasterKey(testMasterKeyURI, &mockProvider{
+			storage: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open store error")},
+		}, newLocalLock(t))
+		require.EqualError(t, err, "rotate master key: failed to open store: open store error")
+	})
+
+	t.Run("bad master key URI", func(t *testing.T) {
+		_, err := RotateMasterKey("bad-prefix://test/key/uri", &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: newLocalLock(t),
+		}, newLocalLock(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "keyURI must start with")
+	})
+
+	t.Run("iterator error", func(t *testing.T) {
+		_, err := RotateMasterKey(testMasterKeyURI, &mockProvider{
+			storage: &mockstorage.MockStoreProvider{Store: &mockstorage.MockStore{
+				Store:  map[string][]byte{},
+				ErrItr: fmt.Errorf("iterator error"),
+			}},
+			secretLock: newLocalLock(t),
+		}, newLocalLock(t))
+		require.EqualError(t, err, "rotate master key: failed to iterate keysets: iterator error")
+	})
+
+	t.Run("keyset encrypted with another master key", func(t *testing.T) {
+		storeProvider := mockstorage.NewMockStoreProvider()
+
+		localKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLocalLock(t)})
+		require.NoError(t, err)
+
+		_, _, err = localKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLocalLock(t)},
+			newLocalLock(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "current master key doesn't decrypt keyset")
+
+		// the keys remain usable with their master key
+		require.NotContains(t, storeProvider.Store.Store, rotationKeyPrefix+testMasterKeyURI)
+	})
+
+	t.Run("one of the keysets encrypted with another master key", func(t *testing.T) {
+		storeProvider := mockstorage.NewMockStoreProvider()
+		lock := newLocalLock(t)
+
+		localKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: lock})
+		require.NoError(t, err)
+
+		keyID, _, err := localKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		otherKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLocalLock(t)})
+		require.NoError(t, err)
+
+		_, _, err = otherKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = RotateMasterKey(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: lock},
+			newLocalLock(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "current master key doesn't decrypt keyset")
+
+		// nothing was written
+		require.NotContains(t, storeProvider.Store.Store, rotationKeyPrefix+testMasterKeyURI)
+
+		_, err = localKMS.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		lock := newLocalLock(t)
+
+		localKMS, err := New(testMasterKeyURI, &mockProvider{
+			storage:    &mockstorage.MockStoreProvider{Store: store},
+			secretLock: lock,
+		})
+		require.NoError(t, err)
+
+		_, _, err = localKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		store.ErrPut = fmt.Errorf("put error")
+
+		_, err = RotateMasterKey(testMasterKeyURI, &mockProvider{
+			storage:    &mockstorage.MockStoreProvider{Store: store},
+			secretLock: lock,
+		}, newLocalLock(t))
+		require.EqualError(t, err, "rotate master key: failed to mark rotation: put error")
+	})
+}
+
+// failingBatchProvider opens batchable stores whose batches fail to commit
+type failingBatchProvider struct {
+	storage.Provider
+}
+
+func (p *failingBatchProvider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &failingBatchStore{Store: store}, nil
+}
+
+type failingBatchStore struct {
+	storage.Store
+}
+
+func (s *failingBatchStore) NewBatch() storage.Batch {
+	return &failingBatch{Batch: s.Store.(storage.BatchableStore).NewBatch()}
+}
+
+type failingBatch struct {
+	storage.Batch
+}
+
+func (b *failingBatch) Commit() error {
+	return errors.New("commit error")
+}
+
+func newLocalLock(t *testing.T) secretlock.Service {
+	t.Helper()
+
+	masterKey := base64.URLEncoding.EncodeToString(random.GetRandomBytes(uint32(32)))
+
+	lock, err := local.NewService(bytes.NewReader([]byte(masterKey)), nil)
+	require.NoError(t, err)
+
+	return lock
+}
@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// metadataKeyPrefix prefixes the store keys of key metadata, keysets are stored under the master key URI
+const metadataKeyPrefix = "kmsmetadata_"
+
+// errInvalidKeyID is returned for IDs that don't reference a keyset wrapped with the master key of the KMS
+var errInvalidKeyID = errors.New("invalid key ID")
+
+// List returns the metadata of all keys wrapped with the master key of the KMS, sorted by creation time.
+// Keys created before metadata was kept only have a KeyID.
+func (l *LocalKMS) List() ([]*kms.KeyMetadata, error) {
+	prefix := keysetIDPrefix(l.masterKeyURI)
+
+	itr := l.store.Iterator(prefix, prefix+storage.EndKeySuffix)
+	defer itr.Release()
+
+	var keys []*kms.KeyMetadata
+
+	for itr.Next() {
+		md, err := l.getMetadata(string(itr.Key()))
+		if err != nil {
+			return nil, fmt.Errorf("list keys: %w", err)
+		}
+
+		keys = append(keys, md)
+	}
+
+	if itr.Error() != nil {
+		return nil, fmt.Errorf("list keys: %w", itr.Error())
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].Created.Equal(keys[j].Created) {
+			return keys[i].KeyID < keys[j].KeyID
+		}
+
+		return keys[i].Created.Before(keys[j].Created)
+	})
+
+	return keys, nil
+}
+
+// Metadata returns the metadata of the key referenced by keyID
+func (l *LocalKMS) Metadata(keyID string) (*kms.KeyMetadata, error) {
+	err := l.checkKeyID(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	_, err = l.store.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	md, err := l.getMetadata(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key metadata: %w", err)
+	}
+
+	return md, nil
+}
+
+// SetLabels replaces the labels of the key referenced by keyID
+func (l *LocalKMS) SetLabels(keyID string, labels map[string]string) error {
+	md, err := l.Metadata(keyID)
+	if err != nil {
+		return err
+	}
+
+	md.Labels = labels
+
+	err = l.putMetadata(md)
+	if err != nil {
+		return fmt.Errorf("set key labels: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes the key referenced by keyID along with its metadata
+func (l *LocalKMS) Delete(keyID string) error {
+	err := l.checkKeyID(keyID)
+	if err != nil {
+		return fmt.Errorf("delete key: %w", err)
+	}
+
+	_, err = l.store.Get(keyID)
+	if err != nil {
+		return fmt.Errorf("delete key: %w", err)
+	}
+
+	err = l.store.Delete(keyID)
+	if err != nil {
+		return fmt.Errorf("delete key: %w", err)
+	}
+
+	err = l.store.Delete(metadataKeyPrefix + keyID)
+	if err != nil {
+		return fmt.Errorf("delete key metadata: %w", err)
+	}
+
+	return nil
+}
+
+// storeNewKey stores the keyset of a new key of type kt with its metadata and returns its keyID
+func (l *LocalKMS) storeNewKey(kh *keyset.Handle, kt kms.KeyType, rotatedFrom *kms.KeyMetadata) (string, error) {
+	kID, err := l.storeKeySet(kh)
+	if err != nil {
+		return "", err
+	}
+
+	md := &kms.KeyMetadata{
+		KeyID:   kID,
+		KeyType: kt,
+		Created: time.Now().UTC(),
+	}
+
+	if rotatedFrom != nil {
+		md.RotatedFrom = rotatedFrom.KeyID
+		md.Labels = rotatedFrom.Labels
+	}
+
+	err = l.putMetadata(md)
+	if err != nil {
+		return "", fmt.Errorf("store key metadata: %w", err)
+	}
+
+	return kID, nil
+}
+
+// checkKeyID makes sure keyID references a keyset wrapped with the master key of the KMS and not any other record
+// of the store, such as key metadata or another master key's keysets.
+func (l *LocalKMS) checkKeyID(keyID string) error {
+	prefix := keysetIDPrefix(l.masterKeyURI)
+
+	if !strings.HasPrefix(keyID, prefix) || strings.Contains(keyID[len(prefix):], "/") || len(keyID) == len(prefix) {
+		return errInvalidKeyID
+	}
+
+	return nil
+}
+
+func (l *LocalKMS) getMetadata(keyID string) (*kms.KeyMetadata, error) {
+	data, err := l.store.Get(metadataKeyPrefix + keyID)
+	if err != nil {
+		if err == storage.ErrDataNotFound {
+			return &kms.KeyMetadata{KeyID: keyID}, nil
+		}
+
+		return nil, err
+	}
+
+	md := &kms.KeyMetadata{}
+
+	err = json.Unmarshal(data, md)
+	if err != nil {
+		return nil, err
+	}
+
+	return md, nil
+}
+
+func (l *LocalKMS) putMetadata(md *kms.KeyMetadata) error {
+	data, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+
+	return l.store.Put(metadataKeyPrefix+md.KeyID, data)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_KeyMetadata(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    storeProvider,
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keys, err := kmsService.List()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	edKeyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	p256KeyID, _, err := kmsService.Create(kms.ECDSAP256Type)
+	require.NoError(t, err)
+
+	t.Run("create stores the key metadata", func(t *testing.T) {
+		md, e := kmsService.Metadata(edKeyID)
+		require.NoError(t, e)
+		require.Equal(t, edKeyID, md.KeyID)
+		require.Equal(t, kms.ED25519Type, md.KeyType)
+		require.False(t, md.Created.IsZero())
+		require.Empty(t, md.RotatedFrom)
+		require.Empty(t, md.Labels)
+	})
+
+	t.Run("list returns the keys in creation order", func(t *testing.T) {
+		keys, e := kmsService.List()
+		require.NoError(t, e)
+		require.Len(t, keys, 2)
+		require.Equal(t, edKeyID, keys[0].KeyID)
+		require.Equal(t, p256KeyID, keys[1].KeyID)
+		require.Equal(t, kms.ECDSAP256Type, keys[1].KeyType)
+	})
+
+	t.Run("set labels", func(t *testing.T) {
+		labels := map[string]string{"purpose": "signing"}
+
+		require.NoError(t, kmsService.SetLabels(edKeyID, labels))
+
+		md, e := kmsService.Metadata(edKeyID)
+		require.NoError(t, e)
+		require.Equal(t, labels, md.Labels)
+
+		e = kmsService.SetLabels(keysetIDPrefix(testMasterKeyURI)+"unknown", labels)
+		require.EqualError(t, e, "get key metadata: "+storage.ErrDataNotFound.Error())
+	})
+
+	t.Run("rotate keeps track of the rotated key", func(t *testing.T) {
+		rotatedKeyID, _, e := kmsService.Rotate(kms.ED25519Type, edKeyID)
+		require.NoError(t, e)
+		require.NotEqual(t, edKeyID, rotatedKeyID)
+
+		md, e := kmsService.Metadata(rotatedKeyID)
+		require.NoError(t, e)
+		require.Equal(t, edKeyID, md.RotatedFrom)
+		require.Equal(t, map[string]string{"purpose": "signing"}, md.Labels)
+
+		_, e = kmsService.Metadata(edKeyID)
+		require.Error(t, e)
+
+		edKeyID = rotatedKeyID
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, kmsService.Delete(p256KeyID))
+
+		_, e := kmsService.Get(p256KeyID)
+		require.Error(t, e)
+
+		_, e = kmsService.Metadata(p256KeyID)
+		require.EqualError(t, e, "get key metadata: "+storage.ErrDataNotFound.Error())
+
+		keys, e := kmsService.List()
+		require.NoError(t, e)
+		require.Len(t, keys, 1)
+		require.Equal(t, edKeyID, keys[0].KeyID)
+
+		e = kmsService.Delete(p256KeyID)
+		require.EqualError(t, e, "delete key: "+storage.ErrDataNotFound.Error())
+	})
+
+	t.Run("only key IDs of the master key are accepted", func(t *testing.T) {
+		otherKeyID := "local-lock://other/master/key/" + edKeyID[len(keysetIDPrefix(testMasterKeyURI)):]
+		storeProvider.Store.Store[otherKeyID] = []byte("keyset")
+		defer delete(storeProvider.Store.Store, otherKeyID)
+
+		for _, keyID := range []string{
+			metadataKeyPrefix + edKeyID,
+			rotationKeyPrefix + testMasterKeyURI,
+			otherKeyID,
+			keysetIDPrefix(testMasterKeyURI),
+			edKeyID + "/nested",
+		} {
+			_, e := kmsService.Metadata(keyID)
+			require.True(t, errors.Is(e, errInvalidKeyID), keyID)
+
+			e = kmsService.SetLabels(keyID, map[string]string{"purpose": "signing"})
+			require.True(t, errors.Is(e, errInvalidKeyID), keyID)
+
+			e = kmsService.Delete(keyID)
+			require.True(t, errors.Is(e, errInvalidKeyID), keyID)
+		}
+
+		require.Contains(t, storeProvider.Store.Store, otherKeyID)
+		require.Contains(t, storeProvider.Store.Store, metadataKeyPrefix+edKeyID)
+	})
+
+	t.Run("key without metadata", func(t *testing.T) {
+		keyID, _, e := kmsService.Create(kms.AES128GCMType)
+		require.NoError(t, e)
+
+		// keys created before metadata was kept
+		delete(storeProvider.Store.Store, metadataKeyPrefix+keyID)
+
+		md, e := kmsService.Metadata(keyID)
+		require.NoError(t, e)
+		require.Equal(t, &kms.KeyMetadata{KeyID: keyID}, md)
+
+		require.NoError(t, kmsService.Delete(keyID))
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		storeProvider.Store.Store[metadataKeyPrefix+edKeyID] = []byte("{")
+		defer delete(storeProvider.Store.Store, metadataKeyPrefix+edKeyID)
+
+		_, e := kmsService.Metadata(edKeyID)
+		require.Error(t, e)
+
+		_, e = kmsService.List()
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "list keys: ")
+	})
+}
+
+func TestLocalKMS_KeyMetadataStoreErrors(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    storeProvider,
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	keyID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	errStore := errors.New("store error")
+
+	t.Run("list iterator error", func(t *testing.T) {
+		storeProvider.Store.ErrItr = errStore
+		defer func() { storeProvider.Store.ErrItr = nil }()
+
+		_, e := kmsService.List()
+		require.EqualError(t, e, "list keys: store error")
+	})
+
+	t.Run("put metadata error", func(t *testing.T) {
+		storeProvider.Store.ErrPut = errStore
+		defer func() { storeProvider.Store.ErrPut = nil }()
+
+		e := kmsService.SetLabels(keyID, map[string]string{"k": "v"})
+		require.EqualError(t, e, "set key labels: store error")
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		storeProvider.Store.ErrDelete = errStore
+		defer func() { storeProvider.Store.ErrDelete = nil }()
+
+		e := kmsService.Delete(keyID)
+		require.EqualError(t, e, "delete key: store error")
+	})
+}
@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/primitive/secp256k1"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/rawkeyset"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/argon2"
+)
+
+const (
+	ecdsaPrivateKeyTypeURL   = "type.googleapis.com/google.crypto.tink.EcdsaPrivateKey"
+	ed25519PrivateKeyTypeURL = "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey"
+
+	// exportKeyURI is the key URI of the lock of exported keys, the lock ignores it
+	exportKeyURI = keywrapper.LocalKeyURIPrefix + "export"
+)
+
+// encryptedPrivateKey is the format of keys exported with ExportEncryptedPrivateKey: a Tink JSON keyset encrypted
+// with a key derived from the passphrase using Argon2id, along with the key type. The salt and the Argon2id
+// parameters are stored with the encrypted key of the keyset.
+type encryptedPrivateKey struct {
+	KeyType kms.KeyType     `json:"keyType,omitempty"`
+	Keyset  json.RawMessage `json:"keyset"`
+}
+
+// ImportPrivateKey stores privKey as a new key of type kt, wrapped with the master key like the keys created with
+// Create, and returns its keyID and key handle.
+// Supported private keys are ed25519.PrivateKey for ED25519Type and *ecdsa.PrivateKey for ECDSAP256Type,
+// ECDSAP384Type, ECDSAP521Type and ECDSASecp256k1Type.
+func (l *LocalKMS) ImportPrivateKey(privKey interface{}, kt kms.KeyType) (string, interface{}, error) {
+	kh, err := privateKeyToHandle(privKey, kt)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	kID, err := l.storeNewKey(kh, kt, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	return kID, kh, nil
+}
+
+// ExportEncryptedPrivateKey returns the keyset referenced by keyID encrypted with a key derived from passphrase
+// instead of the master key, to be imported in another KMS with ImportEncryptedPrivateKey
+func (l *LocalKMS) ExportEncryptedPrivateKey(keyID, passphrase string) ([]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("export private key: %w", err)
+	}
+
+	exportAEAD, err := passphraseAEAD(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("export private key: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = kh.Write(keyset.NewJSONWriter(buf), exportAEAD)
+	if err != nil {
+		return nil, fmt.Errorf("export private key: %w", err)
+	}
+
+	md, err := l.getMetadata(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("export private key: %w", err)
+	}
+
+	return json.Marshal(&encryptedPrivateKey{KeyType: md.KeyType, Keyset: buf.Bytes()})
+}
+
+// ImportEncryptedPrivateKey stores a keyset exported with ExportEncryptedPrivateKey as a new key and returns its
+// keyID and key handle
+func (l *LocalKMS) ImportEncryptedPrivateKey(encryptedKey []byte, passphrase string) (string, interface{}, error) {
+	var exported encryptedPrivateKey
+
+	err := json.Unmarshal(encryptedKey, &exported)
+	if err != nil {
+		return "", nil, fmt.Errorf("import encrypted private key: %w", err)
+	}
+
+	exportAEAD, err := passphraseAEAD(passphrase)
+	if err != nil {
+		return "", nil, fmt.Errorf("import encrypted private key: %w", err)
+	}
+
+	kh, err := keyset.Read(keyset.NewJSONReader(bytes.NewReader(exported.Keyset)), exportAEAD)
+	if err != nil {
+		return "", nil, fmt.Errorf("import encrypted private key: %w", err)
+	}
+
+	kID, err := l.storeNewKey(kh, exported.KeyType, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("import encrypted private key: %w", err)
+	}
+
+	return kID, kh, nil
+}
+
+// passphraseAEAD returns the AEAD encrypting exported keysets with an Argon2id lock of passphrase, a lock of the
+// same passphrase decrypts them whatever the salt and the parameters it was created with
+func passphraseAEAD(passphrase string) (*aead.KMSEnvelopeAEAD, error) {
+	lock, err := argon2.NewMasterLock(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	kw, err := keywrapper.New(lock, exportKeyURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.NewKMSEnvelopeAEAD(*aead.AES256GCMKeyTemplate(), kw), nil
+}
+
+func privateKeyToHandle(privKey interface{}, kt kms.KeyType) (*keyset.Handle, error) {
+	switch k := privKey.(type) {
+	case ed25519.PrivateKey:
+		if kt != kms.ED25519Type {
+			return nil, fmt.Errorf("invalid key type %s for an ed25519 private key", kt)
+		}
+
+		return ed25519KeyHandle(k)
+	case *ecdsa.PrivateKey:
+		if kt == kms.ECDSASecp256k1Type {
+			if k.Curve != btcec.S256() {
+				return nil, errors.New("private key is not a secp256k1 key")
+			}
+
+			return secp256k1.PrivateKeyHandle(k.D.Bytes())
+		}
+
+		return ecdsaKeyHandle(k, kt)
+	default:
+		return nil, fmt.Errorf("private key type not supported: %T", privKey)
+	}
+}
+
+func ed25519KeyHandle(privKey ed25519.PrivateKey) (*keyset.Handle, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+
+	pubKey, ok := privKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+
+	keyValue, err := proto.Marshal(&ed25519pb.Ed25519PrivateKey{
+		Version:   0,
+		KeyValue:  privKey.Seed(),
+		PublicKey: &ed25519pb.Ed25519PublicKey{Version: 0, KeyValue: pubKey},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rawkeyset.NewHandle(ed25519PrivateKeyTypeURL, keyValue, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+}
+
+func ecdsaKeyHandle(privKey *ecdsa.PrivateKey, kt kms.KeyType) (*keyset.Handle, error) {
+	var (
+		curveName string
+		curve     commonpb.EllipticCurveType
+		hashType  commonpb.HashType
+	)
+
+	// hash types match the ones of the Tink key templates used by Create
+	switch kt {
+	case kms.ECDSAP256Type:
+		curveName, curve, hashType = "P-256", commonpb.EllipticCurveType_NIST_P256, commonpb.HashType_SHA256
+	case kms.ECDSAP384Type:
+		curveName, curve, hashType = "P-384", commonpb.EllipticCurveType_NIST_P384, commonpb.HashType_SHA512
+	case kms.ECDSAP521Type:
+		curveName, curve, hashType = "P-521", commonpb.EllipticCurveType_NIST_P521, commonpb.HashType_SHA512
+	default:
+		return nil, fmt.Errorf("invalid key type %s for an ecdsa private key", kt)
+	}
+
+	if privKey.Curve.Params().Name != curveName {
+		return nil, fmt.Errorf("private key curve %s doesn't match key type %s", privKey.Curve.Params().Name, kt)
+	}
+
+	keyValue, err := proto.Marshal(&ecdsapb.EcdsaPrivateKey{
+		Version:  0,
+		KeyValue: privKey.D.Bytes(),
+		PublicKey: &ecdsapb.EcdsaPublicKey{
+			Version: 0,
+			Params: &ecdsapb.EcdsaParams{
+				Curve:    curve,
+				Encoding: ecdsapb.EcdsaSignatureEncoding_DER,
+				HashType: hashType,
+			},
+			X: privKey.X.Bytes(),
+			Y: privKey.Y.Bytes(),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rawkeyset.NewHandle(ecdsaPrivateKeyTypeURL, keyValue, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+func TestLocalKMS_ImportPrivateKey(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	edPubKey, edPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		keyType   kms.KeyType
+		privKey   interface{}
+		pubKeyRaw []byte
+	}{
+		{kms.ED25519Type, edPrivKey, edPubKey},
+		{kms.ECDSAP256Type, p256Key, elliptic.Marshal(p256Key.Curve, p256Key.X, p256Key.Y)},
+		{kms.ECDSAP384Type, p384Key, elliptic.Marshal(p384Key.Curve, p384Key.X, p384Key.Y)},
+		{kms.ECDSAP521Type, p521Key, elliptic.Marshal(p521Key.Curve, p521Key.X, p521Key.Y)},
+		{kms.ECDSASecp256k1Type, secp256k1Key, elliptic.Marshal(secp256k1Key.Curve, secp256k1Key.X, secp256k1Key.Y)},
+	}
+
+	c := &tinkcrypto.Crypto{}
+	msg := []byte("test message")
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(string(tc.keyType), func(t *testing.T) {
+			keyID, kh, e := kmsService.ImportPrivateKey(tc.privKey, tc.keyType)
+			require.NoError(t, e)
+			require.NotEmpty(t, keyID)
+
+			pubKeyBytes, e := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, e)
+			require.Equal(t, tc.pubKeyRaw, pubKeyBytes)
+
+			// the imported key signs and its public key verifies
+			sig, e := c.Sign(msg, kh)
+			require.NoError(t, e)
+
+			pubKH, e := kmsService.PubKeyBytesToHandle(pubKeyBytes, tc.keyType)
+			require.NoError(t, e)
+			require.NoError(t, c.Verify(sig, msg, pubKH))
+		})
+	}
+
+	t.Run("import errors", func(t *testing.T) {
+		_, _, err = kmsService.ImportPrivateKey(edPrivKey, kms.ECDSAP256Type)
+		require.EqualError(t, err, "import private key: invalid key type ECDSAP256 for an ed25519 private key")
+
+		_, _, err = kmsService.ImportPrivateKey(ed25519.PrivateKey("bad key"), kms.ED25519Type)
+		require.EqualError(t, err, "import private key: invalid ed25519 private key")
+
+		_, _, err = kmsService.ImportPrivateKey(p256Key, kms.ECDSAP384Type)
+		require.EqualError(t, err, "import private key: private key curve P-256 doesn't match key type ECDSAP384")
+
+		_, _, err = kmsService.ImportPrivateKey(p256Key, kms.ED25519Type)
+		require.EqualError(t, err, "import private key: invalid key type ED25519 for an ecdsa private key")
+
+		_, _, err = kmsService.ImportPrivateKey(p256Key, kms.ECDSASecp256k1Type)
+		require.EqualError(t, err, "import private key: private key is not a secp256k1 key")
+
+		_, _, err = kmsService.ImportPrivateKey("bad key", kms.ED25519Type)
+		require.EqualError(t, err, "import private key: private key type not supported: string")
+	})
+}
+
+func TestLocalKMS_ExportImportEncryptedPrivateKey(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	// another KMS with a different master key
+	otherKMS, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type, kms.ECDSASecp256k1Type, kms.BLS12381G2Type} {
+		keyID, _, e := kmsService.Create(kt)
+		require.NoError(t, e)
+
+		exported, e := kmsService.ExportEncryptedPrivateKey(keyID, "passphrase")
+		require.NoError(t, e)
+		// the Argon2id salt is kept with the encrypted keyset key, not next to it
+		require.NotContains(t, string(exported), "salt")
+
+		_, _, e = otherKMS.ImportEncryptedPrivateKey(exported, "wrong passphrase")
+		require.Error(t, e)
+
+		newKeyID, kh, e := otherKMS.ImportEncryptedPrivateKey(exported, "passphrase")
+		require.NoError(t, e)
+		require.NotNil(t, kh.(*keyset.Handle))
+
+		pubKeyBytes, e := kmsService.ExportPubKeyBytes(keyID)
+		require.NoError(t, e)
+
+		importedPubKeyBytes, e := otherKMS.ExportPubKeyBytes(newKeyID)
+		require.NoError(t, e)
+		require.Equal(t, pubKeyBytes, importedPubKeyBytes)
+	}
+
+	t.Run("export errors", func(t *testing.T) {
+		_, err = kmsService.ExportEncryptedPrivateKey("unknown", "passphrase")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export private key")
+
+		keyID, _, e := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, e)
+
+		_, err = kmsService.ExportEncryptedPrivateKey(keyID, "")
+		require.EqualError(t, err, "export private key: passphrase is empty")
+	})
+
+	t.Run("import errors", func(t *testing.T) {
+		_, _, err = otherKMS.ImportEncryptedPrivateKey([]byte("bad key"), "passphrase")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import encrypted private key")
+
+		_, _, err = otherKMS.ImportEncryptedPrivateKey([]byte("{}"), "")
+		require.EqualError(t, err, "import encrypted private key: passphrase is empty")
+	})
+}
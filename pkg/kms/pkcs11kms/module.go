@@ -0,0 +1,157 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+)
+
+// ErrKeyNotFound is returned when the token has no key object of a key ID
+var ErrKeyNotFound = errors.New("key not found")
+
+// Module is a PKCS#11 module logged in to a token, shared by the KMS and the crypto of the token's keys
+type Module struct {
+	ctx          *pkcs11.Ctx
+	slot         uint
+	loginSession pkcs11.SessionHandle
+}
+
+// NewModule loads the PKCS#11 library at libPath and logs in as user with pin to the token labeled tokenLabel.
+// The module must be closed with Close when it is no longer used.
+func NewModule(libPath, tokenLabel, pin string) (*Module, error) {
+	ctx := pkcs11.New(libPath)
+	if ctx == nil {
+		return nil, fmt.Errorf("new module: failed to load PKCS#11 library %s", libPath)
+	}
+
+	m := &Module{ctx: ctx}
+
+	if err := m.login(tokenLabel, pin); err != nil {
+		ctx.Destroy()
+
+		return nil, fmt.Errorf("new module: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Module) login(tokenLabel, pin string) error {
+	if err := m.ctx.Initialize(); err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+
+	slot, err := m.findSlot(tokenLabel)
+	if err != nil {
+		_ = m.ctx.Finalize()
+
+		return err
+	}
+
+	m.slot = slot
+
+	// the login applies to all the sessions of the token opened by the module, it lasts as long as this session
+	m.loginSession, err = m.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err == nil {
+		err = m.ctx.Login(m.loginSession, pkcs11.CKU_USER, pin)
+	}
+
+	if err != nil {
+		_ = m.ctx.CloseAllSessions(slot)
+		_ = m.ctx.Finalize()
+
+		return fmt.Errorf("login: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Module) findSlot(tokenLabel string) (uint, error) {
+	slots, err := m.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("get slot list: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, e := m.ctx.GetTokenInfo(slot)
+		if e != nil {
+			return 0, fmt.Errorf("get token info: %w", e)
+		}
+
+		if info.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("token %s not found", tokenLabel)
+}
+
+// Close logs out of the token and unloads the PKCS#11 library
+func (m *Module) Close() error {
+	if err := m.ctx.Logout(m.loginSession); err != nil {
+		return fmt.Errorf("close module: logout: %w", err)
+	}
+
+	if err := m.ctx.CloseAllSessions(m.slot); err != nil {
+		return fmt.Errorf("close module: close sessions: %w", err)
+	}
+
+	if err := m.ctx.Finalize(); err != nil {
+		return fmt.Errorf("close module: finalize: %w", err)
+	}
+
+	m.ctx.Destroy()
+
+	return nil
+}
+
+// WithSession calls fn with a new read-write session of the token, closed when fn returns. PKCS#11 sessions must
+// not be used concurrently, each operation runs in its own session.
+func (m *Module) WithSession(fn func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error) error {
+	session, err := m.ctx.OpenSession(m.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+
+	defer func() {
+		if e := m.ctx.CloseSession(session); e != nil {
+			logger.Warnf("failed to close PKCS#11 session: %s", e)
+		}
+	}()
+
+	return fn(m.ctx, session)
+}
+
+// FindKey returns the key object of class (pkcs11.CKO_PRIVATE_KEY or pkcs11.CKO_PUBLIC_KEY) with the CKA_ID id, or
+// ErrKeyNotFound if the token has none.
+func FindKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, id []byte) (pkcs11.ObjectHandle, error) {
+	err := ctx.FindObjectsInit(session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("find key: %w", err)
+	}
+
+	objects, _, err := ctx.FindObjects(session, 1)
+
+	if e := ctx.FindObjectsFinal(session); e != nil && err == nil {
+		err = e
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("find key: %w", err)
+	}
+
+	if len(objects) == 0 {
+		return 0, ErrKeyNotFound
+	}
+
+	return objects[0], nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package pkcs11kms implements a kms.KeyManager of keys generated and kept in a PKCS#11 token (HSM), to be used with
+// the crypto.Crypto of package pkg/crypto/pkcs11crypto. It supports ED25519Type and ECDSAP256Type keys, the private
+// keys never leave the token.
+//
+// The tests against a token are skipped unless PKCS11_LIBRARY, PKCS11_TOKEN_LABEL and PKCS11_PIN are set, e.g. with
+// SoftHSM:
+//
+//	softhsm2-util --init-token --free --label aries --pin 1234 --so-pin 1234
+//	PKCS11_LIBRARY=/usr/lib/softhsm/libsofthsm2.so PKCS11_TOKEN_LABEL=aries PKCS11_PIN=1234 go test ./pkg/...
+package pkcs11kms
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+var logger = log.New("aries-framework/kms/pkcs11kms")
+
+// Edwards curves constants of PKCS#11 v3.0, not defined by github.com/miekg/pkcs11
+const (
+	// CKKECEdwards is the CKK_EC_EDWARDS key type
+	CKKECEdwards = 0x00000040
+	// CKMECEdwardsKeyPairGen is the CKM_EC_EDWARDS_KEY_PAIR_GEN mechanism
+	CKMECEdwardsKeyPairGen = 0x00001055
+	// CKMEdDSA is the CKM_EDDSA mechanism
+	CKMEdDSA = 0x00001057
+)
+
+const keyIDSize = 16
+
+var (
+	// DER encoded curve OIDs of the CKA_EC_PARAMS attribute
+	ed25519Params = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+	p256Params    = []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+
+	errUnsupportedKeyType = errors.New("key type not supported")
+)
+
+// KeyHandle is the key handle of a key pair of the token returned by the KMS
+type KeyHandle struct {
+	// ID is the CKA_ID of the private and public key objects
+	ID      []byte
+	KeyType kms.KeyType
+}
+
+// KMS is a kms.KeyManager of the keys of a PKCS#11 token
+type KMS struct {
+	module *Module
+}
+
+// New creates a new KMS of the keys of the token of module
+func New(module *Module) *KMS {
+	return &KMS{module: module}
+}
+
+// Create generates a new key pair of type kt in the token and returns its key ID and *KeyHandle
+func (k *KMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	mechanism, keyType, params, err := keyPairGenParams(kt)
+	if err != nil {
+		return "", nil, fmt.Errorf("create: %w", err)
+	}
+
+	id := make([]byte, keyIDSize)
+
+	_, err = rand.Read(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("create: %w", err)
+	}
+
+	keyID := base64.RawURLEncoding.EncodeToString(id)
+
+	err = k.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		_, _, e := ctx.GenerateKeyPair(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+				pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+				pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params),
+				pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+			},
+			[]*pkcs11.Attribute{
+				pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+				pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+				pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+				pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+				pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+				pkcs11.NewAttribute(pkcs11.CKA_DERIVE, kt == kms.ECDSAP256Type),
+				pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+				pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+			})
+
+		return e
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("create: generate key pair: %w", err)
+	}
+
+	return keyID, &KeyHandle{ID: id, KeyType: kt}, nil
+}
+
+// Get returns the *KeyHandle of the key pair of keyID
+func (k *KMS) Get(keyID string) (interface{}, error) {
+	id, err := base64.RawURLEncoding.DecodeString(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get: invalid key ID: %w", err)
+	}
+
+	var kt kms.KeyType
+
+	err = k.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		pubKey, e := FindKey(ctx, session, pkcs11.CKO_PUBLIC_KEY, id)
+		if e != nil {
+			return e
+		}
+
+		attrs, e := ctx.GetAttributeValue(session, pubKey, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		})
+		if e != nil {
+			return e
+		}
+
+		kt, e = keyTypeFromParams(attrs[0].Value)
+
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return &KeyHandle{ID: id, KeyType: kt}, nil
+}
+
+// Rotate generates a new key pair of type kt replacing the key pair of keyID, which is destroyed
+func (k *KMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	id, err := base64.RawURLEncoding.DecodeString(keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("rotate: invalid key ID: %w", err)
+	}
+
+	newKeyID, kh, err := k.Create(kt)
+	if err != nil {
+		return "", nil, fmt.Errorf("rotate: %w", err)
+	}
+
+	err = k.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		for _, class := range []uint{pkcs11.CKO_PRIVATE_KEY, pkcs11.CKO_PUBLIC_KEY} {
+			obj, e := FindKey(ctx, session, class, id)
+			if e != nil {
+				return e
+			}
+
+			if e = ctx.DestroyObject(session, obj); e != nil {
+				return e
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("rotate: destroy key %s: %w", keyID, err)
+	}
+
+	return newKeyID, kh, nil
+}
+
+// ExportPubKeyBytes returns the public key of keyID, the raw key of an ED25519Type key and the uncompressed point
+// of an ECDSAP256Type key
+func (k *KMS) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	id, err := base64.RawURLEncoding.DecodeString(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: invalid key ID: %w", err)
+	}
+
+	var pubKey []byte
+
+	err = k.module.WithSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		var e error
+
+		pubKey, e = PublicKeyBytes(ctx, session, id)
+
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// PublicKeyBytes returns the public key of the key pair of CKA_ID id, the raw key of an Ed25519 key and the
+// uncompressed point of an ECDSA key
+func PublicKeyBytes(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, id []byte) ([]byte, error) {
+	pubKey, err := FindKey(ctx, session, pkcs11.CKO_PUBLIC_KEY, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := ctx.GetAttributeValue(session, pubKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	return ecPoint(attrs[0].Value), nil
+}
+
+// ecPoint returns the point of a CKA_EC_POINT value, a DER encoded OCTET STRING as per the specification although
+// some tokens return the raw point
+func ecPoint(value []byte) []byte {
+	var point []byte
+
+	rest, err := asn1.Unmarshal(value, &point)
+	if err != nil || len(rest) != 0 {
+		return value
+	}
+
+	return point
+}
+
+func keyPairGenParams(kt kms.KeyType) (uint, uint, []byte, error) {
+	switch kt {
+	case kms.ED25519Type:
+		return CKMECEdwardsKeyPairGen, CKKECEdwards, ed25519Params, nil
+	case kms.ECDSAP256Type:
+		return pkcs11.CKM_EC_KEY_PAIR_GEN, pkcs11.CKK_EC, p256Params, nil
+	default:
+		return 0, 0, nil, fmt.Errorf("%w: %s", errUnsupportedKeyType, kt)
+	}
+}
+
+func keyTypeFromParams(params []byte) (kms.KeyType, error) {
+	switch string(params) {
+	case string(ed25519Params):
+		return kms.ED25519Type, nil
+	case string(p256Params):
+		return kms.ECDSAP256Type, nil
+	default:
+		return "", fmt.Errorf("%w: curve parameters %x", errUnsupportedKeyType, params)
+	}
+}
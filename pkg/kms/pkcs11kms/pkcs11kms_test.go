@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+func TestNewModule(t *testing.T) {
+	_, err := NewModule("/invalid/libsofthsm2.so", "aries", "1234")
+	require.EqualError(t, err, "new module: failed to load PKCS#11 library /invalid/libsofthsm2.so")
+
+	module := newTestModule(t)
+
+	require.NoError(t, module.Close())
+
+	lib := os.Getenv("PKCS11_LIBRARY")
+
+	_, err = NewModule(lib, "unknown token", "1234")
+	require.EqualError(t, err, "new module: token unknown token not found")
+
+	_, err = NewModule(lib, os.Getenv("PKCS11_TOKEN_LABEL"), "invalid pin")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "new module: login")
+}
+
+func TestKMS(t *testing.T) {
+	module := newTestModule(t)
+
+	defer func() { require.NoError(t, module.Close()) }()
+
+	k := New(module)
+
+	t.Run("create, get and export ED25519 key", func(t *testing.T) {
+		keyID, kh, err := k.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519Type, kh.(*KeyHandle).KeyType)
+
+		got, err := k.Get(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kh, got)
+
+		pubKey, err := k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+		require.Len(t, pubKey, ed25519.PublicKeySize)
+	})
+
+	t.Run("create, get and export ECDSA P-256 key", func(t *testing.T) {
+		keyID, kh, err := k.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		got, err := k.Get(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kh, got)
+
+		pubKey, err := k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		x, _ := elliptic.Unmarshal(elliptic.P256(), pubKey)
+		require.NotNil(t, x)
+	})
+
+	t.Run("rotate key", func(t *testing.T) {
+		keyID, _, err := k.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		newKeyID, kh, err := k.Rotate(kms.ECDSAP256Type, keyID)
+		require.NoError(t, err)
+		require.NotEqual(t, keyID, newKeyID)
+		require.Equal(t, kms.ECDSAP256Type, kh.(*KeyHandle).KeyType)
+
+		_, err = k.Get(keyID)
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+
+		_, _, err = k.Rotate(kms.ECDSAP256Type, keyID)
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := k.Create(kms.AES256GCMType)
+		require.True(t, errors.Is(err, errUnsupportedKeyType))
+
+		_, err = k.Get("dW5rbm93bg")
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+
+		_, err = k.ExportPubKeyBytes("dW5rbm93bg")
+		require.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+}
+
+func TestKMS_InvalidKeyID(t *testing.T) {
+	k := New(nil)
+
+	_, err := k.Get("invalid+key+id")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "get: invalid key ID")
+
+	_, _, err = k.Rotate(kms.ED25519Type, "invalid+key+id")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rotate: invalid key ID")
+
+	_, err = k.ExportPubKeyBytes("invalid+key+id")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "export public key: invalid key ID")
+
+	_, _, err = k.Create(kms.BLS12381G2Type)
+	require.True(t, errors.Is(err, errUnsupportedKeyType))
+}
+
+func TestKeyTypeFromParams(t *testing.T) {
+	kt, err := keyTypeFromParams(ed25519Params)
+	require.NoError(t, err)
+	require.Equal(t, kms.ED25519Type, kt)
+
+	kt, err = keyTypeFromParams(p256Params)
+	require.NoError(t, err)
+	require.Equal(t, kms.ECDSAP256Type, kt)
+
+	_, err = keyTypeFromParams([]byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x22})
+	require.True(t, errors.Is(err, errUnsupportedKeyType))
+}
+
+func TestECPoint(t *testing.T) {
+	point := []byte{0x04, 0x01, 0x02, 0x03}
+
+	der, err := asn1.Marshal(point)
+	require.NoError(t, err)
+
+	require.Equal(t, point, ecPoint(der))
+	require.Equal(t, point, ecPoint(point))
+	require.Equal(t, append(der, 0x00), ecPoint(append(der, 0x00)))
+}
+
+// newTestModule returns a module of the token configured with the PKCS11_LIBRARY, PKCS11_TOKEN_LABEL and PKCS11_PIN
+// environment variables, skipping the test if they are not set
+func newTestModule(t *testing.T) *Module {
+	t.Helper()
+
+	lib := os.Getenv("PKCS11_LIBRARY")
+	if lib == "" {
+		t.Skip("PKCS11_LIBRARY is not set, skipping PKCS#11 token tests")
+	}
+
+	module, err := NewModule(lib, os.Getenv("PKCS11_TOKEN_LABEL"), os.Getenv("PKCS11_PIN"))
+	require.NoError(t, err)
+
+	return module
+}
@@ -0,0 +1,89 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// Paths of the remote KMS operations, relative to the keystore URL for KeysPath and WrapKeyPath and to the key URL
+// for the others.
+const (
+	// KeysPath is the path creating keys and, followed by a key ID, of the key URLs
+	KeysPath = "/keys"
+	// ExportPubKeyPath is the path exporting the public key of a key
+	ExportPubKeyPath = "/export"
+	// SignPath is the path signing a message with a key
+	SignPath = "/sign"
+	// VerifyPath is the path verifying a signature with a key
+	VerifyPath = "/verify"
+	// WrapKeyPath is the path wrapping a key with ECDH-ES, or ECDH-1PU with the sender key when relative to its key URL
+	WrapKeyPath = "/wrap"
+	// UnwrapKeyPath is the path unwrapping a key with a recipient key
+	UnwrapKeyPath = "/unwrap"
+)
+
+// CreateKeyRequest is the request to create a key
+type CreateKeyRequest struct {
+	KeyType kms.KeyType `json:"keyType"`
+}
+
+// CreateKeyResponse is the response of a created key, its URL is the keystore URL followed by KeysPath and KeyID
+type CreateKeyResponse struct {
+	KeyID string `json:"keyID"`
+}
+
+// ExportPubKeyResponse is the response of an exported public key
+type ExportPubKeyResponse struct {
+	PublicKey []byte `json:"publicKey"`
+}
+
+// SignRequest is the request to sign a message
+type SignRequest struct {
+	Message []byte `json:"message"`
+}
+
+// SignResponse is the response of a signed message
+type SignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// VerifyRequest is the request to verify the signature of a message
+type VerifyRequest struct {
+	Signature []byte `json:"signature"`
+	Message   []byte `json:"message"`
+}
+
+// WrapKeyRequest is the request to wrap a content encryption key for a recipient
+type WrapKeyRequest struct {
+	CEK                []byte            `json:"cek"`
+	APU                []byte            `json:"apu,omitempty"`
+	APV                []byte            `json:"apv,omitempty"`
+	RecipientPublicKey *crypto.PublicKey `json:"recipientPublicKey"`
+}
+
+// WrapKeyResponse is the response of a wrapped key
+type WrapKeyResponse struct {
+	WrappedKey *crypto.RecipientWrappedKey `json:"wrappedKey"`
+}
+
+// UnwrapKeyRequest is the request to unwrap a key, with the sender public key for keys wrapped with ECDH-1PU
+type UnwrapKeyRequest struct {
+	WrappedKey      *crypto.RecipientWrappedKey `json:"wrappedKey"`
+	SenderPublicKey *crypto.PublicKey           `json:"senderPublicKey,omitempty"`
+}
+
+// UnwrapKeyResponse is the response of an unwrapped key
+type UnwrapKeyResponse struct {
+	Key []byte `json:"key"`
+}
+
+// ErrorResponse is the response of a failed request
+type ErrorResponse struct {
+	Message string `json:"errMessage"`
+}
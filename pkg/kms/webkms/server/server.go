@@ -0,0 +1,256 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package server is a reference implementation of the remote KMS used by the webkms packages, serving a keystore
+// of a local kms.KeyManager and crypto.Crypto, typically localkms and tinkcrypto. It doesn't authenticate
+// requests and is meant to be run behind an authenticating proxy or for tests.
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/gorilla/mux"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/kms/webkms/server")
+
+const (
+	keyIDPathVar = "keyID"
+	keyPath      = webkms.KeysPath + "/{" + keyIDPathVar + "}"
+)
+
+// keyManager is the kms.KeyManager of the keys of the keystore, able to export their public keys
+type keyManager interface {
+	kms.KeyManager
+	ExportPubKeyBytes(keyID string) ([]byte, error)
+}
+
+// Server serves the keys of a key manager as a keystore of a remote KMS
+type Server struct {
+	kms    keyManager
+	crypto crypto.Crypto
+	router *mux.Router
+}
+
+// New returns a remote KMS server of the keys of km, executing their operations with c
+func New(km keyManager, c crypto.Crypto) *Server {
+	s := &Server{kms: km, crypto: c, router: mux.NewRouter()}
+
+	s.router.HandleFunc(webkms.KeysPath, s.createKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+webkms.ExportPubKeyPath, s.exportPubKey).Methods(http.MethodGet)
+	s.router.HandleFunc(keyPath+webkms.SignPath, s.sign).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+webkms.VerifyPath, s.verify).Methods(http.MethodPost)
+	s.router.HandleFunc(webkms.WrapKeyPath, s.wrapKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+webkms.WrapKeyPath, s.wrapKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+webkms.UnwrapKeyPath, s.unwrapKey).Methods(http.MethodPost)
+
+	return s
+}
+
+// ServeHTTP serves the remote KMS requests
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(rw, req)
+}
+
+func (s *Server) createKey(rw http.ResponseWriter, req *http.Request) {
+	var request webkms.CreateKeyRequest
+
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		writeError(rw, http.StatusBadRequest, fmt.Errorf("request decode: %w", err))
+		return
+	}
+
+	keyID, _, err := s.kms.Create(request.KeyType)
+	if err != nil {
+		writeError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	// key IDs of the local KMS contain the master key URI, they are encoded to be used in key URLs
+	writeResponse(rw, http.StatusCreated, &webkms.CreateKeyResponse{
+		KeyID: base64.RawURLEncoding.EncodeToString([]byte(keyID)),
+	})
+}
+
+func (s *Server) exportPubKey(rw http.ResponseWriter, req *http.Request) {
+	keyID, err := localKeyID(req)
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	pubKey, err := s.kms.ExportPubKeyBytes(keyID)
+	if err != nil {
+		writeError(rw, keyErrorStatus(err), err)
+		return
+	}
+
+	writeResponse(rw, http.StatusOK, &webkms.ExportPubKeyResponse{PublicKey: pubKey})
+}
+
+func (s *Server) sign(rw http.ResponseWriter, req *http.Request) {
+	var request webkms.SignRequest
+
+	kh, ok := s.decodeKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	sig, err := s.crypto.Sign(request.Message, kh)
+	if err != nil {
+		writeError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(rw, http.StatusOK, &webkms.SignResponse{Signature: sig})
+}
+
+func (s *Server) verify(rw http.ResponseWriter, req *http.Request) {
+	var request webkms.VerifyRequest
+
+	kh, ok := s.decodeKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	err := s.crypto.Verify(request.Signature, request.Message, publicKeyHandle(kh))
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// wrapKey wraps a key with ECDH-ES, or with ECDH-1PU when the sender key is given in the path
+func (s *Server) wrapKey(rw http.ResponseWriter, req *http.Request) {
+	var (
+		request webkms.WrapKeyRequest
+		opts    []crypto.WrapKeyOpts
+	)
+
+	if mux.Vars(req)[keyIDPathVar] != "" {
+		senderKH, ok := s.decodeKeyRequest(rw, req, &request)
+		if !ok {
+			return
+		}
+
+		opts = append(opts, crypto.WithSender(senderKH))
+	} else if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		writeError(rw, http.StatusBadRequest, fmt.Errorf("request decode: %w", err))
+		return
+	}
+
+	wk, err := s.crypto.WrapKey(request.CEK, request.APU, request.APV, request.RecipientPublicKey, opts...)
+	if err != nil {
+		writeError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(rw, http.StatusOK, &webkms.WrapKeyResponse{WrappedKey: wk})
+}
+
+func (s *Server) unwrapKey(rw http.ResponseWriter, req *http.Request) {
+	var request webkms.UnwrapKeyRequest
+
+	kh, ok := s.decodeKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	var opts []crypto.WrapKeyOpts
+
+	if request.SenderPublicKey != nil {
+		opts = append(opts, crypto.WithSender(request.SenderPublicKey))
+	}
+
+	key, err := s.crypto.UnwrapKey(request.WrappedKey, kh, opts...)
+	if err != nil {
+		writeError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(rw, http.StatusOK, &webkms.UnwrapKeyResponse{Key: key})
+}
+
+// decodeKeyRequest decodes the request body in request and returns the handle of the key of the request path,
+// writing the error response if it fails
+func (s *Server) decodeKeyRequest(rw http.ResponseWriter, req *http.Request, request interface{}) (interface{}, bool) {
+	keyID, err := localKeyID(req)
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	if err = json.NewDecoder(req.Body).Decode(request); err != nil {
+		writeError(rw, http.StatusBadRequest, fmt.Errorf("request decode: %w", err))
+		return nil, false
+	}
+
+	kh, err := s.kms.Get(keyID)
+	if err != nil {
+		writeError(rw, keyErrorStatus(err), err)
+		return nil, false
+	}
+
+	return kh, true
+}
+
+// publicKeyHandle returns the public key handle of a Tink private key handle, which Tink requires to verify
+// signatures. Other key handles are returned as is.
+func publicKeyHandle(kh interface{}) interface{} {
+	if keyHandle, ok := kh.(*keyset.Handle); ok {
+		if pubKH, err := keyHandle.Public(); err == nil {
+			return pubKH
+		}
+	}
+
+	return kh
+}
+
+// localKeyID returns the key ID in the local KMS of the key of the request path
+func localKeyID(req *http.Request) (string, error) {
+	keyID, err := base64.RawURLEncoding.DecodeString(mux.Vars(req)[keyIDPathVar])
+	if err != nil {
+		return "", fmt.Errorf("invalid key ID: %w", err)
+	}
+
+	return string(keyID), nil
+}
+
+func keyErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
+
+func writeResponse(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		logger.Errorf("Unable to send response: %v", err)
+	}
+}
+
+func writeError(rw http.ResponseWriter, status int, err error) {
+	logger.Debugf("remote kms request failed: %v", err)
+
+	writeResponse(rw, status, &webkms.ErrorResponse{Message: err.Error()})
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	webcrypto "github.com/hyperledger/aries-framework-go/pkg/crypto/webkms"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestRemoteKMS(t *testing.T) {
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(New(localKMS, &tinkcrypto.Crypto{}))
+	defer srv.Close()
+
+	remoteKMS, err := webkms.New(srv.URL)
+	require.NoError(t, err)
+
+	remoteCrypto, err := webcrypto.New(srv.URL)
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	for _, kt := range []kms.KeyType{kms.ED25519Type, kms.ECDSAP256Type, kms.ECDSASecp256k1Type} {
+		kt := kt
+
+		t.Run("sign and verify with "+string(kt), func(t *testing.T) {
+			keyID, kh, e := remoteKMS.Create(kt)
+			require.NoError(t, e)
+			require.Equal(t, webkms.KeyURL(srv.URL, keyID), kh)
+
+			sig, e := remoteCrypto.Sign(msg, kh)
+			require.NoError(t, e)
+
+			require.NoError(t, remoteCrypto.Verify(sig, msg, kh))
+
+			e = remoteCrypto.Verify(sig, []byte("other message"), kh)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), "verify msg: remote kms responded with status 400")
+
+			// the exported public key verifies the signature locally
+			pubKey, e := remoteKMS.ExportPubKeyBytes(keyID)
+			require.NoError(t, e)
+
+			pubKH, e := localKMS.PubKeyBytesToHandle(pubKey, kt)
+			require.NoError(t, e)
+			require.NoError(t, (&tinkcrypto.Crypto{}).Verify(sig, msg, pubKH))
+		})
+	}
+
+	t.Run("wrap and unwrap keys", func(t *testing.T) {
+		recKeyID, recKH, err := remoteKMS.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		senderKeyID, senderKH, err := remoteKMS.Create(kms.ECDSAP256Type)
+		require.NoError(t, err)
+
+		recPubKey := localPublicKey(t, localKMS, recKeyID)
+		senderPubKey := localPublicKey(t, localKMS, senderKeyID)
+
+		cek := []byte("0123456789abcdef0123456789abcdef")
+
+		wk, err := remoteCrypto.WrapKey(cek, []byte("apu"), []byte("apv"), recPubKey)
+		require.NoError(t, err)
+		require.Equal(t, crypto.ECDHESA256KWAlg, wk.Alg)
+
+		unwrapped, err := remoteCrypto.UnwrapKey(wk, recKH)
+		require.NoError(t, err)
+		require.Equal(t, cek, unwrapped)
+
+		wk, err = remoteCrypto.WrapKey(cek, []byte("apu"), []byte("apv"), recPubKey, crypto.WithSender(senderKH))
+		require.NoError(t, err)
+		require.Equal(t, crypto.ECDH1PUA256KWAlg, wk.Alg)
+
+		unwrapped, err = remoteCrypto.UnwrapKey(wk, recKH, crypto.WithSender(senderPubKey))
+		require.NoError(t, err)
+		require.Equal(t, cek, unwrapped)
+
+		_, err = remoteCrypto.UnwrapKey(wk, recKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sender public key is required")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := remoteKMS.Create("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "remote kms responded with status 500")
+
+		unknownKH, err := remoteKMS.Get("dW5rbm93bg")
+		require.NoError(t, err)
+
+		_, err = remoteCrypto.Sign(msg, unknownKH)
+		require.Error(t, err)
+
+		_, err = remoteKMS.ExportPubKeyBytes("dW5rbm93bg")
+		require.Error(t, err)
+
+		_, err = remoteKMS.ExportPubKeyBytes("invalid+key+id")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status 400")
+	})
+}
+
+func TestRemoteKMS_Framework(t *testing.T) {
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(New(localKMS, &tinkcrypto.Crypto{}))
+	defer srv.Close()
+
+	remoteCrypto, err := webcrypto.New(srv.URL)
+	require.NoError(t, err)
+
+	framework, err := aries.New(
+		aries.WithKMS(func(kms.Provider) (kms.KeyManager, error) {
+			return webkms.New(srv.URL)
+		}),
+		aries.WithCrypto(remoteCrypto))
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, framework.Close()) }()
+
+	ctx, err := framework.Context()
+	require.NoError(t, err)
+
+	_, kh, err := ctx.KMS().Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	sig, err := ctx.Crypto().Sign([]byte("test message"), kh)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Crypto().Verify(sig, []byte("test message"), kh))
+}
+
+func TestServer_InvalidRequests(t *testing.T) {
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	s := New(localKMS, &tinkcrypto.Crypto{})
+
+	keyPathPrefix := webkms.KeysPath + "/a2V5"
+
+	for _, path := range []string{
+		webkms.KeysPath,
+		webkms.WrapKeyPath,
+		keyPathPrefix + webkms.SignPath,
+		keyPathPrefix + webkms.VerifyPath,
+		keyPathPrefix + webkms.WrapKeyPath,
+		keyPathPrefix + webkms.UnwrapKeyPath,
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString("{"))
+		rr := httptest.NewRecorder()
+
+		s.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, path)
+		require.Contains(t, rr.Body.String(), "request decode", path)
+	}
+}
+
+func localPublicKey(t *testing.T, localKMS *localkms.LocalKMS, remoteKeyID string) *crypto.PublicKey {
+	t.Helper()
+
+	keyID, err := base64.RawURLEncoding.DecodeString(remoteKeyID)
+	require.NoError(t, err)
+
+	kh, err := localKMS.Get(string(keyID))
+	require.NoError(t, err)
+
+	pubKey, err := tinkcrypto.ExportPublicKey(kh)
+	require.NoError(t, err)
+
+	return pubKey
+}
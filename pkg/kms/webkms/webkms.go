@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package webkms implements a kms.KeyManager keeping its keys in a remote KMS reached over HTTP, so that private
+// keys never leave the key service. Key handles of the remote KMS are key URLs, to be used with the remote
+// crypto.Crypto of package pkg/crypto/webkms.
+package webkms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+var logger = log.New("aries-framework/kms/webkms")
+
+// RemoteKMS is a kms.KeyManager of a keystore of a remote KMS
+type RemoteKMS struct {
+	keystoreURL string
+	httpClient  *http.Client
+}
+
+// Option configures the remote KMS
+type Option func(opts *RemoteKMS)
+
+// WithHTTPClient option sets the HTTP client sending the requests to the remote KMS, to set its timeout and TLS
+// configuration
+func WithHTTPClient(client *http.Client) Option {
+	return func(opts *RemoteKMS) {
+		opts.httpClient = client
+	}
+}
+
+// New creates a new remote KMS of the keystore at keystoreURL
+func New(keystoreURL string, opts ...Option) (*RemoteKMS, error) {
+	_, err := url.ParseRequestURI(keystoreURL)
+	if err != nil {
+		return nil, fmt.Errorf("keystore URL invalid: %w", err)
+	}
+
+	r := &RemoteKMS{keystoreURL: strings.TrimSuffix(keystoreURL, "/"), httpClient: &http.Client{}}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Create a new key of type kt in the remote KMS and return its keyID and key URL
+func (r *RemoteKMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	var resp CreateKeyResponse
+
+	err := DoRequest(r.httpClient, http.MethodPost, r.keystoreURL+KeysPath, &CreateKeyRequest{KeyType: kt}, &resp)
+	if err != nil {
+		return "", nil, fmt.Errorf("create key: %w", err)
+	}
+
+	if resp.KeyID == "" {
+		return "", nil, errors.New("create key: empty key ID")
+	}
+
+	return resp.KeyID, KeyURL(r.keystoreURL, resp.KeyID), nil
+}
+
+// Get the key URL, which is the key handle of the remote KMS, of the given keyID
+func (r *RemoteKMS) Get(keyID string) (interface{}, error) {
+	if keyID == "" {
+		return nil, errors.New("get key: key ID is empty")
+	}
+
+	return KeyURL(r.keystoreURL, keyID), nil
+}
+
+// Rotate is not supported by the remote KMS
+func (r *RemoteKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	return "", nil, errors.New("rotate key: not supported by the remote kms")
+}
+
+// ExportPubKeyBytes returns the public key of the key referenced by keyID, in the format of
+// localkms.LocalKMS.ExportPubKeyBytes
+func (r *RemoteKMS) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	var resp ExportPubKeyResponse
+
+	err := DoRequest(r.httpClient, http.MethodGet, KeyURL(r.keystoreURL, keyID)+ExportPubKeyPath, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	return resp.PublicKey, nil
+}
+
+// KeyURL returns the URL of the key keyID of the keystore at keystoreURL
+func KeyURL(keystoreURL, keyID string) string {
+	return strings.TrimSuffix(keystoreURL, "/") + KeysPath + "/" + url.PathEscape(keyID)
+}
+
+// DoRequest sends request marshaled in JSON to reqURL, unless it is nil, and unmarshals the response in response,
+// unless it is nil. Responses with an error status are returned as errors.
+func DoRequest(client *http.Client, method, reqURL string, request, response interface{}) error {
+	var body io.Reader
+
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(reqBytes)
+	}
+
+	httpReq, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	defer closeResponseBody(resp.Body)
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		errResp := ErrorResponse{}
+		if json.Unmarshal(respBytes, &errResp) != nil || errResp.Message == "" {
+			errResp.Message = string(respBytes)
+		}
+
+		return fmt.Errorf("remote kms responded with status %d: %s", resp.StatusCode, errResp.Message)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(respBytes, response)
+	if err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func closeResponseBody(respBody io.Closer) {
+	e := respBody.Close()
+	if e != nil {
+		logger.Errorf("Failed to close response body: %v", e)
+	}
+}
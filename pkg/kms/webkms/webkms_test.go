@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("invalid url")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "keystore URL invalid")
+
+	client := &http.Client{Timeout: time.Second}
+
+	r, err := New("https://kms.example.com/keystores/123/", WithHTTPClient(client))
+	require.NoError(t, err)
+	require.Equal(t, "https://kms.example.com/keystores/123", r.keystoreURL)
+	require.Equal(t, client, r.httpClient)
+}
+
+func TestRemoteKMS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case KeysPath:
+			var request CreateKeyRequest
+
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+			require.Equal(t, kms.ED25519Type, request.KeyType)
+
+			rw.WriteHeader(http.StatusCreated)
+			require.NoError(t, json.NewEncoder(rw).Encode(&CreateKeyResponse{KeyID: "keyID"}))
+		case KeysPath + "/keyID" + ExportPubKeyPath:
+			require.NoError(t, json.NewEncoder(rw).Encode(&ExportPubKeyResponse{PublicKey: []byte("public key")}))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			require.NoError(t, json.NewEncoder(rw).Encode(&ErrorResponse{Message: "key not found"}))
+		}
+	}))
+	defer srv.Close()
+
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+
+	t.Run("create", func(t *testing.T) {
+		keyID, kh, e := r.Create(kms.ED25519Type)
+		require.NoError(t, e)
+		require.Equal(t, "keyID", keyID)
+		require.Equal(t, srv.URL+KeysPath+"/keyID", kh)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		kh, e := r.Get("keyID")
+		require.NoError(t, e)
+		require.Equal(t, srv.URL+KeysPath+"/keyID", kh)
+
+		_, e = r.Get("")
+		require.EqualError(t, e, "get key: key ID is empty")
+	})
+
+	t.Run("export public key", func(t *testing.T) {
+		pubKey, e := r.ExportPubKeyBytes("keyID")
+		require.NoError(t, e)
+		require.Equal(t, []byte("public key"), pubKey)
+
+		_, e = r.ExportPubKeyBytes("unknown")
+		require.EqualError(t, e, "export public key: remote kms responded with status 404: key not found")
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		_, _, e := r.Rotate(kms.ED25519Type, "keyID")
+		require.Error(t, e)
+	})
+}
+
+func TestRemoteKMS_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case KeysPath:
+			_, err := rw.Write([]byte("{}"))
+			require.NoError(t, err)
+		default:
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, err := rw.Write([]byte("server error"))
+			require.NoError(t, err)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+
+	_, _, err = r.Create(kms.ED25519Type)
+	require.EqualError(t, err, "create key: empty key ID")
+
+	_, err = r.ExportPubKeyBytes("keyID")
+	require.EqualError(t, err, "export public key: remote kms responded with status 500: server error")
+
+	err = DoRequest(&http.Client{}, http.MethodPost, srv.URL+KeysPath, make(chan int), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "marshal request")
+
+	err = DoRequest(&http.Client{}, http.MethodPost, srv.URL+KeysPath, nil, &[]string{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unmarshal response")
+
+	err = DoRequest(&http.Client{}, "bad method", srv.URL, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "create request")
+
+	srv.Close()
+
+	_, _, err = r.Create(kms.ED25519Type)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "send request")
+}
@@ -6,6 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package crypto
 
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+)
+
 // Crypto mock
 type Crypto struct {
 	EncryptValue      []byte
@@ -19,6 +23,16 @@ type Crypto struct {
 	ComputeMACValue   []byte
 	ComputeMACErr     error
 	VerifyMACErr      error
+	WrapValue         *crypto.RecipientWrappedKey
+	WrapError         error
+	UnwrapValue       []byte
+	UnwrapError       error
+	SignMultiValue    []byte
+	SignMultiErr      error
+	VerifyMultiErr    error
+	DeriveProofValue  []byte
+	DeriveProofErr    error
+	VerifyProofErr    error
 }
 
 // Encrypt returns mocked values and a mocked error
@@ -50,3 +64,36 @@ func (c *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
 func (c *Crypto) VerifyMAC(mac, data []byte, kh interface{}) error {
 	return c.VerifyMACErr
 }
+
+// WrapKey returns a mocked value and a mocked error
+func (c *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *crypto.PublicKey,
+	opts ...crypto.WrapKeyOpts) (*crypto.RecipientWrappedKey, error) {
+	return c.WrapValue, c.WrapError
+}
+
+// UnwrapKey returns a mocked value and a mocked error
+func (c *Crypto) UnwrapKey(recWK *crypto.RecipientWrappedKey, kh interface{},
+	opts ...crypto.WrapKeyOpts) ([]byte, error) {
+	return c.UnwrapValue, c.UnwrapError
+}
+
+// SignMulti returns a mocked value and a mocked error
+func (c *Crypto) SignMulti(messages [][]byte, kh interface{}) ([]byte, error) {
+	return c.SignMultiValue, c.SignMultiErr
+}
+
+// VerifyMulti returns a mocked value
+func (c *Crypto) VerifyMulti(messages [][]byte, signature []byte, kh interface{}) error {
+	return c.VerifyMultiErr
+}
+
+// DeriveProof returns a mocked value and a mocked error
+func (c *Crypto) DeriveProof(messages [][]byte, bbsSignature, nonce []byte, revealedIndexes []int,
+	kh interface{}) ([]byte, error) {
+	return c.DeriveProofValue, c.DeriveProofErr
+}
+
+// VerifyProof returns a mocked value
+func (c *Crypto) VerifyProof(revealedMessages [][]byte, proof, nonce []byte, kh interface{}) error {
+	return c.VerifyProofErr
+}
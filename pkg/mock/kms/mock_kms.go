@@ -29,6 +29,22 @@ type KeyManager struct {
 	RotateKeyID    string
 	RotateKeyValue *keyset.Handle
 	RotateKeyErr   error
+
+	ImportPrivateKeyID      string
+	ImportPrivateKeyValue   *keyset.Handle
+	ImportPrivateKeyErr     error
+	ExportEncryptedKeyValue []byte
+	ExportEncryptedKeyErr   error
+	ImportEncryptedKeyID    string
+	ImportEncryptedKeyValue *keyset.Handle
+	ImportEncryptedKeyErr   error
+
+	ListValue     []*kmsservice.KeyMetadata
+	ListErr       error
+	MetadataValue *kmsservice.KeyMetadata
+	MetadataErr   error
+	SetLabelsErr  error
+	DeleteErr     error
 }
 
 // Create a new mock ey/keyset/key handle for the type kt
@@ -58,6 +74,61 @@ func (k *KeyManager) Rotate(kt kmsservice.KeyType, keyID string) (string, interf
 	return k.RotateKeyID, k.RotateKeyValue, nil
 }
 
+// ImportPrivateKey returns a mocked imported key ID and handle
+func (k *KeyManager) ImportPrivateKey(privKey interface{}, kt kmsservice.KeyType) (string, interface{}, error) {
+	if k.ImportPrivateKeyErr != nil {
+		return "", nil, k.ImportPrivateKeyErr
+	}
+
+	return k.ImportPrivateKeyID, k.ImportPrivateKeyValue, nil
+}
+
+// ExportEncryptedPrivateKey returns a mocked encrypted key
+func (k *KeyManager) ExportEncryptedPrivateKey(keyID, passphrase string) ([]byte, error) {
+	if k.ExportEncryptedKeyErr != nil {
+		return nil, k.ExportEncryptedKeyErr
+	}
+
+	return k.ExportEncryptedKeyValue, nil
+}
+
+// ImportEncryptedPrivateKey returns a mocked imported key ID and handle
+func (k *KeyManager) ImportEncryptedPrivateKey(encryptedKey []byte, passphrase string) (string, interface{}, error) {
+	if k.ImportEncryptedKeyErr != nil {
+		return "", nil, k.ImportEncryptedKeyErr
+	}
+
+	return k.ImportEncryptedKeyID, k.ImportEncryptedKeyValue, nil
+}
+
+// List returns mocked key metadata
+func (k *KeyManager) List() ([]*kmsservice.KeyMetadata, error) {
+	if k.ListErr != nil {
+		return nil, k.ListErr
+	}
+
+	return k.ListValue, nil
+}
+
+// Metadata returns mocked key metadata for the given keyID
+func (k *KeyManager) Metadata(keyID string) (*kmsservice.KeyMetadata, error) {
+	if k.MetadataErr != nil {
+		return nil, k.MetadataErr
+	}
+
+	return k.MetadataValue, nil
+}
+
+// SetLabels mocks setting the labels of a key
+func (k *KeyManager) SetLabels(keyID string, labels map[string]string) error {
+	return k.SetLabelsErr
+}
+
+// Delete mocks deleting a key
+func (k *KeyManager) Delete(keyID string) error {
+	return k.DeleteErr
+}
+
 // CreateMockKeyHandle is a utility function that returns a mock key (for tests only. ie: not registered in Tink)
 func CreateMockKeyHandle() (*keyset.Handle, error) {
 	ks := testutil.NewTestAESGCMKeyset(tinkpb.OutputPrefixType_TINK)
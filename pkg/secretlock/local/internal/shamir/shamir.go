@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// maxShares is the maximum number of shares of a secret, the number of non zero x coordinates of GF(256)
+const maxShares = 255
+
+// Split splits secret into n shares, any threshold of which reconstruct secret with Combine.
+// Each share is the x coordinate of the share followed by the values of the random polynomials of degree threshold-1
+// of the bytes of secret (Shamir's secret sharing over GF(256)).
+// This function is to be used by secretlock/local package only
+func Split(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+
+	if threshold < 2 || threshold > n || n > maxShares {
+		return nil, fmt.Errorf("invalid number of shares %d with threshold %d: 2 <= threshold <= shares <= %d",
+			n, threshold, maxShares)
+	}
+
+	shares := make([][]byte, n)
+
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+
+	for i, b := range secret {
+		coefficients[0] = b
+
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to create polynomial: %w", err)
+		}
+
+		for _, share := range shares {
+			share[i+1] = evaluate(coefficients, share[0])
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine reconstructs the secret of shares created by Split, interpolating the polynomials at x = 0.
+// The result is only the secret if at least the threshold of the split shares are given.
+// This function is to be used by secretlock/local package only
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least 2 shares are required")
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errors.New("invalid share")
+	}
+
+	xs := make([]byte, len(shares))
+
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errors.New("shares have different sizes")
+		}
+
+		if share[0] == 0 {
+			return nil, errors.New("invalid share")
+		}
+
+		for _, x := range xs[:i] {
+			if x == share[0] {
+				return nil, errors.New("duplicate share")
+			}
+		}
+
+		xs[i] = share[0]
+	}
+
+	secret := make([]byte, size-1)
+
+	for i, share := range shares {
+		// Lagrange basis polynomial of share at x = 0, subtraction is addition (xor) in GF(256)
+		basis := byte(1)
+
+		for j, x := range xs {
+			if j != i {
+				basis = mul(basis, mul(x, inverse(x^xs[i])))
+			}
+		}
+
+		for k := range secret {
+			secret[k] ^= mul(share[k+1], basis)
+		}
+	}
+
+	return secret, nil
+}
+
+// evaluate returns the value of the polynomial of coefficients (lowest degree first) at x, with Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	var y byte
+
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coefficients[i]
+	}
+
+	return y
+}
+
+// mul multiplies a and b in GF(256) with the AES polynomial x^8 + x^4 + x^3 + x + 1, without secret dependent
+// branches or table lookups
+func mul(a, b byte) byte {
+	var p byte
+
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = (a << 1) ^ (-(a >> 7) & 0x1b) // nolint:gomnd
+		b >>= 1
+	}
+
+	return p
+}
+
+// inverse returns the multiplicative inverse of a non zero a in GF(256), a^254
+func inverse(a byte) byte {
+	b := mul(a, a) // a^2
+	c := mul(b, a) // a^3
+	b = mul(c, c)  // a^6
+	b = mul(b, b)  // a^12
+	c = mul(b, c)  // a^15
+	b = mul(b, b)  // a^24
+	b = mul(b, b)  // a^48
+	b = mul(b, c)  // a^63
+	b = mul(b, b)  // a^126
+	b = mul(a, b)  // a^127
+
+	return mul(b, b) // a^254
+}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package shamir
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := random.GetRandomBytes(32)
+
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	for _, share := range shares {
+		require.Len(t, share, len(secret)+1)
+	}
+
+	// any 3 shares or more reconstruct the secret
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3}, {0, 1, 2, 3, 4}} {
+		var selected [][]byte
+
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		combined, e := Combine(selected)
+		require.NoError(t, e)
+		require.Equal(t, secret, combined)
+	}
+
+	// 2 shares don't
+	combined, err := Combine(shares[:2])
+	require.NoError(t, err)
+	require.NotEqual(t, secret, combined)
+}
+
+func TestSplitErrors(t *testing.T) {
+	_, err := Split(nil, 3, 2)
+	require.EqualError(t, err, "secret is empty")
+
+	for _, params := range [][2]int{{3, 1}, {2, 3}, {256, 2}} {
+		_, err = Split([]byte("secret"), params[0], params[1])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid number of shares")
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	for _, tc := range []struct {
+		shares [][]byte
+		errMsg string
+	}{
+		{[][]byte{{1, 2}}, "at least 2 shares are required"},
+		{[][]byte{{1}, {2}}, "invalid share"},
+		{[][]byte{{1, 2}, {2, 3, 4}}, "shares have different sizes"},
+		{[][]byte{{1, 2}, {0, 3}}, "invalid share"},
+		{[][]byte{{1, 2}, {2, 3}, {1, 4}}, "duplicate share"},
+	} {
+		_, err := Combine(tc.shares)
+		require.EqualError(t, err, tc.errMsg)
+	}
+}
+
+func TestField(t *testing.T) {
+	// multiplications in the AES field (FIPS 197)
+	require.Equal(t, byte(0x05), mul(0x03, 0x03))
+	require.Equal(t, byte(0xc1), mul(0x57, 0x83))
+
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), mul(byte(a), inverse(byte(a))))
+	}
+}
@@ -22,7 +22,8 @@ import (
 // in a local file or an environment variable prior to using this service.
 //
 // The user has the option to encrypt the master key using hkdf.NewMasterLock(passphrase, hash func(), salt)
-// found in the sub package masterlock/hkdf.
+// found in the sub package masterlock/hkdf, or argon2.NewMasterLock(passphrase, opts...) found in the sub package
+// masterlock/argon2 when the passphrase is chosen by a human (eg a wallet PIN), as Argon2id resists brute-force attacks.
 //
 // The master key must be stored (encrypted with a MasterLock or not encrypted) either in a file or in
 // an environment variable.
@@ -43,6 +44,10 @@ import (
 // and secLock which is the masterKey lock used to encrypt/decrypt the master key. If secLock is nil
 // then the masterKey content in reader will be used as-is without being decrypted. The keys however are always
 // encrypted using the read masterKey.
+//
+// To recover the keys if the master key (or the passphrase of its MasterLock) is lost, the master key can be split
+// into recovery shares with Lock.MasterKeyShares(n, threshold). Any threshold of the shares then recreate the service
+// with NewServiceFromShares(shares), or the unprotected master key with MasterKeyFromShares(shares).
 
 var logger = log.New("aries-framework/lock")
 
@@ -51,6 +56,8 @@ const masterKeyLen = 512
 // Lock is a secret lock service responsible for encrypting keys using a master key
 type Lock struct {
 	aead cipher.AEAD
+	// masterKey is kept to split it into recovery shares with MasterKeyShares
+	masterKey []byte
 }
 
 // NewService creates a new instance of local secret lock service using a master key in masterKeyReader.
@@ -105,7 +112,7 @@ func NewService(masterKeyReader io.Reader, secLock secretlock.Service) (secretlo
 		return nil, err
 	}
 
-	return &Lock{aead: aead}, nil
+	return &Lock{aead: aead, masterKey: masterKey}, nil
 }
 
 // Encrypt a key in req using master key in the local secret lock service
@@ -10,14 +10,17 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/google/tink/go/subtle/random"
 	"github.com/stretchr/testify/require"
 
+	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
 )
@@ -382,3 +385,44 @@ func TestCreateServiceFromEnvWithMasterLock(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, someKey, []byte(someKeyDec.Plaintext))
 }
+
+func TestCreateMasterKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "masterkey")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	masterLocker, err := hkdf.NewMasterLock("secretPassphrase", sha256.New, nil)
+	require.NoError(t, err)
+
+	for name, secLock := range map[string]secretlock.Service{
+		"unprotected": nil,
+		"protected":   masterLocker,
+	} {
+		path := filepath.Join(dir, name)
+
+		require.NoError(t, CreateMasterKeyFile(path, secLock))
+
+		// the master key file is never overwritten
+		require.Error(t, CreateMasterKeyFile(path, secLock))
+
+		r, e := MasterKeyFromPath(path)
+		require.NoError(t, e)
+
+		s, e := NewService(r, secLock)
+		require.NoError(t, e)
+
+		enc, e := s.Encrypt("", &secretlock.EncryptRequest{Plaintext: "someKey"})
+		require.NoError(t, e)
+
+		dec, e := s.Decrypt("", &secretlock.DecryptRequest{Ciphertext: enc.Ciphertext})
+		require.NoError(t, e)
+		require.Equal(t, "someKey", dec.Plaintext)
+	}
+
+	err = CreateMasterKeyFile(filepath.Join(dir, "encrypt-error"),
+		&mocksecretlock.MockSecretLock{ErrEncrypt: errors.New("encrypt error")})
+	require.EqualError(t, err, "failed to encrypt master key: encrypt error")
+}
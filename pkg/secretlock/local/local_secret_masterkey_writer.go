@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package local
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/google/tink/go/subtle/random"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+)
+
+// masterKeySize is the size of the master keys created by CreateMasterKeyFile, an AES-256 key
+const masterKeySize = 32
+
+// CreateMasterKeyFile creates a new random master key and stores it in a new file at `path`, to be read with
+// MasterKeyFromPath(path). If secLock is not nil, the master key is encrypted with it (eg a masterlock/hkdf lock),
+// otherwise it is stored base64URL encoded. It fails if the file already exists.
+func CreateMasterKeyFile(path string, secLock secretlock.Service) error {
+	masterKey := random.GetRandomBytes(masterKeySize)
+
+	content := base64.URLEncoding.EncodeToString(masterKey)
+
+	if secLock != nil {
+		encResponse, err := secLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(masterKey)})
+		if err != nil {
+			return fmt.Errorf("failed to encrypt master key: %w", err)
+		}
+
+		content = encResponse.Ciphertext
+	}
+
+	masterKeyFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // nolint:gomnd
+	if err != nil {
+		return err
+	}
+
+	_, err = masterKeyFile.WriteString(content)
+	if err != nil {
+		if e := masterKeyFile.Close(); e != nil {
+			logger.Warnf("failed to close file: %s", e)
+		}
+
+		return err
+	}
+
+	return masterKeyFile.Close()
+}
@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package local
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/internal/shamir"
+)
+
+const (
+	// shareVersion1 is the version of the format of the recovery shares:
+	// version, threshold, checksum of the master key and Shamir share (x coordinate followed by the y values)
+	shareVersion1 = 1
+
+	shareChecksumSize   = 8
+	shareChecksumOffset = 2
+	shareDataOffset     = shareChecksumOffset + shareChecksumSize
+)
+
+// MasterKeyShares splits the master key of the lock into n recovery shares, base64URL encoded, any threshold of which
+// recreate the lock with NewServiceFromShares (Shamir's secret sharing over GF(256)). Fewer shares than threshold
+// reveal nothing about the master key, the shares must nonetheless be distributed to different custodians.
+func (s *Lock) MasterKeyShares(n, threshold int) ([]string, error) {
+	shares, err := shamir.Split(s.masterKey, n, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split master key: %w", err)
+	}
+
+	checksum := masterKeyChecksum(s.masterKey)
+	encodedShares := make([]string, len(shares))
+
+	for i, share := range shares {
+		data := make([]byte, 0, shareDataOffset+len(share))
+		data = append(data, shareVersion1, byte(threshold))
+		data = append(data, checksum...)
+		data = append(data, share...)
+
+		encodedShares[i] = base64.URLEncoding.EncodeToString(data)
+	}
+
+	return encodedShares, nil
+}
+
+// MasterKeyFromShares recreates the master key split by Lock.MasterKeyShares from at least threshold of its shares.
+// It returns an io.Reader of the unprotected master key, base64URL encoded, to be stored (eg with a new MasterLock) or
+// to be passed to NewService(reader, nil).
+func MasterKeyFromShares(shares []string) (io.Reader, error) {
+	masterKey, err := combineShares(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(masterKey))), nil
+}
+
+// NewServiceFromShares creates a new instance of local secret lock service using the master key recreated from
+// at least threshold of the shares created by Lock.MasterKeyShares.
+func NewServiceFromShares(shares []string) (secretlock.Service, error) {
+	r, err := MasterKeyFromShares(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewService(r, nil)
+}
+
+func combineShares(encodedShares []string) ([]byte, error) {
+	if len(encodedShares) == 0 {
+		return nil, errors.New("no master key shares")
+	}
+
+	var header []byte
+
+	shares := make([][]byte, len(encodedShares))
+
+	for i, encodedShare := range encodedShares {
+		data, err := decodeShare(encodedShare)
+		if err != nil {
+			return nil, err
+		}
+
+		if header == nil {
+			header = data[:shareDataOffset]
+		} else if !bytes.Equal(header, data[:shareDataOffset]) {
+			return nil, errors.New("master key shares are not shares of the same master key")
+		}
+
+		shares[i] = data[shareDataOffset:]
+	}
+
+	if threshold := int(header[1]); len(shares) < threshold {
+		return nil, fmt.Errorf("%d master key shares are required, got %d", threshold, len(shares))
+	}
+
+	masterKey, err := shamir.Combine(shares)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine master key shares: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare(masterKeyChecksum(masterKey), header[shareChecksumOffset:]) != 1 {
+		return nil, errors.New("invalid master key shares: checksum mismatch")
+	}
+
+	return masterKey, nil
+}
+
+func decodeShare(encodedShare string) ([]byte, error) {
+	data, err := base64.URLEncoding.DecodeString(encodedShare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode master key share: %w", err)
+	}
+
+	if len(data) <= shareDataOffset {
+		return nil, errors.New("invalid master key share")
+	}
+
+	if data[0] != shareVersion1 {
+		return nil, fmt.Errorf("unsupported master key share version %d", data[0])
+	}
+
+	return data, nil
+}
+
+// masterKeyChecksum identifies the master key of shares and verifies the recreated master key
+func masterKeyChecksum(masterKey []byte) []byte {
+	sum := sha256.Sum256(masterKey)
+
+	return sum[:shareChecksumSize]
+}
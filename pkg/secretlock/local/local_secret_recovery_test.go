@@ -0,0 +1,105 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package local
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+)
+
+func TestMasterKeyShares(t *testing.T) {
+	masterKey := random.GetRandomBytes(masterKeySize)
+
+	s, err := NewService(bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(masterKey))), nil)
+	require.NoError(t, err)
+
+	enc, err := s.Encrypt("", &secretlock.EncryptRequest{Plaintext: "someKey"})
+	require.NoError(t, err)
+
+	shares, err := s.(*Lock).MasterKeyShares(5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	t.Run("recover secret lock from threshold shares", func(t *testing.T) {
+		recovered, e := NewServiceFromShares([]string{shares[4], shares[0], shares[2]})
+		require.NoError(t, e)
+
+		dec, e := recovered.Decrypt("", &secretlock.DecryptRequest{Ciphertext: enc.Ciphertext})
+		require.NoError(t, e)
+		require.Equal(t, "someKey", dec.Plaintext)
+	})
+
+	t.Run("recover master key from all shares", func(t *testing.T) {
+		r, e := MasterKeyFromShares(shares)
+		require.NoError(t, e)
+
+		content, e := ioutil.ReadAll(r)
+		require.NoError(t, e)
+		require.Equal(t, base64.URLEncoding.EncodeToString(masterKey), string(content))
+	})
+
+	t.Run("not enough shares", func(t *testing.T) {
+		_, e := NewServiceFromShares(shares[:2])
+		require.EqualError(t, e, "3 master key shares are required, got 2")
+	})
+
+	t.Run("shares of different master keys", func(t *testing.T) {
+		other, e := NewService(bytes.NewReader(random.GetRandomBytes(masterKeySize)), nil)
+		require.NoError(t, e)
+
+		otherShares, e := other.(*Lock).MasterKeyShares(3, 3)
+		require.NoError(t, e)
+
+		_, e = NewServiceFromShares([]string{shares[0], shares[1], otherShares[2]})
+		require.EqualError(t, e, "master key shares are not shares of the same master key")
+	})
+
+	t.Run("invalid split parameters", func(t *testing.T) {
+		_, e := s.(*Lock).MasterKeyShares(2, 3)
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "failed to split master key")
+	})
+}
+
+func TestMasterKeyFromShares_Errors(t *testing.T) {
+	s, err := NewService(bytes.NewReader(random.GetRandomBytes(masterKeySize)), nil)
+	require.NoError(t, err)
+
+	shares, err := s.(*Lock).MasterKeyShares(3, 2)
+	require.NoError(t, err)
+
+	data, err := base64.URLEncoding.DecodeString(shares[1])
+	require.NoError(t, err)
+
+	data[len(data)-1]++
+	tampered := base64.URLEncoding.EncodeToString(data)
+
+	data[0] = 2
+	badVersion := base64.URLEncoding.EncodeToString(data)
+
+	for _, tc := range []struct {
+		shares []string
+		errMsg string
+	}{
+		{nil, "no master key shares"},
+		{[]string{"bad{}base64URLstring[]"}, "failed to decode master key share"},
+		{[]string{base64.URLEncoding.EncodeToString([]byte("short"))}, "invalid master key share"},
+		{[]string{shares[0], badVersion}, "unsupported master key share version 2"},
+		{[]string{shares[0], shares[0]}, "failed to combine master key shares: duplicate share"},
+		{[]string{shares[0], tampered}, "invalid master key shares: checksum mismatch"},
+	} {
+		_, err = MasterKeyFromShares(tc.shares)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.errMsg)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package argon2
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/google/tink/go/subtle/random"
+	"golang.org/x/crypto/argon2"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	cipherutil "github.com/hyperledger/aries-framework-go/pkg/secretlock/local/internal/cipher"
+)
+
+const (
+	// version of the format of the ciphertexts, prefixed with their key derivation parameters and salt
+	version1 = 1
+
+	saltSize = 16
+	keySize  = 32
+
+	// offsets of the ciphertext prefix: version, time, memory, threads and salt
+	timeOffset    = 1
+	memoryOffset  = timeOffset + 4
+	threadsOffset = memoryOffset + 4
+	saltOffset    = threadsOffset + 1
+	headerSize    = saltOffset + saltSize
+
+	// minMemoryPerThread is the minimum memory (in KiB) per thread of Argon2
+	minMemoryPerThread = 8
+
+	// maxMemory bounds the memory (in KiB) of the parameters read from a ciphertext, 256 MiB, 4 times the memory of
+	// DefaultParams, so that a forged header can't make a decryption allocate gigabytes
+	maxMemory = 256 * 1024
+
+	// maxTime bounds the passes of the parameters read from a ciphertext, well above the recommended parameters
+	// (RFC 9106 and OWASP use at most 5 passes) so that a forged header can't make a decryption run for hours
+	maxTime = 64
+)
+
+// Params are the Argon2id parameters deriving the key of a master lock from its passphrase.
+type Params struct {
+	// Time is the number of passes over the memory
+	Time uint32
+	// Memory is the size of the memory in KiB
+	Memory uint32
+	// Threads is the degree of parallelism
+	Threads uint8
+}
+
+// DefaultParams returns the default Argon2id parameters, the second recommended option of RFC 9106 (64 MiB of memory).
+// Devices with less memory, eg browsers, may use a lower memory with a higher time.
+func DefaultParams() Params {
+	return Params{Time: 3, Memory: 64 * 1024, Threads: 4} // nolint:gomnd
+}
+
+// Option configures the master lock.
+type Option func(opts *masterLock)
+
+// WithParams sets the Argon2id parameters of the keys deriving the master keys encrypted by the master lock.
+func WithParams(params Params) Option {
+	return func(opts *masterLock) {
+		opts.params = params
+	}
+}
+
+type masterLock struct {
+	passphrase string
+	params     Params
+	// header prefixes the ciphertexts encrypted by the lock, with the parameters and the salt of aead
+	header []byte
+	aead   cipher.AEAD
+	// aeads caches the ciphers of the headers of decrypted ciphertexts
+	aeads map[string]cipher.AEAD
+	mutex sync.Mutex
+}
+
+// NewMasterLock is responsible for encrypting/decrypting a master key with a key derived from `passphrase` using
+// Argon2id, a memory-hard function making brute-force attacks of human-chosen passphrases (eg PINs) costly.
+// A random salt is created for the lock, the salt and the Argon2id parameters (DefaultParams() unless set with
+// WithParams) prefix the encrypted master keys, with a format version, so that they can be decrypted by a lock of the
+// same passphrase only, regardless of its parameters.
+// Like masterlock/hkdf, this implementation must not be used directly in Aries framework. It should be passed in
+// as the second argument to local secret lock service constructor:
+// `local.NewService(masterKeyReader io.Reader, secLock secretlock.Service)`
+func NewMasterLock(passphrase string, opts ...Option) (secretlock.Service, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+
+	m := &masterLock{
+		passphrase: passphrase,
+		params:     DefaultParams(),
+		aeads:      map[string]cipher.AEAD{},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if err := validateParams(m.params); err != nil {
+		return nil, err
+	}
+
+	m.header = newHeader(m.params, random.GetRandomBytes(saltSize))
+
+	aead, err := m.deriveAEAD(m.params, m.header[saltOffset:])
+	if err != nil {
+		return nil, err
+	}
+
+	m.aead = aead
+
+	return m, nil
+}
+
+// Encrypt a master key in req
+// (keyURI is used for remote locks, it is ignored by this implementation)
+func (m *masterLock) Encrypt(keyURI string, req *secretlock.EncryptRequest) (*secretlock.EncryptResponse, error) {
+	nonce := random.GetRandomBytes(uint32(m.aead.NonceSize()))
+	aad := append(append([]byte{}, m.header...), req.AdditionalAuthenticatedData...)
+
+	ct := make([]byte, 0, len(m.header)+len(nonce)+len(req.Plaintext)+m.aead.Overhead())
+	ct = append(ct, m.header...)
+	ct = append(ct, nonce...)
+	ct = m.aead.Seal(ct, nonce, []byte(req.Plaintext), aad)
+
+	return &secretlock.EncryptResponse{
+		Ciphertext: base64.URLEncoding.EncodeToString(ct),
+	}, nil
+}
+
+// Decrypt a master key in req, with a key derived with the parameters and the salt of its ciphertext
+// (keyURI is used for remote locks, it is ignored by this implementation)
+func (m *masterLock) Decrypt(keyURI string, req *secretlock.DecryptRequest) (*secretlock.DecryptResponse, error) {
+	ct, err := base64.URLEncoding.DecodeString(req.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ct) < headerSize {
+		return nil, fmt.Errorf("invalid request")
+	}
+
+	header := ct[:headerSize]
+
+	aead, err := m.headerAEAD(header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := aead.NonceSize()
+	ct = ct[headerSize:]
+
+	// ensure ciphertext contains more than nonce+ciphertext (result from Encrypt())
+	if len(ct) <= nonceSize {
+		return nil, fmt.Errorf("invalid request")
+	}
+
+	aad := append(append([]byte{}, header...), req.AdditionalAuthenticatedData...)
+
+	pt, err := aead.Open(nil, ct[:nonceSize], ct[nonceSize:], aad)
+	if err != nil {
+		return nil, err
+	}
+
+	m.cacheAEAD(header, aead)
+
+	return &secretlock.DecryptResponse{Plaintext: string(pt)}, nil
+}
+
+// headerAEAD returns the cipher of the parameters and the salt of a ciphertext header
+func (m *masterLock) headerAEAD(header []byte) (cipher.AEAD, error) {
+	if string(header) == string(m.header) {
+		return m.aead, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if aead, ok := m.aeads[string(header)]; ok {
+		return aead, nil
+	}
+
+	if header[0] != version1 {
+		return nil, fmt.Errorf("unsupported version %d", header[0])
+	}
+
+	params := Params{
+		Time:    binary.BigEndian.Uint32(header[timeOffset:memoryOffset]),
+		Memory:  binary.BigEndian.Uint32(header[memoryOffset:threadsOffset]),
+		Threads: header[threadsOffset],
+	}
+
+	if err := validateParams(params); err != nil {
+		return nil, err
+	}
+
+	return m.deriveAEAD(params, header[saltOffset:])
+}
+
+// cacheAEAD caches the cipher of a ciphertext header once it decrypted the ciphertext, so that forged headers aren't
+// cached
+func (m *masterLock) cacheAEAD(header []byte, aead cipher.AEAD) {
+	if string(header) == string(m.header) {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.aeads[string(header)] = aead
+}
+
+func (m *masterLock) deriveAEAD(params Params, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(m.passphrase), salt, params.Time, params.Memory, params.Threads, keySize)
+
+	return cipherutil.CreateAESCipher(key)
+}
+
+func newHeader(params Params, salt []byte) []byte {
+	header := make([]byte, headerSize)
+
+	header[0] = version1
+	binary.BigEndian.PutUint32(header[timeOffset:memoryOffset], params.Time)
+	binary.BigEndian.PutUint32(header[memoryOffset:threadsOffset], params.Memory)
+	header[threadsOffset] = params.Threads
+	copy(header[saltOffset:], salt)
+
+	return header
+}
+
+func validateParams(params Params) error {
+	if params.Time < 1 || params.Threads < 1 {
+		return fmt.Errorf("invalid argon2 parameters: time and threads must be positive")
+	}
+
+	if params.Time > maxTime {
+		return fmt.Errorf("invalid argon2 parameters: time must be at most %d", maxTime)
+	}
+
+	minMemory := minMemoryPerThread * uint32(params.Threads)
+
+	if params.Memory < minMemory || params.Memory > maxMemory {
+		return fmt.Errorf("invalid argon2 parameters: memory must be between %d and %d KiB", minMemory, maxMemory)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package argon2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+)
+
+// testParams are cheap parameters for the tests
+var testParams = Params{Time: 1, Memory: 64, Threads: 1}
+
+func TestMasterLock(t *testing.T) {
+	testKey := random.GetRandomBytes(uint32(keySize))
+	goodPassphrase := "1234"
+
+	mkLock, err := NewMasterLock(goodPassphrase, WithParams(testParams))
+	require.NoError(t, err)
+
+	encryptedMk, err := mkLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(testKey)})
+	require.NoError(t, err)
+	require.NotEmpty(t, encryptedMk)
+
+	decryptedMk, err := mkLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encryptedMk.Ciphertext})
+	require.NoError(t, err)
+	require.Equal(t, testKey, []byte(decryptedMk.Plaintext))
+
+	// a new lock instance with the same passphrase decrypts with the parameters and salt of the ciphertext
+	mkLock2, err := NewMasterLock(goodPassphrase, WithParams(Params{Time: 2, Memory: 128, Threads: 2}))
+	require.NoError(t, err)
+
+	decryptedMk2, err := mkLock2.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encryptedMk.Ciphertext})
+	require.NoError(t, err)
+	require.Equal(t, testKey, []byte(decryptedMk2.Plaintext))
+
+	// the ciphers of the ciphertexts of other locks are cached
+	require.Len(t, mkLock2.(*masterLock).aeads, 1)
+
+	decryptedMk2, err = mkLock2.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encryptedMk.Ciphertext})
+	require.NoError(t, err)
+	require.Equal(t, testKey, []byte(decryptedMk2.Plaintext))
+
+	// each lock has its own salt
+	encryptedMk2, err := mkLock2.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(testKey)})
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertextHeader(t, encryptedMk.Ciphertext), ciphertextHeader(t, encryptedMk2.Ciphertext))
+
+	// try with a bad passphrase
+	mkLock2, err = NewMasterLock("4321", WithParams(testParams))
+	require.NoError(t, err)
+
+	decryptedMk2, err = mkLock2.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encryptedMk.Ciphertext})
+	require.Error(t, err)
+	require.Empty(t, decryptedMk2)
+
+	// the cipher of a ciphertext which isn't decrypted isn't cached
+	require.Empty(t, mkLock2.(*masterLock).aeads)
+
+	t.Run("additional authenticated data", func(t *testing.T) {
+		enc, e := mkLock.Encrypt("", &secretlock.EncryptRequest{
+			Plaintext:                   string(testKey),
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, e)
+
+		dec, e := mkLock.Decrypt("", &secretlock.DecryptRequest{
+			Ciphertext:                  enc.Ciphertext,
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, e)
+		require.Equal(t, testKey, []byte(dec.Plaintext))
+
+		_, e = mkLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: enc.Ciphertext})
+		require.Error(t, e)
+	})
+
+	t.Run("tampered parameters", func(t *testing.T) {
+		ct, e := base64.URLEncoding.DecodeString(encryptedMk.Ciphertext)
+		require.NoError(t, e)
+
+		ct[timeOffset+3]++
+
+		_, e = mkLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: base64.URLEncoding.EncodeToString(ct)})
+		require.Error(t, e)
+	})
+}
+
+func TestMasterLock_Errors(t *testing.T) {
+	_, err := NewMasterLock("")
+	require.EqualError(t, err, "passphrase is empty")
+
+	_, err = NewMasterLock("1234", WithParams(Params{Time: 0, Memory: 64, Threads: 1}))
+	require.EqualError(t, err, "invalid argon2 parameters: time and threads must be positive")
+
+	_, err = NewMasterLock("1234", WithParams(Params{Time: 1, Memory: 64, Threads: 0}))
+	require.EqualError(t, err, "invalid argon2 parameters: time and threads must be positive")
+
+	_, err = NewMasterLock("1234", WithParams(Params{Time: maxTime + 1, Memory: 64, Threads: 1}))
+	require.EqualError(t, err, "invalid argon2 parameters: time must be at most 64")
+
+	_, err = NewMasterLock("1234", WithParams(Params{Time: 1, Memory: 8, Threads: 2}))
+	require.EqualError(t, err, "invalid argon2 parameters: memory must be between 16 and 262144 KiB")
+
+	mkLock, err := NewMasterLock("1234", WithParams(testParams))
+	require.NoError(t, err)
+
+	badParamsHeader := newHeader(Params{Time: 1, Memory: 1, Threads: 1}, make([]byte, saltSize))
+	badTimeHeader := newHeader(Params{Time: 1 << 31, Memory: 64, Threads: 1}, make([]byte, saltSize))
+	badMemoryHeader := newHeader(Params{Time: 1, Memory: maxMemory + 1, Threads: 1}, make([]byte, saltSize))
+
+	for ct, errMsg := range map[string]string{
+		"bad{}base64URLstring[]":                                  "illegal base64 data",
+		encode([]byte("short")):                                   "invalid request",
+		encode(newHeader(testParams, make([]byte, saltSize))):     "invalid request",
+		encode(append([]byte{2}, make([]byte, headerSize+20)...)): "unsupported version 2",
+		encode(append(badParamsHeader, make([]byte, 20)...)):      "invalid argon2 parameters",
+		encode(append(badTimeHeader, make([]byte, 20)...)):        "invalid argon2 parameters: time must be at most",
+		encode(append(badMemoryHeader, make([]byte, 20)...)):      "invalid argon2 parameters: memory must be between",
+	} {
+		_, err = mkLock.Decrypt("", &secretlock.DecryptRequest{Ciphertext: ct})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errMsg)
+	}
+}
+
+func TestMasterLock_LocalSecretLock(t *testing.T) {
+	masterKey := random.GetRandomBytes(uint32(keySize))
+
+	mkLock, err := NewMasterLock("1234", WithParams(testParams))
+	require.NoError(t, err)
+
+	encryptedMk, err := mkLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(masterKey)})
+	require.NoError(t, err)
+
+	// the master key is unlocked by a new master lock of the passphrase, eg when the wallet is opened again
+	mkLock, err = NewMasterLock("1234")
+	require.NoError(t, err)
+
+	s, err := local.NewService(bytes.NewReader([]byte(encryptedMk.Ciphertext)), mkLock)
+	require.NoError(t, err)
+
+	enc, err := s.Encrypt("", &secretlock.EncryptRequest{Plaintext: "someKey"})
+	require.NoError(t, err)
+
+	dec, err := s.Decrypt("", &secretlock.DecryptRequest{Ciphertext: enc.Ciphertext})
+	require.NoError(t, err)
+	require.Equal(t, "someKey", dec.Plaintext)
+}
+
+func ciphertextHeader(t *testing.T, ciphertext string) []byte {
+	t.Helper()
+
+	ct, err := base64.URLEncoding.DecodeString(ciphertext)
+	require.NoError(t, err)
+
+	return ct[:headerSize]
+}
+
+func encode(ct []byte) string {
+	return base64.URLEncoding.EncodeToString(ct)
+}
@@ -0,0 +1,378 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	blankPathErrMsg = "path for new bolt provider can't be blank"
+
+	// endKeySuffix replaces storage.EndKeySuffix in range queries, keys are compared byte-wise
+	endKeySuffix = "~"
+
+	defaultOpenTimeout = 10 * time.Second
+)
+
+// errStoreClosed is returned when using a store after it was closed
+var errStoreClosed = errors.New("store is closed")
+
+// Provider is a bbolt implementation of the storage.Provider interface.
+// All the stores live in a single database file, each store (namespace) in its own bucket.
+type Provider struct {
+	db          *bbolt.DB
+	dbs         map[string]*boltStore
+	openTimeout time.Duration
+	sync.RWMutex
+}
+
+// Option configures the bolt provider
+type Option func(opts *Provider)
+
+// WithOpenTimeout option sets how long to wait for the lock of a database file used by another process
+func WithOpenTimeout(timeout time.Duration) Option {
+	return func(opts *Provider) {
+		opts.openTimeout = timeout
+	}
+}
+
+// NewProvider instantiates Provider, creating the database file at the given path if it doesn't exist.
+func NewProvider(path string, opts ...Option) (*Provider, error) {
+	if path == "" {
+		return nil, errors.New(blankPathErrMsg)
+	}
+
+	p := &Provider{dbs: make(map[string]*boltStore), openTimeout: defaultOpenTimeout}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: p.openTimeout}) // nolint:gomnd
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	p.db = db
+
+	return p, nil
+}
+
+// OpenStore opens and returns a store for given name space, creating its bucket if it doesn't exist.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	name = strings.ToLower(name)
+
+	store, ok := p.dbs[name]
+	if ok {
+		return store, nil
+	}
+
+	err := p.db.Update(func(tx *bbolt.Tx) error {
+		_, e := tx.CreateBucketIfNotExists([]byte(name))
+		return e
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bucket %s: %w", name, err)
+	}
+
+	store = &boltStore{db: p.db, bucket: []byte(name)}
+	p.dbs[name] = store
+
+	return store, nil
+}
+
+// CloseStore closes the store of given name space. Its data is kept and can be accessed by opening it again.
+func (p *Provider) CloseStore(name string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	k := strings.ToLower(name)
+
+	store, ok := p.dbs[k]
+	if ok {
+		delete(p.dbs, k)
+		store.close()
+	}
+
+	return nil
+}
+
+// Close closes all stores created under this store provider and the database file
+func (p *Provider) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, store := range p.dbs {
+		store.close()
+	}
+
+	p.dbs = make(map[string]*boltStore)
+
+	return p.db.Close()
+}
+
+type boltStore struct {
+	db     *bbolt.DB
+	bucket []byte
+	closed bool
+	lock   sync.RWMutex
+}
+
+func (s *boltStore) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+}
+
+// view runs fn in a read-only transaction on the store's bucket
+func (s *boltStore) view(fn func(b *bbolt.Bucket) error) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return errStoreClosed
+	}
+
+	return s.db.View(func(tx *bbolt.Tx) error {
+		return fn(tx.Bucket(s.bucket))
+	})
+}
+
+// update runs fn in a read-write transaction on the store's bucket
+func (s *boltStore) update(fn func(b *bbolt.Bucket) error) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return errStoreClosed
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return fn(tx.Bucket(s.bucket))
+	})
+}
+
+// Put stores the key and the record
+func (s *boltStore) Put(k string, v []byte) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	err := s.update(func(b *bbolt.Bucket) error {
+		return b.Put([]byte(k), v)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store data: %w", err)
+	}
+
+	return nil
+}
+
+// Get fetches the record based on key
+func (s *boltStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	var v []byte
+
+	err := s.view(func(b *bbolt.Bucket) error {
+		data := b.Get([]byte(k))
+		if data == nil {
+			return storage.ErrDataNotFound
+		}
+
+		// the data is only valid during the transaction
+		v = append([]byte{}, data...)
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("failed to get data: %w", err)
+	}
+
+	return v, nil
+}
+
+// Iterator returns iterator for the latest snapshot of the underlying db.
+// The range is read upfront in a single transaction, so that no transaction stays open while the caller iterates.
+func (s *boltStore) Iterator(startKey, endKey string) storage.StoreIterator {
+	start := []byte(startKey)
+	end := []byte(strings.ReplaceAll(endKey, storage.EndKeySuffix, endKeySuffix))
+	// the whole bucket is read for the whole key range, including the keys sorting after endKeySuffix
+	all := startKey == "" && endKey == storage.EndKeySuffix
+
+	itr := &boltIterator{}
+
+	err := s.view(func(b *bbolt.Bucket) error {
+		c := b.Cursor()
+
+		for k, v := c.Seek(start); k != nil && (all || bytes.Compare(k, end) < 0); k, v = c.Next() {
+			itr.items = append(itr.items, [2][]byte{append([]byte{}, k...), append([]byte{}, v...)})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return &boltIterator{err: fmt.Errorf("failed to read data: %w", err)}
+	}
+
+	return itr
+}
+
+// Delete will delete record with k key
+func (s *boltStore) Delete(k string) error {
+	if k == "" {
+		return errors.New("key is mandatory")
+	}
+
+	err := s.update(func(b *bbolt.Bucket) error {
+		return b.Delete([]byte(k))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	return nil
+}
+
+// NewBatch returns a batch whose writes are applied atomically in a single bbolt transaction
+func (s *boltStore) NewBatch() storage.Batch {
+	return &boltBatch{store: s}
+}
+
+// boltBatchOp is a write recorded in a boltBatch
+type boltBatchOp struct {
+	key    string
+	value  []byte
+	delete bool
+}
+
+type boltBatch struct {
+	store *boltStore
+	ops   []boltBatchOp
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *boltBatch) Put(k string, v []byte) {
+	b.ops = append(b.ops, boltBatchOp{key: k, value: v})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *boltBatch) Delete(k string) {
+	b.ops = append(b.ops, boltBatchOp{key: k, delete: true})
+}
+
+// Commit applies the writes of the batch in one transaction
+func (b *boltBatch) Commit() error {
+	for _, op := range b.ops {
+		if op.key == "" {
+			return errors.New("key is mandatory")
+		}
+
+		if !op.delete && op.value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	err := b.store.update(func(bucket *bbolt.Bucket) error {
+		for _, op := range b.ops {
+			var e error
+
+			if op.delete {
+				e = bucket.Delete([]byte(op.key))
+			} else {
+				e = bucket.Put([]byte(op.key), op.value)
+			}
+
+			if e != nil {
+				return e
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	b.ops = nil
+
+	return nil
+}
+
+type boltIterator struct {
+	currentIndex int
+	currentItem  *[2][]byte
+	items        [][2][]byte
+	err          error
+}
+
+// Next moves pointer to next value of iterator.
+// It returns false if the iterator is exhausted.
+func (i *boltIterator) Next() bool {
+	if i.currentIndex >= len(i.items) {
+		i.currentItem = nil
+
+		return false
+	}
+
+	i.currentItem = &i.items[i.currentIndex]
+	i.currentIndex++
+
+	return true
+}
+
+// Release releases associated resources.
+func (i *boltIterator) Release() {
+	i.currentIndex = 0
+	i.currentItem = nil
+	i.items = nil
+}
+
+// Error returns error in iterator.
+func (i *boltIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key/value pair.
+func (i *boltIterator) Key() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[0]
+}
+
+// Value returns the value of the current key/value pair.
+func (i *boltIterator) Value() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[1]
+}
@@ -0,0 +1,426 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bolt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func setupBoltDB(t testing.TB) (string, func()) {
+	dbPath, err := ioutil.TempDir("", "boltdb")
+	if err != nil {
+		t.Fatalf("Failed to create bolt directory: %s", err)
+	}
+
+	return filepath.Join(dbPath, "aries.db"), func() {
+		err := os.RemoveAll(dbPath)
+		if err != nil {
+			t.Fatalf("Failed to clear bolt directory: %s", err)
+		}
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Run("Test blank path", func(t *testing.T) {
+		prov, err := NewProvider("")
+		require.EqualError(t, err, blankPathErrMsg)
+		require.Nil(t, prov)
+	})
+
+	t.Run("Test invalid path", func(t *testing.T) {
+		prov, err := NewProvider(filepath.Join(os.TempDir(), "missing-dir", "sub", "aries.db"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open database")
+		require.Nil(t, prov)
+	})
+
+	t.Run("Test database file locked by another provider", func(t *testing.T) {
+		path, cleanup := setupBoltDB(t)
+		defer cleanup()
+
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		other, err := NewProvider(path, WithOpenTimeout(50*time.Millisecond))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timeout")
+		require.Nil(t, other)
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestBoltStore(t *testing.T) {
+	path, cleanup := setupBoltDB(t)
+	defer cleanup()
+
+	t.Run("Test bolt store put and get", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		const key = "did:example:123"
+		data := []byte("value")
+
+		err = store.Put(key, data)
+		require.NoError(t, err)
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.NotEmpty(t, doc)
+		require.Equal(t, data, doc)
+
+		// update value
+		err = store.Put(key, []byte("value2"))
+		require.NoError(t, err)
+
+		doc, err = store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), doc)
+
+		did2 := "did:example:789"
+		_, err = store.Get(did2)
+		require.True(t, err == storage.ErrDataNotFound)
+
+		// nil key
+		_, err = store.Get("")
+		require.Error(t, err)
+
+		// nil value
+		err = store.Put(key, nil)
+		require.Error(t, err)
+
+		// nil key
+		err = store.Put("", data)
+		require.Error(t, err)
+
+		err = prov.Close()
+		require.NoError(t, err)
+
+		// try to get after provider is closed
+		_, err = store.Get(key)
+		require.Error(t, err)
+
+		// try to put after provider is closed
+		err = store.Put(key, data)
+		require.Error(t, err)
+
+		// try to delete after provider is closed
+		err = store.Delete(key)
+		require.Error(t, err)
+
+		// try to iterate after provider is closed
+		itr := store.Iterator("did:", "did:"+storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+	})
+
+	t.Run("Test bolt multi store put and get", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		const commonKey = "did:example:1"
+		data := []byte("value1")
+		// create store 1 & store 2
+		store1, err := prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		store2, err := prov.OpenStore("store2")
+		require.NoError(t, err)
+
+		// put in store 1
+		err = store1.Put(commonKey, data)
+		require.NoError(t, err)
+
+		// get in store 1 - found
+		doc, err := store1.Get(commonKey)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// get in store 2 - not found
+		doc, err = store2.Get(commonKey)
+		require.Equal(t, err, storage.ErrDataNotFound)
+		require.Empty(t, doc)
+
+		// put in store 2
+		err = store2.Put(commonKey, data)
+		require.NoError(t, err)
+
+		// get in store 2 - found
+		doc, err = store2.Get(commonKey)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// create new store 3 with same name as store1
+		store3, err := prov.OpenStore("STORE1")
+		require.NoError(t, err)
+
+		// get in store 3 - found
+		doc, err = store3.Get(commonKey)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// store length
+		require.Len(t, prov.dbs, 2)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test bolt store data survives provider restart", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("persisted")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.NoError(t, err)
+
+		require.NoError(t, prov.Close())
+
+		prov, err = NewProvider(path)
+		require.NoError(t, err)
+
+		store, err = prov.OpenStore("persisted")
+		require.NoError(t, err)
+
+		doc, err := store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), doc)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test bolt multi store close by name", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		const commonKey = "did:example:1"
+		data := []byte("value1")
+
+		storeNames := []string{"store_1", "store_2", "store_3", "store_4", "store_5"}
+		storesToClose := []string{"store_1", "STore_3", "stOre_5"}
+
+		for _, name := range storeNames {
+			store, e := prov.OpenStore(name)
+			require.NoError(t, e)
+
+			e = store.Put(commonKey, data)
+			require.NoError(t, e)
+		}
+
+		closedStore, err := prov.OpenStore("store_1")
+		require.NoError(t, err)
+
+		// verify store length
+		require.Len(t, prov.dbs, 5)
+
+		for _, name := range storesToClose {
+			e := prov.CloseStore(name)
+			require.NoError(t, e)
+		}
+
+		// verify store length
+		require.Len(t, prov.dbs, 2)
+
+		// try to close non existing db
+		err = prov.CloseStore("store_x")
+		require.NoError(t, err)
+
+		// verify store length
+		require.Len(t, prov.dbs, 2)
+
+		// a closed store can't be used anymore
+		_, err = closedStore.Get(commonKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errStoreClosed.Error())
+
+		// reopened store still has its data
+		store, err := prov.OpenStore("store_1")
+		require.NoError(t, err)
+
+		dataRead, err := store.Get(commonKey)
+		require.NoError(t, err)
+		require.Equal(t, data, dataRead)
+
+		err = prov.Close()
+		require.NoError(t, err)
+
+		// verify store length
+		require.Empty(t, prov.dbs)
+	})
+
+	t.Run("Test bolt store iterator", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test-iterator")
+		require.NoError(t, err)
+
+		const valPrefix = "val-for-%s"
+		keys := []string{"abc_123", "abc_124", "abc_125", "abc_126", "jkl_123", "mno_123", "dab_123"}
+
+		for _, key := range keys {
+			err = store.Put(key, []byte(fmt.Sprintf(valPrefix, key)))
+			require.NoError(t, err)
+		}
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		verifyItr(t, itr, 4, "abc_")
+
+		itr = store.Iterator("", "")
+		verifyItr(t, itr, 0, "")
+
+		itr = store.Iterator("abc_", "mno_"+storage.EndKeySuffix)
+		verifyItr(t, itr, 7, "")
+
+		itr = store.Iterator("abc_", "mno_123")
+		verifyItr(t, itr, 6, "")
+
+		// iteration is ordered by key
+		itr = store.Iterator("abc_", "dab_"+storage.EndKeySuffix)
+
+		var iterated []string
+		for itr.Next() {
+			iterated = append(iterated, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, []string{"abc_123", "abc_124", "abc_125", "abc_126", "dab_123"}, iterated)
+
+		// the whole key range includes the keys sorting after "~"
+		require.NoError(t, store.Put("~xyz_123", []byte(fmt.Sprintf(valPrefix, "~xyz_123"))))
+
+		itr = store.Iterator("", storage.EndKeySuffix)
+		verifyItr(t, itr, 8, "")
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
+	var vals []string
+
+	for itr.Next() {
+		if prefix != "" {
+			require.True(t, strings.HasPrefix(string(itr.Key()), prefix))
+		}
+
+		vals = append(vals, string(itr.Value()))
+	}
+	require.Len(t, vals, count)
+	require.NoError(t, itr.Error())
+
+	itr.Release()
+	require.False(t, itr.Next())
+	require.Empty(t, itr.Key())
+	require.Empty(t, itr.Value())
+}
+
+func TestBoltStoreDelete(t *testing.T) {
+	path, cleanup := setupBoltDB(t)
+	defer cleanup()
+
+	const commonKey = "did:example:1"
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	data := []byte("value1")
+
+	store1, err := prov.OpenStore("store1")
+	require.NoError(t, err)
+
+	// put in store 1
+	err = store1.Put(commonKey, data)
+	require.NoError(t, err)
+
+	// get in store 1 - found
+	doc, err := store1.Get(commonKey)
+	require.NoError(t, err)
+	require.Equal(t, data, doc)
+
+	// now try Delete with an empty key - should fail
+	err = store1.Delete("")
+	require.EqualError(t, err, "key is mandatory")
+
+	// finally test Delete an existing key
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
+
+	doc, err = store1.Get(commonKey)
+	require.EqualError(t, err, storage.ErrDataNotFound.Error())
+	require.Empty(t, doc)
+
+	// deleting a missing key is not an error
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestBoltStoreBatch(t *testing.T) {
+	path, cleanup := setupBoltDB(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("batch")
+	require.NoError(t, err)
+
+	batchable, ok := store.(storage.BatchableStore)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+
+	batch := batchable.NewBatch()
+	batch.Put("k2", []byte("v2"))
+	batch.Delete("k1")
+	require.NoError(t, batch.Commit())
+
+	_, err = store.Get("k1")
+	require.Equal(t, storage.ErrDataNotFound, err)
+
+	v, err := store.Get("k2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), v)
+
+	batch = batchable.NewBatch()
+	batch.Put("k3", []byte("v3"))
+	batch.Put("k4", nil)
+	require.EqualError(t, batch.Commit(), "key and value are mandatory")
+
+	batch = batchable.NewBatch()
+	batch.Delete("")
+	require.EqualError(t, batch.Commit(), "key is mandatory")
+
+	_, err = store.Get("k3")
+	require.Equal(t, storage.ErrDataNotFound, err)
+
+	require.NoError(t, prov.CloseStore("batch"))
+
+	batch = batchable.NewBatch()
+	batch.Put("k3", []byte("v3"))
+	require.Error(t, batch.Commit())
+
+	require.NoError(t, prov.Close())
+}
@@ -0,0 +1,404 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cache provides a storage.Provider decorator keeping the most recently read records of every store in
+// memory, in front of a provider with costly reads such as CouchDB:
+//
+//	framework, err := aries.New(aries.WithStoreProvider(cache.NewProvider(couchDBProvider)))
+//
+// Reads go through a LRU cache of each store, writes go to the underlying store and invalidate the cached records.
+// Only the writes made through this provider are seen: the underlying stores must not be written by other means.
+// Records expiring in the underlying store are never cached. The stores are only queryable if the underlying stores are,
+// queries aren't cached. Their other extensions are forwarded to the underlying stores with the storage helpers, e.g.
+// their batches are only atomic and they can only be watched if the underlying stores support it.
+package cache
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// defaultSize is the number of records cached per store unless WithSize is used
+	defaultSize = 1000
+
+	// sweepInterval is how often the keys written with a TTL which have expired are forgotten
+	sweepInterval = time.Minute
+)
+
+// Provider is a storage.Provider caching the records of an underlying storage.Provider
+type Provider struct {
+	provider storage.Provider
+	size     int
+	stores   map[string]*cacheStore
+	// metrics are kept when a store is closed, so that they cover the lifetime of the provider
+	metrics map[string]*counters
+	lock    sync.RWMutex
+}
+
+// Option configures the cache provider
+type Option func(opts *Provider)
+
+// WithSize option sets the maximum number of records cached per store
+func WithSize(size int) Option {
+	return func(opts *Provider) {
+		opts.size = size
+	}
+}
+
+// NewProvider instantiates Provider
+func NewProvider(p storage.Provider, opts ...Option) *Provider {
+	cp := &Provider{
+		provider: p,
+		size:     defaultSize,
+		stores:   make(map[string]*cacheStore),
+		metrics:  make(map[string]*counters),
+	}
+
+	for _, opt := range opts {
+		opt(cp)
+	}
+
+	return cp
+}
+
+// OpenStore opens and returns a store for given name space.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	k := strings.ToLower(name)
+
+	if store, ok := p.stores[k]; ok {
+		return store.extended(), nil
+	}
+
+	store, err := p.provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.metrics[k]; !ok {
+		p.metrics[k] = &counters{}
+	}
+
+	p.stores[k] = &cacheStore{
+		store:     store,
+		cache:     newLRU(p.size),
+		expiring:  make(map[string]time.Time),
+		nextSweep: time.Now().Add(sweepInterval),
+		metrics:   p.metrics[k],
+	}
+
+	return p.stores[k].extended(), nil
+}
+
+// CloseStore closes store of given name space, dropping its cached records
+func (p *Provider) CloseStore(name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.stores, strings.ToLower(name))
+
+	return p.provider.CloseStore(name)
+}
+
+// Close closes all stores created under this store provider
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.stores = make(map[string]*cacheStore)
+
+	return p.provider.Close()
+}
+
+// StoreExists tells whether the underlying provider has the store of given name space, if it supports it
+func (p *Provider) StoreExists(name string) (bool, error) {
+	return storage.StoreExists(p.provider, name)
+}
+
+// Metrics are the cache hits and misses of the reads of a store
+type Metrics struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Metrics returns the cache hits and misses of the store of given name space since the provider was created
+func (p *Provider) Metrics(name string) Metrics {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	c, ok := p.metrics[strings.ToLower(name)]
+	if !ok {
+		return Metrics{}
+	}
+
+	return Metrics{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+type counters struct {
+	hits   uint64
+	misses uint64
+}
+
+type cacheStore struct {
+	store   storage.Store
+	metrics *counters
+	lock    sync.Mutex
+	cache   *lru
+	// version is incremented by every write, a record read from the underlying store is only cached if no
+	// write happened while it was read
+	version uint64
+	// expiring holds the keys written with a TTL, with a time their record has expired at in the underlying store
+	expiring map[string]time.Time
+	// nextSweep is when the expired keys are next removed from expiring
+	nextSweep time.Time
+}
+
+// extended returns the store, queryable if the underlying store is
+func (s *cacheStore) extended() storage.Store {
+	if qs, ok := s.store.(storage.QueryableStore); ok {
+		return &queryableStore{cacheStore: s, queryable: qs}
+	}
+
+	return s
+}
+
+// Put stores the key and the record
+func (s *cacheStore) Put(k string, v []byte) error {
+	return s.write(func() error { return s.store.Put(k, v) }, cacheOp{key: k})
+}
+
+// Get fetches the record based on key, from the cache if it's there
+func (s *cacheStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	s.lock.Lock()
+
+	if v, ok := s.cache.get(k); ok {
+		s.lock.Unlock()
+		atomic.AddUint64(&s.metrics.hits, 1)
+
+		return append([]byte{}, v...), nil
+	}
+
+	version := s.version
+	_, expiring := s.expiring[k]
+	s.lock.Unlock()
+
+	atomic.AddUint64(&s.metrics.misses, 1)
+
+	v, err := s.store.Get(k)
+	if err != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		// forget expired records
+		if errors.Is(err, storage.ErrDataNotFound) && s.version == version {
+			delete(s.expiring, k)
+		}
+
+		return nil, err
+	}
+
+	if expiring {
+		return v, nil
+	}
+
+	// the record may have been written with a TTL by other means, e.g. before a restart
+	expiresAt, err := s.expiry(k)
+	if err != nil {
+		return v, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.version != version {
+		return v, nil
+	}
+
+	if !expiresAt.IsZero() {
+		s.expiring[k] = expiresAt
+
+		return v, nil
+	}
+
+	if _, ok := s.expiring[k]; !ok {
+		s.cache.add(k, append([]byte{}, v...))
+	}
+
+	return v, nil
+}
+
+// expiry returns the time the record of the given key expires at in the underlying store, the zero time if it
+// doesn't expire or the underlying store doesn't expire records
+func (s *cacheStore) expiry(k string) (time.Time, error) {
+	if _, ok := s.store.(storage.ExpiringStore); !ok {
+		return time.Time{}, nil
+	}
+
+	return storage.Expiry(s.store, k)
+}
+
+// Iterator returns an iterator of the underlying store, records aren't cached when iterating
+func (s *cacheStore) Iterator(startKey, endKey string) storage.StoreIterator {
+	return s.store.Iterator(startKey, endKey)
+}
+
+// Delete will delete record with k key
+func (s *cacheStore) Delete(k string) error {
+	return s.write(func() error { return s.store.Delete(k) }, cacheOp{key: k})
+}
+
+// PutWithTTL stores the key and the record, the record expires once ttl has elapsed if the underlying store
+// implements storage.ExpiringStore.
+func (s *cacheStore) PutWithTTL(k string, v []byte, ttl time.Duration) error {
+	return s.write(func() error { return storage.PutWithTTL(s.store, k, v, ttl) }, cacheOp{key: k, ttl: &ttl})
+}
+
+// Expiry fetches the time the record of the given key expires at from the underlying store
+func (s *cacheStore) Expiry(k string) (time.Time, error) {
+	return storage.Expiry(s.store, k)
+}
+
+// Watch watches the underlying store, it fails if the underlying store doesn't implement storage.WatchableStore
+func (s *cacheStore) Watch(prefix string) (<-chan storage.Change, func(), error) {
+	return storage.Watch(s.store, prefix)
+}
+
+// NewBatch returns a batch of the underlying store, invalidating the cached records it writes on Commit. The batch
+// is only atomic if the underlying store implements storage.BatchableStore.
+func (s *cacheStore) NewBatch() storage.Batch {
+	return &cacheBatch{store: s, batch: storage.NewBatch(s.store)}
+}
+
+// cacheOp is a write whose key must be removed from the cache
+type cacheOp struct {
+	key string
+	// ttl is set for the records written with a TTL
+	ttl *time.Duration
+}
+
+// write applies writeFn to the underlying store, invalidating the written keys before and after it: no cached
+// record is read once the write started, and the records read from the underlying store while it is applied aren't
+// kept. The keys are invalidated even if the write failed since the state of the underlying store is unknown then.
+func (s *cacheStore) write(writeFn func() error, ops ...cacheOp) error {
+	s.invalidate(ops...)
+	defer s.invalidate(ops...)
+
+	return writeFn()
+}
+
+// invalidate removes the written keys from the cache and prevents the reads in progress from caching them.
+// The keys written with a TTL are kept in expiring until their record has expired: their expiration time is taken
+// when the write returns, which is no earlier than the expiration time set by the underlying store.
+func (s *cacheStore) invalidate(ops ...cacheOp) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.version++
+
+	now := time.Now()
+
+	for _, op := range ops {
+		s.cache.remove(op.key)
+
+		if op.ttl != nil {
+			s.expiring[op.key] = now.Add(*op.ttl)
+		} else {
+			delete(s.expiring, op.key)
+		}
+	}
+
+	s.sweep(now)
+}
+
+// sweep forgets the keys whose record has expired, at most once per sweepInterval. The caller must hold the lock.
+func (s *cacheStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for k, expiresAt := range s.expiring {
+		if !now.Before(expiresAt) {
+			delete(s.expiring, k)
+		}
+	}
+
+	s.nextSweep = now.Add(sweepInterval)
+}
+
+type cacheBatch struct {
+	store *cacheStore
+	batch storage.Batch
+	ops   []cacheOp
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *cacheBatch) Put(k string, v []byte) {
+	b.batch.Put(k, v)
+	b.ops = append(b.ops, cacheOp{key: k})
+}
+
+// PutWithTTL adds the storing of an expiring record to the batch, if the underlying batch supports it
+func (b *cacheBatch) PutWithTTL(k string, v []byte, ttl time.Duration) {
+	storage.BatchPutWithTTL(b.batch, k, v, ttl)
+	b.ops = append(b.ops, cacheOp{key: k, ttl: &ttl})
+}
+
+// PutWithTags adds the storing of a tagged record to the batch, if the underlying batch supports it
+func (b *cacheBatch) PutWithTags(k string, v []byte, tags ...storage.Tag) {
+	storage.BatchPutWithTags(b.batch, k, v, tags...)
+	b.ops = append(b.ops, cacheOp{key: k})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *cacheBatch) Delete(k string) {
+	b.batch.Delete(k)
+	b.ops = append(b.ops, cacheOp{key: k})
+}
+
+// Commit applies the writes of the underlying batch
+func (b *cacheBatch) Commit() error {
+	err := b.store.write(b.batch.Commit, b.ops...)
+	if err != nil {
+		return err
+	}
+
+	b.ops = nil
+
+	return nil
+}
+
+// queryableStore is the cache store of an underlying storage.QueryableStore
+type queryableStore struct {
+	*cacheStore
+	queryable storage.QueryableStore
+}
+
+// PutWithTags stores the key and the record along with the given tags
+func (s *queryableStore) PutWithTags(k string, v []byte, tags ...storage.Tag) error {
+	return s.write(func() error { return s.queryable.PutWithTags(k, v, tags...) }, cacheOp{key: k})
+}
+
+// GetTags fetches the tags of the record from the underlying store, tags aren't cached
+func (s *queryableStore) GetTags(k string) ([]storage.Tag, error) {
+	return s.queryable.GetTags(k)
+}
+
+// Query queries the underlying store, the records it returns aren't cached
+func (s *queryableStore) Query(name, value string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	return s.queryable.Query(name, value, opts...)
+}
@@ -0,0 +1,436 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestCacheStore(t *testing.T) {
+	t.Run("reads are cached", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		prov := NewProvider(underlying)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value")))
+
+		for i := 0; i < 3; i++ {
+			v, e := store.Get("key")
+			require.NoError(t, e)
+			require.Equal(t, []byte("value"), v)
+		}
+
+		require.Equal(t, Metrics{Hits: 2, Misses: 1}, prov.Metrics("TEST"))
+
+		// values returned from the cache can't alter it
+		v, err := store.Get("key")
+		require.NoError(t, err)
+
+		v[0] = 'x'
+
+		v, err = store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+
+		// not found records aren't cached
+		_, err = store.Get("missing")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		_, err = store.Get("missing")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		require.Equal(t, Metrics{Hits: 4, Misses: 3}, prov.Metrics("test"))
+		require.Equal(t, Metrics{}, prov.Metrics("unknown"))
+
+		_, err = store.Get("")
+		require.EqualError(t, err, "key is mandatory")
+
+		// same store is returned for the same name
+		store2, err := prov.OpenStore("TEST")
+		require.NoError(t, err)
+		require.Equal(t, store, store2)
+	})
+
+	t.Run("writes invalidate cached records", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value")))
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value2")))
+
+		v, err := store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		require.NoError(t, store.Delete("key"))
+
+		_, err = store.Get("key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		require.Equal(t, Metrics{Misses: 3}, prov.Metrics("test"))
+	})
+
+	t.Run("records read during a write aren't cached", func(t *testing.T) {
+		memStore, err := mem.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		blocking := &blockingStore{Store: memStore, started: make(chan struct{}), release: make(chan struct{})}
+		prov := NewProvider(&storeProvider{store: blocking})
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, memStore.Put("key", []byte("value")))
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		written := make(chan error)
+
+		go func() {
+			written <- store.Put("key", []byte("value2"))
+		}()
+
+		<-blocking.started
+
+		// the cached record is invalidated once the write started
+		v, err := store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+		require.Equal(t, Metrics{Misses: 2}, prov.Metrics("test"))
+
+		close(blocking.release)
+		require.NoError(t, <-written)
+
+		v, err = store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		s, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		store, ok := s.(storage.QueryableStore)
+		require.True(t, ok)
+
+		require.NoError(t, store.Put("key", []byte("value")))
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		require.NoError(t, store.PutWithTags("key", []byte("value2"), storage.Tag{Name: "tag", Value: "v"}))
+
+		v, err := store.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		tags, err := store.GetTags("key")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "tag", Value: "v"}}, tags)
+
+		itr, err := store.Query("tag", "v")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("key"), itr.Key())
+
+		// stores of underlying stores which can't be queried can't be queried either
+		s, err = NewProvider(mockstorage.NewMockStoreProvider()).OpenStore("test")
+		require.NoError(t, err)
+
+		_, ok = s.(storage.QueryableStore)
+		require.False(t, ok)
+	})
+
+	t.Run("batch writes invalidate cached records", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.Put("k2", []byte("v2")))
+
+		_, err = store.Get("k1")
+		require.NoError(t, err)
+
+		_, err = store.Get("k2")
+		require.NoError(t, err)
+
+		batch := storage.NewBatch(store)
+		batch.Put("k1", []byte("v1-updated"))
+		batch.Delete("k2")
+		require.NoError(t, batch.Commit())
+
+		v, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1-updated"), v)
+
+		_, err = store.Get("k2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		batch = storage.NewBatch(store)
+		batch.Put("", []byte("v"))
+		require.Error(t, batch.Commit())
+	})
+
+	t.Run("expiring records aren't cached", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider(mem.WithSweepInterval(time.Hour)))
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.PutWithTTL(store, "key", []byte("value"), 50*time.Millisecond))
+
+		batch := storage.NewBatch(store)
+		storage.BatchPutWithTTL(batch, "key2", []byte("value2"), 50*time.Millisecond)
+		require.NoError(t, batch.Commit())
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		_, err = store.Get("key2")
+		require.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = store.Get("key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		_, err = store.Get("key2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// writing the key without TTL makes it cacheable again
+		require.NoError(t, store.Put("key", []byte("value")))
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		require.Equal(t, Metrics{Hits: 1, Misses: 5}, prov.Metrics("test"))
+	})
+
+	t.Run("records expiring in the underlying store aren't cached", func(t *testing.T) {
+		underlying := mem.NewProvider(mem.WithSweepInterval(time.Hour))
+
+		us, err := underlying.OpenStore("test")
+		require.NoError(t, err)
+
+		// written with a TTL without going through the cache, e.g. before a restart
+		require.NoError(t, storage.PutWithTTL(us, "key", []byte("value"), 50*time.Millisecond))
+
+		prov := NewProvider(underlying)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+		require.Contains(t, prov.stores["test"].expiring, "key")
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = store.Get("key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+		require.Equal(t, Metrics{Misses: 2}, prov.Metrics("test"))
+	})
+
+	t.Run("expired keys are forgotten", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.PutWithTTL(store, "key", []byte("value"), 10*time.Millisecond))
+		require.NoError(t, storage.PutWithTTL(store, "key2", []byte("value2"), time.Hour))
+
+		cs := prov.stores["test"]
+		require.Len(t, cs.expiring, 2)
+
+		time.Sleep(20 * time.Millisecond)
+
+		// expired keys are only swept once per interval, by the writes
+		require.NoError(t, store.Put("key3", []byte("value3")))
+		require.Len(t, cs.expiring, 2)
+
+		cs.nextSweep = time.Now()
+
+		require.NoError(t, store.Put("key3", []byte("value3")))
+		require.Len(t, cs.expiring, 1)
+		require.Contains(t, cs.expiring, "key2")
+	})
+
+	t.Run("least recently used records are evicted", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider(), WithSize(2))
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			k := fmt.Sprintf("key%d", i)
+
+			require.NoError(t, store.Put(k, []byte("value")))
+
+			_, err = store.Get(k)
+			require.NoError(t, err)
+		}
+
+		cs := prov.stores["test"]
+		require.Equal(t, 2, cs.cache.len())
+
+		_, ok := cs.cache.get("key0")
+		require.False(t, ok)
+
+		_, err = store.Get("key0")
+		require.NoError(t, err)
+		require.Equal(t, Metrics{Misses: 4}, prov.Metrics("test"))
+	})
+
+	t.Run("iterator", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("abc_1", []byte("value")))
+		require.NoError(t, store.Put("abc_2", []byte("value")))
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+		count := 0
+		for itr.Next() {
+			count++
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, 2, count)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		changes, stop, err := storage.Watch(store, "key")
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, store.Put("key", []byte("value")))
+		require.Equal(t, storage.Change{Key: "key", Value: []byte("value")}, <-changes)
+
+		store, err = NewProvider(mockstorage.NewMockStoreProvider()).OpenStore("test")
+		require.NoError(t, err)
+
+		_, _, err = storage.Watch(store, "key")
+		require.True(t, errors.Is(err, storage.ErrWatchNotSupported))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		prov := NewProvider(mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value")))
+
+		_, err = store.Get("key")
+		require.NoError(t, err)
+
+		require.NoError(t, prov.CloseStore("test"))
+		require.Empty(t, prov.stores)
+
+		// metrics are kept after a store is closed
+		require.Equal(t, Metrics{Misses: 1}, prov.Metrics("test"))
+
+		_, err = prov.OpenStore("test")
+		require.NoError(t, err)
+		require.NoError(t, prov.Close())
+		require.Empty(t, prov.stores)
+	})
+
+	t.Run("extensions forwarded to an underlying store without them", func(t *testing.T) {
+		store, err := NewProvider(mockstorage.NewMockStoreProvider()).OpenStore("test")
+		require.NoError(t, err)
+
+		// records written with a TTL don't expire
+		require.NoError(t, storage.PutWithTTL(store, "key", []byte("value"), time.Millisecond))
+
+		expiry, err := storage.Expiry(store, "key")
+		require.NoError(t, err)
+		require.True(t, expiry.IsZero())
+
+		batch := storage.NewBatch(store)
+		batch.Put("key2", []byte("value2"))
+		require.NoError(t, batch.Commit())
+
+		v, err := store.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		prov := NewProvider(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")})
+
+		store, err := prov.OpenStore("test")
+		require.EqualError(t, err, "open error")
+		require.Nil(t, store)
+	})
+}
+
+// storeProvider opens the same store for every name space
+type storeProvider struct {
+	store storage.Store
+}
+
+func (p *storeProvider) OpenStore(string) (storage.Store, error) {
+	return p.store, nil
+}
+
+func (p *storeProvider) CloseStore(string) error {
+	return nil
+}
+
+func (p *storeProvider) Close() error {
+	return nil
+}
+
+// blockingStore signals its writes on started then waits for release to be closed to apply them
+type blockingStore struct {
+	storage.Store
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Put(k string, v []byte) error {
+	s.started <- struct{}{}
+	<-s.release
+
+	return s.Store.Put(k, v)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import "container/list"
+
+// lru is a least recently used cache of records, it isn't safe for concurrent use
+type lru struct {
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns the value of k and marks it as the most recently used
+func (c *lru) get(k string) ([]byte, bool) {
+	e, ok := c.items[k]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+
+	return e.Value.(*lruEntry).value, true
+}
+
+// add sets the value of k, evicting the least recently used entry if the cache is full
+func (c *lru) add(k string, v []byte) {
+	if e, ok := c.items[k]; ok {
+		e.Value.(*lruEntry).value = v
+		c.order.MoveToFront(e)
+
+		return
+	}
+
+	c.items[k] = c.order.PushFront(&lruEntry{key: k, value: v})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lru) remove(k string) {
+	if e, ok := c.items[k]; ok {
+		c.order.Remove(e)
+		delete(c.items, k)
+	}
+}
+
+func (c *lru) len() int {
+	return c.order.Len()
+}
@@ -14,6 +14,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,9 +23,13 @@ import (
 	_ "github.com/go-kivik/couchdb" // The CouchDB driver
 	"github.com/go-kivik/kivik"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/internal/watcher"
 )
 
+var logger = log.New("aries-framework/storage/couchdb")
+
 // Provider represents an CouchDB implementation of the storage.Provider interface
 type Provider struct {
 	hostURL       string
@@ -37,6 +43,14 @@ const (
 	blankHostErrMsg           = "hostURL for new CouchDB provider can't be blank"
 	failToCloseProviderErrMsg = "failed to close provider"
 	couchDBNotFoundErr        = "Not Found:"
+
+	// tagsField is the document field holding the record's tags as a name to value object
+	tagsField = "aries_tags"
+
+	// changesFeedHeartbeat is how often, in milliseconds, CouchDB writes to an idle changes feed to keep it open
+	changesFeedHeartbeat = 30000
+
+	designDocPrefix = "_design/"
 )
 
 // Option configures the couchdb provider
@@ -97,13 +111,34 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 		return nil, db.Err()
 	}
 
-	store := &CouchDBStore{db: db}
+	store := &CouchDBStore{db: db, indexes: make(map[string]struct{}), watchers: watcher.New()}
 
 	p.dbs[name] = store
 
 	return store, nil
 }
 
+// StoreExists tells whether the database of the store with the given name exists.
+func (p *Provider) StoreExists(name string) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	if _, exists := p.dbs[name]; exists {
+		return true, nil
+	}
+
+	exists, err := p.couchDBClient.DBExists(context.Background(), name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check db: %w", err)
+	}
+
+	return exists, nil
+}
+
 // CloseStore closes a previously opened store.
 func (p *Provider) CloseStore(name string) error {
 	p.Lock()
@@ -119,6 +154,7 @@ func (p *Provider) CloseStore(name string) error {
 	}
 
 	delete(p.dbs, name)
+	store.stopWatching()
 
 	return store.db.Close(context.Background())
 }
@@ -129,6 +165,8 @@ func (p *Provider) Close() error {
 	defer p.Unlock()
 
 	for _, store := range p.dbs {
+		store.stopWatching()
+
 		err := store.db.Close(context.Background())
 		if err != nil {
 			return fmt.Errorf(failToCloseProviderErrMsg+": %w", err)
@@ -147,10 +185,23 @@ func (p *Provider) Close() error {
 // CouchDBStore represents a CouchDB-backed database.
 type CouchDBStore struct {
 	db *kivik.DB
+	// indexes holds the tag names for which a Mango index was ensured
+	indexes     map[string]struct{}
+	indexesLock sync.Mutex
+	watchers    *watcher.Watchers
+	// stopFeed stops reading the changes feed, it is nil while the store isn't watched
+	stopFeed  context.CancelFunc
+	watchLock sync.Mutex
 }
 
 // Put stores the given key-value pair in the store.
 func (c *CouchDBStore) Put(k string, v []byte) error {
+	return c.PutWithTags(k, v)
+}
+
+// PutWithTags stores the given key-value pair in the store along with the given tags.
+// The tags are kept in a field of the CouchDB document so that Query can use Mango indexes.
+func (c *CouchDBStore) PutWithTags(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
@@ -167,8 +218,28 @@ func (c *CouchDBStore) Put(k string, v []byte) error {
 		return err
 	}
 
+	fields := make(map[string]interface{})
+
 	if revID != "" {
-		valueToPut, err = c.addRevID(valueToPut, revID)
+		fields["_rev"] = revID
+	}
+
+	if len(tags) > 0 {
+		tagValues := make(map[string]string, len(tags))
+
+		for _, tag := range tags {
+			if tag.Name == "" {
+				return errors.New("tag name is mandatory")
+			}
+
+			tagValues[tag.Name] = tag.Value
+		}
+
+		fields[tagsField] = tagValues
+	}
+
+	if len(fields) > 0 {
+		valueToPut, err = c.addFields(valueToPut, fields)
 		if err != nil {
 			return err
 		}
@@ -216,13 +287,15 @@ func (c *CouchDBStore) Get(k string) ([]byte, error) {
 	return c.getStoredValueFromRawDoc(rawDoc, k)
 }
 
-func (c *CouchDBStore) addRevID(valueToPut []byte, revID string) ([]byte, error) {
+func (c *CouchDBStore) addFields(valueToPut []byte, fields map[string]interface{}) ([]byte, error) {
 	var m map[string]interface{}
 	if err := json.Unmarshal(valueToPut, &m); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal put value: %w", err)
 	}
 
-	m["_rev"] = revID
+	for name, value := range fields {
+		m[name] = value
+	}
 
 	newValue, err := json.Marshal(m)
 	if err != nil {
@@ -269,6 +342,168 @@ func (c *CouchDBStore) Delete(k string) error {
 	return nil
 }
 
+// NewBatch returns a batch whose writes are sent to CouchDB with a single _bulk_docs request.
+// CouchDB applies the documents of the request independently, so the batch isn't atomic: if some of them
+// fail to be written, Commit returns an error listing them while the others are kept.
+func (c *CouchDBStore) NewBatch() storage.Batch {
+	return &couchDBBatch{store: c}
+}
+
+// couchDBBatchOp is a write recorded in a couchDBBatch
+type couchDBBatchOp struct {
+	key    string
+	value  []byte
+	delete bool
+	tags   []storage.Tag
+}
+
+type couchDBBatch struct {
+	store *CouchDBStore
+	ops   []couchDBBatchOp
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *couchDBBatch) Put(k string, v []byte) {
+	b.ops = append(b.ops, couchDBBatchOp{key: k, value: v})
+}
+
+// PutWithTags adds the storing of the key and the record along with the given tags to the batch
+func (b *couchDBBatch) PutWithTags(k string, v []byte, tags ...storage.Tag) {
+	b.ops = append(b.ops, couchDBBatchOp{key: k, value: v, tags: tags})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *couchDBBatch) Delete(k string) {
+	b.ops = append(b.ops, couchDBBatchOp{key: k, delete: true})
+}
+
+// Commit sends the writes of the batch to CouchDB
+func (b *couchDBBatch) Commit() error {
+	docs, err := b.docs()
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		b.ops = nil
+
+		return nil
+	}
+
+	results, err := b.store.db.BulkDocs(context.Background(), docs)
+	if err != nil {
+		return fmt.Errorf("failed to store batch: %w", err)
+	}
+
+	var failures []string
+
+	for results.Next() {
+		if e := results.UpdateErr(); e != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", results.ID(), e))
+		}
+	}
+
+	if err = results.Err(); err != nil {
+		return fmt.Errorf("failed to read batch results: %w", err)
+	}
+
+	if err = results.Close(); err != nil {
+		return fmt.Errorf("failed to close batch results: %w", err)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to store batch documents: %s", strings.Join(failures, "; "))
+	}
+
+	b.ops = nil
+
+	return nil
+}
+
+// docs converts the writes of the batch to CouchDB documents. A document can appear only once in a
+// _bulk_docs request, so only the last write of every key is kept.
+func (b *couchDBBatch) docs() ([]interface{}, error) {
+	last := make(map[string]int, len(b.ops))
+
+	for i, op := range b.ops {
+		if op.key == "" {
+			return nil, errors.New("key is mandatory")
+		}
+
+		if !op.delete && op.value == nil {
+			return nil, errors.New("key and value are mandatory")
+		}
+
+		for _, tag := range op.tags {
+			if tag.Name == "" {
+				return nil, errors.New("tag name is mandatory")
+			}
+		}
+
+		last[op.key] = i
+	}
+
+	var docs []interface{}
+
+	for i, op := range b.ops {
+		if last[op.key] != i {
+			continue
+		}
+
+		doc, err := b.doc(op)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs, nil
+}
+
+// doc returns the CouchDB document of a batch write, or nil when deleting a missing document
+func (b *couchDBBatch) doc(op couchDBBatchOp) (json.RawMessage, error) {
+	revID, err := b.store.getRevID(op.key)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{"_id": op.key}
+
+	if revID != "" {
+		fields["_rev"] = revID
+	}
+
+	if op.delete {
+		if revID == "" {
+			return nil, nil
+		}
+
+		fields["_deleted"] = true
+
+		return json.Marshal(fields)
+	}
+
+	if len(op.tags) > 0 {
+		tagValues := make(map[string]string, len(op.tags))
+
+		for _, tag := range op.tags {
+			tagValues[tag.Name] = tag.Value
+		}
+
+		fields[tagsField] = tagValues
+	}
+
+	value := op.value
+	if !isJSON(value) {
+		value = wrapTextAsCouchDBAttachment(value)
+	}
+
+	return b.store.addFields(value, fields)
+}
+
 // Iterator returns iterator for the latest snapshot of the underlying db.
 func (c *CouchDBStore) Iterator(startKey, endKey string) storage.StoreIterator {
 	resultRows, err := c.db.AllDocs(context.TODO(), kivik.Options{
@@ -292,7 +527,14 @@ type couchDBResultsIterator struct {
 }
 
 func (i *couchDBResultsIterator) Next() bool {
-	return i.resultRows.Next()
+	for i.resultRows.Next() {
+		// the design documents of the Mango indexes of the queries aren't records
+		if !strings.HasPrefix(i.resultRows.ID(), designDocPrefix) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (i *couchDBResultsIterator) Release() {
@@ -358,6 +600,7 @@ func (c *CouchDBStore) getStoredValueFromRawDoc(rawDoc map[string]interface{}, k
 	// Strip out the CouchDB-specific fields
 	delete(rawDoc, "_id")
 	delete(rawDoc, "_rev")
+	delete(rawDoc, tagsField)
 
 	strippedJSON, err := json.Marshal(rawDoc)
 	if err != nil {
@@ -380,3 +623,259 @@ func (c *CouchDBStore) getDataFromAttachment(k string) ([]byte, error) {
 
 	return data, nil
 }
+
+// GetTags fetches the tags associated with the record of the given key
+func (c *CouchDBStore) GetTags(k string) ([]storage.Tag, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	rawDoc := struct {
+		Tags map[string]string `json:"aries_tags"`
+	}{}
+
+	err := c.db.Get(context.Background(), k).ScanDoc(&rawDoc)
+	if err != nil {
+		if strings.Contains(err.Error(), couchDBNotFoundErr) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return nil, err
+	}
+
+	var tags []storage.Tag
+
+	for name, value := range rawDoc.Tags {
+		tags = append(tags, storage.Tag{Name: name, Value: value})
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+// Query returns an iterator over the records having a tag with the given name and value,
+// using a Mango index on the tag which is created on first use.
+func (c *CouchDBStore) Query(name, value string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	options, err := storage.NewQueryOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// dots separate nested fields in Mango, tag names are single fields
+	field := tagsField + "." + strings.ReplaceAll(name, ".", "\\.")
+
+	err = c.ensureIndex(name, field)
+	if err != nil {
+		return nil, err
+	}
+
+	var condition interface{} = value
+	if value == "" {
+		condition = map[string]interface{}{"$exists": true}
+	}
+
+	// Mango returns 25 documents unless told otherwise
+	limit := math.MaxInt32
+	if options.PageSize > 0 {
+		limit = options.PageSize
+	}
+
+	resultRows, err := c.db.Find(context.Background(), map[string]interface{}{
+		"selector": map[string]interface{}{field: condition},
+		"sort":     []map[string]string{{field: "asc"}},
+		"skip":     options.Offset,
+		"limit":    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query docs: %w", err)
+	}
+
+	return &couchDBQueryIterator{store: c, resultRows: resultRows}, nil
+}
+
+func (c *CouchDBStore) ensureIndex(name, field string) error {
+	c.indexesLock.Lock()
+	defer c.indexesLock.Unlock()
+
+	if _, ok := c.indexes[name]; ok {
+		return nil
+	}
+
+	err := c.db.CreateIndex(context.Background(), "", "",
+		map[string]interface{}{"fields": []string{field}})
+	if err != nil {
+		return fmt.Errorf("failed to create index for tag %s: %w", name, err)
+	}
+
+	c.indexes[name] = struct{}{}
+
+	return nil
+}
+
+// couchDBQueryIterator iterates over Mango query results, which carry documents but no keys
+type couchDBQueryIterator struct {
+	store      *CouchDBStore
+	resultRows *kivik.Rows
+	key        []byte
+	value      []byte
+	err        error
+}
+
+// Next moves pointer to next value of iterator.
+// It returns false if the iterator is exhausted.
+func (i *couchDBQueryIterator) Next() bool {
+	i.key, i.value = nil, nil
+
+	if i.err != nil || !i.resultRows.Next() {
+		return false
+	}
+
+	rawDoc := make(map[string]interface{})
+
+	if err := i.resultRows.ScanDoc(&rawDoc); err != nil {
+		i.err = err
+
+		return false
+	}
+
+	k, ok := rawDoc["_id"].(string)
+	if !ok {
+		i.err = errors.New("query result is missing the document ID")
+
+		return false
+	}
+
+	v, err := i.store.getStoredValueFromRawDoc(rawDoc, k)
+	if err != nil {
+		i.err = err
+
+		return false
+	}
+
+	i.key, i.value = []byte(k), v
+
+	return true
+}
+
+// Release releases associated resources.
+func (i *couchDBQueryIterator) Release() {
+	i.key, i.value = nil, nil
+
+	if err := i.resultRows.Close(); err != nil {
+		i.err = err
+	}
+}
+
+// Error returns error in iterator.
+func (i *couchDBQueryIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.resultRows.Err()
+}
+
+// Key returns the key of the current key-value pair.
+func (i *couchDBQueryIterator) Key() []byte {
+	return i.key
+}
+
+// Value returns the value of the current key-value pair.
+func (i *couchDBQueryIterator) Value() []byte {
+	return i.value
+}
+
+// Watch returns a channel receiving the changes of the records whose key starts with prefix.
+// The changes are read from the continuous _changes feed of the database, which is opened on the first call,
+// so unlike with the other stores the writes of other clients of the database are notified as well.
+// If the feed fails, the channels of all the watchers are closed.
+func (c *CouchDBStore) Watch(prefix string) (<-chan storage.Change, func(), error) {
+	c.watchLock.Lock()
+	defer c.watchLock.Unlock()
+
+	if c.stopFeed == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		feed, err := c.db.Changes(ctx, kivik.Options{
+			"feed":         "continuous",
+			"since":        "now",
+			"include_docs": "true",
+			"heartbeat":    changesFeedHeartbeat,
+		})
+		if err != nil {
+			cancel()
+
+			return nil, nil, fmt.Errorf("failed to open changes feed: %w", err)
+		}
+
+		c.stopFeed = cancel
+
+		go c.readChanges(ctx, feed)
+	}
+
+	changes, stop := c.watchers.Add(prefix)
+
+	return changes, stop, nil
+}
+
+// readChanges notifies the watchers of the changes read from the feed until it is closed
+func (c *CouchDBStore) readChanges(ctx context.Context, feed *kivik.Changes) {
+	for feed.Next() {
+		// design documents hold the indexes, they aren't records
+		if strings.HasPrefix(feed.ID(), designDocPrefix) {
+			continue
+		}
+
+		if feed.Deleted() {
+			c.watchers.Notify(storage.Change{Key: feed.ID(), Deleted: true})
+
+			continue
+		}
+
+		rawDoc := make(map[string]interface{})
+
+		if err := feed.ScanDoc(&rawDoc); err != nil {
+			logger.Warnf("failed to read changed document %s: %s", feed.ID(), err)
+
+			continue
+		}
+
+		v, err := c.getStoredValueFromRawDoc(rawDoc, feed.ID())
+		if err != nil {
+			logger.Warnf("failed to read changed document %s: %s", feed.ID(), err)
+
+			continue
+		}
+
+		c.watchers.Notify(storage.Change{Key: feed.ID(), Value: v})
+	}
+
+	if err := feed.Close(); err != nil {
+		logger.Warnf("failed to close changes feed: %s", err)
+	}
+
+	// the feed only ends on its own when it fails
+	if ctx.Err() == nil {
+		logger.Warnf("changes feed closed: %v", feed.Err())
+
+		c.stopWatching()
+	}
+}
+
+// stopWatching closes the changes feed and the channels of the watchers
+func (c *CouchDBStore) stopWatching() {
+	c.watchLock.Lock()
+	defer c.watchLock.Unlock()
+
+	if c.stopFeed != nil {
+		c.stopFeed()
+		c.stopFeed = nil
+	}
+
+	c.watchers.Close()
+}
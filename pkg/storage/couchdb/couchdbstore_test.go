@@ -9,6 +9,7 @@ SPDX-License-Identifier: Apache-2.0
 package couchdbstore
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -20,6 +21,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/migrate"
 )
 
 const (
@@ -342,3 +345,297 @@ func TestCouchDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestCouchDBStoreQuery(t *testing.T) {
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("query")
+	require.NoError(t, err)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	err = queryable.PutWithTags("vc1", []byte("data1"),
+		storage.Tag{Name: "issuer", Value: "did:example:1"}, storage.Tag{Name: "type", Value: "degree"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc2", []byte("data2"), storage.Tag{Name: "issuer", Value: "did:example:2"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc3", []byte("data3"), storage.Tag{Name: "issuer", Value: "did:example:1"})
+	require.NoError(t, err)
+
+	err = queryable.Put("vc4", []byte("data4"))
+	require.NoError(t, err)
+
+	t.Run("query by tag name and value", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "did:example:1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3"}, iteratedKeys(t, itr))
+	})
+
+	t.Run("query by tag name", func(t *testing.T) {
+		// ordered by tag value, then document ID
+		itr, err := queryable.Query("issuer", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3", "vc2"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("type", "")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("vc1"), itr.Key())
+		require.Equal(t, []byte("data1"), itr.Value())
+		require.False(t, itr.Next())
+	})
+
+	t.Run("query with pagination", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "", storage.WithPageSize(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithPageSize(2), storage.WithOffset(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc2"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithOffset(3))
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		_, err = queryable.Query("issuer", "", storage.WithOffset(-1))
+		require.Error(t, err)
+	})
+
+	t.Run("iteration skips the index design documents", func(t *testing.T) {
+		itr := queryable.Iterator("", storage.EndKeySuffix)
+		require.Equal(t, []string{"vc1", "vc2", "vc3", "vc4"}, iteratedKeys(t, itr))
+	})
+
+	t.Run("get tags", func(t *testing.T) {
+		tags, err := queryable.GetTags("vc1")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "issuer", Value: "did:example:1"}, {Name: "type", Value: "degree"}}, tags)
+
+		tags, err = queryable.GetTags("vc4")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		_, err = queryable.GetTags("vc5")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = queryable.GetTags("")
+		require.EqualError(t, err, "key is mandatory")
+	})
+
+	t.Run("put and delete update tags", func(t *testing.T) {
+		err := queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "new"})
+		require.NoError(t, err)
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		itr, err := queryable.Query("state", "new")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("state", "done")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc5"}, iteratedKeys(t, itr))
+
+		err = queryable.Put("vc5", []byte("data5"))
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		err = queryable.Delete("vc5")
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := queryable.Query("", "value")
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = queryable.PutWithTags("vc6", []byte("data6"), storage.Tag{Value: "value"})
+		require.EqualError(t, err, "tag name is mandatory")
+	})
+
+	t.Run("tags are not part of the stored value", func(t *testing.T) {
+		err := queryable.PutWithTags("vc7", []byte(`{"name":"value"}`), storage.Tag{Name: "state", Value: "new"})
+		require.NoError(t, err)
+
+		doc, err := queryable.Get("vc7")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"name":"value"}`, string(doc))
+
+		itr, err := queryable.Query("state", "new")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.JSONEq(t, `{"name":"value"}`, string(itr.Value()))
+		itr.Release()
+	})
+}
+
+func TestCouchDBStoreExport(t *testing.T) {
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("export")
+	require.NoError(t, err)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	err = queryable.PutWithTags("vc1", []byte("data1"), storage.Tag{Name: "issuer", Value: "did:example:1"})
+	require.NoError(t, err)
+
+	// the query creates the design document of its index in the database
+	itr, err := queryable.Query("issuer", "did:example:1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"vc1"}, iteratedKeys(t, itr))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, migrate.Export(prov, &buf, migrate.WithNamespaces("export")))
+
+	dest := mem.NewProvider()
+	require.NoError(t, migrate.Import(dest, &buf))
+
+	imported, err := dest.OpenStore("export")
+	require.NoError(t, err)
+
+	// the design documents aren't exported as records
+	require.Equal(t, []string{"vc1"}, iteratedKeys(t, imported.Iterator("", storage.EndKeySuffix)))
+}
+
+func iteratedKeys(t *testing.T, itr storage.StoreIterator) []string {
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
+
+func TestCouchDBStoreBatch(t *testing.T) {
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("batch")
+	require.NoError(t, err)
+
+	batchable, ok := store.(storage.BatchableStore)
+	require.True(t, ok)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, queryable.PutWithTags("k2", []byte(`{"field":"v2"}`), storage.Tag{Name: "tag", Value: "value"}))
+
+	t.Run("commit applies all writes", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k2", []byte(`{"field":"v2-updated"}`))
+		batch.Put("k3", []byte("v3"))
+		batch.Delete("k1")
+		batch.Put("k3", []byte("v3-updated"))
+		// deleting a missing document is a no-op
+		batch.Delete("k6")
+		require.NoError(t, batch.Commit())
+
+		_, err := store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		v, err := store.Get("k2")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"field":"v2-updated"}`), v)
+
+		v, err = store.Get("k3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3-updated"), v)
+
+		tags, err := queryable.GetTags("k2")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+	})
+
+	t.Run("invalid write makes the whole batch fail", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Put("k5", nil)
+		require.EqualError(t, batch.Commit(), "key and value are mandatory")
+
+		batch = batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Delete("")
+		require.EqualError(t, batch.Commit(), "key is mandatory")
+
+		_, err := store.Get("k4")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		require.NoError(t, batchable.NewBatch().Commit())
+	})
+}
+
+func TestCouchDBStoreWatch(t *testing.T) {
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("watch")
+	require.NoError(t, err)
+
+	watchable, ok := store.(storage.WatchableStore)
+	require.True(t, ok)
+
+	changes, stop, err := watchable.Watch("conn_")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("other_1", []byte("value")))
+	require.NoError(t, store.Put("conn_1", []byte(`{"field":"value"}`)))
+	require.NoError(t, store.Put("conn_2", []byte("value2")))
+	require.NoError(t, store.Delete("conn_1"))
+
+	expected := []storage.Change{
+		{Key: "conn_1", Value: []byte(`{"field":"value"}`)},
+		{Key: "conn_2", Value: []byte("value2")},
+		{Key: "conn_1", Deleted: true},
+	}
+
+	for _, e := range expected {
+		select {
+		case c := <-changes:
+			require.Equal(t, e, c)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for change", e.Key)
+		}
+	}
+
+	stop()
+
+	_, open := <-changes
+	require.False(t, open)
+
+	// closing the store closes the channels of its watchers
+	changes, _, err = watchable.Watch("")
+	require.NoError(t, err)
+
+	require.NoError(t, prov.CloseStore("watch"))
+
+	_, open = <-changes
+	require.False(t, open)
+}
@@ -0,0 +1,751 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package encrypted provides a storage.Provider decorator which encrypts records before they reach the underlying
+// provider.
+//
+// Values are encrypted along with their original key with an AEAD key held by a kms.KeyManager. Keys are replaced
+// by blind indexes computed with a MAC key: a key is split into segments at keySeparator and every segment is
+// replaced by the MAC of the key up to and including it. Prefix iteration therefore keeps working as long as the
+// prefix ends on a segment boundary, which is how the framework's stores build their keys (e.g. "conn_<id>").
+//
+// Tags are encrypted within the records and given to the underlying store as blind indexes too, so that stores of an
+// underlying storage.QueryableStore are queryable by exact tag values.
+//
+// The KMS used here must not store its keys through this provider, create it on top of the underlying provider.
+package encrypted
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/internal/watcher"
+)
+
+const keySeparator = "_"
+
+// Provider is a storage.Provider encrypting the data of an underlying storage.Provider
+type Provider struct {
+	provider storage.Provider
+	crypto   crypto.Crypto
+	encKH    interface{}
+	macKH    interface{}
+	stores   map[string]*encryptedStore
+	lock     sync.RWMutex
+}
+
+// NewProvider instantiates Provider. encKeyID must reference an AEAD key (e.g. kms.AES256GCMType) and macKeyID a
+// MAC key (kms.HMACSHA256Tag256Type) of the given key manager.
+func NewProvider(p storage.Provider, keyManager kms.KeyManager, c crypto.Crypto,
+	encKeyID, macKeyID string) (*Provider, error) {
+	if encKeyID == "" || macKeyID == "" {
+		return nil, errors.New("encryption and MAC key IDs are mandatory")
+	}
+
+	encKH, err := keyManager.Get(encKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	macKH, err := keyManager.Get(macKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC key: %w", err)
+	}
+
+	return &Provider{
+		provider: p,
+		crypto:   c,
+		encKH:    encKH,
+		macKH:    macKH,
+		stores:   make(map[string]*encryptedStore),
+	}, nil
+}
+
+// OpenStore opens and returns a store for given name space.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	k := strings.ToLower(name)
+
+	if store, ok := p.stores[k]; ok {
+		return store.extended(), nil
+	}
+
+	store, err := p.provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.stores[k] = &encryptedStore{
+		store:    store,
+		crypto:   p.crypto,
+		encKH:    p.encKH,
+		macKH:    p.macKH,
+		watchers: watcher.New(),
+	}
+
+	return p.stores[k].extended(), nil
+}
+
+// StoreExists tells whether the underlying provider has the store of given name space, if it supports it
+func (p *Provider) StoreExists(name string) (bool, error) {
+	return storage.StoreExists(p.provider, name)
+}
+
+// CloseStore closes store of given name space
+func (p *Provider) CloseStore(name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	k := strings.ToLower(name)
+
+	if store, ok := p.stores[k]; ok {
+		store.watchers.Close()
+		delete(p.stores, k)
+	}
+
+	return p.provider.CloseStore(name)
+}
+
+// Close closes all stores created under this store provider
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, store := range p.stores {
+		store.watchers.Close()
+	}
+
+	p.stores = make(map[string]*encryptedStore)
+
+	return p.provider.Close()
+}
+
+// record is the plaintext of an encrypted record, the original key is kept to be returned by iterators
+type record struct {
+	Key   string        `json:"key"`
+	Value []byte        `json:"value"`
+	Tags  []storage.Tag `json:"tags,omitempty"`
+}
+
+// encryptedRecord is what gets stored in the underlying store
+type encryptedRecord struct {
+	Ciphertext []byte `json:"ciphertext"`
+	Nonce      []byte `json:"nonce"`
+}
+
+type encryptedStore struct {
+	store    storage.Store
+	crypto   crypto.Crypto
+	encKH    interface{}
+	macKH    interface{}
+	watchers *watcher.Watchers
+}
+
+// extended returns the store along with the extensions supported by the underlying store
+func (s *encryptedStore) extended() storage.Store {
+	if qs, ok := s.store.(storage.QueryableStore); ok {
+		return &queryableStore{encryptedStore: s, queryable: qs}
+	}
+
+	return s
+}
+
+// Put stores the key and the record
+func (s *encryptedStore) Put(k string, v []byte) error {
+	blindKey, value, err := s.seal(k, v, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Put(blindKey, value)
+	if err != nil {
+		return err
+	}
+
+	s.watchers.Notify(storage.Change{Key: k, Value: v})
+
+	return nil
+}
+
+// PutWithTTL stores the key and the record, the record expires once ttl has elapsed if the underlying store
+// implements storage.ExpiringStore.
+func (s *encryptedStore) PutWithTTL(k string, v []byte, ttl time.Duration) error {
+	blindKey, value, err := s.seal(k, v, nil)
+	if err != nil {
+		return err
+	}
+
+	err = storage.PutWithTTL(s.store, blindKey, value, ttl)
+	if err != nil {
+		return err
+	}
+
+	s.watchers.Notify(storage.Change{Key: k, Value: v})
+
+	return nil
+}
+
+// Get fetches the record based on key
+func (s *encryptedStore) Get(k string) ([]byte, error) {
+	rec, err := s.getRecord(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return rec.Value, nil
+}
+
+// Expiry fetches the time the record of the given key expires at from the underlying store
+func (s *encryptedStore) Expiry(k string) (time.Time, error) {
+	if k == "" {
+		return time.Time{}, errors.New("key is mandatory")
+	}
+
+	blindKey, err := s.blindKey(k)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return storage.Expiry(s.store, blindKey)
+}
+
+func (s *encryptedStore) getRecord(k string) (*record, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	blindKey, err := s.blindKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := s.store.Get(blindKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decrypt(blindKey, value)
+}
+
+// seal returns the blind key and the encrypted value of the record
+func (s *encryptedStore) seal(k string, v []byte, tags []storage.Tag) (string, []byte, error) {
+	if k == "" || v == nil {
+		return "", nil, errors.New("key and value are mandatory")
+	}
+
+	blindKey, err := s.blindKey(k)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, err := s.encrypt(blindKey, &record{Key: k, Value: v, Tags: tags})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return blindKey, value, nil
+}
+
+// Iterator returns an iterator for the latest snapshot of the underlying store.
+// Since blind indexes don't preserve the order of keys, only prefix ranges (endKey being
+// startKey + storage.EndKeySuffix) where startKey ends on a key segment boundary are supported, the empty prefix
+// iterating over all the records in no particular order.
+func (s *encryptedStore) Iterator(startKey, endKey string) storage.StoreIterator {
+	if startKey == "" && endKey == "" {
+		return &encryptedIterator{}
+	}
+
+	if startKey == "" && endKey == storage.EndKeySuffix {
+		return &encryptedIterator{store: s, iterator: s.store.Iterator("", storage.EndKeySuffix)}
+	}
+
+	if endKey != startKey+storage.EndKeySuffix {
+		return &encryptedIterator{err: errors.New("only prefix iteration is supported by encrypted store")}
+	}
+
+	prefix := strings.TrimSuffix(startKey, keySeparator)
+
+	blindPrefix, err := s.blindKey(prefix)
+	if err != nil {
+		return &encryptedIterator{err: err}
+	}
+
+	blindPrefix += keySeparator
+
+	return &encryptedIterator{
+		store:    s,
+		iterator: s.store.Iterator(blindPrefix, blindPrefix+storage.EndKeySuffix),
+	}
+}
+
+// Delete will delete a record with k key
+func (s *encryptedStore) Delete(k string) error {
+	if k == "" {
+		return errors.New("key is mandatory")
+	}
+
+	blindKey, err := s.blindKey(k)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.exists(blindKey)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Delete(blindKey)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		s.watchers.Notify(storage.Change{Key: k, Deleted: true})
+	}
+
+	return nil
+}
+
+// exists tells if the underlying store has a record for the blind key
+func (s *encryptedStore) exists(blindKey string) (bool, error) {
+	_, err := s.store.Get(blindKey)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Watch returns a channel receiving the changes of the records whose key starts with prefix.
+// Only the writes made through this provider are notified.
+func (s *encryptedStore) Watch(prefix string) (<-chan storage.Change, func(), error) {
+	changes, stop := s.watchers.Add(prefix)
+
+	return changes, stop, nil
+}
+
+// NewBatch returns a batch of encrypted writes, applied with a batch of the underlying store
+func (s *encryptedStore) NewBatch() storage.Batch {
+	return &encryptedBatch{store: s, batch: storage.NewBatch(s.store)}
+}
+
+// blindKey replaces every segment of k by the hex encoded MAC of k up to and including that segment
+func (s *encryptedStore) blindKey(k string) (string, error) {
+	segments := strings.Split(k, keySeparator)
+	blindSegments := make([]string, len(segments))
+
+	for i := range segments {
+		mac, err := s.crypto.ComputeMAC([]byte(strings.Join(segments[:i+1], keySeparator)), s.macKH)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute blind index: %w", err)
+		}
+
+		blindSegments[i] = hex.EncodeToString(mac)
+	}
+
+	return strings.Join(blindSegments, keySeparator), nil
+}
+
+// encrypt encrypts rec, binding it to its blind key to prevent records from being swapped
+func (s *encryptedStore) encrypt(blindKey string, rec *record) ([]byte, error) {
+	recBytes, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	ct, nonce, err := s.crypto.Encrypt(recBytes, []byte(blindKey), s.encKH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt record: %w", err)
+	}
+
+	return json.Marshal(&encryptedRecord{Ciphertext: ct, Nonce: nonce})
+}
+
+func (s *encryptedStore) decrypt(blindKey string, value []byte) (*record, error) {
+	encRec := &encryptedRecord{}
+
+	err := json.Unmarshal(value, encRec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted record: %w", err)
+	}
+
+	recBytes, err := s.crypto.Decrypt(encRec.Ciphertext, encRec.Nonce, []byte(blindKey), s.encKH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt record: %w", err)
+	}
+
+	rec := &record{}
+
+	err = json.Unmarshal(recBytes, rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal record: %w", err)
+	}
+
+	return rec, nil
+}
+
+// queryableStore is the encrypted store of an underlying storage.QueryableStore, the tags are given to the
+// underlying store as blind indexes
+type queryableStore struct {
+	*encryptedStore
+	queryable storage.QueryableStore
+}
+
+// PutWithTags stores the key and the record along with the given tags
+func (s *queryableStore) PutWithTags(k string, v []byte, tags ...storage.Tag) error {
+	tags, err := uniqueTags(tags)
+	if err != nil {
+		return err
+	}
+
+	blindKey, value, err := s.seal(k, v, tags)
+	if err != nil {
+		return err
+	}
+
+	blindTags, err := s.blindTags(tags)
+	if err != nil {
+		return err
+	}
+
+	err = s.queryable.PutWithTags(blindKey, value, blindTags...)
+	if err != nil {
+		return err
+	}
+
+	s.watchers.Notify(storage.Change{Key: k, Value: v})
+
+	return nil
+}
+
+// GetTags fetches the tags associated with the record of the given key
+func (s *queryableStore) GetTags(k string) ([]storage.Tag, error) {
+	rec, err := s.getRecord(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return rec.Tags, nil
+}
+
+// Query returns an iterator over the records having a tag with the given name and, unless it is empty, the given
+// value. Records are ordered by their blind key.
+func (s *queryableStore) Query(name, value string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	blindTag, err := s.blindTag(storage.Tag{Name: name, Value: value})
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		blindTag.Value = ""
+	}
+
+	itr, err := s.queryable.Query(blindTag.Name, blindTag.Value, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedIterator{store: s.encryptedStore, iterator: itr}, nil
+}
+
+// blindTag replaces the tag name by its MAC, and the tag value by the MAC of the name and the value
+func (s *encryptedStore) blindTag(tag storage.Tag) (storage.Tag, error) {
+	name, err := s.crypto.ComputeMAC([]byte(tag.Name), s.macKH)
+	if err != nil {
+		return storage.Tag{}, fmt.Errorf("failed to compute blind tag: %w", err)
+	}
+
+	value, err := s.crypto.ComputeMAC([]byte(tag.Name+"\x00"+tag.Value), s.macKH)
+	if err != nil {
+		return storage.Tag{}, fmt.Errorf("failed to compute blind tag: %w", err)
+	}
+
+	return storage.Tag{Name: hex.EncodeToString(name), Value: hex.EncodeToString(value)}, nil
+}
+
+// blindTags returns the blind indexes of the tags
+func (s *encryptedStore) blindTags(tags []storage.Tag) ([]storage.Tag, error) {
+	blindTags := make([]storage.Tag, len(tags))
+
+	for i, tag := range tags {
+		blindTag, err := s.blindTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		blindTags[i] = blindTag
+	}
+
+	return blindTags, nil
+}
+
+// uniqueTags keeps the last value of every tag name, ordered by name
+func uniqueTags(tags []storage.Tag) ([]storage.Tag, error) {
+	values := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		if tag.Name == "" {
+			return nil, errors.New("tag name is mandatory")
+		}
+
+		values[tag.Name] = tag.Value
+	}
+
+	var unique []storage.Tag
+
+	for name, value := range values {
+		unique = append(unique, storage.Tag{Name: name, Value: value})
+	}
+
+	sort.Slice(unique, func(i, j int) bool { return unique[i].Name < unique[j].Name })
+
+	return unique, nil
+}
+
+// encryptedBatch encrypts the writes of a batch of the underlying store. A write failing to be encrypted fails
+// Commit before any write is applied.
+type encryptedBatch struct {
+	store   *encryptedStore
+	batch   storage.Batch
+	changes []batchChange
+	err     error
+}
+
+// batchChange is a change of the batch along with the blind key of the record
+type batchChange struct {
+	storage.Change
+	blindKey string
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *encryptedBatch) Put(k string, v []byte) {
+	b.put(k, v, nil, func(blindKey string, value []byte) error {
+		b.batch.Put(blindKey, value)
+
+		return nil
+	})
+}
+
+// fail keeps the first error of the batch, to be returned by Commit
+func (b *encryptedBatch) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// PutWithTTL adds the storing of an expiring record to the batch
+func (b *encryptedBatch) PutWithTTL(k string, v []byte, ttl time.Duration) {
+	b.put(k, v, nil, func(blindKey string, value []byte) error {
+		storage.BatchPutWithTTL(b.batch, blindKey, value, ttl)
+
+		return nil
+	})
+}
+
+// PutWithTags adds the storing of a tagged record to the batch, the tags being given to the underlying batch as
+// blind indexes if it supports tags
+func (b *encryptedBatch) PutWithTags(k string, v []byte, tags ...storage.Tag) {
+	tags, err := uniqueTags(tags)
+	if err != nil {
+		b.fail(err)
+
+		return
+	}
+
+	b.put(k, v, tags, func(blindKey string, value []byte) error {
+		blindTags, e := b.store.blindTags(tags)
+		if e != nil {
+			return e
+		}
+
+		storage.BatchPutWithTags(b.batch, blindKey, value, blindTags...)
+
+		return nil
+	})
+}
+
+func (b *encryptedBatch) put(k string, v []byte, tags []storage.Tag, putFn func(blindKey string, value []byte) error) {
+	if b.err != nil {
+		return
+	}
+
+	blindKey, value, err := b.store.seal(k, v, tags)
+	if err != nil {
+		b.fail(err)
+
+		return
+	}
+
+	if err = putFn(blindKey, value); err != nil {
+		b.fail(err)
+
+		return
+	}
+
+	b.changes = append(b.changes, batchChange{Change: storage.Change{Key: k, Value: v}, blindKey: blindKey})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *encryptedBatch) Delete(k string) {
+	if b.err != nil {
+		return
+	}
+
+	if k == "" {
+		b.err = errors.New("key is mandatory")
+
+		return
+	}
+
+	blindKey, err := b.store.blindKey(k)
+	if err != nil {
+		b.err = err
+
+		return
+	}
+
+	b.batch.Delete(blindKey)
+
+	b.changes = append(b.changes, batchChange{Change: storage.Change{Key: k, Deleted: true}, blindKey: blindKey})
+}
+
+// Commit applies the writes of the batch with the batch of the underlying store
+func (b *encryptedBatch) Commit() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	changes, err := b.notifiedChanges()
+	if err != nil {
+		return err
+	}
+
+	err = b.batch.Commit()
+	if err != nil {
+		return err
+	}
+
+	b.changes = nil
+
+	b.store.watchers.Notify(changes...)
+
+	return nil
+}
+
+// notifiedChanges returns the changes of the batch without the deletions of records which don't exist
+func (b *encryptedBatch) notifiedChanges() ([]storage.Change, error) {
+	var changes []storage.Change
+
+	// whether the record exists once the previous writes of the batch are applied
+	existing := make(map[string]bool)
+
+	for _, c := range b.changes {
+		if c.Deleted {
+			exists, ok := existing[c.blindKey]
+			if !ok {
+				var err error
+
+				exists, err = b.store.exists(c.blindKey)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if !exists {
+				continue
+			}
+		}
+
+		existing[c.blindKey] = !c.Deleted
+		changes = append(changes, c.Change)
+	}
+
+	return changes, nil
+}
+
+// encryptedIterator decrypts the records of the underlying iterator
+type encryptedIterator struct {
+	store    *encryptedStore
+	iterator storage.StoreIterator
+	current  *record
+	err      error
+}
+
+// Next moves the iterator to the next key/value pair.
+// It returns false if the iterator is exhausted or if a record fails to decrypt.
+func (i *encryptedIterator) Next() bool {
+	i.current = nil
+
+	if i.err != nil || i.iterator == nil || !i.iterator.Next() {
+		return false
+	}
+
+	rec, err := i.store.decrypt(string(i.iterator.Key()), i.iterator.Value())
+	if err != nil {
+		i.err = err
+
+		return false
+	}
+
+	i.current = rec
+
+	return true
+}
+
+// Release releases associated resources.
+func (i *encryptedIterator) Release() {
+	i.current = nil
+
+	if i.iterator != nil {
+		i.iterator.Release()
+	}
+}
+
+// Error returns any accumulated error.
+func (i *encryptedIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	if i.iterator != nil {
+		return i.iterator.Error()
+	}
+
+	return nil
+}
+
+// Key returns the original key of the current key/value pair, or nil if done.
+func (i *encryptedIterator) Key() []byte {
+	if i.current == nil {
+		return nil
+	}
+
+	return []byte(i.current.Key)
+}
+
+// Value returns the decrypted value of the current key/value pair, or nil if done.
+func (i *encryptedIterator) Value() []byte {
+	if i.current == nil {
+		return nil
+	}
+
+	return i.current.Value
+}
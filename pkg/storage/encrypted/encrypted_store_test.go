@@ -0,0 +1,465 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func newTestProvider(t *testing.T, underlying storage.Provider) *Provider {
+	t.Helper()
+
+	k, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	encKeyID, _, err := k.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	macKeyID, _, err := k.Create(kms.HMACSHA256Tag256Type)
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	p, err := NewProvider(underlying, k, c, encKeyID, macKeyID)
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestNewProvider(t *testing.T) {
+	k, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProvider(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	keyID, _, err := k.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	t.Run("missing key IDs", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), k, c, "", keyID)
+		require.EqualError(t, err, "encryption and MAC key IDs are mandatory")
+		require.Nil(t, p)
+	})
+
+	t.Run("unknown encryption key", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), k, c, "unknown", keyID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get encryption key")
+		require.Nil(t, p)
+	})
+
+	t.Run("unknown MAC key", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), k, c, keyID, "unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get MAC key")
+		require.Nil(t, p)
+	})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	t.Run("put and get", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		prov := newTestProvider(t, underlying)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		const key = "did:example:123"
+		data := []byte("value")
+
+		err = store.Put(key, data)
+		require.NoError(t, err)
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// update value
+		err = store.Put(key, []byte("value2"))
+		require.NoError(t, err)
+
+		doc, err = store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), doc)
+
+		_, err = store.Get("did:example:789")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// missing key or value
+		_, err = store.Get("")
+		require.EqualError(t, err, "key is mandatory")
+
+		err = store.Put(key, nil)
+		require.EqualError(t, err, "key and value are mandatory")
+
+		err = store.Put("", data)
+		require.EqualError(t, err, "key and value are mandatory")
+
+		// same store is returned for the same name
+		store2, err := prov.OpenStore("TEST")
+		require.NoError(t, err)
+		require.Equal(t, store, store2)
+
+		require.NoError(t, prov.CloseStore("test"))
+		require.Empty(t, prov.stores)
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("neither keys nor values reach the underlying store in plain text", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		prov := newTestProvider(t, underlying)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("conn_123", []byte("secret value"))
+		require.NoError(t, err)
+
+		raw, err := underlying.OpenStore("test")
+		require.NoError(t, err)
+
+		_, err = raw.Get("conn_123")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		itr := raw.Iterator("", "~")
+
+		count := 0
+
+		for itr.Next() {
+			count++
+
+			require.False(t, strings.Contains(string(itr.Key()), "conn"))
+			require.False(t, bytes.Contains(itr.Value(), []byte("secret value")))
+			require.False(t, bytes.Contains(itr.Value(), []byte("conn_123")))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("tampered records fail to decrypt", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		prov := newTestProvider(t, underlying)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key_1", []byte("value1")))
+		require.NoError(t, store.Put("key_2", []byte("value2")))
+
+		encStore := prov.stores["test"]
+
+		raw, err := underlying.OpenStore("test")
+		require.NoError(t, err)
+
+		blindKey1, err := encStore.blindKey("key_1")
+		require.NoError(t, err)
+
+		blindKey2, err := encStore.blindKey("key_2")
+		require.NoError(t, err)
+
+		// records are bound to their key
+		value2, err := raw.Get(blindKey2)
+		require.NoError(t, err)
+		require.NoError(t, raw.Put(blindKey1, value2))
+
+		_, err = store.Get("key_1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt record")
+
+		itr := store.Iterator("key_", "key_"+storage.EndKeySuffix)
+		for itr.Next() {
+			require.Equal(t, []byte("key_2"), itr.Key())
+		}
+
+		require.Error(t, itr.Error())
+
+		require.NoError(t, raw.Put(blindKey1, []byte("not json")))
+
+		_, err = store.Get("key_1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal encrypted record")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		prov := newTestProvider(t, mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value")))
+		require.EqualError(t, store.Delete(""), "key is mandatory")
+		require.NoError(t, store.Delete("key"))
+
+		_, err = store.Get("key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		prov := newTestProvider(t, &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")})
+
+		store, err := prov.OpenStore("test")
+		require.EqualError(t, err, "open error")
+		require.Nil(t, store)
+	})
+}
+
+func TestEncryptedStoreIterator(t *testing.T) {
+	prov := newTestProvider(t, mem.NewProvider())
+
+	store, err := prov.OpenStore("test-iterator")
+	require.NoError(t, err)
+
+	const valPrefix = "val-for-%s"
+	keys := []string{"abc_123", "abc_124", "abc_125", "abc_126", "jkl_123", "mno_123", "dab_123", "abc_123_1"}
+
+	for _, key := range keys {
+		err = store.Put(key, []byte(fmt.Sprintf(valPrefix, key)))
+		require.NoError(t, err)
+	}
+
+	itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+	verifyItr(t, itr, 5, "abc_")
+
+	// prefix without trailing separator
+	itr = store.Iterator("abc", "abc"+storage.EndKeySuffix)
+	verifyItr(t, itr, 5, "abc_")
+
+	itr = store.Iterator("abc_123", "abc_123"+storage.EndKeySuffix)
+	verifyItr(t, itr, 1, "abc_123_")
+
+	itr = store.Iterator("xyz_", "xyz_"+storage.EndKeySuffix)
+	verifyItr(t, itr, 0, "")
+
+	itr = store.Iterator("", "")
+	verifyItr(t, itr, 0, "")
+
+	// all the records
+	itr = store.Iterator("", storage.EndKeySuffix)
+	verifyItr(t, itr, len(keys), "")
+
+	// arbitrary ranges can't be supported over blind indexes
+	itr = store.Iterator("abc_", "mno_"+storage.EndKeySuffix)
+	require.False(t, itr.Next())
+	require.EqualError(t, itr.Error(), "only prefix iteration is supported by encrypted store")
+	itr.Release()
+}
+
+func TestEncryptedStoreExtensions(t *testing.T) {
+	t.Run("tags", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		prov := newTestProvider(t, underlying)
+
+		s, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		store, ok := s.(storage.QueryableStore)
+		require.True(t, ok)
+
+		require.NoError(t, store.PutWithTags("conn_1", []byte("value1"),
+			storage.Tag{Name: "state", Value: "completed"}, storage.Tag{Name: "role", Value: "invitee"}))
+		require.NoError(t, store.PutWithTags("conn_2", []byte("value2"), storage.Tag{Name: "state", Value: "requested"}))
+		require.NoError(t, store.Put("conn_3", []byte("value3")))
+
+		tags, err := store.GetTags("conn_1")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "role", Value: "invitee"}, {Name: "state", Value: "completed"}}, tags)
+
+		tags, err = store.GetTags("conn_3")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		_, err = store.GetTags("conn_4")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		itr, err := store.Query("state", "completed")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, "conn_1", string(itr.Key()))
+		require.Equal(t, "value1", string(itr.Value()))
+		require.False(t, itr.Next())
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		itr, err = store.Query("state", "")
+		require.NoError(t, err)
+
+		count := 0
+		for itr.Next() {
+			count++
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, 2, count)
+
+		_, err = store.Query("", "completed")
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = store.PutWithTags("conn_1", []byte("value1"), storage.Tag{Value: "completed"})
+		require.EqualError(t, err, "tag name is mandatory")
+
+		// tags don't reach the underlying store in plain text
+		raw, err := underlying.OpenStore("test")
+		require.NoError(t, err)
+
+		rawItr, err := raw.(storage.QueryableStore).Query("state", "")
+		require.NoError(t, err)
+		require.False(t, rawItr.Next())
+	})
+
+	t.Run("tags are not supported without a queryable underlying store", func(t *testing.T) {
+		prov := newTestProvider(t, mockstorage.NewMockStoreProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		_, ok := store.(storage.QueryableStore)
+		require.False(t, ok)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		prov := newTestProvider(t, mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.PutWithTTL(store, "key_1", []byte("value"), time.Millisecond))
+		require.NoError(t, storage.PutWithTTL(store, "key_2", []byte("value"), time.Hour))
+
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = store.Get("key_1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		v, err := store.Get("key_2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+
+		require.EqualError(t, storage.PutWithTTL(store, "", []byte("value"), time.Hour), "key and value are mandatory")
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		prov := newTestProvider(t, mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key_1", []byte("value1")))
+
+		batch := storage.NewBatch(store)
+		batch.Put("key_2", []byte("value2"))
+		storage.BatchPutWithTTL(batch, "key_3", []byte("value3"), time.Hour)
+		batch.Delete("key_1")
+		require.NoError(t, batch.Commit())
+
+		_, err = store.Get("key_1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		v, err := store.Get("key_3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value3"), v)
+
+		// a write failing to be encrypted fails the whole batch
+		batch = storage.NewBatch(store)
+		batch.Put("key_4", []byte("value4"))
+		batch.Put("", []byte("value"))
+		batch.Delete("key_2")
+		require.EqualError(t, batch.Commit(), "key and value are mandatory")
+
+		_, err = store.Get("key_4")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		batch = storage.NewBatch(store)
+		batch.Delete("")
+		require.EqualError(t, batch.Commit(), "key is mandatory")
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		prov := newTestProvider(t, mem.NewProvider())
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		changes, stop, err := storage.Watch(store, "conn_")
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, store.Put("other_1", []byte("value")))
+		require.NoError(t, store.Put("conn_1", []byte("value1")))
+		require.NoError(t, store.Delete("conn_2"))
+		require.NoError(t, store.Delete("conn_1"))
+
+		batch := storage.NewBatch(store)
+		batch.Put("conn_2", []byte("value2"))
+		batch.Delete("conn_2")
+		batch.Delete("conn_2")
+		require.NoError(t, batch.Commit())
+
+		for _, expected := range []storage.Change{
+			{Key: "conn_1", Value: []byte("value1")},
+			{Key: "conn_1", Deleted: true},
+			{Key: "conn_2", Value: []byte("value2")},
+			{Key: "conn_2", Deleted: true},
+		} {
+			select {
+			case c := <-changes:
+				require.Equal(t, expected, c)
+			case <-time.After(time.Second):
+				require.Fail(t, "change not received")
+			}
+		}
+
+		require.NoError(t, prov.CloseStore("test"))
+
+		_, ok := <-changes
+		require.False(t, ok)
+	})
+}
+
+func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
+	t.Helper()
+
+	var vals []string
+
+	for itr.Next() {
+		if prefix != "" {
+			require.True(t, strings.HasPrefix(string(itr.Key()), prefix))
+		}
+
+		require.Equal(t, fmt.Sprintf("val-for-%s", itr.Key()), string(itr.Value()))
+
+		vals = append(vals, string(itr.Value()))
+	}
+	require.Len(t, vals, count)
+	require.NoError(t, itr.Error())
+
+	itr.Release()
+	require.False(t, itr.Next())
+	require.Empty(t, itr.Key())
+	require.Empty(t, itr.Value())
+}
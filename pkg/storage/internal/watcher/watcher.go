@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package watcher delivers the changes of a store to its watchers for storage.WatchableStore implementations.
+package watcher
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Watchers are the watchers of a store. Notify never blocks: the changes are queued for every watcher and
+// delivered in order by a goroutine of the watcher.
+type Watchers struct {
+	watchers map[*watcher]struct{}
+	lock     sync.Mutex
+}
+
+// New returns an empty set of watchers
+func New() *Watchers {
+	return &Watchers{watchers: make(map[*watcher]struct{})}
+}
+
+// Add adds a watcher of the records whose key starts with prefix, it returns the channel receiving the changes
+// and a function removing the watcher.
+func (w *Watchers) Add(prefix string) (<-chan storage.Change, func()) {
+	wt := &watcher{prefix: prefix, out: make(chan storage.Change), done: make(chan struct{})}
+	wt.cond = sync.NewCond(&wt.lock)
+
+	w.lock.Lock()
+	w.watchers[wt] = struct{}{}
+	w.lock.Unlock()
+
+	go wt.run()
+
+	return wt.out, func() {
+		w.lock.Lock()
+		delete(w.watchers, wt)
+		w.lock.Unlock()
+
+		wt.stop()
+	}
+}
+
+// Notify queues the changes for the watchers of their keys
+func (w *Watchers) Notify(changes ...storage.Change) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for wt := range w.watchers {
+		wt.push(changes)
+	}
+}
+
+// Close removes all the watchers, closing their channels
+func (w *Watchers) Close() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for wt := range w.watchers {
+		wt.stop()
+	}
+
+	w.watchers = make(map[*watcher]struct{})
+}
+
+type watcher struct {
+	prefix  string
+	out     chan storage.Change
+	done    chan struct{}
+	queue   []storage.Change
+	stopped bool
+	lock    sync.Mutex
+	cond    *sync.Cond
+}
+
+func (w *watcher) push(changes []storage.Change) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, c := range changes {
+		if strings.HasPrefix(c.Key, w.prefix) {
+			w.queue = append(w.queue, c)
+		}
+	}
+
+	w.cond.Signal()
+}
+
+func (w *watcher) stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stopped {
+		return
+	}
+
+	w.stopped = true
+	w.queue = nil
+
+	close(w.done)
+	w.cond.Signal()
+}
+
+// run delivers the queued changes until the watcher is stopped
+func (w *watcher) run() {
+	defer close(w.out)
+
+	for {
+		w.lock.Lock()
+
+		for len(w.queue) == 0 && !w.stopped {
+			w.cond.Wait()
+		}
+
+		if w.stopped {
+			w.lock.Unlock()
+
+			return
+		}
+
+		c := w.queue[0]
+		w.queue = w.queue[1:]
+
+		w.lock.Unlock()
+
+		select {
+		case w.out <- c:
+		case <-w.done:
+			return
+		}
+	}
+}
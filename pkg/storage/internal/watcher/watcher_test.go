@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watcher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestWatchers(t *testing.T) {
+	t.Run("changes are delivered in order without blocking the writer", func(t *testing.T) {
+		w := New()
+
+		changes, stop := w.Add("key_")
+		defer stop()
+
+		for i := 0; i < 100; i++ {
+			w.Notify(storage.Change{Key: fmt.Sprintf("key_%d", i), Value: []byte("value")},
+				storage.Change{Key: "other", Deleted: true})
+		}
+
+		for i := 0; i < 100; i++ {
+			c := <-changes
+			require.Equal(t, fmt.Sprintf("key_%d", i), c.Key)
+		}
+	})
+
+	t.Run("stop closes the channel and drops the pending changes", func(t *testing.T) {
+		w := New()
+
+		changes, stop := w.Add("")
+
+		w.Notify(storage.Change{Key: "key_1"}, storage.Change{Key: "key_2"})
+
+		stop()
+		// stopping twice is harmless
+		stop()
+
+		for range changes {
+			// the change being delivered when stopping may still be received
+		}
+
+		require.Empty(t, w.watchers)
+	})
+
+	t.Run("close closes all the channels", func(t *testing.T) {
+		w := New()
+
+		changes1, _ := w.Add("")
+		changes2, stop2 := w.Add("key_")
+
+		w.Close()
+
+		_, open := <-changes1
+		require.False(t, open)
+
+		_, open = <-changes2
+		require.False(t, open)
+
+		stop2()
+		require.Empty(t, w.watchers)
+	})
+}
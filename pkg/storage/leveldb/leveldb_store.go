@@ -9,30 +9,80 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/util"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/internal/watcher"
 )
 
-const pathPattern = "%s-%s"
+var logger = log.New("aries-framework/storage/leveldb")
+
+// errNULKey is returned when storing a key with a NUL character, as the keys of the indexes start with one
+var errNULKey = errors.New("key can't contain a NUL character")
+
+const (
+	pathPattern = "%s-%s"
+
+	// tag index entries are kept in the store's db under reserved prefixes sorting before any printable key:
+	// tagIndexPrefix + name + sep + value + sep + key -> empty, for lookups by tag
+	// tagListPrefix + key -> JSON of the key's tags, to clean up the index on update and delete
+	tagSeparator   = "\x00"
+	tagIndexPrefix = tagSeparator + "tag" + tagSeparator
+	tagListPrefix  = tagSeparator + "tags" + tagSeparator
+
+	// expiring records are tracked the same way, with the expiration time encoded as big-endian Unix nanoseconds:
+	// ttlPrefix + key -> expiration time, to check and clean up the key's expiration
+	// ttlIndexPrefix + expiration time + key -> empty, to find the expired records in order
+	ttlPrefix      = tagSeparator + "ttl" + tagSeparator
+	ttlIndexPrefix = tagSeparator + "ttlidx" + tagSeparator
+
+	// defaultSweepInterval is how often expired records are removed unless WithSweepInterval is used
+	defaultSweepInterval = time.Minute
+)
 
 // Provider leveldb implementation of storage.Provider interface
 type Provider struct {
-	dbPath string
-	dbs    map[string]*leveldbStore
-	lock   sync.RWMutex
+	dbPath        string
+	dbs           map[string]*leveldbStore
+	sweepInterval time.Duration
+	// stopSweep stops the goroutine removing expired records, nil when it isn't running
+	stopSweep chan struct{}
+	lock      sync.RWMutex
+}
+
+// Option configures the leveldb provider
+type Option func(opts *Provider)
+
+// WithSweepInterval option sets how often expired records are removed from the stores
+func WithSweepInterval(interval time.Duration) Option {
+	return func(opts *Provider) {
+		opts.sweepInterval = interval
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider(dbPath string) *Provider {
-	return &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath}
+func NewProvider(dbPath string, opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath, sweepInterval: defaultSweepInterval}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -45,6 +95,20 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	return store, nil
 }
 
+// StoreExists tells whether the store of given name space exists in the db path
+func (p *Provider) StoreExists(name string) (bool, error) {
+	if p.getLeveldbStore(name) != nil {
+		return true, nil
+	}
+
+	_, err := os.Stat(fmt.Sprintf(pathPattern, p.dbPath, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
 // getLeveldbStore finds level db store with given name
 // returns nil if not found
 func (p *Provider) getLeveldbStore(name string) *leveldbStore {
@@ -65,9 +129,14 @@ func (p *Provider) newLeveldbStore(name string) (*leveldbStore, error) {
 		return nil, err
 	}
 
-	store := &leveldbStore{db}
+	store := &leveldbStore{db: db, startSweeper: p.startSweeper, watchers: watcher.New()}
 	p.dbs[strings.ToLower(name)] = store
 
+	// records put with a TTL before a restart still need to be removed
+	if store.hasExpiringRecords() {
+		p.startSweeperLocked()
+	}
+
 	return store, nil
 }
 
@@ -79,6 +148,8 @@ func (p *Provider) Close() error {
 	var errs []error
 
 	for _, v := range p.dbs {
+		v.watchers.Close()
+
 		e := v.db.Close()
 		if e != nil && e != leveldb.ErrClosed {
 			errs = append(errs, e)
@@ -91,6 +162,11 @@ func (p *Provider) Close() error {
 
 	p.dbs = make(map[string]*leveldbStore)
 
+	if p.stopSweep != nil {
+		close(p.stopSweep)
+		p.stopSweep = nil
+	}
+
 	return nil
 }
 
@@ -104,23 +180,306 @@ func (p *Provider) CloseStore(name string) error {
 	store, ok := p.dbs[k]
 	if ok {
 		delete(p.dbs, k)
+		store.watchers.Close()
+
 		return store.db.Close()
 	}
 
 	return nil
 }
 
+// startSweeper starts removing expired records in the background, unless it is already done
+func (p *Provider) startSweeper() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.startSweeperLocked()
+}
+
+func (p *Provider) startSweeperLocked() {
+	if p.stopSweep != nil {
+		return
+	}
+
+	p.stopSweep = make(chan struct{})
+
+	go p.sweep(p.sweepInterval, p.stopSweep)
+}
+
+func (p *Provider) sweep(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.lock.RLock()
+
+			for name, store := range p.dbs {
+				if err := store.removeExpired(now); err != nil {
+					logger.Warnf("failed to remove expired records from store %s: %s", name, err)
+				}
+			}
+
+			p.lock.RUnlock()
+		}
+	}
+}
+
 type leveldbStore struct {
-	db *leveldb.DB
+	db           *leveldb.DB
+	startSweeper func()
+	watchers     *watcher.Watchers
+	// writeLock is held from reading the tags and the expiration of the written keys until the batch is written,
+	// it also keeps the changes notified to the watchers in the order of the writes
+	writeLock sync.Mutex
+}
+
+// write writes the batch to the db and notifies the watchers of its changes, the caller must hold writeLock
+func (s *leveldbStore) write(batch *leveldb.Batch, changes ...storage.Change) error {
+	if err := s.db.Write(batch, nil); err != nil {
+		return err
+	}
+
+	s.watchers.Notify(changes...)
+
+	return nil
+}
+
+// Watch returns a channel receiving the changes of the records whose key starts with prefix
+func (s *leveldbStore) Watch(prefix string) (<-chan storage.Change, func(), error) {
+	changes, stop := s.watchers.Add(prefix)
+
+	return changes, stop, nil
 }
 
 // Put stores the key and the record
 func (s *leveldbStore) Put(k string, v []byte) error {
+	return s.PutWithTags(k, v)
+}
+
+// PutWithTTL stores the key and the record, the record expires once ttl has elapsed
+func (s *leveldbStore) PutWithTTL(k string, v []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("ttl must be positive")
+	}
+
+	if err := s.put(k, v, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	s.startSweeper()
+
+	return nil
+}
+
+// PutWithTags stores the key and the record along with the given tags.
+// The record and its tag index entries are written atomically.
+func (s *leveldbStore) PutWithTags(k string, v []byte, tags ...storage.Tag) error {
+	return s.put(k, v, time.Time{}, tags...)
+}
+
+// put stores the record, it expires at expiresAt unless it is the zero time
+func (s *leveldbStore) put(k string, v []byte, expiresAt time.Time, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
-	return s.db.Put([]byte(k), v, nil)
+	if strings.Contains(k, tagSeparator) {
+		return errNULKey
+	}
+
+	tags, err := uniqueTags(tags)
+	if err != nil {
+		return err
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	batch := new(leveldb.Batch)
+
+	if err = s.deleteTags(batch, k); err != nil {
+		return err
+	}
+
+	if err = s.deleteExpiry(batch, k); err != nil {
+		return err
+	}
+
+	batch.Put([]byte(k), v)
+
+	if !expiresAt.IsZero() {
+		setExpiry(batch, k, expiresAt)
+	}
+
+	if err = putTags(batch, k, tags); err != nil {
+		return err
+	}
+
+	return s.write(batch, storage.Change{Key: k, Value: v})
+}
+
+// putTags adds the tag list and the tag index entries of the key to the batch
+func putTags(batch *leveldb.Batch, k string, tags []storage.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tagsBytes, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	batch.Put([]byte(tagListPrefix+k), tagsBytes)
+
+	for _, tag := range tags {
+		batch.Put([]byte(tagIndexKey(tag.Name, tag.Value, k)), []byte{})
+	}
+
+	return nil
+}
+
+// GetTags fetches the tags associated with the record of the given key
+func (s *leveldbStore) GetTags(k string) ([]storage.Tag, error) {
+	if _, err := s.Get(k); err != nil {
+		return nil, err
+	}
+
+	return s.getTags(k)
+}
+
+// Query returns an iterator over the records having a tag with the given name and value,
+// ordered by tag value and key.
+func (s *leveldbStore) Query(name, value string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	options, err := storage.NewQueryOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := tagIndexPrefix + name + tagSeparator
+	if value != "" {
+		prefix += value + tagSeparator
+	}
+
+	itr := s.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer itr.Release()
+
+	result := &queryIterator{}
+
+	for skipped := 0; itr.Next(); {
+		if options.PageSize > 0 && len(result.items) == options.PageSize {
+			break
+		}
+
+		indexKey := string(itr.Key())
+		k := indexKey[strings.LastIndex(indexKey, tagSeparator)+1:]
+
+		v, err := s.Get(k)
+		if errors.Is(err, storage.ErrDataNotFound) {
+			// expired, but not removed yet
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tagged record: %w", err)
+		}
+
+		if skipped < options.Offset {
+			skipped++
+
+			continue
+		}
+
+		result.items = append(result.items, [2][]byte{[]byte(k), v})
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to query tag index: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *leveldbStore) getTags(k string) ([]storage.Tag, error) {
+	tagsBytes, err := s.db.Get([]byte(tagListPrefix+k), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	var tags []storage.Tag
+
+	if err := json.Unmarshal(tagsBytes, &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// deleteTags adds the removal of the key's tag index entries to the batch
+func (s *leveldbStore) deleteTags(batch *leveldb.Batch, k string) error {
+	tags, err := s.getTags(k)
+	if err != nil {
+		return err
+	}
+
+	removeTags(batch, k, tags)
+
+	return nil
+}
+
+// removeTags adds the removal of the given tags of the key to the batch
+func removeTags(batch *leveldb.Batch, k string, tags []storage.Tag) {
+	if len(tags) == 0 {
+		return
+	}
+
+	batch.Delete([]byte(tagListPrefix + k))
+
+	for _, tag := range tags {
+		batch.Delete([]byte(tagIndexKey(tag.Name, tag.Value, k)))
+	}
+}
+
+func tagIndexKey(name, value, k string) string {
+	return tagIndexPrefix + name + tagSeparator + value + tagSeparator + k
+}
+
+// uniqueTags validates the tags and keeps the last value of every tag name
+func uniqueTags(tags []storage.Tag) ([]storage.Tag, error) {
+	values := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		if tag.Name == "" {
+			return nil, errors.New("tag name is mandatory")
+		}
+
+		if strings.Contains(tag.Name, tagSeparator) || strings.Contains(tag.Value, tagSeparator) {
+			return nil, errors.New("tag name and value can't contain a NUL character")
+		}
+
+		values[tag.Name] = tag.Value
+	}
+
+	result := make([]storage.Tag, 0, len(values))
+
+	for name, value := range values {
+		result = append(result, storage.Tag{Name: name, Value: value})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
 }
 
 // Get fetches the record based on key
@@ -138,17 +497,37 @@ func (s *leveldbStore) Get(k string) ([]byte, error) {
 		return nil, err
 	}
 
+	expired, err := s.isExpired(k, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if expired {
+		return nil, storage.ErrDataNotFound
+	}
+
 	return data, nil
 }
 
 // Iterator returns iterator for the latest snapshot of the underlying db.
+// Records which have expired but aren't removed yet are skipped, as well as the entries of the tag and expiry indexes.
 func (s *leveldbStore) Iterator(start, limit string) storage.StoreIterator {
 	if start == "" || limit == "" {
 		iterator.NewEmptyIterator(errors.New("start or limit key is mandatory"))
 	}
 
-	return s.db.NewIterator(&util.Range{Start: []byte(start),
-		Limit: []byte(strings.ReplaceAll(limit, storage.EndKeySuffix, "~"))}, nil)
+	r := &util.Range{Start: []byte(start), Limit: []byte(strings.ReplaceAll(limit, storage.EndKeySuffix, "~"))}
+
+	// a nil limit reaches the end of the db, including the keys sorting after "~"
+	if start == "" && limit == storage.EndKeySuffix {
+		r.Limit = nil
+	}
+
+	return &expiryIterator{
+		Iterator: s.db.NewIterator(r, nil),
+		store:    s,
+		now:      time.Now(),
+	}
 }
 
 // Delete will delete record with k key
@@ -157,5 +536,462 @@ func (s *leveldbStore) Delete(k string) error {
 		return errors.New("key is mandatory")
 	}
 
-	return s.db.Delete([]byte(k), nil)
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	exists, err := s.db.Has([]byte(k), nil)
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+
+	if err = s.deleteTags(batch, k); err != nil {
+		return err
+	}
+
+	if err = s.deleteExpiry(batch, k); err != nil {
+		return err
+	}
+
+	batch.Delete([]byte(k))
+
+	if !exists {
+		return s.write(batch)
+	}
+
+	return s.write(batch, storage.Change{Key: k, Deleted: true})
+}
+
+// Expiry fetches the time the record of the given key expires at, the zero time if it doesn't expire
+func (s *leveldbStore) Expiry(k string) (time.Time, error) {
+	if _, err := s.Get(k); err != nil {
+		return time.Time{}, err
+	}
+
+	expiry, err := s.db.Get([]byte(ttlPrefix+k), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get expiry: %w", err)
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(expiry))), nil
+}
+
+func encodeExpiry(expiresAt time.Time) []byte {
+	b := make([]byte, 8) // nolint:gomnd
+
+	binary.BigEndian.PutUint64(b, uint64(expiresAt.UnixNano()))
+
+	return b
+}
+
+func ttlIndexKey(expiry []byte, k string) []byte {
+	return append(append([]byte(ttlIndexPrefix), expiry...), k...)
+}
+
+// setExpiry adds the expiration of the key to the batch
+func setExpiry(batch *leveldb.Batch, k string, expiresAt time.Time) {
+	expiry := encodeExpiry(expiresAt)
+
+	batch.Put([]byte(ttlPrefix+k), expiry)
+	batch.Put(ttlIndexKey(expiry, k), []byte{})
+}
+
+// deleteExpiry adds the removal of the key's expiration to the batch
+func (s *leveldbStore) deleteExpiry(batch *leveldb.Batch, k string) error {
+	expiry, err := s.db.Get([]byte(ttlPrefix+k), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get expiry: %w", err)
+	}
+
+	batch.Delete([]byte(ttlPrefix + k))
+	batch.Delete(ttlIndexKey(expiry, k))
+
+	return nil
+}
+
+// isExpired tells whether the record of the key has expired at the given time
+func (s *leveldbStore) isExpired(k string, now time.Time) (bool, error) {
+	expiry, err := s.db.Get([]byte(ttlPrefix+k), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get expiry: %w", err)
+	}
+
+	return string(expiry) <= string(encodeExpiry(now)), nil
+}
+
+func (s *leveldbStore) hasExpiringRecords() bool {
+	itr := s.db.NewIterator(util.BytesPrefix([]byte(ttlIndexPrefix)), nil)
+	defer itr.Release()
+
+	return itr.Next()
+}
+
+// removeExpired deletes the records which have expired at the given time
+func (s *leveldbStore) removeExpired(now time.Time) error {
+	// the expirations are checked and the records deleted without any write in between
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	// the index is ordered by expiration time, stop at the first record which hasn't expired
+	itr := s.db.NewIterator(&util.Range{
+		Start: []byte(ttlIndexPrefix),
+		Limit: ttlIndexKey(encodeExpiry(now.Add(time.Nanosecond)), ""),
+	}, nil)
+	defer itr.Release()
+
+	batch := new(leveldb.Batch)
+
+	var changes []storage.Change
+
+	for itr.Next() {
+		expiry := itr.Key()[len(ttlIndexPrefix) : len(ttlIndexPrefix)+len(encodeExpiry(now))]
+		k := string(itr.Key()[len(ttlIndexPrefix)+len(expiry):])
+
+		batch.Delete(append([]byte{}, itr.Key()...))
+
+		// the key may have been written again since the index entry was read
+		current, err := s.db.Get([]byte(ttlPrefix+k), nil)
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return fmt.Errorf("failed to get expiry: %w", err)
+		}
+
+		if string(current) != string(expiry) {
+			continue
+		}
+
+		if err = s.deleteTags(batch, k); err != nil {
+			return err
+		}
+
+		batch.Delete([]byte(k))
+		batch.Delete([]byte(ttlPrefix + k))
+
+		changes = append(changes, storage.Change{Key: k, Deleted: true})
+	}
+
+	if err := itr.Error(); err != nil {
+		return fmt.Errorf("failed to read expiry index: %w", err)
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	return s.write(batch, changes...)
+}
+
+// expiryIterator skips the records which have expired
+type expiryIterator struct {
+	iterator.Iterator
+	store *leveldbStore
+	now   time.Time
+	err   error
+}
+
+// Next moves the iterator to the next record which hasn't expired
+func (i *expiryIterator) Next() bool {
+	for i.Iterator.Next() {
+		if isIndexKey(i.Key()) {
+			continue
+		}
+
+		expired, err := i.store.isExpired(string(i.Key()), i.now)
+		if err != nil {
+			i.err = err
+
+			return false
+		}
+
+		if !expired {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIndexKey tells if the db key k is an entry of the indexes kept under the reserved prefixes, not a record
+func isIndexKey(k []byte) bool {
+	for _, prefix := range []string{tagIndexPrefix, tagListPrefix, ttlPrefix, ttlIndexPrefix} {
+		if bytes.HasPrefix(k, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Error returns any accumulated error
+func (i *expiryIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.Iterator.Error()
+}
+
+// NewBatch returns a batch whose writes are applied atomically with a single leveldb.Batch
+func (s *leveldbStore) NewBatch() storage.Batch {
+	return &leveldbBatch{store: s}
+}
+
+// leveldbBatchOp is a write recorded in a leveldbBatch
+type leveldbBatchOp struct {
+	key    string
+	value  []byte
+	delete bool
+	// ttl is set for expiring records
+	ttl  *time.Duration
+	tags []storage.Tag
+}
+
+type leveldbBatch struct {
+	store *leveldbStore
+	ops   []leveldbBatchOp
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *leveldbBatch) Put(k string, v []byte) {
+	b.ops = append(b.ops, leveldbBatchOp{key: k, value: v})
+}
+
+// PutWithTTL adds the storing of a record expiring once ttl has elapsed to the batch
+func (b *leveldbBatch) PutWithTTL(k string, v []byte, ttl time.Duration) {
+	b.ops = append(b.ops, leveldbBatchOp{key: k, value: v, ttl: &ttl})
+}
+
+// PutWithTags adds the storing of the key and the record along with the given tags to the batch
+func (b *leveldbBatch) PutWithTags(k string, v []byte, tags ...storage.Tag) {
+	b.ops = append(b.ops, leveldbBatchOp{key: k, value: v, tags: tags})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *leveldbBatch) Delete(k string) {
+	b.ops = append(b.ops, leveldbBatchOp{key: k, delete: true})
+}
+
+// Commit writes the batch to the db, along with the removal of the tags of the written keys
+func (b *leveldbBatch) Commit() error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+
+	expiring, err := b.write()
+	if err != nil {
+		return err
+	}
+
+	b.ops = nil
+
+	// the sweeper is started once writeLock is released, as it takes writeLock while holding the provider's lock
+	if expiring {
+		b.store.startSweeper()
+	}
+
+	return nil
+}
+
+// write writes the operations of the batch to the db under writeLock and tells whether any record expires
+func (b *leveldbBatch) write() (bool, error) {
+	b.store.writeLock.Lock()
+	defer b.store.writeLock.Unlock()
+
+	batch := new(leveldb.Batch)
+	// expiries and tagged hold the expirations and the tags set by this batch, which aren't in the db yet
+	expiries := make(map[string][]byte)
+	tagged := make(map[string][]storage.Tag)
+	expiring := false
+	changes := make([]storage.Change, 0, len(b.ops))
+
+	for _, op := range b.ops {
+		if err := b.store.deleteTags(batch, op.key); err != nil {
+			return false, err
+		}
+
+		removeTags(batch, op.key, tagged[op.key])
+		delete(tagged, op.key)
+
+		if err := b.resetExpiry(batch, op.key, expiries); err != nil {
+			return false, err
+		}
+
+		if op.delete {
+			var err error
+
+			if changes, err = b.appendDeleteChange(changes, op.key); err != nil {
+				return false, err
+			}
+
+			batch.Delete([]byte(op.key))
+
+			continue
+		}
+
+		if err := addPut(batch, op, expiries, tagged); err != nil {
+			return false, err
+		}
+
+		changes = append(changes, storage.Change{Key: op.key, Value: op.value})
+		expiring = expiring || op.ttl != nil
+	}
+
+	if err := b.store.write(batch, changes...); err != nil {
+		return false, fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	return expiring, nil
+}
+
+// addPut adds the storing of the record of op to the batch, along with its tags and its expiration which are also
+// kept in tagged and expiries
+func addPut(batch *leveldb.Batch, op leveldbBatchOp, expiries map[string][]byte, tagged map[string][]storage.Tag) error {
+	batch.Put([]byte(op.key), op.value)
+
+	if err := putTags(batch, op.key, op.tags); err != nil {
+		return err
+	}
+
+	tagged[op.key] = op.tags
+
+	if op.ttl != nil {
+		expiresAt := time.Now().Add(*op.ttl)
+		setExpiry(batch, op.key, expiresAt)
+		expiries[op.key] = encodeExpiry(expiresAt)
+	}
+
+	return nil
+}
+
+// resetExpiry removes the expiration of k, which may have been set earlier in the batch
+func (b *leveldbBatch) resetExpiry(batch *leveldb.Batch, k string, expiries map[string][]byte) error {
+	if expiry, ok := expiries[k]; ok {
+		batch.Delete([]byte(ttlPrefix + k))
+		batch.Delete(ttlIndexKey(expiry, k))
+		delete(expiries, k)
+
+		return nil
+	}
+
+	return b.store.deleteExpiry(batch, k)
+}
+
+// appendDeleteChange appends the change of deleting k to the changes of the batch so far, unless k neither exists
+// in the db nor in these changes
+func (b *leveldbBatch) appendDeleteChange(changes []storage.Change, k string) ([]storage.Change, error) {
+	deleted := storage.Change{Key: k, Deleted: true}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].Key == k {
+			if changes[i].Deleted {
+				return changes, nil
+			}
+
+			return append(changes, deleted), nil
+		}
+	}
+
+	exists, err := b.store.db.Has([]byte(k), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return changes, nil
+	}
+
+	return append(changes, deleted), nil
+}
+
+func (b *leveldbBatch) validate() error {
+	for i, op := range b.ops {
+		tags, err := uniqueTags(op.tags)
+		if err != nil {
+			return err
+		}
+
+		b.ops[i].tags = tags
+
+		if op.key == "" {
+			return errors.New("key is mandatory")
+		}
+
+		if !op.delete && op.value == nil {
+			return errors.New("key and value are mandatory")
+		}
+
+		if !op.delete && strings.Contains(op.key, tagSeparator) {
+			return errNULKey
+		}
+
+		if op.ttl != nil && *op.ttl <= 0 {
+			return errors.New("ttl must be positive")
+		}
+	}
+
+	return nil
+}
+
+// queryIterator iterates over the records found by a query
+type queryIterator struct {
+	currentIndex int
+	currentItem  *[2][]byte
+	items        [][2][]byte
+}
+
+// Next moves pointer to next value of iterator.
+// It returns false if the iterator is exhausted.
+func (i *queryIterator) Next() bool {
+	if i.currentIndex >= len(i.items) {
+		i.currentItem = nil
+
+		return false
+	}
+
+	i.currentItem = &i.items[i.currentIndex]
+	i.currentIndex++
+
+	return true
+}
+
+// Release releases associated resources.
+func (i *queryIterator) Release() {
+	i.currentIndex = 0
+	i.currentItem = nil
+	i.items = nil
+}
+
+// Error returns error in iterator.
+func (i *queryIterator) Error() error {
+	return nil
+}
+
+// Key returns the key of the current key/value pair.
+func (i *queryIterator) Key() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[0]
+}
+
+// Value returns the value of the current key/value pair.
+func (i *queryIterator) Value() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[1]
 }
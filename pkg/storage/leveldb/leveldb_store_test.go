@@ -13,9 +13,12 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -294,3 +297,539 @@ func TestLevelDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestLevelDBStoreQuery(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("query")
+	require.NoError(t, err)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	err = queryable.PutWithTags("vc1", []byte("data1"),
+		storage.Tag{Name: "issuer", Value: "did:example:1"}, storage.Tag{Name: "type", Value: "degree"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc2", []byte("data2"), storage.Tag{Name: "issuer", Value: "did:example:2"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc3", []byte("data3"), storage.Tag{Name: "issuer", Value: "did:example:1"})
+	require.NoError(t, err)
+
+	err = queryable.Put("vc4", []byte("data4"))
+	require.NoError(t, err)
+
+	t.Run("query by tag name and value", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "did:example:1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3"}, iteratedKeys(t, itr))
+	})
+
+	t.Run("query by tag name", func(t *testing.T) {
+		// ordered by tag value, then key
+		itr, err := queryable.Query("issuer", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3", "vc2"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("type", "")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("vc1"), itr.Key())
+		require.Equal(t, []byte("data1"), itr.Value())
+		require.False(t, itr.Next())
+	})
+
+	t.Run("query with pagination", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "", storage.WithPageSize(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithPageSize(2), storage.WithOffset(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc2"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithOffset(3))
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		_, err = queryable.Query("issuer", "", storage.WithOffset(-1))
+		require.Error(t, err)
+	})
+
+	t.Run("get tags", func(t *testing.T) {
+		tags, err := queryable.GetTags("vc1")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "issuer", Value: "did:example:1"}, {Name: "type", Value: "degree"}}, tags)
+
+		tags, err = queryable.GetTags("vc4")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		_, err = queryable.GetTags("vc5")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = queryable.GetTags("")
+		require.EqualError(t, err, "key is mandatory")
+	})
+
+	t.Run("put and delete update tags", func(t *testing.T) {
+		err := queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "new"})
+		require.NoError(t, err)
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		itr, err := queryable.Query("state", "new")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("state", "done")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc5"}, iteratedKeys(t, itr))
+
+		err = queryable.Put("vc5", []byte("data5"))
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		err = queryable.Delete("vc5")
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := queryable.Query("", "value")
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = queryable.PutWithTags("vc6", []byte("data6"), storage.Tag{Value: "value"})
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = queryable.PutWithTags("vc6", []byte("data6"), storage.Tag{Name: "state", Value: "a\x00b"})
+		require.Error(t, err)
+
+		// keys with a NUL character would collide with the tag index
+		err = queryable.PutWithTags(tagListPrefix+"vc1", []byte("[]"))
+		require.EqualError(t, err, "key can't contain a NUL character")
+
+		err = queryable.Put("vc\x006", []byte("data6"))
+		require.EqualError(t, err, "key can't contain a NUL character")
+	})
+
+	t.Run("concurrent puts of the same key keep the tag index consistent", func(t *testing.T) {
+		const writers, writes = 8, 50
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				for j := 0; j < writes; j++ {
+					tag := storage.Tag{Name: "writer", Value: fmt.Sprintf("%d-%d", i, j)}
+
+					if i%2 == 0 {
+						require.NoError(t, queryable.PutWithTags("vc7", []byte("data7"), tag))
+
+						continue
+					}
+
+					batch := queryable.(storage.BatchableStore).NewBatch()
+					storage.BatchPutWithTags(batch, "vc7", []byte("data7"), tag)
+					require.NoError(t, batch.Commit())
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		tags, err := queryable.GetTags("vc7")
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+
+		// only the index entry of the last write is left
+		itr, err := queryable.Query("writer", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc7"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("writer", tags[0].Value)
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc7"}, iteratedKeys(t, itr))
+
+		require.NoError(t, queryable.Delete("vc7"))
+	})
+
+	t.Run("tag index is not visible to iterator", func(t *testing.T) {
+		itr := queryable.Iterator("vc", "vc"+storage.EndKeySuffix)
+		verifyItr(t, itr, 4, "vc")
+
+		// a full scan covers the reserved prefixes of the index
+		itr = queryable.Iterator("", storage.EndKeySuffix)
+		require.Equal(t, []string{"vc1", "vc2", "vc3", "vc4"}, iteratedKeys(t, itr))
+	})
+}
+
+func iteratedKeys(t *testing.T, itr storage.StoreIterator) []string {
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
+
+func TestLevelDBStoreBatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("batch")
+	require.NoError(t, err)
+
+	batchable, ok := store.(storage.BatchableStore)
+	require.True(t, ok)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, queryable.PutWithTags("k2", []byte("v2"), storage.Tag{Name: "tag", Value: "value"}))
+
+	t.Run("commit applies all writes", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k2", []byte("v2-updated"))
+		batch.Put("k3", []byte("v3"))
+		batch.Delete("k1")
+		batch.Put("k3", []byte("v3-updated"))
+		require.NoError(t, batch.Commit())
+
+		_, err := store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		v, err := store.Get("k2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2-updated"), v)
+
+		v, err = store.Get("k3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3-updated"), v)
+
+		// a put within a batch removes the tags and their index entries, as a plain put does
+		tags, err := queryable.GetTags("k2")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		itr, err := queryable.Query("tag", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+	})
+
+	t.Run("tagged writes", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		storage.BatchPutWithTags(batch, "k6", []byte("v6"), storage.Tag{Name: "tag", Value: "first"})
+		storage.BatchPutWithTags(batch, "k6", []byte("v6"), storage.Tag{Name: "tag", Value: "second"})
+		storage.BatchPutWithTags(batch, "k7", []byte("v7"), storage.Tag{Name: "tag", Value: "second"})
+		require.NoError(t, batch.Commit())
+
+		tags, err := queryable.GetTags("k6")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "tag", Value: "second"}}, tags)
+
+		// the tags set earlier in the batch are replaced
+		itr, err := queryable.Query("tag", "first")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("tag", "second")
+		require.NoError(t, err)
+		require.Equal(t, []string{"k6", "k7"}, iteratedKeys(t, itr))
+
+		batch = batchable.NewBatch()
+		storage.BatchPutWithTags(batch, "k8", []byte("v8"), storage.Tag{Value: "value"})
+		require.EqualError(t, batch.Commit(), "tag name is mandatory")
+	})
+
+	t.Run("invalid write makes the whole batch fail", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Put("k5", nil)
+		require.EqualError(t, batch.Commit(), "key and value are mandatory")
+
+		batch = batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Delete("")
+		require.EqualError(t, batch.Commit(), "key is mandatory")
+
+		batch = batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Put(ttlPrefix+"k4", []byte("v4"))
+		require.EqualError(t, batch.Commit(), "key can't contain a NUL character")
+
+		_, err := store.Get("k4")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("commit fails once the db is closed", func(t *testing.T) {
+		require.NoError(t, prov.Close())
+
+		batch := batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+
+		err := batch.Commit()
+		require.Error(t, err)
+	})
+}
+
+func TestLevelDBStoreTTL(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path, WithSweepInterval(10*time.Millisecond))
+
+	store, err := prov.OpenStore("ttl")
+	require.NoError(t, err)
+
+	expiring, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+
+	t.Run("expiry", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("expiry_1", []byte("v1"), time.Hour))
+		require.NoError(t, store.Put("expiry_2", []byte("v2")))
+
+		expiresAt, err := expiring.Expiry("expiry_1")
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+		expiresAt, err = expiring.Expiry("expiry_2")
+		require.NoError(t, err)
+		require.True(t, expiresAt.IsZero())
+
+		_, err = expiring.Expiry("expiry_3")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		require.NoError(t, store.Delete("expiry_1"))
+		require.NoError(t, store.Delete("expiry_2"))
+	})
+
+	t.Run("expired records are hidden then removed", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("ttl_1", []byte("v1"), 50*time.Millisecond))
+		require.NoError(t, expiring.PutWithTTL("ttl_2", []byte("v2"), time.Hour))
+		require.NoError(t, store.Put("ttl_3", []byte("v3")))
+
+		v, err := store.Get("ttl_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = store.Get("ttl_1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		itr := store.Iterator("ttl_", "ttl_"+storage.EndKeySuffix)
+		require.Equal(t, []string{"ttl_2", "ttl_3"}, iteratedKeys(t, itr))
+
+		// a full scan covers the reserved prefixes of the expiry index
+		itr = store.Iterator("", storage.EndKeySuffix)
+		require.Equal(t, []string{"ttl_2", "ttl_3"}, iteratedKeys(t, itr))
+
+		// the record and its expiration entries were removed from the db
+		db := store.(*leveldbStore).db
+
+		_, err = db.Get([]byte("ttl_1"), nil)
+		require.Equal(t, leveldb.ErrNotFound, err)
+
+		_, err = db.Get([]byte(ttlPrefix+"ttl_1"), nil)
+		require.Equal(t, leveldb.ErrNotFound, err)
+	})
+
+	t.Run("writing the key again removes the expiration", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("ttl_4", []byte("v4"), 50*time.Millisecond))
+		require.NoError(t, store.Put("ttl_4", []byte("v4-updated")))
+
+		time.Sleep(100 * time.Millisecond)
+
+		v, err := store.Get("ttl_4")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4-updated"), v)
+	})
+
+	t.Run("writing the key again in a batch removes the expiration", func(t *testing.T) {
+		batch, ok := store.(storage.BatchableStore).NewBatch().(storage.ExpiringBatch)
+		require.True(t, ok)
+
+		batch.PutWithTTL("ttl_4b", []byte("v4b"), 50*time.Millisecond)
+		batch.Put("ttl_4b", []byte("v4b-updated"))
+		require.NoError(t, batch.Commit())
+
+		time.Sleep(100 * time.Millisecond)
+
+		v, err := store.Get("ttl_4b")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4b-updated"), v)
+
+		expiry, err := expiring.Expiry("ttl_4b")
+		require.NoError(t, err)
+		require.True(t, expiry.IsZero())
+
+		_, err = store.(*leveldbStore).db.Get([]byte(ttlPrefix+"ttl_4b"), nil)
+		require.Equal(t, leveldb.ErrNotFound, err)
+	})
+
+	t.Run("expired tagged records aren't returned by queries", func(t *testing.T) {
+		require.NoError(t, store.(storage.QueryableStore).PutWithTags("ttl_5", []byte("v5"),
+			storage.Tag{Name: "tag", Value: "value"}))
+
+		batch, ok := store.(storage.BatchableStore).NewBatch().(storage.ExpiringBatch)
+		require.True(t, ok)
+
+		batch.PutWithTTL("ttl_6", []byte("v6"), 50*time.Millisecond)
+		batch.PutWithTTL("ttl_6", []byte("v6"), 60*time.Millisecond)
+		batch.Put("ttl_7", []byte("v7"))
+		require.NoError(t, batch.Commit())
+
+		require.NoError(t, store.(storage.QueryableStore).PutWithTags("ttl_6", []byte("v6"),
+			storage.Tag{Name: "tag", Value: "value"}))
+		require.NoError(t, expiring.PutWithTTL("ttl_8", []byte("v8"), 50*time.Millisecond))
+
+		time.Sleep(100 * time.Millisecond)
+
+		// a plain put removed the batch expirations of ttl_6
+		itr, err := store.(storage.QueryableStore).Query("tag", "value")
+		require.NoError(t, err)
+		require.Equal(t, []string{"ttl_5", "ttl_6"}, iteratedKeys(t, itr))
+
+		_, err = store.Get("ttl_8")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("removals of expired records are notified", func(t *testing.T) {
+		changes, stop, err := store.(storage.WatchableStore).Watch("ttl_watch")
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, expiring.PutWithTTL("ttl_watch", []byte("v"), 50*time.Millisecond))
+
+		require.Equal(t, []storage.Change{
+			{Key: "ttl_watch", Value: []byte("v")},
+			{Key: "ttl_watch", Deleted: true},
+		}, receiveChanges(t, changes, 2))
+	})
+
+	t.Run("records expire after a restart", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("ttl_9", []byte("v9"), 50*time.Millisecond))
+		require.NoError(t, prov.Close())
+
+		time.Sleep(100 * time.Millisecond)
+
+		reopenedProv := NewProvider(path, WithSweepInterval(10*time.Millisecond))
+
+		reopened, err := reopenedProv.OpenStore("ttl")
+		require.NoError(t, err)
+
+		_, err = reopened.Get("ttl_9")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		_, err = reopened.(*leveldbStore).db.Get([]byte("ttl_9"), nil)
+		require.Equal(t, leveldb.ErrNotFound, err)
+
+		err = reopened.(storage.ExpiringStore).PutWithTTL("ttl_10", []byte("v10"), -time.Second)
+		require.EqualError(t, err, "ttl must be positive")
+
+		require.NoError(t, reopenedProv.Close())
+	})
+}
+
+func TestLevelDBStoreWatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+
+	store, err := prov.OpenStore("watch")
+	require.NoError(t, err)
+
+	watchable, ok := store.(storage.WatchableStore)
+	require.True(t, ok)
+
+	changes, stop, err := watchable.Watch("conn_")
+	require.NoError(t, err)
+
+	allChanges, _, err := watchable.Watch("")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("other_1", []byte("value")))
+	require.NoError(t, store.Put("conn_1", []byte("value1")))
+	require.NoError(t, store.(storage.ExpiringStore).PutWithTTL("conn_2", []byte("value2"), time.Hour))
+	require.NoError(t, store.Delete("conn_1"))
+	// deleting a missing record isn't a change
+	require.NoError(t, store.Delete("conn_3"))
+
+	batch := storage.NewBatch(store)
+	batch.Put("conn_3", []byte("value3"))
+	batch.Delete("conn_3")
+	batch.Delete("conn_3")
+	batch.Delete("conn_2")
+	batch.Delete("conn_4")
+	require.NoError(t, batch.Commit())
+
+	expected := []storage.Change{
+		{Key: "conn_1", Value: []byte("value1")},
+		{Key: "conn_2", Value: []byte("value2")},
+		{Key: "conn_1", Deleted: true},
+		{Key: "conn_3", Value: []byte("value3")},
+		{Key: "conn_3", Deleted: true},
+		{Key: "conn_2", Deleted: true},
+	}
+
+	require.Equal(t, expected, receiveChanges(t, changes, len(expected)))
+	require.Equal(t, append([]storage.Change{{Key: "other_1", Value: []byte("value")}}, expected...),
+		receiveChanges(t, allChanges, len(expected)+1))
+
+	stop()
+
+	_, open := <-changes
+	require.False(t, open)
+
+	// closing the provider closes the channels of the watchers
+	require.NoError(t, prov.Close())
+
+	_, open = <-allChanges
+	require.False(t, open)
+}
+
+func receiveChanges(t *testing.T, changes <-chan storage.Change, count int) []storage.Change {
+	t.Helper()
+
+	var received []storage.Change
+
+	for len(received) < count {
+		select {
+		case c := <-changes:
+			received = append(received, c)
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout waiting for changes")
+		}
+	}
+
+	return received
+}
@@ -8,24 +8,46 @@ package mem
 
 import (
 	"errors"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/internal/watcher"
 )
 
-// TODO https://github.com/hyperledger/aries-framework-go/issues/750 - we will need to consider
-//  automatic eviction based on TTL.
+// defaultSweepInterval is how often expired records are removed unless WithSweepInterval is used
+const defaultSweepInterval = time.Minute
 
 // Provider leveldb implementation of storage.Provider interface
 type Provider struct {
-	dbs  map[string]*memStore
-	lock sync.RWMutex
+	dbs           map[string]*memStore
+	sweepInterval time.Duration
+	// stopSweep stops the goroutine removing expired records, nil when it isn't running
+	stopSweep chan struct{}
+	lock      sync.RWMutex
+}
+
+// Option configures the mem provider
+type Option func(opts *Provider)
+
+// WithSweepInterval option sets how often expired records are removed from the stores
+func WithSweepInterval(interval time.Duration) Option {
+	return func(opts *Provider) {
+		opts.sweepInterval = interval
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider() *Provider {
-	return &Provider{dbs: make(map[string]*memStore)}
+func NewProvider(opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*memStore), sweepInterval: defaultSweepInterval}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -38,6 +60,11 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	return store, nil
 }
 
+// StoreExists tells whether the store of given name space was opened
+func (p *Provider) StoreExists(name string) (bool, error) {
+	return p.getMemStore(name) != nil, nil
+}
+
 // getMemStore finds mem store with given name
 // returns nil if not found
 func (p *Provider) getMemStore(name string) *memStore {
@@ -53,7 +80,8 @@ func (p *Provider) newMemStore(name string) *memStore {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	store := &memStore{db: make(map[string][]byte)}
+	store := &memStore{startSweeper: p.startSweeper, watchers: watcher.New()}
+	store.clear()
 	p.dbs[strings.ToLower(name)] = store
 
 	return store
@@ -65,11 +93,17 @@ func (p *Provider) Close() error {
 	defer p.lock.Unlock()
 
 	for _, memStore := range p.dbs {
-		memStore.db = make(map[string][]byte)
+		memStore.clear()
+		memStore.watchers.Close()
 	}
 
 	p.dbs = make(map[string]*memStore)
 
+	if p.stopSweep != nil {
+		close(p.stopSweep)
+		p.stopSweep = nil
+	}
+
 	return nil
 }
 
@@ -84,30 +118,234 @@ func (p *Provider) CloseStore(name string) error {
 	if ok {
 		delete(p.dbs, k)
 
-		memStore.db = make(map[string][]byte)
+		memStore.clear()
+		memStore.watchers.Close()
 	}
 
 	return nil
 }
 
+// startSweeper starts removing expired records in the background, unless it is already done
+func (p *Provider) startSweeper() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopSweep != nil {
+		return
+	}
+
+	p.stopSweep = make(chan struct{})
+
+	go p.sweep(p.sweepInterval, p.stopSweep)
+}
+
+func (p *Provider) sweep(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.lock.RLock()
+
+			stores := make([]*memStore, 0, len(p.dbs))
+			for _, store := range p.dbs {
+				stores = append(stores, store)
+			}
+
+			p.lock.RUnlock()
+
+			for _, store := range stores {
+				store.removeExpired(now)
+			}
+		}
+	}
+}
+
 type memStore struct {
 	db map[string][]byte
+	// tags holds the tag names and values of each tagged key
+	tags map[string]map[string]string
+	// expiry holds the expiration time of each expiring key
+	expiry       map[string]time.Time
+	startSweeper func()
+	watchers     *watcher.Watchers
 	sync.RWMutex
 }
 
+func (s *memStore) clear() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.db = make(map[string][]byte)
+	s.tags = make(map[string]map[string]string)
+	s.expiry = make(map[string]time.Time)
+}
+
 // Put stores the key and the record
 func (s *memStore) Put(k string, v []byte) error {
+	return s.PutWithTags(k, v)
+}
+
+// PutWithTTL stores the key and the record, the record expires once ttl has elapsed
+func (s *memStore) PutWithTTL(k string, v []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("ttl must be positive")
+	}
+
+	if err := s.put(k, v, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+
+	s.startSweeper()
+
+	return nil
+}
+
+// PutWithTags stores the key and the record along with the given tags
+func (s *memStore) PutWithTags(k string, v []byte, tags ...storage.Tag) error {
+	return s.put(k, v, time.Time{}, tags...)
+}
+
+// put stores the record, it expires at expiresAt unless it is the zero time
+func (s *memStore) put(k string, v []byte, expiresAt time.Time, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
+	tagMap := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		if tag.Name == "" {
+			return errors.New("tag name is mandatory")
+		}
+
+		tagMap[tag.Name] = tag.Value
+	}
+
 	s.Lock()
 	s.db[k] = v
+
+	if len(tagMap) > 0 {
+		s.tags[k] = tagMap
+	} else {
+		delete(s.tags, k)
+	}
+
+	if !expiresAt.IsZero() {
+		s.expiry[k] = expiresAt
+	} else {
+		delete(s.expiry, k)
+	}
+
+	s.watchers.Notify(storage.Change{Key: k, Value: v})
 	s.Unlock()
 
 	return nil
 }
 
+// isExpired tells whether the record of the key has expired, the caller must hold the lock
+func (s *memStore) isExpired(k string, now time.Time) bool {
+	expiresAt, ok := s.expiry[k]
+
+	return ok && !now.Before(expiresAt)
+}
+
+// removeExpired deletes the records which have expired at the given time
+func (s *memStore) removeExpired(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	var changes []storage.Change
+
+	for k := range s.expiry {
+		if s.isExpired(k, now) {
+			delete(s.db, k)
+			delete(s.tags, k)
+			delete(s.expiry, k)
+
+			changes = append(changes, storage.Change{Key: k, Deleted: true})
+		}
+	}
+
+	s.watchers.Notify(changes...)
+}
+
+// GetTags fetches the tags associated with the record of the given key
+func (s *memStore) GetTags(k string) ([]storage.Tag, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if _, ok := s.db[k]; !ok || s.isExpired(k, time.Now()) {
+		return nil, storage.ErrDataNotFound
+	}
+
+	var tags []storage.Tag
+
+	for name, value := range s.tags[k] {
+		tags = append(tags, storage.Tag{Name: name, Value: value})
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+// Query returns an iterator over the records having a tag with the given name and value, ordered by key.
+func (s *memStore) Query(name, value string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	options, err := storage.NewQueryOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	var keys []string
+
+	now := time.Now()
+
+	for k, tags := range s.tags {
+		if s.isExpired(k, now) {
+			continue
+		}
+
+		if v, ok := tags[name]; ok && (value == "" || v == value) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	if options.Offset >= len(keys) {
+		return newMemIterator(nil), nil
+	}
+
+	keys = keys[options.Offset:]
+
+	if options.PageSize > 0 && options.PageSize < len(keys) {
+		keys = keys[:options.PageSize]
+	}
+
+	batch := make([][]string, len(keys))
+
+	for i, k := range keys {
+		batch[i] = []string{k, string(s.db[k])}
+	}
+
+	return newMemIterator(batch), nil
+}
+
 // Get fetches the record based on key
 func (s *memStore) Get(k string) ([]byte, error) {
 	if k == "" {
@@ -116,15 +354,32 @@ func (s *memStore) Get(k string) ([]byte, error) {
 
 	s.RLock()
 	data, ok := s.db[k]
+	expired := s.isExpired(k, time.Now())
 	s.RUnlock()
 
-	if !ok {
+	if !ok || expired {
 		return nil, storage.ErrDataNotFound
 	}
 
 	return data, nil
 }
 
+// Expiry fetches the time the record of the given key expires at, the zero time if it doesn't expire
+func (s *memStore) Expiry(k string) (time.Time, error) {
+	if k == "" {
+		return time.Time{}, errors.New("key is mandatory")
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if _, ok := s.db[k]; !ok || s.isExpired(k, time.Now()) {
+		return time.Time{}, storage.ErrDataNotFound
+	}
+
+	return s.expiry[k], nil
+}
+
 // Iterator returns iterator for the latest snapshot of the underlying db.
 func (s *memStore) Iterator(start, limit string) storage.StoreIterator {
 	// TODO Change Store Iterator https://github.com/hyperledger/aries-framework-go/issues/852
@@ -134,8 +389,10 @@ func (s *memStore) Iterator(start, limit string) storage.StoreIterator {
 
 	var batch [][]string
 
+	now := time.Now()
+
 	for k, v := range data {
-		if strings.HasPrefix(k, start) {
+		if strings.HasPrefix(k, start) && !s.isExpired(k, now) {
 			batch = append(batch, []string{k, string(v)})
 		}
 	}
@@ -150,12 +407,141 @@ func (s *memStore) Delete(k string) error {
 	}
 
 	s.Lock()
+	_, exists := s.db[k]
 	delete(s.db, k)
+	delete(s.tags, k)
+	delete(s.expiry, k)
+
+	if exists {
+		s.watchers.Notify(storage.Change{Key: k, Deleted: true})
+	}
+
 	s.Unlock()
 
 	return nil
 }
 
+// Watch returns a channel receiving the changes of the records whose key starts with prefix
+func (s *memStore) Watch(prefix string) (<-chan storage.Change, func(), error) {
+	changes, stop := s.watchers.Add(prefix)
+
+	return changes, stop, nil
+}
+
+// NewBatch returns a batch whose writes are applied to the store at once
+func (s *memStore) NewBatch() storage.Batch {
+	return &memBatch{store: s}
+}
+
+// memBatchOp is a write recorded in a memBatch
+type memBatchOp struct {
+	key    string
+	value  []byte
+	delete bool
+	// ttl is set for expiring records
+	ttl  *time.Duration
+	tags []storage.Tag
+}
+
+type memBatch struct {
+	store *memStore
+	ops   []memBatchOp
+}
+
+// Put adds the storing of the key and the record to the batch
+func (b *memBatch) Put(k string, v []byte) {
+	b.ops = append(b.ops, memBatchOp{key: k, value: v})
+}
+
+// PutWithTTL adds the storing of a record expiring once ttl has elapsed to the batch
+func (b *memBatch) PutWithTTL(k string, v []byte, ttl time.Duration) {
+	b.ops = append(b.ops, memBatchOp{key: k, value: v, ttl: &ttl})
+}
+
+// PutWithTags adds the storing of the key and the record along with the given tags to the batch
+func (b *memBatch) PutWithTags(k string, v []byte, tags ...storage.Tag) {
+	b.ops = append(b.ops, memBatchOp{key: k, value: v, tags: tags})
+}
+
+// Delete adds the deletion of the record with k key to the batch
+func (b *memBatch) Delete(k string) {
+	b.ops = append(b.ops, memBatchOp{key: k, delete: true})
+}
+
+// Commit applies all the writes of the batch under the store lock, nothing is written if one of them is invalid
+func (b *memBatch) Commit() error {
+	for _, op := range b.ops {
+		if op.key == "" {
+			return errors.New("key is mandatory")
+		}
+
+		if !op.delete && op.value == nil {
+			return errors.New("key and value are mandatory")
+		}
+
+		if op.ttl != nil && *op.ttl <= 0 {
+			return errors.New("ttl must be positive")
+		}
+
+		for _, tag := range op.tags {
+			if tag.Name == "" {
+				return errors.New("tag name is mandatory")
+			}
+		}
+	}
+
+	if b.apply() {
+		b.store.startSweeper()
+	}
+
+	b.ops = nil
+
+	return nil
+}
+
+// apply writes the operations to the store and tells whether some of the records expire
+func (b *memBatch) apply() bool {
+	b.store.Lock()
+	defer b.store.Unlock()
+
+	now := time.Now()
+	expiring := false
+	changes := make([]storage.Change, 0, len(b.ops))
+
+	for _, op := range b.ops {
+		if op.delete {
+			if _, ok := b.store.db[op.key]; ok {
+				changes = append(changes, storage.Change{Key: op.key, Deleted: true})
+			}
+
+			delete(b.store.db, op.key)
+		} else {
+			b.store.db[op.key] = op.value
+			changes = append(changes, storage.Change{Key: op.key, Value: op.value})
+		}
+
+		delete(b.store.tags, op.key)
+		delete(b.store.expiry, op.key)
+
+		if len(op.tags) > 0 {
+			b.store.tags[op.key] = make(map[string]string, len(op.tags))
+
+			for _, tag := range op.tags {
+				b.store.tags[op.key][tag.Name] = tag.Value
+			}
+		}
+
+		if op.ttl != nil {
+			b.store.expiry[op.key] = now.Add(*op.ttl)
+			expiring = true
+		}
+	}
+
+	b.store.watchers.Notify(changes...)
+
+	return expiring
+}
+
 type memIterator struct {
 	currentIndex int
 	currentItem  []string
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package mem
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -245,3 +247,352 @@ func TestMemStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestMemStoreQuery(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("query")
+	require.NoError(t, err)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	err = queryable.PutWithTags("vc1", []byte("data1"),
+		storage.Tag{Name: "issuer", Value: "did:example:1"}, storage.Tag{Name: "type", Value: "degree"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc2", []byte("data2"), storage.Tag{Name: "issuer", Value: "did:example:2"})
+	require.NoError(t, err)
+
+	err = queryable.PutWithTags("vc3", []byte("data3"), storage.Tag{Name: "issuer", Value: "did:example:1"})
+	require.NoError(t, err)
+
+	err = queryable.Put("vc4", []byte("data4"))
+	require.NoError(t, err)
+
+	t.Run("query by tag name and value", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "did:example:1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc3"}, iteratedKeys(t, itr))
+	})
+
+	t.Run("query by tag name", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc2", "vc3"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("type", "")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("vc1"), itr.Key())
+		require.Equal(t, []byte("data1"), itr.Value())
+		require.False(t, itr.Next())
+	})
+
+	t.Run("query with pagination", func(t *testing.T) {
+		itr, err := queryable.Query("issuer", "", storage.WithPageSize(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc1", "vc2"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithPageSize(2), storage.WithOffset(2))
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc3"}, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("issuer", "", storage.WithOffset(3))
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		_, err = queryable.Query("issuer", "", storage.WithOffset(-1))
+		require.Error(t, err)
+	})
+
+	t.Run("get tags", func(t *testing.T) {
+		tags, err := queryable.GetTags("vc1")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "issuer", Value: "did:example:1"}, {Name: "type", Value: "degree"}}, tags)
+
+		tags, err = queryable.GetTags("vc4")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+
+		_, err = queryable.GetTags("vc5")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = queryable.GetTags("")
+		require.EqualError(t, err, "key is mandatory")
+	})
+
+	t.Run("put and delete update tags", func(t *testing.T) {
+		err := queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "new"})
+		require.NoError(t, err)
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		itr, err := queryable.Query("state", "new")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		itr, err = queryable.Query("state", "done")
+		require.NoError(t, err)
+		require.Equal(t, []string{"vc5"}, iteratedKeys(t, itr))
+
+		err = queryable.Put("vc5", []byte("data5"))
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+
+		err = queryable.PutWithTags("vc5", []byte("data5"), storage.Tag{Name: "state", Value: "done"})
+		require.NoError(t, err)
+
+		err = queryable.Delete("vc5")
+		require.NoError(t, err)
+
+		itr, err = queryable.Query("state", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := queryable.Query("", "value")
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = queryable.PutWithTags("vc6", []byte("data6"), storage.Tag{Value: "value"})
+		require.EqualError(t, err, "tag name is mandatory")
+	})
+}
+
+func iteratedKeys(t *testing.T, itr storage.StoreIterator) []string {
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
+
+func TestMemStoreBatch(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("batch")
+	require.NoError(t, err)
+
+	batchable, ok := store.(storage.BatchableStore)
+	require.True(t, ok)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, queryable.PutWithTags("k2", []byte("v2"), storage.Tag{Name: "tag", Value: "value"}))
+
+	t.Run("commit applies all writes", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k2", []byte("v2-updated"))
+		batch.Put("k3", []byte("v3"))
+		batch.Delete("k1")
+		batch.Put("k3", []byte("v3-updated"))
+		require.NoError(t, batch.Commit())
+
+		_, err := store.Get("k1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		v, err := store.Get("k2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2-updated"), v)
+
+		v, err = store.Get("k3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3-updated"), v)
+
+		// a put within a batch removes the tags, as a plain put does
+		itr, err := queryable.Query("tag", "")
+		require.NoError(t, err)
+		require.Empty(t, iteratedKeys(t, itr))
+	})
+
+	t.Run("invalid write makes the whole batch fail", func(t *testing.T) {
+		batch := batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Put("k5", nil)
+		require.EqualError(t, batch.Commit(), "key and value are mandatory")
+
+		batch = batchable.NewBatch()
+		batch.Put("k4", []byte("v4"))
+		batch.Delete("")
+		require.EqualError(t, batch.Commit(), "key is mandatory")
+
+		_, err := store.Get("k4")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+func TestMemStoreTTL(t *testing.T) {
+	prov := NewProvider(WithSweepInterval(10 * time.Millisecond))
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("ttl")
+	require.NoError(t, err)
+
+	expiring, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+
+	t.Run("expired records are hidden then removed", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("ttl_1", []byte("v1"), 50*time.Millisecond))
+		require.NoError(t, expiring.PutWithTTL("ttl_2", []byte("v2"), time.Hour))
+		require.NoError(t, store.Put("ttl_3", []byte("v3")))
+
+		v, err := store.Get("ttl_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = store.Get("ttl_1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		itr := store.Iterator("ttl_", "ttl_"+storage.EndKeySuffix)
+		require.ElementsMatch(t, []string{"ttl_2", "ttl_3"}, iteratedKeys(t, itr))
+
+		memStore, ok := store.(*memStore)
+		require.True(t, ok)
+
+		memStore.RLock()
+		_, found := memStore.db["ttl_1"]
+		memStore.RUnlock()
+		require.False(t, found)
+	})
+
+	t.Run("writing the key again removes the expiration", func(t *testing.T) {
+		require.NoError(t, expiring.PutWithTTL("ttl_4", []byte("v4"), 50*time.Millisecond))
+		require.NoError(t, store.Put("ttl_4", []byte("v4-updated")))
+
+		time.Sleep(100 * time.Millisecond)
+
+		v, err := store.Get("ttl_4")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v4-updated"), v)
+	})
+
+	t.Run("expiring records in a batch", func(t *testing.T) {
+		batch, ok := store.(storage.BatchableStore).NewBatch().(storage.ExpiringBatch)
+		require.True(t, ok)
+
+		batch.PutWithTTL("ttl_5", []byte("v5"), 50*time.Millisecond)
+		batch.Put("ttl_6", []byte("v6"))
+		require.NoError(t, batch.Commit())
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err := store.Get("ttl_5")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		_, err = store.Get("ttl_6")
+		require.NoError(t, err)
+
+		batch, ok = store.(storage.BatchableStore).NewBatch().(storage.ExpiringBatch)
+		require.True(t, ok)
+
+		batch.PutWithTTL("ttl_7", []byte("v7"), 0)
+		require.EqualError(t, batch.Commit(), "ttl must be positive")
+	})
+
+	t.Run("removals of expired records are notified", func(t *testing.T) {
+		changes, stop, err := store.(storage.WatchableStore).Watch("ttl_watch")
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, expiring.PutWithTTL("ttl_watch", []byte("v"), 50*time.Millisecond))
+
+		require.Equal(t, []storage.Change{
+			{Key: "ttl_watch", Value: []byte("v")},
+			{Key: "ttl_watch", Deleted: true},
+		}, receiveChanges(t, changes, 2))
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		err := expiring.PutWithTTL("ttl_8", []byte("v8"), 0)
+		require.EqualError(t, err, "ttl must be positive")
+
+		err = expiring.PutWithTTL("", []byte("v8"), time.Hour)
+		require.EqualError(t, err, "key and value are mandatory")
+	})
+}
+
+func TestMemStoreWatch(t *testing.T) {
+	prov := NewProvider()
+
+	store, err := prov.OpenStore("watch")
+	require.NoError(t, err)
+
+	watchable, ok := store.(storage.WatchableStore)
+	require.True(t, ok)
+
+	changes, stop, err := watchable.Watch("conn_")
+	require.NoError(t, err)
+
+	allChanges, _, err := watchable.Watch("")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("other_1", []byte("value")))
+	require.NoError(t, store.Put("conn_1", []byte("value1")))
+	require.NoError(t, store.(storage.QueryableStore).PutWithTags("conn_2", []byte("value2"),
+		storage.Tag{Name: "state", Value: "completed"}))
+	require.NoError(t, store.Delete("conn_1"))
+	// deleting a missing record isn't a change
+	require.NoError(t, store.Delete("conn_3"))
+
+	batch := storage.NewBatch(store)
+	batch.Put("conn_3", []byte("value3"))
+	batch.Delete("conn_2")
+	batch.Delete("conn_4")
+	require.NoError(t, batch.Commit())
+
+	expected := []storage.Change{
+		{Key: "conn_1", Value: []byte("value1")},
+		{Key: "conn_2", Value: []byte("value2")},
+		{Key: "conn_1", Deleted: true},
+		{Key: "conn_3", Value: []byte("value3")},
+		{Key: "conn_2", Deleted: true},
+	}
+
+	require.Equal(t, expected, receiveChanges(t, changes, len(expected)))
+	require.Equal(t, append([]storage.Change{{Key: "other_1", Value: []byte("value")}}, expected...),
+		receiveChanges(t, allChanges, len(expected)+1))
+
+	stop()
+
+	_, open := <-changes
+	require.False(t, open)
+
+	// closing the store closes the channels of its watchers
+	require.NoError(t, prov.CloseStore("watch"))
+
+	_, open = <-allChanges
+	require.False(t, open)
+}
+
+func receiveChanges(t *testing.T, changes <-chan storage.Change, count int) []storage.Change {
+	t.Helper()
+
+	var received []storage.Change
+
+	for len(received) < count {
+		select {
+		case c := <-changes:
+			received = append(received, c)
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout waiting for changes")
+		}
+	}
+
+	return received
+}
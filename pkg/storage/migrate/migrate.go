@@ -0,0 +1,393 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package migrate exports the stores of a storage.Provider to a portable archive and imports such an archive into
+// another provider, so that an agent can be backed up, restored or moved from a storage backend to another
+// (e.g. from leveldb to CouchDB).
+//
+// The archive is a JSON document holding every record (with its tags if the store is a storage.QueryableStore, and its
+// expiration time if it is a storage.ExpiringStore) of the exported namespaces. Namespaces missing from a
+// storage.StoreChecker provider are skipped rather than created. The archive is held in memory while being exported
+// or imported. Archives can be encrypted with a
+// secretlock.Service (e.g. a passphrase based master lock), in which case the records are encrypted with a random
+// AES-GCM key which is itself encrypted by the secret lock.
+package migrate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/audit"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/presentproof"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/route"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/store/did"
+	"github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
+)
+
+const (
+	archiveVersion = 1
+
+	// dataKeySize is the size of the AES-256 key encrypting the records of an encrypted archive
+	dataKeySize = 32
+
+	// archiveAAD binds the ciphertext of encrypted archives to their format
+	archiveAAD = "aries-framework-go/storage/migrate/v1"
+)
+
+// DefaultNamespaces returns the names of the stores the framework keeps in its storage providers: keys, key audit
+// records, connections, DID exchange, route coordination, DIDs, verifiable credentials, peer DIDs, messenger,
+// protocol states and out-of-band invitations.
+// The out-of-band store is kept by the transient storage provider, which is exported along with the connection
+// records it holds when it is passed to Export.
+func DefaultNamespaces() []string {
+	return []string{
+		localkms.Namespace,
+		legacykms.KeyStoreNamespace,
+		audit.Namespace,
+		connection.Namespace,
+		route.Coordination,
+		did.NameSpace,
+		did.StoreName,
+		verifiable.NameSpace,
+		peer.StoreNamespace,
+		messenger.MessengerStore,
+		introduce.Introduce,
+		issuecredential.Name,
+		presentproof.Name,
+		outofband.Name,
+	}
+}
+
+type options struct {
+	namespaces []string
+	secretLock secretlock.Service
+	keyURI     string
+}
+
+// Option configures Export and Import
+type Option func(opts *options)
+
+// WithNamespaces option sets the namespaces to export, DefaultNamespaces() are exported by default.
+// It has no effect on Import, which restores every namespace of the archive.
+func WithNamespaces(names ...string) Option {
+	return func(opts *options) {
+		opts.namespaces = names
+	}
+}
+
+// WithSecretLock option encrypts the exported archive, or decrypts the imported archive, with the master key
+// referenced by keyURI in the given secret lock.
+func WithSecretLock(lock secretlock.Service, keyURI string) Option {
+	return func(opts *options) {
+		opts.secretLock = lock
+		opts.keyURI = keyURI
+	}
+}
+
+// archive is the document written by Export, either Namespaces or EncryptedKey and Ciphertext are set
+type archive struct {
+	Version      int          `json:"version"`
+	Namespaces   []*namespace `json:"namespaces,omitempty"`
+	EncryptedKey string       `json:"encryptedKey,omitempty"`
+	Ciphertext   []byte       `json:"ciphertext,omitempty"`
+}
+
+type namespace struct {
+	Name    string    `json:"name"`
+	Records []*record `json:"records"`
+}
+
+type record struct {
+	Key       string        `json:"key"`
+	Value     []byte        `json:"value"`
+	Tags      []storage.Tag `json:"tags,omitempty"`
+	ExpiresAt *time.Time    `json:"expiresAt,omitempty"`
+}
+
+// Export writes the records of the namespaces of provider p to w.
+func Export(p storage.Provider, w io.Writer, opts ...Option) error {
+	o := &options{namespaces: DefaultNamespaces()}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	a := &archive{Version: archiveVersion}
+
+	for _, name := range o.namespaces {
+		ns, err := exportNamespace(p, name)
+		if err != nil {
+			return err
+		}
+
+		if ns != nil {
+			a.Namespaces = append(a.Namespaces, ns)
+		}
+	}
+
+	if o.secretLock != nil {
+		err := encryptArchive(a, o.secretLock, o.keyURI)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := json.NewEncoder(w).Encode(a)
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return nil
+}
+
+// exportNamespace reads the records of the store of the namespace name, it returns nil if p tells the store
+// doesn't exist
+func exportNamespace(p storage.Provider, name string) (*namespace, error) {
+	exists, err := storage.StoreExists(p, name)
+	if err != nil && !errors.Is(err, storage.ErrStoreCheckNotSupported) {
+		return nil, fmt.Errorf("failed to check store %s: %w", name, err)
+	}
+
+	if err == nil && !exists {
+		return nil, nil
+	}
+
+	store, err := p.OpenStore(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store %s: %w", name, err)
+	}
+
+	ns := &namespace{Name: name, Records: []*record{}}
+
+	// the whole key range, including the keys sorting after the "~" bound of prefix ranges
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	for itr.Next() {
+		rec, e := exportRecord(store, string(itr.Key()), itr.Value())
+		if errors.Is(e, storage.ErrDataNotFound) {
+			// expired since the iterator read it
+			continue
+		}
+
+		if e != nil {
+			return nil, fmt.Errorf("failed to export %s in store %s: %w", itr.Key(), name, e)
+		}
+
+		ns.Records = append(ns.Records, rec)
+	}
+
+	if itr.Error() != nil {
+		return nil, fmt.Errorf("failed to read store %s: %w", name, itr.Error())
+	}
+
+	return ns, nil
+}
+
+// exportRecord returns the record of the key k and the value v, with its tags and expiration time
+func exportRecord(store storage.Store, k string, v []byte) (*record, error) {
+	rec := &record{Key: k, Value: append([]byte{}, v...)}
+
+	if queryable, ok := store.(storage.QueryableStore); ok {
+		tags, err := queryable.GetTags(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags: %w", err)
+		}
+
+		rec.Tags = tags
+	}
+
+	expiresAt, err := storage.Expiry(store, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiry: %w", err)
+	}
+
+	if !expiresAt.IsZero() {
+		rec.ExpiresAt = &expiresAt
+	}
+
+	return rec, nil
+}
+
+// Import restores the records of the archive read from r into provider p, every namespace being written with one
+// storage.Batch. Records already in p are kept, unless the archive has a record with the same key. Records which
+// have expired since the archive was exported are skipped.
+func Import(p storage.Provider, r io.Reader, opts ...Option) error {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	a := &archive{}
+
+	err := json.NewDecoder(r).Decode(a)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if a.Version != archiveVersion {
+		return fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+
+	if a.EncryptedKey != "" {
+		if o.secretLock == nil {
+			return errors.New("archive is encrypted, a secret lock is required to import it")
+		}
+
+		err = decryptArchive(a, o.secretLock, o.keyURI)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ns := range a.Namespaces {
+		err = importNamespace(p, ns)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importNamespace(p storage.Provider, ns *namespace) error {
+	store, err := p.OpenStore(ns.Name)
+	if err != nil {
+		return fmt.Errorf("failed to open store %s: %w", ns.Name, err)
+	}
+
+	batch := storage.NewBatch(store)
+	now := time.Now()
+
+	for _, rec := range ns.Records {
+		// stores drop the tags of the records written with an expiration, such a record can't be restored as is
+		if rec.ExpiresAt != nil && len(rec.Tags) > 0 {
+			return fmt.Errorf("failed to import %s in store %s: record has both tags and an expiration",
+				rec.Key, ns.Name)
+		}
+
+		switch {
+		case rec.ExpiresAt != nil:
+			if !rec.ExpiresAt.After(now) {
+				continue
+			}
+
+			storage.BatchPutWithTTL(batch, rec.Key, rec.Value, rec.ExpiresAt.Sub(now))
+		case len(rec.Tags) > 0:
+			storage.BatchPutWithTags(batch, rec.Key, rec.Value, rec.Tags...)
+		default:
+			batch.Put(rec.Key, rec.Value)
+		}
+	}
+
+	err = batch.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to import store %s: %w", ns.Name, err)
+	}
+
+	return nil
+}
+
+// encryptArchive replaces the namespaces of a by their ciphertext
+func encryptArchive(a *archive, lock secretlock.Service, keyURI string) error {
+	plaintext, err := json.Marshal(a.Namespaces)
+	if err != nil {
+		return fmt.Errorf("failed to marshal namespaces: %w", err)
+	}
+
+	dataKey := make([]byte, dataKeySize)
+
+	_, err = rand.Read(dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to create archive key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return fmt.Errorf("failed to create nonce: %w", err)
+	}
+
+	encKey, err := lock.Encrypt(keyURI, &secretlock.EncryptRequest{
+		Plaintext:                   string(dataKey),
+		AdditionalAuthenticatedData: archiveAAD,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt archive key: %w", err)
+	}
+
+	a.Namespaces = nil
+	a.EncryptedKey = encKey.Ciphertext
+	a.Ciphertext = aead.Seal(nonce, nonce, plaintext, []byte(archiveAAD))
+
+	return nil
+}
+
+// decryptArchive sets the namespaces of a from its ciphertext
+func decryptArchive(a *archive, lock secretlock.Service, keyURI string) error {
+	dataKey, err := lock.Decrypt(keyURI, &secretlock.DecryptRequest{
+		Ciphertext:                  a.EncryptedKey,
+		AdditionalAuthenticatedData: archiveAAD,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt archive key: %w", err)
+	}
+
+	aead, err := newAEAD([]byte(dataKey.Plaintext))
+	if err != nil {
+		return err
+	}
+
+	nonceSize := aead.NonceSize()
+
+	if len(a.Ciphertext) < nonceSize {
+		return errors.New("invalid archive ciphertext")
+	}
+
+	plaintext, err := aead.Open(nil, a.Ciphertext[:nonceSize], a.Ciphertext[nonceSize:], []byte(archiveAAD))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	err = json.Unmarshal(plaintext, &a.Namespaces)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal namespaces: %w", err)
+	}
+
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
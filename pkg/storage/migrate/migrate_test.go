@@ -0,0 +1,308 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func newSourceProvider(t *testing.T) storage.Provider {
+	t.Helper()
+
+	p := mem.NewProvider()
+
+	kmsStore, err := p.OpenStore("kmsdb")
+	require.NoError(t, err)
+
+	require.NoError(t, kmsStore.Put("key1", []byte("keyset1")))
+	require.NoError(t, kmsStore.Put("key2", []byte("keyset2")))
+
+	connStore, err := p.OpenStore("didexchange")
+	require.NoError(t, err)
+
+	queryable, ok := connStore.(storage.QueryableStore)
+	require.True(t, ok)
+
+	require.NoError(t, queryable.PutWithTags("conn_1", []byte("record1"), storage.Tag{Name: "state", Value: "completed"}))
+	require.NoError(t, connStore.Put("conn_2", []byte("record2")))
+
+	return p
+}
+
+func requireImported(t *testing.T, p storage.Provider) {
+	t.Helper()
+
+	kmsStore, err := p.OpenStore("kmsdb")
+	require.NoError(t, err)
+
+	v, err := kmsStore.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("keyset1"), v)
+
+	v, err = kmsStore.Get("key2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("keyset2"), v)
+
+	connStore, err := p.OpenStore("didexchange")
+	require.NoError(t, err)
+
+	v, err = connStore.Get("conn_2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("record2"), v)
+
+	tags, err := connStore.(storage.QueryableStore).GetTags("conn_1")
+	require.NoError(t, err)
+	require.Equal(t, []storage.Tag{{Name: "state", Value: "completed"}}, tags)
+}
+
+func TestExportImport(t *testing.T) {
+	t.Run("plain archive", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := Export(newSourceProvider(t), &buf)
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), "conn_1")
+
+		dest := mem.NewProvider()
+
+		err = Import(dest, &buf)
+		require.NoError(t, err)
+
+		requireImported(t, dest)
+	})
+
+	t.Run("encrypted archive", func(t *testing.T) {
+		lock, err := hkdf.NewMasterLock("passphrase", sha256.New, nil)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		err = Export(newSourceProvider(t), &buf, WithSecretLock(lock, ""))
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "conn_1")
+
+		archived := buf.Bytes()
+
+		err = Import(mem.NewProvider(), bytes.NewReader(archived))
+		require.EqualError(t, err, "archive is encrypted, a secret lock is required to import it")
+
+		wrongLock, err := hkdf.NewMasterLock("wrong passphrase", sha256.New, nil)
+		require.NoError(t, err)
+
+		err = Import(mem.NewProvider(), bytes.NewReader(archived), WithSecretLock(wrongLock, ""))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt archive key")
+
+		dest := mem.NewProvider()
+
+		err = Import(dest, bytes.NewReader(archived), WithSecretLock(lock, ""))
+		require.NoError(t, err)
+
+		requireImported(t, dest)
+	})
+
+	t.Run("selected namespaces", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := Export(newSourceProvider(t), &buf, WithNamespaces("kmsdb"))
+		require.NoError(t, err)
+
+		dest := mem.NewProvider()
+
+		err = Import(dest, &buf)
+		require.NoError(t, err)
+
+		kmsStore, err := dest.OpenStore("kmsdb")
+		require.NoError(t, err)
+
+		_, err = kmsStore.Get("key1")
+		require.NoError(t, err)
+
+		connStore, err := dest.OpenStore("didexchange")
+		require.NoError(t, err)
+
+		_, err = connStore.Get("conn_2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+func TestExportImportLeveldb(t *testing.T) {
+	path, cleanup := setupLeveldb(t)
+	defer cleanup()
+
+	source := leveldb.NewProvider(path + "/source")
+
+	store, err := source.OpenStore("didexchange")
+	require.NoError(t, err)
+
+	queryable, ok := store.(storage.QueryableStore)
+	require.True(t, ok)
+
+	require.NoError(t, queryable.PutWithTags("conn_1", []byte("record1"), storage.Tag{Name: "state", Value: "completed"}))
+	require.NoError(t, storage.PutWithTTL(store, "conn_2", []byte("record2"), time.Hour))
+	// sorts after the "~" bound of prefix ranges
+	require.NoError(t, store.Put("~conn_3", []byte("record3")))
+
+	var buf bytes.Buffer
+
+	err = Export(source, &buf)
+	require.NoError(t, err)
+
+	a := &archive{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), a))
+
+	// neither the index entries of the stores nor the missing namespaces are exported
+	require.Len(t, a.Namespaces, 1)
+	require.Len(t, a.Namespaces[0].Records, 3)
+
+	// exporting doesn't create the missing stores
+	exists, err := storage.StoreExists(source, "kmsdb")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, source.Close())
+
+	dest := leveldb.NewProvider(path + "/dest")
+	defer func() { require.NoError(t, dest.Close()) }()
+
+	err = Import(dest, &buf)
+	require.NoError(t, err)
+
+	store, err = dest.OpenStore("didexchange")
+	require.NoError(t, err)
+
+	itr, err := store.(storage.QueryableStore).Query("state", "completed")
+	require.NoError(t, err)
+	require.True(t, itr.Next())
+	require.Equal(t, "conn_1", string(itr.Key()))
+
+	expiresAt, err := storage.Expiry(store, "conn_2")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	v, err := store.Get("~conn_3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("record3"), v)
+}
+
+func TestImportExpiredRecords(t *testing.T) {
+	expiresAt := time.Now().Add(-time.Minute)
+
+	a := &archive{Version: archiveVersion, Namespaces: []*namespace{{
+		Name:    "didexchange",
+		Records: []*record{{Key: "conn_1", Value: []byte("record1"), ExpiresAt: &expiresAt}},
+	}}}
+
+	archived, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	dest := mem.NewProvider()
+
+	err = Import(dest, bytes.NewReader(archived))
+	require.NoError(t, err)
+
+	store, err := dest.OpenStore("didexchange")
+	require.NoError(t, err)
+
+	_, err = store.Get("conn_1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+}
+
+func setupLeveldb(t *testing.T) (string, func()) {
+	t.Helper()
+
+	path, err := ioutil.TempDir("", "migrate")
+	require.NoError(t, err)
+
+	return path, func() {
+		require.NoError(t, os.RemoveAll(path))
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	t.Run("open store error", func(t *testing.T) {
+		err := Export(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}, &bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open error")
+	})
+
+	t.Run("iterator error", func(t *testing.T) {
+		p := mockstorage.NewMockStoreProvider()
+		p.Store.ErrItr = errors.New("iterator error")
+
+		err := Export(p, &bytes.Buffer{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator error")
+	})
+}
+
+func TestImportErrors(t *testing.T) {
+	t.Run("invalid archive", func(t *testing.T) {
+		err := Import(mem.NewProvider(), bytes.NewBufferString("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read archive")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		err := Import(mem.NewProvider(), bytes.NewBufferString(`{"version":2}`))
+		require.EqualError(t, err, "unsupported archive version 2")
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := Export(newSourceProvider(t), &buf)
+		require.NoError(t, err)
+
+		err = Import(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}, &buf)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open error")
+	})
+
+	t.Run("record with tags and expiration", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+
+		a := &archive{Version: archiveVersion, Namespaces: []*namespace{{
+			Name: "didexchange",
+			Records: []*record{{
+				Key: "conn_1", Value: []byte("record1"),
+				Tags:      []storage.Tag{{Name: "state", Value: "completed"}},
+				ExpiresAt: &expiresAt,
+			}},
+		}}}
+
+		archived, err := json.Marshal(a)
+		require.NoError(t, err)
+
+		dest := mem.NewProvider()
+
+		err = Import(dest, bytes.NewReader(archived))
+		require.EqualError(t, err,
+			"failed to import conn_1 in store didexchange: record has both tags and an expiration")
+
+		store, err := dest.OpenStore("didexchange")
+		require.NoError(t, err)
+
+		_, err = store.Get("conn_1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
@@ -0,0 +1,337 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/storage/sql")
+
+const (
+	// SQLite is the driver name of the SQLite dialect (github.com/mattn/go-sqlite3).
+	SQLite = "sqlite3"
+	// PostgreSQL is the driver name of the PostgreSQL dialect (github.com/lib/pq).
+	PostgreSQL = "postgres"
+
+	blankDriverErrMsg = "driver name for new SQL provider can't be blank"
+	blankDSNErrMsg    = "data source name for new SQL provider can't be blank"
+
+	// endKeySuffix replaces storage.EndKeySuffix in range queries, keys are compared byte-wise
+	endKeySuffix = "~"
+)
+
+// dialect holds the statements which differ between the supported databases.
+type dialect struct {
+	createTable string
+	put         string
+	get         string
+	iterate     string
+	iterateAll  string
+	delete      string
+	// maxOpenConns limits the connection pool, 0 means unlimited
+	maxOpenConns int
+}
+
+// nolint:gochecknoglobals
+var dialects = map[string]*dialect{
+	SQLite: {
+		createTable: "CREATE TABLE IF NOT EXISTS %s (key TEXT NOT NULL PRIMARY KEY, value BLOB NOT NULL)",
+		put: "INSERT INTO %s (key, value) VALUES (?, ?) " +
+			"ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		get:        "SELECT value FROM %s WHERE key = ?",
+		iterate:    "SELECT key, value FROM %s WHERE key >= ? AND key < ? ORDER BY key",
+		iterateAll: "SELECT key, value FROM %s ORDER BY key",
+		delete:     "DELETE FROM %s WHERE key = ?",
+		// SQLite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+		maxOpenConns: 1,
+	},
+	PostgreSQL: {
+		// "C" collation makes text comparison byte-wise, as required by the iterator range semantics
+		createTable: `CREATE TABLE IF NOT EXISTS %s (key TEXT COLLATE "C" NOT NULL PRIMARY KEY, value BYTEA NOT NULL)`,
+		put: "INSERT INTO %s (key, value) VALUES ($1, $2) " +
+			"ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		get:        "SELECT value FROM %s WHERE key = $1",
+		iterate:    "SELECT key, value FROM %s WHERE key >= $1 AND key < $2 ORDER BY key",
+		iterateAll: "SELECT key, value FROM %s ORDER BY key",
+		delete:     "DELETE FROM %s WHERE key = $1",
+	},
+}
+
+// Provider represents a SQL database implementation of the storage.Provider interface.
+// Every store (namespace) is kept in its own table.
+type Provider struct {
+	db          *sql.DB
+	dialect     *dialect
+	tablePrefix string
+	dbs         map[string]*sqlStore
+	sync.RWMutex
+}
+
+// Option configures the SQL provider
+type Option func(opts *Provider)
+
+// WithTablePrefix option is for adding prefix to table names
+func WithTablePrefix(tablePrefix string) Option {
+	return func(opts *Provider) {
+		opts.tablePrefix = tablePrefix
+	}
+}
+
+// NewProvider instantiates Provider. The driver for the given driverName must be registered by the caller,
+// for example by importing github.com/mattn/go-sqlite3 (SQLite) or github.com/lib/pq (PostgreSQL).
+func NewProvider(driverName, dataSourceName string, opts ...Option) (*Provider, error) {
+	if driverName == "" {
+		return nil, errors.New(blankDriverErrMsg)
+	}
+
+	if dataSourceName == "" {
+		return nil, errors.New(blankDSNErrMsg)
+	}
+
+	d, ok := dialects[driverName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SQL driver: %s", driverName)
+	}
+
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	db.SetMaxOpenConns(d.maxOpenConns)
+
+	p := &Provider{db: db, dialect: d, dbs: make(map[string]*sqlStore)}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// OpenStore opens and returns a store for given name space, creating its table if it doesn't exist.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	name = strings.ToLower(name)
+
+	store, ok := p.dbs[name]
+	if ok {
+		return store, nil
+	}
+
+	table := p.tableName(name)
+
+	_, err := p.db.Exec(fmt.Sprintf(p.dialect.createTable, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", table, err)
+	}
+
+	store = &sqlStore{db: p.db, dialect: p.dialect, table: table}
+	p.dbs[name] = store
+
+	return store, nil
+}
+
+// CloseStore closes the store of given name space
+func (p *Provider) CloseStore(name string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.dbs, strings.ToLower(name))
+
+	return nil
+}
+
+// Close closes all stores created under this store provider and the underlying database
+func (p *Provider) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.dbs = make(map[string]*sqlStore)
+
+	return p.db.Close()
+}
+
+func (p *Provider) tableName(name string) string {
+	if p.tablePrefix != "" {
+		name = p.tablePrefix + "_" + name
+	}
+
+	return quoteIdentifier(name)
+}
+
+// quoteIdentifier quotes a table name, store names aren't restricted to valid SQL identifiers
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect *dialect
+	table   string
+}
+
+// Put stores the key and the record
+func (s *sqlStore) Put(k string, v []byte) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf(s.dialect.put, s.table), k, v)
+	if err != nil {
+		return fmt.Errorf("failed to store data: %w", err)
+	}
+
+	return nil
+}
+
+// Get fetches the record based on key
+func (s *sqlStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, errors.New("key is mandatory")
+	}
+
+	var v []byte
+
+	err := s.db.QueryRow(fmt.Sprintf(s.dialect.get, s.table), k).Scan(&v)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get data: %w", err)
+	}
+
+	return v, nil
+}
+
+// Iterator returns iterator for the latest snapshot of the underlying db.
+// The whole range is read upfront so that the connection isn't held while the caller iterates.
+func (s *sqlStore) Iterator(startKey, endKey string) storage.StoreIterator {
+	rows, err := s.queryRange(startKey, endKey)
+	if err != nil {
+		return &sqlIterator{err: fmt.Errorf("failed to query data: %w", err)}
+	}
+
+	defer func() {
+		if e := rows.Close(); e != nil {
+			logger.Warnf("failed to close rows: %s", e)
+		}
+	}()
+
+	itr := &sqlIterator{}
+
+	for rows.Next() {
+		var item [2][]byte
+
+		if e := rows.Scan(&item[0], &item[1]); e != nil {
+			return &sqlIterator{err: fmt.Errorf("failed to read data: %w", e)}
+		}
+
+		itr.items = append(itr.items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return &sqlIterator{err: fmt.Errorf("failed to read data: %w", err)}
+	}
+
+	return itr
+}
+
+// queryRange selects the records of the key range, the whole table for the whole key range so that the keys sorting
+// after endKeySuffix are included
+func (s *sqlStore) queryRange(startKey, endKey string) (*sql.Rows, error) {
+	if startKey == "" && endKey == storage.EndKeySuffix {
+		return s.db.Query(fmt.Sprintf(s.dialect.iterateAll, s.table))
+	}
+
+	return s.db.Query(fmt.Sprintf(s.dialect.iterate, s.table),
+		startKey, strings.ReplaceAll(endKey, storage.EndKeySuffix, endKeySuffix))
+}
+
+// Delete will delete record with k key
+func (s *sqlStore) Delete(k string) error {
+	if k == "" {
+		return errors.New("key is mandatory")
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf(s.dialect.delete, s.table), k)
+	if err != nil {
+		return fmt.Errorf("failed to delete data: %w", err)
+	}
+
+	return nil
+}
+
+type sqlIterator struct {
+	currentIndex int
+	currentItem  *[2][]byte
+	items        [][2][]byte
+	err          error
+}
+
+// Next moves pointer to next value of iterator.
+// It returns false if the iterator is exhausted.
+func (i *sqlIterator) Next() bool {
+	if i.currentIndex >= len(i.items) {
+		i.currentItem = nil
+
+		return false
+	}
+
+	i.currentItem = &i.items[i.currentIndex]
+	i.currentIndex++
+
+	return true
+}
+
+// Release releases associated resources.
+func (i *sqlIterator) Release() {
+	i.currentIndex = 0
+	i.currentItem = nil
+	i.items = nil
+}
+
+// Error returns error in iterator.
+func (i *sqlIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key/value pair.
+func (i *sqlIterator) Key() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[0]
+}
+
+// Value returns the value of the current key/value pair.
+func (i *sqlIterator) Value() []byte {
+	if i.currentItem == nil {
+		return nil
+	}
+
+	return i.currentItem[1]
+}
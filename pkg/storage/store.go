@@ -6,7 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package storage
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // EndKeySuffix end key suffix
 const EndKeySuffix = "!!"
@@ -42,6 +45,8 @@ type Store interface {
 	// startKey: Start of the key range, include in the range.
 	// endKey: End of the key range, not include in the range.
 	//
+	// An empty startKey with EndKeySuffix as endKey iterates over every record of the store.
+	//
 	// Returns:
 	//
 	// StoreIterator: iterator for result range
@@ -75,3 +80,268 @@ type StoreIterator interface {
 	// its contents may change on the next call to any 'seeks method'.
 	Value() []byte
 }
+
+// Tag is a name/value pair associated with a record, used to look the record up without knowing its key.
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// QueryableStore is an optional extension of Store for stores which can find records by their tags.
+// Tag names are unique per record; if a name is given more than once, the last value wins.
+type QueryableStore interface {
+	Store
+
+	// PutWithTags stores the key and the record along with the given tags.
+	// Tags previously associated with the key are replaced. A plain Put removes them.
+	PutWithTags(k string, v []byte, tags ...Tag) error
+
+	// GetTags fetches the tags associated with the record of the given key
+	GetTags(k string) ([]Tag, error)
+
+	// Query returns an iterator over the records having a tag with the given name and, unless it is empty,
+	// the given value. Results come in a stable order so they can be paged through using QueryOption.
+	Query(name, value string, opts ...QueryOption) (StoreIterator, error)
+}
+
+// QueryOptions holds the options of QueryableStore.Query
+type QueryOptions struct {
+	// PageSize is the maximum number of records returned, zero means no limit
+	PageSize int
+	// Offset is the number of matching records to skip
+	Offset int
+}
+
+// QueryOption configures QueryableStore.Query
+type QueryOption func(opts *QueryOptions)
+
+// WithPageSize option limits the number of records returned by a query
+func WithPageSize(size int) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.PageSize = size
+	}
+}
+
+// WithOffset option skips the given number of matching records
+func WithOffset(offset int) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.Offset = offset
+	}
+}
+
+// NewQueryOptions applies the given options, it is meant to be used by QueryableStore implementations
+func NewQueryOptions(opts ...QueryOption) (*QueryOptions, error) {
+	options := &QueryOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.PageSize < 0 || options.Offset < 0 {
+		return nil, errors.New("page size and offset can't be negative")
+	}
+
+	return options, nil
+}
+
+// Batch collects writes to a store so that they are applied together by Commit.
+type Batch interface {
+	// Put adds the storing of the key and the record to the batch
+	Put(k string, v []byte)
+
+	// Delete adds the deletion of the record with k key to the batch
+	Delete(k string)
+
+	// Commit applies the writes of the batch in the order they were added.
+	// A batch shouldn't be used anymore once committed.
+	Commit() error
+}
+
+// BatchableStore is an optional extension of Store for stores which can apply several writes in one operation.
+type BatchableStore interface {
+	Store
+
+	// NewBatch returns an empty batch for the store. Unless the implementation documents otherwise,
+	// either all of its writes are applied by Commit or none is.
+	NewBatch() Batch
+}
+
+// NewBatch returns a batch for the given store. If the store doesn't implement BatchableStore, the returned
+// batch applies its writes one after the other on Commit and stops at the first failure, without atomicity.
+func NewBatch(s Store) Batch {
+	if bs, ok := s.(BatchableStore); ok {
+		return bs.NewBatch()
+	}
+
+	return &sequentialBatch{store: s}
+}
+
+// ExpiringStore is an optional extension of Store for stores which can expire records.
+// Expired records are neither returned by Get nor by iterators, and are removed in the background.
+type ExpiringStore interface {
+	Store
+
+	// PutWithTTL stores the key and the record, the record expires once ttl has elapsed.
+	// Writing the key again through any other method removes the expiration.
+	PutWithTTL(k string, v []byte, ttl time.Duration) error
+
+	// Expiry fetches the time the record of the given key expires at, the zero time if it doesn't expire
+	Expiry(k string) (time.Time, error)
+}
+
+// ExpiringBatch is an optional extension of Batch for batches which can write expiring records.
+type ExpiringBatch interface {
+	Batch
+
+	// PutWithTTL adds the storing of an expiring record to the batch, see ExpiringStore.PutWithTTL
+	PutWithTTL(k string, v []byte, ttl time.Duration)
+}
+
+// PutWithTTL stores a record expiring once ttl has elapsed if the store implements ExpiringStore.
+// Otherwise the record is stored with Put and doesn't expire.
+func PutWithTTL(s Store, k string, v []byte, ttl time.Duration) error {
+	if es, ok := s.(ExpiringStore); ok {
+		return es.PutWithTTL(k, v, ttl)
+	}
+
+	return s.Put(k, v)
+}
+
+// Expiry fetches the time the record of the given key expires at if the store implements ExpiringStore.
+// Otherwise it returns the zero time, records don't expire.
+func Expiry(s Store, k string) (time.Time, error) {
+	if es, ok := s.(ExpiringStore); ok {
+		return es.Expiry(k)
+	}
+
+	_, err := s.Get(k)
+
+	return time.Time{}, err
+}
+
+// TaggingBatch is an optional extension of Batch for batches which can write tagged records.
+type TaggingBatch interface {
+	Batch
+
+	// PutWithTags adds the storing of a record along with its tags to the batch, see QueryableStore.PutWithTags
+	PutWithTags(k string, v []byte, tags ...Tag)
+}
+
+// BatchPutWithTags adds the storing of a tagged record to the batch if it implements TaggingBatch.
+// Otherwise the record is added with Put, without its tags.
+func BatchPutWithTags(b Batch, k string, v []byte, tags ...Tag) {
+	if tb, ok := b.(TaggingBatch); ok {
+		tb.PutWithTags(k, v, tags...)
+
+		return
+	}
+
+	b.Put(k, v)
+}
+
+// BatchPutWithTTL adds the storing of an expiring record to the batch if it implements ExpiringBatch.
+// Otherwise the record is added with Put and doesn't expire.
+func BatchPutWithTTL(b Batch, k string, v []byte, ttl time.Duration) {
+	if eb, ok := b.(ExpiringBatch); ok {
+		eb.PutWithTTL(k, v, ttl)
+
+		return
+	}
+
+	b.Put(k, v)
+}
+
+// Change is a write of a record notified to the watchers of a store
+type Change struct {
+	Key string
+	// Value is the new value of the record, nil if it was deleted
+	Value   []byte
+	Deleted bool
+}
+
+// WatchableStore is an optional extension of Store for stores which notify the writes of their records.
+type WatchableStore interface {
+	Store
+
+	// Watch returns a channel receiving the changes of the records whose key starts with prefix (all the records
+	// if prefix is empty), and a function to stop watching which closes the channel.
+	// Writers don't wait for the changes to be received. The channel is also closed when the store is closed.
+	// Records removed because they expired are notified as deletions.
+	Watch(prefix string) (<-chan Change, func(), error)
+}
+
+// ErrWatchNotSupported is returned by Watch for stores which don't implement WatchableStore
+var ErrWatchNotSupported = errors.New("store doesn't support watching")
+
+// Watch watches the records of the store whose key starts with prefix if it implements WatchableStore,
+// otherwise it returns ErrWatchNotSupported.
+func Watch(s Store, prefix string) (<-chan Change, func(), error) {
+	if ws, ok := s.(WatchableStore); ok {
+		return ws.Watch(prefix)
+	}
+
+	return nil, nil, ErrWatchNotSupported
+}
+
+// StoreChecker is an optional extension of Provider for providers which can tell whether a store exists, without
+// creating it as OpenStore does.
+type StoreChecker interface {
+	Provider
+
+	// StoreExists tells whether the store of given name space exists
+	StoreExists(name string) (bool, error)
+}
+
+// ErrStoreCheckNotSupported is returned by StoreExists for providers which don't implement StoreChecker
+var ErrStoreCheckNotSupported = errors.New("provider doesn't support checking stores")
+
+// StoreExists tells whether provider p has the store of given name space if it implements StoreChecker,
+// otherwise it returns ErrStoreCheckNotSupported.
+func StoreExists(p Provider, name string) (bool, error) {
+	if sc, ok := p.(StoreChecker); ok {
+		return sc.StoreExists(name)
+	}
+
+	return false, ErrStoreCheckNotSupported
+}
+
+// sequentialBatch is the fallback Batch of stores which don't support batches
+type sequentialBatch struct {
+	store Store
+	ops   []func() error
+}
+
+func (b *sequentialBatch) Put(k string, v []byte) {
+	b.ops = append(b.ops, func() error { return b.store.Put(k, v) })
+}
+
+func (b *sequentialBatch) PutWithTTL(k string, v []byte, ttl time.Duration) {
+	b.ops = append(b.ops, func() error { return PutWithTTL(b.store, k, v, ttl) })
+}
+
+// PutWithTags stores the tagged record with the store if it implements QueryableStore, or with Put otherwise
+func (b *sequentialBatch) PutWithTags(k string, v []byte, tags ...Tag) {
+	b.ops = append(b.ops, func() error {
+		if qs, ok := b.store.(QueryableStore); ok {
+			return qs.PutWithTags(k, v, tags...)
+		}
+
+		return b.store.Put(k, v)
+	})
+}
+
+func (b *sequentialBatch) Delete(k string) {
+	b.ops = append(b.ops, func() error { return b.store.Delete(k) })
+}
+
+func (b *sequentialBatch) Commit() error {
+	for _, op := range b.ops {
+		if err := op(); err != nil {
+			return err
+		}
+	}
+
+	b.ops = nil
+
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNewBatch(t *testing.T) {
+	t.Run("batchable store", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		batch := storage.NewBatch(store)
+		batch.Put("k1", []byte("v1"))
+		batch.Put("k2", nil)
+
+		// the native batch validates all the writes upfront
+		require.Error(t, batch.Commit())
+
+		_, err = store.Get("k1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("store without batch support", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{"k1": []byte("v1")}}
+
+		batch := storage.NewBatch(store)
+		batch.Put("k2", []byte("v2"))
+		batch.Delete("k1")
+		require.NoError(t, batch.Commit())
+
+		require.Equal(t, map[string][]byte{"k2": []byte("v2")}, store.Store)
+	})
+
+	t.Run("writes stop at the first failure", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		batch := storage.NewBatch(store)
+		batch.Put("k1", []byte("v1"))
+		batch.Put("", []byte("v2"))
+		batch.Put("k3", []byte("v3"))
+		require.EqualError(t, batch.Commit(), "key is mandatory")
+
+		require.Equal(t, map[string][]byte{"k1": []byte("v1")}, store.Store)
+	})
+}
+
+func TestPutWithTTL(t *testing.T) {
+	t.Run("expiring store", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		require.EqualError(t, storage.PutWithTTL(store, "k1", []byte("v1"), 0), "ttl must be positive")
+		require.NoError(t, storage.PutWithTTL(store, "k1", []byte("v1"), time.Hour))
+
+		batch := storage.NewBatch(store)
+		storage.BatchPutWithTTL(batch, "k2", []byte("v2"), 0)
+		require.EqualError(t, batch.Commit(), "ttl must be positive")
+	})
+
+	t.Run("store without expiration support", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		require.NoError(t, storage.PutWithTTL(store, "k1", []byte("v1"), time.Hour))
+
+		batch := storage.NewBatch(store)
+		storage.BatchPutWithTTL(batch, "k2", []byte("v2"), time.Hour)
+		require.NoError(t, batch.Commit())
+
+		require.Equal(t, map[string][]byte{"k1": []byte("v1"), "k2": []byte("v2")}, store.Store)
+	})
+}
+
+func TestBatchPutWithTags(t *testing.T) {
+	t.Run("tagging batch", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		batch := storage.NewBatch(store)
+		storage.BatchPutWithTags(batch, "k1", []byte("v1"), storage.Tag{Name: "tag", Value: "value"})
+		require.NoError(t, batch.Commit())
+
+		tags, err := store.(storage.QueryableStore).GetTags("k1")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "tag", Value: "value"}}, tags)
+
+		batch = storage.NewBatch(store)
+		storage.BatchPutWithTags(batch, "k2", []byte("v2"), storage.Tag{Value: "value"})
+		require.EqualError(t, batch.Commit(), "tag name is mandatory")
+	})
+
+	t.Run("store without tag support", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		batch := storage.NewBatch(store)
+		storage.BatchPutWithTags(batch, "k1", []byte("v1"), storage.Tag{Name: "tag", Value: "value"})
+		require.NoError(t, batch.Commit())
+
+		require.Equal(t, map[string][]byte{"k1": []byte("v1")}, store.Store)
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	t.Run("expiring store", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.PutWithTTL(store, "k1", []byte("v1"), time.Hour))
+
+		expiresAt, err := storage.Expiry(store, "k1")
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	})
+
+	t.Run("store without expiration support", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{"k1": []byte("v1")}}
+
+		expiresAt, err := storage.Expiry(store, "k1")
+		require.NoError(t, err)
+		require.True(t, expiresAt.IsZero())
+
+		_, err = storage.Expiry(store, "k2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+}
+
+func TestStoreExists(t *testing.T) {
+	t.Run("store checker", func(t *testing.T) {
+		p := mem.NewProvider()
+
+		exists, err := storage.StoreExists(p, "test")
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		_, err = p.OpenStore("test")
+		require.NoError(t, err)
+
+		exists, err = storage.StoreExists(p, "TEST")
+		require.NoError(t, err)
+		require.True(t, exists)
+	})
+
+	t.Run("provider without store checks", func(t *testing.T) {
+		_, err := storage.StoreExists(mockstorage.NewMockStoreProvider(), "test")
+		require.True(t, errors.Is(err, storage.ErrStoreCheckNotSupported))
+	})
+}
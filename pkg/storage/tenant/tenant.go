@@ -0,0 +1,355 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tenant provides tenant-scoped storage providers sharing one underlying storage.Provider, so that the
+// framework contexts of several agents can be hosted in a single process on top of the same LevelDB or CouchDB:
+//
+//	manager, err := tenant.NewManager(leveldbProvider)
+//	tenantProvider, err := manager.CreateTenant("acme")
+//	framework, err := aries.New(aries.WithStoreProvider(tenantProvider))
+//
+// The store of a tenant for a namespace is the store of the underlying provider named after the tenant ID,
+// NamespaceSeparator and the namespace. Tenant IDs are made of lowercase letters, digits and dashes so that the
+// names of the stores of two tenants never collide and are valid database names for every provider.
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// RegistryNamespace is the namespace of the underlying provider where the tenants are recorded
+	RegistryNamespace = "tenants"
+
+	// NamespaceSeparator separates the tenant ID from the namespace in the names of the underlying stores
+	NamespaceSeparator = "_"
+
+	tenantKeyPrefix = "tenant_"
+)
+
+// ErrTenantNotFound is returned when a tenant doesn't exist or was deleted
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// ErrTenantExists is returned when creating a tenant which already exists
+var ErrTenantExists = errors.New("tenant already exists")
+
+//nolint:gochecknoglobals
+var tenantIDPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// tenantRecord is what gets stored in the registry for a tenant
+type tenantRecord struct {
+	// Namespaces are the namespaces ever opened by the tenant, their stores are cleared when it's deleted
+	Namespaces []string `json:"namespaces"`
+}
+
+// Manager creates, lists and deletes the tenants of an underlying storage.Provider
+type Manager struct {
+	provider storage.Provider
+	registry storage.Store
+	// tenants are the providers handed out, there is one per tenant
+	tenants map[string]*Provider
+	lock    sync.Mutex
+}
+
+// NewManager instantiates Manager
+func NewManager(p storage.Provider) (*Manager, error) {
+	registry, err := p.OpenStore(RegistryNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tenant registry: %w", err)
+	}
+
+	return &Manager{provider: p, registry: registry, tenants: make(map[string]*Provider)}, nil
+}
+
+// CreateTenant records a new tenant and returns its provider
+func (m *Manager) CreateTenant(id string) (*Provider, error) {
+	if !tenantIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid tenant ID %q: it must start with a lowercase letter and only contain "+
+			"lowercase letters, digits and dashes", id)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	_, err := m.getTenant(id)
+	if err == nil {
+		return nil, ErrTenantExists
+	}
+
+	if !errors.Is(err, ErrTenantNotFound) {
+		return nil, err
+	}
+
+	rec := &tenantRecord{Namespaces: []string{}}
+
+	if err = m.putTenant(id, rec); err != nil {
+		return nil, err
+	}
+
+	return m.newProvider(id, rec), nil
+}
+
+// Provider returns the provider of an existing tenant
+func (m *Manager) Provider(id string) (*Provider, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if p, ok := m.tenants[id]; ok {
+		return p, nil
+	}
+
+	rec, err := m.getTenant(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.newProvider(id, rec), nil
+}
+
+// Tenants returns the sorted IDs of the tenants
+func (m *Manager) Tenants() ([]string, error) {
+	itr := m.registry.Iterator(tenantKeyPrefix, tenantKeyPrefix+storage.EndKeySuffix)
+	defer itr.Release()
+
+	ids := []string{}
+
+	for itr.Next() {
+		ids = append(ids, strings.TrimPrefix(string(itr.Key()), tenantKeyPrefix))
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// DeleteTenant deletes the records of all the stores of a tenant, closes them and removes the tenant.
+// The provider of the tenant can't open stores anymore.
+func (m *Manager) DeleteTenant(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	rec, err := m.getTenant(id)
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range rec.Namespaces {
+		if err = m.clearStore(storeName(id, ns)); err != nil {
+			return fmt.Errorf("failed to clear store %s of tenant %s: %w", ns, id, err)
+		}
+	}
+
+	if err = m.registry.Delete(tenantKeyPrefix + id); err != nil {
+		return fmt.Errorf("failed to delete tenant %s: %w", id, err)
+	}
+
+	if p, ok := m.tenants[id]; ok {
+		p.deleted = true
+		p.stores = make(map[string]struct{})
+
+		delete(m.tenants, id)
+	}
+
+	return nil
+}
+
+func (m *Manager) newProvider(id string, rec *tenantRecord) *Provider {
+	p := &Provider{
+		manager:    m,
+		id:         id,
+		namespaces: make(map[string]struct{}),
+		stores:     make(map[string]struct{}),
+	}
+
+	for _, ns := range rec.Namespaces {
+		p.namespaces[ns] = struct{}{}
+	}
+
+	m.tenants[id] = p
+
+	return p
+}
+
+func (m *Manager) getTenant(id string) (*tenantRecord, error) {
+	b, err := m.registry.Get(tenantKeyPrefix + id)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return nil, ErrTenantNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant %s: %w", id, err)
+	}
+
+	rec := &tenantRecord{}
+
+	if err = json.Unmarshal(b, rec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tenant %s: %w", id, err)
+	}
+
+	return rec, nil
+}
+
+func (m *Manager) putTenant(id string, rec *tenantRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant %s: %w", id, err)
+	}
+
+	if err = m.registry.Put(tenantKeyPrefix+id, b); err != nil {
+		return fmt.Errorf("failed to put tenant %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// clearStore deletes all the records of an underlying store and closes it
+func (m *Manager) clearStore(name string) error {
+	store, err := m.provider.OpenStore(name)
+	if err != nil {
+		return err
+	}
+
+	itr := store.Iterator("", storage.EndKeySuffix)
+
+	batch := storage.NewBatch(store)
+
+	for itr.Next() {
+		batch.Delete(string(itr.Key()))
+	}
+
+	err = itr.Error()
+
+	itr.Release()
+
+	if err != nil {
+		return err
+	}
+
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+
+	return m.provider.CloseStore(name)
+}
+
+// Provider is the storage.Provider of a tenant, its stores are stores of the underlying provider
+type Provider struct {
+	manager *Manager
+	id      string
+	// namespaces are the namespaces recorded for the tenant
+	namespaces map[string]struct{}
+	// stores are the namespaces opened through this provider
+	stores  map[string]struct{}
+	deleted bool
+}
+
+// TenantID returns the ID of the tenant
+func (p *Provider) TenantID() string {
+	return p.id
+}
+
+// OpenStore opens and returns the store of the tenant for given name space.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.manager.lock.Lock()
+	defer p.manager.lock.Unlock()
+
+	if p.deleted {
+		return nil, ErrTenantNotFound
+	}
+
+	ns := strings.ToLower(name)
+
+	if _, ok := p.namespaces[ns]; !ok {
+		if err := p.recordNamespace(ns); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := p.manager.provider.OpenStore(storeName(p.id, ns))
+	if err != nil {
+		return nil, err
+	}
+
+	p.stores[ns] = struct{}{}
+
+	return store, nil
+}
+
+// StoreExists tells whether the store of the tenant for given name space exists, if the underlying provider
+// supports it
+func (p *Provider) StoreExists(name string) (bool, error) {
+	p.manager.lock.Lock()
+	defer p.manager.lock.Unlock()
+
+	if p.deleted {
+		return false, ErrTenantNotFound
+	}
+
+	return storage.StoreExists(p.manager.provider, storeName(p.id, strings.ToLower(name)))
+}
+
+// CloseStore closes the store of the tenant for given name space
+func (p *Provider) CloseStore(name string) error {
+	p.manager.lock.Lock()
+	defer p.manager.lock.Unlock()
+
+	ns := strings.ToLower(name)
+
+	delete(p.stores, ns)
+
+	return p.manager.provider.CloseStore(storeName(p.id, ns))
+}
+
+// Close closes all stores opened by this provider, the stores of other tenants are left open
+func (p *Provider) Close() error {
+	p.manager.lock.Lock()
+	defer p.manager.lock.Unlock()
+
+	for ns := range p.stores {
+		if err := p.manager.provider.CloseStore(storeName(p.id, ns)); err != nil {
+			return fmt.Errorf("failed to close store %s of tenant %s: %w", ns, p.id, err)
+		}
+
+		delete(p.stores, ns)
+	}
+
+	return nil
+}
+
+// recordNamespace adds a namespace to the tenant record before its store is first opened, so that it gets cleared
+// when the tenant is deleted
+func (p *Provider) recordNamespace(ns string) error {
+	rec, err := p.manager.getTenant(p.id)
+	if err != nil {
+		return err
+	}
+
+	rec.Namespaces = append(rec.Namespaces, ns)
+
+	if err = p.manager.putTenant(p.id, rec); err != nil {
+		return err
+	}
+
+	p.namespaces[ns] = struct{}{}
+
+	return nil
+}
+
+func storeName(id, ns string) string {
+	return id + NamespaceSeparator + ns
+}
@@ -0,0 +1,290 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestManager(t *testing.T) {
+	t.Run("tenants are isolated", func(t *testing.T) {
+		underlying := mem.NewProvider()
+
+		m, err := NewManager(underlying)
+		require.NoError(t, err)
+
+		acme, err := m.CreateTenant("acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme", acme.TenantID())
+
+		globex, err := m.CreateTenant("globex-2")
+		require.NoError(t, err)
+
+		acmeStore, err := acme.OpenStore("didexchange")
+		require.NoError(t, err)
+
+		globexStore, err := globex.OpenStore("DIDExchange")
+		require.NoError(t, err)
+
+		require.NoError(t, acmeStore.Put("conn_1", []byte("acme")))
+		require.NoError(t, globexStore.Put("conn_1", []byte("globex")))
+
+		v, err := acmeStore.Get("conn_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("acme"), v)
+
+		v, err = globexStore.Get("conn_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("globex"), v)
+
+		// the stores of the tenants are prefixed stores of the underlying provider
+		s, err := underlying.OpenStore("acme_didexchange")
+		require.NoError(t, err)
+
+		v, err = s.Get("conn_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("acme"), v)
+
+		ids, err := m.Tenants()
+		require.NoError(t, err)
+		require.Equal(t, []string{"acme", "globex-2"}, ids)
+
+		// same provider is returned for a tenant
+		p, err := m.Provider("acme")
+		require.NoError(t, err)
+		require.Equal(t, acme, p)
+	})
+
+	t.Run("tenants are kept by the underlying provider", func(t *testing.T) {
+		underlying := mem.NewProvider()
+
+		m, err := NewManager(underlying)
+		require.NoError(t, err)
+
+		_, err = m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		m, err = NewManager(underlying)
+		require.NoError(t, err)
+
+		ids, err := m.Tenants()
+		require.NoError(t, err)
+		require.Equal(t, []string{"acme"}, ids)
+
+		p, err := m.Provider("acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme", p.TenantID())
+
+		_, err = m.Provider("unknown")
+		require.True(t, errors.Is(err, ErrTenantNotFound))
+	})
+
+	t.Run("create tenant errors", func(t *testing.T) {
+		m, err := NewManager(mem.NewProvider())
+		require.NoError(t, err)
+
+		for _, id := range []string{"", "Acme", "1acme", "acme_corp", "acme/corp"} {
+			_, err = m.CreateTenant(id)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid tenant ID")
+		}
+
+		_, err = m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		_, err = m.CreateTenant("acme")
+		require.True(t, errors.Is(err, ErrTenantExists))
+	})
+
+	t.Run("delete tenant clears its stores", func(t *testing.T) {
+		underlying := mem.NewProvider()
+
+		m, err := NewManager(underlying)
+		require.NoError(t, err)
+
+		acme, err := m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		globex, err := m.CreateTenant("globex")
+		require.NoError(t, err)
+
+		for _, p := range []*Provider{acme, globex} {
+			for _, ns := range []string{"kmsdb", "didexchange"} {
+				s, e := p.OpenStore(ns)
+				require.NoError(t, e)
+
+				for i := 0; i < 3; i++ {
+					require.NoError(t, s.Put(fmt.Sprintf("key_%d", i), []byte("value")))
+				}
+			}
+		}
+
+		require.NoError(t, m.DeleteTenant("acme"))
+
+		ids, err := m.Tenants()
+		require.NoError(t, err)
+		require.Equal(t, []string{"globex"}, ids)
+
+		_, err = acme.OpenStore("kmsdb")
+		require.True(t, errors.Is(err, ErrTenantNotFound))
+
+		_, err = m.Provider("acme")
+		require.True(t, errors.Is(err, ErrTenantNotFound))
+
+		require.True(t, errors.Is(m.DeleteTenant("acme"), ErrTenantNotFound))
+
+		for _, ns := range []string{"acme_kmsdb", "acme_didexchange"} {
+			s, e := underlying.OpenStore(ns)
+			require.NoError(t, e)
+
+			itr := s.Iterator("", storage.EndKeySuffix)
+			require.False(t, itr.Next())
+			itr.Release()
+		}
+
+		s, err := globex.OpenStore("kmsdb")
+		require.NoError(t, err)
+
+		_, err = s.Get("key_0")
+		require.NoError(t, err)
+
+		// the ID of a deleted tenant can be reused
+		_, err = m.CreateTenant("acme")
+		require.NoError(t, err)
+	})
+
+	t.Run("registry errors", func(t *testing.T) {
+		_, err := NewManager(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")})
+		require.EqualError(t, err, "failed to open tenant registry: open error")
+
+		registry := &mockstorage.MockStore{Store: make(map[string][]byte)}
+
+		m, err := NewManager(mockstorage.NewCustomMockStoreProvider(registry))
+		require.NoError(t, err)
+
+		registry.ErrPut = fmt.Errorf("put error")
+
+		_, err = m.CreateTenant("acme")
+		require.EqualError(t, err, "failed to put tenant acme: put error")
+
+		registry.ErrGet = fmt.Errorf("get error")
+
+		_, err = m.CreateTenant("acme")
+		require.EqualError(t, err, "failed to get tenant acme: get error")
+
+		_, err = m.Provider("acme")
+		require.EqualError(t, err, "failed to get tenant acme: get error")
+
+		require.EqualError(t, m.DeleteTenant("acme"), "failed to get tenant acme: get error")
+
+		registry.ErrItr = fmt.Errorf("iterator error")
+
+		_, err = m.Tenants()
+		require.EqualError(t, err, "failed to list tenants: iterator error")
+
+		registry.ErrGet = nil
+		registry.Store[tenantKeyPrefix+"acme"] = []byte("{")
+
+		_, err = m.Provider("acme")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal tenant acme")
+	})
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("close stores of the tenant", func(t *testing.T) {
+		underlying := mem.NewProvider()
+
+		m, err := NewManager(underlying)
+		require.NoError(t, err)
+
+		acme, err := m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		_, err = acme.OpenStore("kmsdb")
+		require.NoError(t, err)
+
+		_, err = acme.OpenStore("didexchange")
+		require.NoError(t, err)
+
+		require.NoError(t, acme.CloseStore("KMSDB"))
+		require.Len(t, acme.stores, 1)
+
+		require.NoError(t, acme.Close())
+		require.Empty(t, acme.stores)
+
+		// the registry is left open
+		_, err = m.Tenants()
+		require.NoError(t, err)
+
+		// stores can be opened again
+		_, err = acme.OpenStore("kmsdb")
+		require.NoError(t, err)
+	})
+
+	t.Run("namespaces are recorded once", func(t *testing.T) {
+		m, err := NewManager(mem.NewProvider())
+		require.NoError(t, err)
+
+		acme, err := m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = acme.OpenStore("kmsdb")
+			require.NoError(t, err)
+		}
+
+		rec, err := m.getTenant("acme")
+		require.NoError(t, err)
+		require.Equal(t, []string{"kmsdb"}, rec.Namespaces)
+	})
+
+	t.Run("open store errors", func(t *testing.T) {
+		registry := &mockstorage.MockStore{Store: make(map[string][]byte)}
+
+		m, err := NewManager(mockstorage.NewCustomMockStoreProvider(registry))
+		require.NoError(t, err)
+
+		acme, err := m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		registry.ErrPut = fmt.Errorf("put error")
+
+		_, err = acme.OpenStore("kmsdb")
+		require.EqualError(t, err, "failed to put tenant acme: put error")
+
+		m, err = NewManager(&failingOpenProvider{Provider: mem.NewProvider()})
+		require.NoError(t, err)
+
+		acme, err = m.CreateTenant("acme")
+		require.NoError(t, err)
+
+		_, err = acme.OpenStore("kmsdb")
+		require.EqualError(t, err, "open error")
+	})
+}
+
+// failingOpenProvider only opens the tenant registry
+type failingOpenProvider struct {
+	storage.Provider
+}
+
+func (p *failingOpenProvider) OpenStore(name string) (storage.Store, error) {
+	if name != RegistryNamespace {
+		return nil, errors.New("open error")
+	}
+
+	return p.Provider.OpenStore(name)
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/internal/watch"
 )
 
 const (
@@ -27,6 +28,9 @@ const (
 	invKeyPrefix        = "inv"
 	eventDataKeyprefix  = "connevent"
 	didConnMapKeyprefix = "didconn_%s,%s"
+	// myDIDTagName and theirDIDTagName are the tags of the completed connection records of queryable stores
+	myDIDTagName    = "myDID"
+	theirDIDTagName = "theirDID"
 	// limitPattern with `~` at the end for lte of given prefix (less than or equal)
 	limitPattern    = "%s" + storage.EndKeySuffix
 	keySeparator    = "_"
@@ -41,6 +45,13 @@ type provider interface {
 	StorageProvider() storage.Provider
 }
 
+// RecordChange is a change of a connection record
+type RecordChange struct {
+	Record *Record
+	// Deleted is set when the record was deleted, only its ConnectionID is set then
+	Deleted bool
+}
+
 // Record contain info about did exchange connection
 type Record struct {
 	ConnectionID    string
@@ -147,6 +158,66 @@ func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 	return records, nil
 }
 
+// WatchConnections returns a channel receiving the changes of the connection records from now on, from the transient
+// store for the connections in progress and from the permanent store for the completed ones, and a function to stop
+// watching which closes the channel. The removal of a completed connection may be received twice. It fails if the
+// underlying stores don't implement storage.WatchableStore.
+func (c *Lookup) WatchConnections() (<-chan *RecordChange, func(), error) {
+	changes := make(chan *RecordChange)
+	prefix := getConnectionKeyPrefix()("")
+
+	stores := []storage.Store{c.transientStore}
+	if c.store != c.transientStore {
+		stores = append(stores, c.store)
+	}
+
+	stop, err := watch.Watch(prefix, func(store storage.Store, change storage.Change, done <-chan struct{}) bool {
+		record, ok := c.recordChange(store, change, prefix)
+		if !ok {
+			return true
+		}
+
+		select {
+		case changes <- record:
+			return true
+		case <-done:
+			return false
+		}
+	}, func() { close(changes) }, stores...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch connection stores: %w", err)
+	}
+
+	return changes, stop, nil
+}
+
+// recordChange returns the connection record change of the change of store, false if it isn't a change of the
+// connection: every record is saved in the transient store before completed records are copied to the permanent
+// store, and transient records are removed or expire while the permanent records are kept.
+func (c *Lookup) recordChange(store storage.Store, change storage.Change, prefix string) (*RecordChange, bool) {
+	if change.Deleted {
+		if store == c.transientStore {
+			if _, err := c.store.Get(change.Key); err == nil {
+				return nil, false
+			}
+		}
+
+		return &RecordChange{Record: &Record{ConnectionID: strings.TrimPrefix(change.Key, prefix)}, Deleted: true}, true
+	}
+
+	if store != c.transientStore {
+		return nil, false
+	}
+
+	var record Record
+
+	if err := json.Unmarshal(change.Value, &record); err != nil {
+		return nil, false
+	}
+
+	return &RecordChange{Record: &record}, true
+}
+
 // GetConnectionRecordAtState return connection record based on the connection ID and state.
 func (c *Lookup) GetConnectionRecordAtState(connectionID, stateID string) (*Record, error) {
 	if stateID == "" {
@@ -181,7 +252,16 @@ func (c *Lookup) GetConnectionRecordByNSThreadID(nsThreadID string) (*Record, er
 }
 
 // GetConnectionIDByDIDs return connection id based on dids (my or their did) metadata.
+// Completed connections are found by their DID tags in queryable stores, and by their did-connection map otherwise,
+// as well as when they were saved before being tagged.
 func (c *Lookup) GetConnectionIDByDIDs(myDID, theirDID string) (string, error) {
+	if qs, ok := c.store.(storage.QueryableStore); ok {
+		connectionID, err := queryConnectionIDByDIDs(qs, myDID, theirDID)
+		if !errors.Is(err, storage.ErrDataNotFound) {
+			return connectionID, err
+		}
+	}
+
 	connectionIDBytes, err := c.store.Get(getDIDConnMapKeyPrefix()(myDID, theirDID))
 	if err != nil {
 		return "", fmt.Errorf("get did-connection map : %w", err)
@@ -190,6 +270,34 @@ func (c *Lookup) GetConnectionIDByDIDs(myDID, theirDID string) (string, error) {
 	return string(connectionIDBytes), nil
 }
 
+// queryConnectionIDByDIDs returns the ID of the connection record of store tagged with theirDID having myDID
+func queryConnectionIDByDIDs(store storage.QueryableStore, myDID, theirDID string) (string, error) {
+	itr, err := store.Query(theirDIDTagName, theirDID)
+	if err != nil {
+		return "", fmt.Errorf("query connection records by DID : %w", err)
+	}
+
+	defer itr.Release()
+
+	for itr.Next() {
+		var record Record
+
+		if err := json.Unmarshal(itr.Value(), &record); err != nil {
+			return "", fmt.Errorf("query connection records by DID : %w", err)
+		}
+
+		if record.MyDID == myDID && record.TheirDID == theirDID {
+			return record.ConnectionID, nil
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return "", fmt.Errorf("query connection records by DID : %w", err)
+	}
+
+	return "", storage.ErrDataNotFound
+}
+
 // GetInvitation finds and parses stored invitation to target type
 // TODO should avoid using target of type `interface{}` [Issue #1030]
 func (c *Lookup) GetInvitation(id string, target interface{}) error {
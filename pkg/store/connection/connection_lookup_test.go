@@ -11,8 +11,10 @@ package connection
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -344,6 +346,78 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 	})
 }
 
+func TestConnectionLookup_WatchConnections(t *testing.T) {
+	t.Run("test saved and removed connection records are received", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		transientStore, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		recorder, err := NewRecorder(&mockProvider{store: store, transientStore: transientStore})
+		require.NoError(t, err)
+
+		changes, stop, err := recorder.WatchConnections()
+		require.NoError(t, err)
+
+		defer stop()
+
+		receive := func() *RecordChange {
+			select {
+			case change := <-changes:
+				return change
+			case <-time.After(time.Second):
+				require.Fail(t, "connection record change not received")
+			}
+
+			return nil
+		}
+
+		record := &Record{ConnectionID: "conn1", ThreadID: "thID1", Namespace: myNSPrefix, State: "requested"}
+		require.NoError(t, recorder.SaveConnectionRecordWithMappings(record))
+		require.Equal(t, &RecordChange{Record: record}, receive())
+
+		record.State = stateNameCompleted
+		require.NoError(t, recorder.SaveConnectionRecord(record))
+		require.Equal(t, &RecordChange{Record: record}, receive())
+
+		require.NoError(t, recorder.RemoveConnection(record.ConnectionID))
+		require.Equal(t, &RecordChange{Record: &Record{ConnectionID: record.ConnectionID}, Deleted: true}, receive())
+	})
+
+	t.Run("test stop closes the channel", func(t *testing.T) {
+		transientStore, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		recorder, err := NewRecorder(&mockProvider{store: transientStore, transientStore: transientStore})
+		require.NoError(t, err)
+
+		changes, stop, err := recorder.WatchConnections()
+		require.NoError(t, err)
+
+		// the record isn't read before stopping
+		require.NoError(t, recorder.SaveConnectionRecord(&Record{ConnectionID: "conn1", State: "requested"}))
+
+		stop()
+		stop()
+
+		for range changes {
+			// the record being delivered when stopping may still be received
+		}
+	})
+
+	t.Run("test error from store not supporting watch", func(t *testing.T) {
+		lookup, err := NewLookup(&mockProvider{})
+		require.NoError(t, err)
+
+		changes, stop, err := lookup.WatchConnections()
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrWatchNotSupported))
+		require.Nil(t, changes)
+		require.Nil(t, stop)
+	})
+}
+
 func TestGetConnectionIDByDIDs(t *testing.T) {
 	myDID := "did:mydid:123"
 	theirDID := "did:theirdid:789"
@@ -378,6 +452,46 @@ func TestGetConnectionIDByDIDs(t *testing.T) {
 		require.Contains(t, err.Error(), "get did-connection map")
 		require.Empty(t, connectionID)
 	})
+	t.Run("get connection record by did - queryable store", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		recorder, err := NewRecorder(&mockProvider{store: store})
+		require.NoError(t, err)
+
+		for _, rec := range []*Record{
+			{ConnectionID: "other", State: stateNameCompleted, MyDID: "did:mydid:456", TheirDID: theirDID},
+			{ConnectionID: sampleConnID, State: stateNameCompleted, MyDID: myDID, TheirDID: theirDID},
+		} {
+			require.NoError(t, recorder.SaveConnectionRecord(rec))
+		}
+
+		connectionID, err := recorder.GetConnectionIDByDIDs(myDID, theirDID)
+		require.NoError(t, err)
+		require.Equal(t, sampleConnID, connectionID)
+
+		// the connections are found by their DID tags, without did-connection map
+		_, err = store.Get(getDIDConnMapKeyPrefix()(myDID, theirDID))
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		tags, err := store.(storage.QueryableStore).GetTags(getConnectionKeyPrefix()(sampleConnID))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []storage.Tag{
+			{Name: myDIDTagName, Value: myDID},
+			{Name: theirDIDTagName, Value: theirDID},
+		}, tags)
+
+		// connections saved before being tagged are found by their did-connection map
+		require.NoError(t, store.Put(getDIDConnMapKeyPrefix()(myDID, "did:theirdid:000"), []byte("untagged")))
+
+		connectionID, err = recorder.GetConnectionIDByDIDs(myDID, "did:theirdid:000")
+		require.NoError(t, err)
+		require.Equal(t, "untagged", connectionID)
+
+		_, err = recorder.GetConnectionIDByDIDs("did:mydid:000", theirDID)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
 }
 
 // mockProvider for connection recorder
@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -25,6 +26,10 @@ const (
 	//  will need to be figured with verification key
 	theirNSPrefix    = "their"
 	errMsgInvalidKey = "invalid key"
+
+	// transientRecordTTL is how long the records of the transient store are kept, an exchange
+	// which isn't completed by then is considered abandoned
+	transientRecordTTL = 7 * 24 * time.Hour
 )
 
 // NewRecorder returns new connection recorder.
@@ -56,29 +61,42 @@ func (c *Recorder) SaveInvitation(id string, invitation interface{}) error {
 
 // SaveConnectionRecord saves given connection records in underlying store
 func (c *Recorder) SaveConnectionRecord(record *Record) error {
-	if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-		record, c.transientStore); err != nil {
-		return fmt.Errorf("save connection record in transient store: %w", err)
+	return c.saveConnectionRecord(record, storage.NewBatch(c.transientStore))
+}
+
+// saveConnectionRecord adds the connection record to the given transient store batch and commits it.
+// Records of completed connections are then saved in the permanent store tagged with their DIDs, along with their
+// DIDs mapping for stores which can't be queried, in a batch of their own.
+func (c *Recorder) saveConnectionRecord(record *Record, transientBatch storage.Batch) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("save connection record: %w", err)
 	}
 
+	storage.BatchPutWithTTL(transientBatch, getConnectionKeyPrefix()(record.ConnectionID), bytes, transientRecordTTL)
+
 	if record.State != "" {
-		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, c.transientStore)
-		if err != nil {
-			return fmt.Errorf("save connection record with state in transient store: %w", err)
-		}
+		storage.BatchPutWithTTL(transientBatch, getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
+			bytes, transientRecordTTL)
+	}
+
+	if err = transientBatch.Commit(); err != nil {
+		return fmt.Errorf("save connection record in transient store: %w", err)
 	}
 
 	if record.State == stateNameCompleted {
-		if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-			record, c.store); err != nil {
-			return fmt.Errorf("save connection record in permanent store: %w", err)
+		batch := storage.NewBatch(c.store)
+		storage.BatchPutWithTags(batch, getConnectionKeyPrefix()(record.ConnectionID), bytes,
+			storage.Tag{Name: myDIDTagName, Value: record.MyDID},
+			storage.Tag{Name: theirDIDTagName, Value: record.TheirDID})
+
+		if _, ok := c.store.(storage.QueryableStore); !ok {
+			// create map between DIDs and ConnectionID
+			batch.Put(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID), []byte(record.ConnectionID))
 		}
 
-		// create map between DIDs and ConnectionID
-		if err := c.store.Put(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID),
-			[]byte(record.ConnectionID)); err != nil {
-			return fmt.Errorf("save did and connection map in store: %w", err)
+		if err = batch.Commit(); err != nil {
+			return fmt.Errorf("save connection record in permanent store: %w", err)
 		}
 	}
 
@@ -86,21 +104,25 @@ func (c *Recorder) SaveConnectionRecord(record *Record) error {
 }
 
 // SaveConnectionRecordWithMappings saves newly created connection record against the connection id in the store
-// and it creates mapping from namespaced ThreadID to connection ID
+// and it creates mapping from namespaced ThreadID to connection ID.
+// The mapping and the connection records are written to the transient store in a single batch.
 func (c *Recorder) SaveConnectionRecordWithMappings(record *Record) error {
 	err := isValidConnection(record)
 	if err != nil {
 		return fmt.Errorf("validation failed while saving connection record with mapping: %w", err)
 	}
 
-	err = c.SaveConnectionRecord(record)
+	key, err := namespaceThreadIDKey(record.ThreadID, record.Namespace)
 	if err != nil {
-		return fmt.Errorf("failed to save connection record with mappings: %w", err)
+		return fmt.Errorf("failed to save connection record with namespace mappings: %w", err)
 	}
 
-	err = c.SaveNamespaceThreadID(record.ThreadID, record.Namespace, record.ConnectionID)
+	batch := storage.NewBatch(c.transientStore)
+	storage.BatchPutWithTTL(batch, key, []byte(record.ConnectionID), transientRecordTTL)
+
+	err = c.saveConnectionRecord(record, batch)
 	if err != nil {
-		return fmt.Errorf("failed to save connection record with namespace mappings: %w", err)
+		return fmt.Errorf("failed to save connection record with mappings: %w", err)
 	}
 
 	return nil
@@ -109,13 +131,23 @@ func (c *Recorder) SaveConnectionRecordWithMappings(record *Record) error {
 // SaveEvent saves event related data for given connection ID
 // TODO connection event data shouldn't be transient [Issues #1029]
 func (c *Recorder) SaveEvent(connectionID string, data []byte) error {
-	return c.transientStore.Put(getEventDataKeyPrefix()(connectionID), data)
+	return storage.PutWithTTL(c.transientStore, getEventDataKeyPrefix()(connectionID), data, transientRecordTTL)
 }
 
 // SaveNamespaceThreadID saves given namespace, threadID and connection ID mapping in transient store
 func (c *Recorder) SaveNamespaceThreadID(threadID, namespace, connectionID string) error {
+	key, err := namespaceThreadIDKey(threadID, namespace)
+	if err != nil {
+		return err
+	}
+
+	return storage.PutWithTTL(c.transientStore, key, []byte(connectionID), transientRecordTTL)
+}
+
+// namespaceThreadIDKey returns the key of the mapping of the given namespace and threadID to a connection ID
+func namespaceThreadIDKey(threadID, namespace string) (string, error) {
 	if namespace != myNSPrefix && namespace != theirNSPrefix {
-		return fmt.Errorf("namespace not supported")
+		return "", fmt.Errorf("namespace not supported")
 	}
 
 	prefix := myNSPrefix
@@ -125,45 +157,47 @@ func (c *Recorder) SaveNamespaceThreadID(threadID, namespace, connectionID strin
 
 	key, err := computeHash([]byte(threadID))
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return c.transientStore.Put(getNamespaceKeyPrefix(prefix)(key), []byte(connectionID))
+	return getNamespaceKeyPrefix(prefix)(key), nil
 }
 
-// RemoveConnection removes connection record from the store for given id
+// RemoveConnection removes connection record from the store for given id.
+// The records are deleted with one batch per store.
 func (c *Recorder) RemoveConnection(connectionID string) error {
 	record, err := c.GetConnectionRecord(connectionID)
 	if err != nil {
 		return fmt.Errorf("unable to get connection record: connectionid=%s err=%w", connectionID, err)
 	}
 
-	if err = c.transientStore.Delete(getConnectionKeyPrefix()(connectionID)); err != nil {
-		return fmt.Errorf("unable to delete connection record from the transient store: connectionid=%s err=%w",
-			connectionID, err)
-	}
+	batch := storage.NewBatch(c.store)
+	batch.Delete(getConnectionKeyPrefix()(connectionID))
+	batch.Delete(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID))
 
-	// remove connection records for different states from transient store
-	err = removeConnectionsForStates(c, connectionID)
+	// remove namespace, threadID and connection ID mapping
+	err = removeMappings(c, record, batch)
 	if err != nil {
-		return fmt.Errorf("remove records for different connections states error: %w", err)
+		return fmt.Errorf("unable to delete connection record with namespace mappings: %w", err)
 	}
 
-	err = c.store.Delete(getConnectionKeyPrefix()(connectionID))
+	transientBatch := storage.NewBatch(c.transientStore)
+	transientBatch.Delete(getConnectionKeyPrefix()(connectionID))
+
+	// remove connection records for different states from transient store
+	err = removeConnectionsForStates(c, connectionID, transientBatch)
 	if err != nil {
-		return fmt.Errorf("unable to delete connection record from the store: connectionid=%s err=%w", connectionID, err)
+		return fmt.Errorf("remove records for different connections states error: %w", err)
 	}
 
-	err = c.store.Delete(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID))
-	if err != nil {
-		return fmt.Errorf("unable to delete did mapping connection record from the store: connectionid=%s err=%w",
+	if err = transientBatch.Commit(); err != nil {
+		return fmt.Errorf("unable to delete connection records from the transient store: connectionid=%s err=%w",
 			connectionID, err)
 	}
 
-	// remove namespace, threadID and connection ID mapping from transient store
-	err = removeMappings(c, record)
-	if err != nil {
-		return fmt.Errorf("unable to delete connection record with namespace mappings: %w", err)
+	if err = batch.Commit(); err != nil {
+		return fmt.Errorf("unable to delete connection records from the store: connectionid=%s err=%w",
+			connectionID, err)
 	}
 
 	return nil
@@ -200,7 +234,8 @@ func computeHash(bytes []byte) (string, error) {
 	return fmt.Sprintf("%x", hash), nil
 }
 
-func removeConnectionsForStates(c *Recorder, connectionID string) error {
+// removeConnectionsForStates adds the deletion of the connection state records to the batch
+func removeConnectionsForStates(c *Recorder, connectionID string, batch storage.Batch) error {
 	itr := c.transientStore.Iterator(getConnectionStateKeyPrefix()(
 		connectionID),
 		getConnectionStateKeyPrefix()(connectionID)+storage.EndKeySuffix,
@@ -208,24 +243,20 @@ func removeConnectionsForStates(c *Recorder, connectionID string) error {
 	defer itr.Release()
 
 	for itr.Next() {
-		key := string(itr.Key())
-		err := c.transientStore.Delete(key)
-
-		if err != nil {
-			return fmt.Errorf(
-				"unable to delete connection state record from the transient store: key=%s connectionid=%s err=%w",
-				key, connectionID, err)
-		}
+		batch.Delete(string(itr.Key()))
 	}
 
 	return itr.Error()
 }
 
-func removeMappings(c *Recorder, record *Record) error {
+// removeMappings adds the deletion of the namespace, threadID and connection ID mapping to the batch
+func removeMappings(c *Recorder, record *Record, batch storage.Batch) error {
 	key, err := computeHash([]byte(record.ThreadID))
 	if err != nil {
 		return fmt.Errorf("compute hash: %w", err)
 	}
 
-	return c.store.Delete(getNamespaceKeyPrefix(record.Namespace)(key))
+	batch.Delete(getNamespaceKeyPrefix(record.Namespace)(key))
+
+	return nil
 }
@@ -81,8 +81,11 @@ func Test_RemoveMappings(t *testing.T) {
 			ThreadID: threadIDValue,
 		}
 
-		err = removeMappings(recorder, record)
+		batch := storage.NewBatch(recorder.store)
+
+		err = removeMappings(recorder, record, batch)
 		require.NoError(t, err)
+		require.NoError(t, batch.Commit())
 	})
 	t.Run("test failed - empty bytes", func(t *testing.T) {
 		recorder, err := NewRecorder(&protocol.MockProvider{})
@@ -93,7 +96,7 @@ func Test_RemoveMappings(t *testing.T) {
 			ThreadID: "",
 		}
 
-		err = removeMappings(recorder, record)
+		err = removeMappings(recorder, record, storage.NewBatch(recorder.store))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "empty bytes")
 	})
@@ -112,7 +115,12 @@ func Test_RemoveMappings(t *testing.T) {
 			ThreadID: threadIDValue,
 		}
 
-		err = removeMappings(recorder, record)
+		batch := storage.NewBatch(recorder.store)
+
+		err = removeMappings(recorder, record, batch)
+		require.NoError(t, err)
+
+		err = batch.Commit()
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errMsg)
 	})
@@ -143,8 +151,12 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, recorder)
 
-		err = removeConnectionsForStates(recorder, record.ConnectionID)
+		batch := storage.NewBatch(recorder.transientStore)
+
+		err = removeConnectionsForStates(recorder, record.ConnectionID, batch)
 		require.NoError(t, err)
+		require.NoError(t, batch.Commit())
+		require.Empty(t, store.Store)
 	})
 	t.Run("test failed to iterate connection state records", func(t *testing.T) {
 		const errMsg = "get error"
@@ -160,7 +172,7 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, recorder)
 
-		err = removeConnectionsForStates(recorder, "anyID")
+		err = removeConnectionsForStates(recorder, "anyID", storage.NewBatch(recorder.transientStore))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errMsg)
 	})
@@ -190,7 +202,12 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, recorder)
 
-		err = removeConnectionsForStates(recorder, record.ConnectionID)
+		batch := storage.NewBatch(recorder.transientStore)
+
+		err = removeConnectionsForStates(recorder, record.ConnectionID, batch)
+		require.NoError(t, err)
+
+		err = batch.Commit()
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errMsg)
 	})
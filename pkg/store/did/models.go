@@ -11,3 +11,10 @@ type Record struct {
 	Name string `json:"name,omitempty"`
 	ID   string `json:"id,omitempty"`
 }
+
+// RecordChange is a change of a DID record
+type RecordChange struct {
+	Record *Record
+	// Deleted is set when the record was deleted, its ID is empty then
+	Deleted bool
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/internal/watch"
 )
 
 const (
@@ -126,6 +127,29 @@ func (s *Store) GetDIDRecords() []*Record {
 	return records
 }
 
+// WatchDIDs returns a channel receiving the changes of the DID records from now on, and a function to stop
+// watching which closes the channel. It fails if the underlying store doesn't implement storage.WatchableStore.
+func (s *Store) WatchDIDs() (<-chan *RecordChange, func(), error) {
+	changes := make(chan *RecordChange)
+
+	stop, err := watch.Watch(didNameKey, func(_ storage.Store, change storage.Change, done <-chan struct{}) bool {
+		record := &RecordChange{Record: &Record{Name: getDIDName(change.Key), ID: string(change.Value)},
+			Deleted: change.Deleted}
+
+		select {
+		case changes <- record:
+			return true
+		case <-done:
+			return false
+		}
+	}, func() { close(changes) }, s.store)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch did store: %w", err)
+	}
+
+	return changes, stop, nil
+}
+
 func didNameDataKey(name string) string {
 	return fmt.Sprintf(didNameKeyPattern, name)
 }
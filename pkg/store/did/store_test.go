@@ -8,6 +8,7 @@ package did
 import (
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -20,6 +21,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 const sampleDIDName = "sampleDIDName"
@@ -266,3 +269,86 @@ func createDIDDocWithKey(pub string) *did.Doc {
 
 	return didDoc
 }
+
+func TestWatchDIDs(t *testing.T) {
+	t.Run("test saved dids are received", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mem.NewProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchDIDs()
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, s.SaveDID(sampleDIDName, &did.Doc{ID: sampleDIDID}))
+
+		select {
+		case record := <-records:
+			require.Equal(t, &RecordChange{Record: &Record{Name: sampleDIDName, ID: sampleDIDID}}, record)
+		case <-time.After(time.Second):
+			require.Fail(t, "did record not received")
+		}
+	})
+
+	t.Run("test deleted dids are received", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: provider,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, s.SaveDID(sampleDIDName, &did.Doc{ID: sampleDIDID}))
+
+		records, stop, err := s.WatchDIDs()
+		require.NoError(t, err)
+
+		defer stop()
+
+		store, err := provider.OpenStore(NameSpace)
+		require.NoError(t, err)
+		require.NoError(t, store.Delete(didNameDataKey(sampleDIDName)))
+
+		select {
+		case record := <-records:
+			require.Equal(t, &RecordChange{Record: &Record{Name: sampleDIDName}, Deleted: true}, record)
+		case <-time.After(time.Second):
+			require.Fail(t, "did record deletion not received")
+		}
+	})
+
+	t.Run("test stop closes the channel", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mem.NewProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchDIDs()
+		require.NoError(t, err)
+
+		// the record isn't read before stopping
+		require.NoError(t, s.SaveDID(sampleDIDName, &did.Doc{ID: sampleDIDID}))
+
+		stop()
+		stop()
+
+		for range records {
+			// the record being delivered when stopping may still be received
+		}
+	})
+
+	t.Run("test error from store not supporting watch", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchDIDs()
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrWatchNotSupported))
+		require.Nil(t, records)
+		require.Nil(t, stop)
+	})
+}
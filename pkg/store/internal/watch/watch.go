@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package watch forwards the changes of the records of storage.WatchableStore stores to the typed channels of the
+// framework stores.
+package watch
+
+import (
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// SendFunc delivers a change of the records of store, it returns false if done was closed before the change could be
+// delivered.
+type SendFunc func(store storage.Store, change storage.Change, done <-chan struct{}) bool
+
+// Watch watches the records whose key starts with prefix in the given stores, passing every change to send from one
+// goroutine per store until the returned function is called. closeFn is called once no more change is sent, e.g. to
+// close the channel send delivers the changes to. It fails if one of the stores doesn't implement
+// storage.WatchableStore.
+func Watch(prefix string, send SendFunc, closeFn func(), stores ...storage.Store) (func(), error) {
+	done := make(chan struct{})
+
+	var (
+		wg    sync.WaitGroup
+		stops []func()
+		once  sync.Once
+	)
+
+	stop := func() {
+		once.Do(func() {
+			close(done)
+
+			for _, s := range stops {
+				s()
+			}
+		})
+	}
+
+	for _, store := range stores {
+		changes, stopStore, err := storage.Watch(store, prefix)
+		if err != nil {
+			stop()
+
+			return nil, err
+		}
+
+		stops = append(stops, stopStore)
+
+		wg.Add(1)
+
+		go func(store storage.Store) {
+			defer wg.Done()
+
+			for change := range changes {
+				if !send(store, change, done) {
+					return
+				}
+			}
+		}(store)
+	}
+
+	go func() {
+		wg.Wait()
+		closeFn()
+	}()
+
+	return stop, nil
+}
@@ -11,3 +11,10 @@ type CredentialRecord struct {
 	Name string `json:"name,omitempty"`
 	ID   string `json:"id,omitempty"`
 }
+
+// CredentialChange is a change of a credential record
+type CredentialChange struct {
+	Record *CredentialRecord
+	// Deleted is set when the record was deleted, its ID is empty then
+	Deleted bool
+}
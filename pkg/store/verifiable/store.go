@@ -12,6 +12,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/internal/watch"
 )
 
 const (
@@ -124,6 +125,31 @@ func (s *Store) GetCredentials() []*CredentialRecord {
 	return records
 }
 
+// WatchCredentials returns a channel receiving the changes of the credential records from now on, and a function to
+// stop watching which closes the channel. It fails if the underlying store doesn't implement storage.WatchableStore.
+func (s *Store) WatchCredentials() (<-chan *CredentialChange, func(), error) {
+	changes := make(chan *CredentialChange)
+
+	stop, err := watch.Watch(credentialNameKey, func(_ storage.Store, change storage.Change, done <-chan struct{}) bool {
+		record := &CredentialChange{
+			Record:  &CredentialRecord{Name: getCredentialName(change.Key), ID: string(change.Value)},
+			Deleted: change.Deleted,
+		}
+
+		select {
+		case changes <- record:
+			return true
+		case <-done:
+			return false
+		}
+	}, func() { close(changes) }, s.store)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch vc store: %w", err)
+	}
+
+	return changes, stop, nil
+}
+
 func credentialNameDataKey(name string) string {
 	return fmt.Sprintf(credentialNameDataKeyPattern, name)
 }
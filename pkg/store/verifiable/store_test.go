@@ -7,9 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package verifiable
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 
@@ -17,6 +19,8 @@ import (
 
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/internal/mock/provider"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 const sampleCredentialName = "sampleVCName"
@@ -289,3 +293,88 @@ func TestGetCredentials(t *testing.T) {
 		require.Equal(t, 1+n, len(records))
 	})
 }
+
+func TestWatchCredentials(t *testing.T) {
+	t.Run("test saved credentials are received", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mem.NewProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchCredentials()
+		require.NoError(t, err)
+
+		defer stop()
+
+		require.NoError(t, s.SaveCredential(sampleCredentialName, &verifiable.Credential{ID: sampleCredentialID}))
+
+		select {
+		case record := <-records:
+			require.Equal(t, &CredentialChange{
+				Record: &CredentialRecord{Name: sampleCredentialName, ID: sampleCredentialID},
+			}, record)
+		case <-time.After(time.Second):
+			require.Fail(t, "credential record not received")
+		}
+	})
+
+	t.Run("test deleted credentials are received", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: provider,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, s.SaveCredential(sampleCredentialName, &verifiable.Credential{ID: sampleCredentialID}))
+
+		records, stop, err := s.WatchCredentials()
+		require.NoError(t, err)
+
+		defer stop()
+
+		store, err := provider.OpenStore(NameSpace)
+		require.NoError(t, err)
+		require.NoError(t, store.Delete(credentialNameDataKey(sampleCredentialName)))
+
+		select {
+		case record := <-records:
+			require.Equal(t, &CredentialChange{Record: &CredentialRecord{Name: sampleCredentialName}, Deleted: true}, record)
+		case <-time.After(time.Second):
+			require.Fail(t, "credential record deletion not received")
+		}
+	})
+
+	t.Run("test stop closes the channel", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mem.NewProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchCredentials()
+		require.NoError(t, err)
+
+		// the record isn't read before stopping
+		require.NoError(t, s.SaveCredential(sampleCredentialName, &verifiable.Credential{ID: sampleCredentialID}))
+
+		stop()
+		stop()
+
+		for range records {
+			// the record being delivered when stopping may still be received
+		}
+	})
+
+	t.Run("test error from store not supporting watch", func(t *testing.T) {
+		s, err := New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+		})
+		require.NoError(t, err)
+
+		records, stop, err := s.WatchCredentials()
+		require.Error(t, err)
+		require.True(t, errors.Is(err, storage.ErrWatchNotSupported))
+		require.Nil(t, records)
+		require.Nil(t, stop)
+	})
+}